@@ -24,6 +24,7 @@ import (
 	"gnd.la/app/cookies"
 	"gnd.la/app/profile"
 	"gnd.la/blobstore"
+	"gnd.la/config"
 	"gnd.la/crypto/cryptoutil"
 	"gnd.la/crypto/hashutil"
 	"gnd.la/encoding/codec"
@@ -184,6 +185,7 @@ type App struct {
 	languageHandler    LanguageHandler
 	name               string
 	userFunc           UserFunc
+	permissionFunc     PermissionFunc
 	assetsManager      *assets.Manager
 	templatesFS        vfs.VFS
 	templatesMutex     sync.RWMutex
@@ -880,7 +882,11 @@ func (app *App) openOrm() (*orm.Orm, error) {
 	if db == nil {
 		return nil, errNoDefaultDatabase
 	}
-	o, err := orm.New(db)
+	var replicas []*config.URL
+	for ii := range app.cfg.DatabaseReplicas {
+		replicas = append(replicas, &app.cfg.DatabaseReplicas[ii])
+	}
+	o, err := orm.NewWithReplicas(db, replicas)
 	if err != nil {
 		return nil, err
 	}
@@ -1398,6 +1404,7 @@ func (app *App) Prepare() error {
 		child.Cipherer = app.Cipherer
 		child.languageHandler = app.languageHandler
 		child.userFunc = app.userFunc
+		child.permissionFunc = app.permissionFunc
 		child.Logger = app.Logger
 	}
 	// Add hooks from each included app to all the other apps
@@ -20,10 +20,13 @@ type Config struct {
 	// or when it returns an empty string.
 	Language string `help:"Set the default language for translating strings"`
 	// Port indicates the port to listen on.
-	Port      int         `default:"8888" help:"Port to listen on"`
-	Database  *config.URL `help:"Default database to use, used by Context.Orm()"`
-	Cache     *config.URL `help:"Default cache, returned by Context.Cache()"`
-	Blobstore *config.URL `help:"Default blobstore, returned by Context.Blobstore()"`
+	Port     int         `default:"8888" help:"Port to listen on"`
+	Database *config.URL `help:"Default database to use, used by Context.Orm()"`
+	// DatabaseReplicas indicates the read replicas for the default
+	// database. See gnd.la/orm.NewWithReplicas for more details.
+	DatabaseReplicas []config.URL `help:"Read replicas for the default database"`
+	Cache            *config.URL  `help:"Default cache, returned by Context.Cache()"`
+	Blobstore        *config.URL  `help:"Default blobstore, returned by Context.Blobstore()"`
 	// Secret indicates the secret associated with the app,
 	// which is used for signed cookies. It should be a
 	// random string with at least 32 characters.
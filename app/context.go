@@ -19,6 +19,7 @@ import (
 	"gnd.la/internal"
 	"gnd.la/log"
 	"gnd.la/net/urlutil"
+	"gnd.la/util/stringutil"
 	"gnd.la/util/types"
 )
 
@@ -49,6 +50,9 @@ type Context struct {
 	background      bool
 	wg              *sync.WaitGroup
 	values          map[string]interface{}
+	o               *Orm
+	requestID       string
+	logFields       []log.Field
 }
 
 func (c *Context) reset() {
@@ -61,6 +65,9 @@ func (c *Context) reset() {
 	c.translations = nil
 	c.hasTranslations = false
 	c.values = nil
+	c.o = nil
+	c.requestID = ""
+	c.logFields = nil
 }
 
 // Count returns the number of elements captured
@@ -370,9 +377,24 @@ func (c *Context) Blobstore() *blobstore.Blobstore {
 // Orm is a shorthand for ctx.App().Orm(), but panics in case
 // of error, rather than returning it.
 func (c *Context) Orm() *Orm {
+	if c.o != nil {
+		return c.o
+	}
 	return c.orm()
 }
 
+// ReadYourWrites makes the ORM returned by Orm send its reads to
+// the primary database once it has written to it, so the rest of
+// the request sees its own writes even if the database uses read
+// replicas with replication lag. It has no effect when the App
+// has no database replicas. See gnd.la/orm.Orm.ReadYourWrites for
+// more details.
+func (c *Context) ReadYourWrites() {
+	if c.o == nil {
+		c.o = &Orm{Orm: c.orm().ReadYourWrites()}
+	}
+}
+
 // Execute loads the template with the given name using the
 // App template loader and executes it with the data argument.
 func (c *Context) Execute(name string, data interface{}) error {
@@ -467,6 +489,9 @@ func (c *Context) backgroundContext() *Context {
 	ctx.background = true
 	ctx.provider = c.provider
 	ctx.reProvider = c.reProvider
+	ctx.handlerName = c.handlerName
+	ctx.requestID = c.RequestID()
+	ctx.logFields = append([]log.Field(nil), c.logFields...)
 	ctx.ResponseWriter = discard
 	return ctx
 }
@@ -567,10 +592,55 @@ func (c *Context) Set(key string, value interface{}) {
 // is disabled, so it's safe to call any gnd.la/log.Interface methods
 // unconditionally (i.e. don't check if the returned value is nil, it'll
 // never be).
+//
+// When the Context is serving a request, the returned logger includes
+// the fields request_id, method, path and handler (the latter only for
+// named handlers), followed by any fields added with AddLogField.
 func (c *Context) Logger() log.Interface {
 	return c.logger()
 }
 
+// AddLogField adds a field which will be included in all the messages
+// logged using the Logger returned by subsequent calls to Logger.
+func (c *Context) AddLogField(key string, value interface{}) {
+	c.logFields = append(c.logFields, log.Field{Key: key, Value: value})
+}
+
+// RequestID returns an identifier for the request being served by
+// the Context, which is included in the messages logged with Logger.
+// If the App trusts X headers (see App.SetTrustXHeaders) and the
+// request includes a X-Request-Id header, its value is used. Otherwise,
+// a random identifier is generated. For Contexts not serving a request,
+// an empty string is returned.
+func (c *Context) RequestID() string {
+	if c.requestID == "" && c.R != nil {
+		if c.app.trustXHeaders {
+			c.requestID = c.R.Header.Get("X-Request-Id")
+		}
+		if c.requestID == "" {
+			c.requestID = stringutil.Random(16)
+		}
+	}
+	return c.requestID
+}
+
+// loggerFields returns the fields which should be attached to
+// the messages logged by this Context. See Logger.
+func (c *Context) loggerFields() []log.Field {
+	var fields []log.Field
+	if c.R != nil {
+		fields = append(fields,
+			log.Field{Key: "request_id", Value: c.RequestID()},
+			log.Field{Key: "method", Value: c.R.Method},
+			log.Field{Key: "path", Value: c.R.URL.Path},
+		)
+		if c.handlerName != "" {
+			fields = append(fields, log.Field{Key: "handler", Value: c.handlerName})
+		}
+	}
+	return append(fields, c.logFields...)
+}
+
 // Intercept http.ResponseWriter calls to find response
 // status code
 
@@ -1,5 +1,9 @@
 package app
 
+import (
+	"gnd.la/log"
+)
+
 // nullLogger logs everything to /dev/null
 type nullLogger struct {
 }
@@ -15,3 +19,5 @@ func (n nullLogger) Warningf(format string, args ...interface{}) {}
 
 func (n nullLogger) Error(args ...interface{})                 {}
 func (n nullLogger) Errorf(format string, args ...interface{}) {}
+
+func (n nullLogger) With(key string, value interface{}) log.Interface { return n }
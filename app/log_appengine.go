@@ -3,6 +3,7 @@
 package app
 
 import (
+	"bytes"
 	"fmt"
 
 	"gnd.la/internal"
@@ -13,20 +14,48 @@ import (
 
 // gaeLoggger logs using the GAE logging APIs
 type gaeLogger struct {
-	c appengine.Context
+	c      appengine.Context
+	fields []log.Field
 }
 
-func (g *gaeLogger) Debug(args ...interface{})                 { g.c.Debugf("%s", fmt.Sprint(args...)) }
-func (g *gaeLogger) Debugf(format string, args ...interface{}) { g.c.Debugf(format, args...) }
+// format appends the logger fields, if any, to
+// the given message.
+func (g *gaeLogger) format(s string) string {
+	if len(g.fields) == 0 {
+		return s
+	}
+	var buf bytes.Buffer
+	buf.WriteString(s)
+	for _, v := range g.fields {
+		fmt.Fprintf(&buf, " %s=%v", v.Key, v.Value)
+	}
+	return buf.String()
+}
+
+func (g *gaeLogger) Debug(args ...interface{}) { g.c.Debugf("%s", g.format(fmt.Sprint(args...))) }
+func (g *gaeLogger) Debugf(format string, args ...interface{}) {
+	g.c.Debugf("%s", g.format(fmt.Sprintf(format, args...)))
+}
 
-func (g *gaeLogger) Info(args ...interface{})                 { g.c.Infof("%s", fmt.Sprint(args...)) }
-func (g *gaeLogger) Infof(format string, args ...interface{}) { g.c.Infof(format, args...) }
+func (g *gaeLogger) Info(args ...interface{}) { g.c.Infof("%s", g.format(fmt.Sprint(args...))) }
+func (g *gaeLogger) Infof(format string, args ...interface{}) {
+	g.c.Infof("%s", g.format(fmt.Sprintf(format, args...)))
+}
 
-func (g *gaeLogger) Warning(args ...interface{})                 { g.c.Warningf("%s", fmt.Sprint(args...)) }
-func (g *gaeLogger) Warningf(format string, args ...interface{}) { g.c.Warningf(format, args...) }
+func (g *gaeLogger) Warning(args ...interface{}) { g.c.Warningf("%s", g.format(fmt.Sprint(args...))) }
+func (g *gaeLogger) Warningf(format string, args ...interface{}) {
+	g.c.Warningf("%s", g.format(fmt.Sprintf(format, args...)))
+}
 
-func (g *gaeLogger) Error(args ...interface{})                 { g.c.Errorf("%s", fmt.Sprint(args...)) }
-func (g *gaeLogger) Errorf(format string, args ...interface{}) { g.c.Errorf(format, args...) }
+func (g *gaeLogger) Error(args ...interface{}) { g.c.Errorf("%s", g.format(fmt.Sprint(args...))) }
+func (g *gaeLogger) Errorf(format string, args ...interface{}) {
+	g.c.Errorf("%s", g.format(fmt.Sprintf(format, args...)))
+}
+
+func (g *gaeLogger) With(key string, value interface{}) log.Interface {
+	fields := append([]log.Field(nil), g.fields...)
+	return &gaeLogger{c: g.c, fields: append(fields, log.Field{Key: key, Value: value})}
+}
 
 func (c *Context) logger() log.Interface {
 	if c.R == nil {
@@ -38,7 +67,7 @@ func (c *Context) logger() log.Interface {
 		if c.app.Logger == nil {
 			return nullLogger{}
 		}
-		return c.app.Logger
+		return c.app.Logger.WithFields(c.loggerFields()...)
 	}
-	return &gaeLogger{c: appengine.NewContext(c.R)}
+	return &gaeLogger{c: appengine.NewContext(c.R), fields: c.loggerFields()}
 }
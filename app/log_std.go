@@ -8,5 +8,8 @@ func (c *Context) logger() log.Interface {
 	if c.app.Logger == nil {
 		return nullLogger{}
 	}
+	if fields := c.loggerFields(); len(fields) > 0 {
+		return c.app.Logger.WithFields(fields...)
+	}
 	return c.app.Logger
 }
@@ -0,0 +1,98 @@
+package app_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/config"
+	"gnd.la/orm"
+	"gnd.la/orm/driver"
+	_ "gnd.la/orm/driver/memory"
+	"gnd.la/orm/index"
+	"gnd.la/util/stringutil"
+)
+
+type replicatedObject struct {
+	Id    int64 `orm:",primary_key,auto_increment"`
+	Value string
+}
+
+func init() {
+	orm.Register(&replicatedObject{}, &orm.Options{
+		Table: "app_test_replicated",
+	})
+}
+
+// tableModel adapts an orm.Table to driver.Model, so its table
+// can be created directly in the replica, which doesn't receive
+// anything from the primary.
+type tableModel struct {
+	table *orm.Table
+}
+
+func (m tableModel) Type() reflect.Type                             { return m.table.Type() }
+func (m tableModel) Table() string                                  { return m.table.TableName() }
+func (m tableModel) Fields() *driver.Fields                         { return m.table.Fields() }
+func (m tableModel) Indexes() []*index.Index                        { return nil }
+func (m tableModel) Map(qname string) (string, reflect.Type, error) { return qname, nil, nil }
+func (m tableModel) Skip() bool                                     { return false }
+func (m tableModel) Join() driver.Join                              { return nil }
+
+var (
+	rywApp  *app.App
+	rywOnce sync.Once
+)
+
+// newReadYourWritesApp returns an App with a read replica, which
+// can only be prepared once, since it registers the ORM models.
+func newReadYourWritesApp(t *testing.T) *app.App {
+	rywOnce.Do(func() {
+		a := app.New()
+		a.Logger = nil
+		a.Config().Database = config.MustParseURL("memory://app-test-primary")
+		a.Config().DatabaseReplicas = []config.URL{*config.MustParseURL("memory://app-test-replica")}
+		a.Handle("^/write/$", func(ctx *app.Context) {
+			if ctx.FormValue("ryw") != "" {
+				ctx.ReadYourWrites()
+			}
+			// Insert an object and check if it can be read back
+			value := stringutil.Random(16)
+			ctx.Orm().MustInsert(&replicatedObject{Value: value})
+			var objs []*replicatedObject
+			ctx.Orm().Query(orm.Eq("Value", value)).MustAll(&objs)
+			fmt.Fprintf(ctx, "%d", len(objs))
+		})
+		if err := a.Prepare(); err != nil {
+			t.Fatal(err)
+		}
+		replica, err := driver.Get("memory")(&a.Config().DatabaseReplicas[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer replica.Close()
+		o, err := a.Orm()
+		if err != nil {
+			t.Fatal(err)
+		}
+		table := o.TypeTable(reflect.TypeOf(replicatedObject{}))
+		if err := replica.Initialize([]driver.Model{tableModel{table}}); err != nil {
+			t.Fatal(err)
+		}
+		rywApp = a
+	})
+	return rywApp
+}
+
+func TestReadYourWrites(t *testing.T) {
+	tt := tester.New(t, newReadYourWritesApp(t))
+	// Reads go to the replica, which doesn't see the write
+	tt.Get("/write/", nil).Expect("0")
+	// Unless the request needs to read its own writes
+	tt.Get("/write/", map[string]interface{}{"ryw": 1}).Expect("1")
+	// Which doesn't affect the next request
+	tt.Get("/write/", nil).Expect("0")
+}
@@ -0,0 +1,66 @@
+package app
+
+// PermissionFunc is called by Context.Can to check if the given user
+// has the given permission. If the permission is being checked on
+// a specific object, it's passed as the object parameter. Otherwise,
+// object is nil. Note that user is never nil, since Context.Can
+// returns false without calling the PermissionFunc when there's no
+// signed in user. See gnd.la/app/rbac for an implementation which
+// stores roles and permissions in the ORM.
+type PermissionFunc func(ctx *Context, user User, permission string, object interface{}) bool
+
+// PermissionFunc returns the function used for checking
+// permissions in this App. See SetPermissionFunc.
+func (app *App) PermissionFunc() PermissionFunc {
+	return app.permissionFunc
+}
+
+// SetPermissionFunc sets the function used for checking permissions
+// in this App and all its included apps. If no PermissionFunc is
+// set, only the administrators have permissions.
+func (app *App) SetPermissionFunc(f PermissionFunc) {
+	app.permissionFunc = f
+	for _, v := range app.included {
+		v.app.permissionFunc = f
+	}
+}
+
+// Can returns true iff the current user has the given permission,
+// optionally on the given object (which might be nil). If there's
+// no signed in user, it returns false. If the App has no
+// PermissionFunc, only the administrators have permissions.
+func (c *Context) Can(permission string, object interface{}) bool {
+	user := c.User()
+	if user == nil {
+		return false
+	}
+	if c.app.permissionFunc == nil {
+		return user.IsAdmin()
+	}
+	return c.app.permissionFunc(c, user, permission, object)
+}
+
+// Permission returns a Transformer which requires the current user to
+// have the given permission. If there's no signed in user, it works like
+// SignedIn. If the user lacks the permission, it responds with a 403
+// error. Permissions on specific objects must be checked by the handler
+// itself, using Context.Can.
+func Permission(permission string) Transformer {
+	return func(handler Handler) Handler {
+		return SignedIn(func(ctx *Context) {
+			if !ctx.Can(permission, nil) {
+				ctx.Forbidden("")
+				return
+			}
+			handler(ctx)
+		})
+	}
+}
+
+func template_can(ctx *Context, permission string, object ...interface{}) bool {
+	var obj interface{}
+	if len(object) > 0 {
+		obj = object[0]
+	}
+	return ctx.Can(permission, obj)
+}
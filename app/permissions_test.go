@@ -0,0 +1,73 @@
+package app_test
+
+import (
+	"fmt"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+)
+
+type permUser int64
+
+func (u permUser) Id() int64     { return int64(u) }
+func (u permUser) IsAdmin() bool { return u == 1 }
+
+func TestPermissions(t *testing.T) {
+	a := app.New()
+	a.Config().Secret = "fe4d4e8a5a8a4b4e9c8d2f7a1b6c3e5d9a0b1c2d"
+	a.SetUserFunc(func(ctx *app.Context, id int64) app.User {
+		return permUser(id)
+	})
+	// Sign in the user from the u parameter before
+	// checking the permissions.
+	signIn := func(handler app.Handler) app.Handler {
+		return func(ctx *app.Context) {
+			var id int64
+			if ctx.ParseFormValue("u", &id) && id > 0 {
+				ctx.MustSignIn(permUser(id))
+			}
+			handler(ctx)
+		}
+	}
+	a.HandleNamed("^/sign-in/$", func(ctx *app.Context) {}, "sign-in")
+	a.Handle("^/can/$", signIn(func(ctx *app.Context) {
+		fmt.Fprintf(ctx, "%v", ctx.Can(ctx.FormValue("p"), nil))
+	}))
+	a.Handle("^/edit/$", signIn(app.Permission("edit")(func(ctx *app.Context) {
+		ctx.WriteString("ok")
+	})))
+	tt := tester.New(t, a)
+	// Without a PermissionFunc, only admins have permissions
+	tt.Get("/can/", map[string]interface{}{"p": "edit"}).Expect("false")
+	tt.Get("/can/", map[string]interface{}{"p": "edit", "u": 2}).Expect("false")
+	tt.Get("/can/", map[string]interface{}{"p": "edit", "u": 1}).Expect("true")
+	a.SetPermissionFunc(func(ctx *app.Context, user app.User, permission string, object interface{}) bool {
+		return user.Id() == 2 && permission == "edit"
+	})
+	tt.Get("/can/", map[string]interface{}{"p": "edit", "u": 2}).Expect("true")
+	tt.Get("/can/", map[string]interface{}{"p": "delete", "u": 2}).Expect("false")
+	tt.Get("/can/", map[string]interface{}{"p": "edit", "u": 1}).Expect("false")
+	tt.Client().Get("/edit/", nil).Expect(302)
+	tt.Get("/edit/", map[string]interface{}{"u": 3}).Expect(403)
+	tt.Get("/edit/", map[string]interface{}{"u": 2}).Expect("ok")
+}
+
+func TestPermissionIncluded(t *testing.T) {
+	a := app.New()
+	a.Config().Secret = "fe4d4e8a5a8a4b4e9c8d2f7a1b6c3e5d9a0b1c2d"
+	a.SetUserFunc(func(ctx *app.Context, id int64) app.User {
+		return permUser(id)
+	})
+	a.HandleNamed("^/sign-in/$", func(ctx *app.Context) {}, "sign-in")
+	child := app.New()
+	child.SetName("Child")
+	child.Handle("^/edit/$", app.Permission("edit")(func(ctx *app.Context) {
+		ctx.WriteString("ok")
+	}))
+	a.Include("/child/", child, "")
+	tt := tester.New(t, a)
+	// The sign in handler must be found from the
+	// included app.
+	tt.Get("/child/edit/", nil).Expect(302).ExpectHeader("Location", "/sign-in/?from=http%3A%2F%2Flocalhost%2Fchild%2Fedit%2F")
+}
@@ -0,0 +1,146 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"gnd.la/app"
+	"gnd.la/commands"
+)
+
+func permissionsCommand(ctx *app.Context) {
+	for _, v := range Permissions() {
+		fmt.Printf("%s\t%s\n", v.Name, v.Description)
+	}
+}
+
+func rolesCommand(ctx *app.Context) {
+	roles, err := Roles(ctx)
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range roles {
+		fmt.Printf("%s\t%s\t%s\n", v.Name, strings.Join(v.Permissions, " "), v.Description)
+	}
+}
+
+func saveRoleCommand(ctx *app.Context) {
+	role := &Role{Name: ctx.RequireIndexValue(0)}
+	for ii := 1; ii < ctx.Count(); ii++ {
+		role.Permissions = append(role.Permissions, ctx.IndexValue(ii))
+	}
+	if len(role.Permissions) == 0 {
+		commands.UsageError("no permissions provided")
+	}
+	ctx.ParseParamValue("description", &role.Description)
+	if err := SaveRole(ctx, role); err != nil {
+		commands.Error(err)
+	}
+}
+
+func deleteRoleCommand(ctx *app.Context) {
+	if err := DeleteRole(ctx, ctx.RequireIndexValue(0)); err != nil {
+		commands.Error(err)
+	}
+}
+
+func userRolesCommand(ctx *app.Context) {
+	var userId int64
+	ctx.MustParseIndexValue(0, &userId)
+	roles, err := UserRoles(ctx, userId)
+	if err != nil {
+		panic(err)
+	}
+	perms, err := UserPermissions(ctx, userId)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("roles: %s\npermissions: %s\n", strings.Join(roles, " "), strings.Join(perms, " "))
+}
+
+func assignCommand(ctx *app.Context) {
+	var userId int64
+	ctx.MustParseIndexValue(0, &userId)
+	if err := AssignRole(ctx, userId, ctx.RequireIndexValue(1)); err != nil {
+		commands.Error(err)
+	}
+}
+
+func unassignCommand(ctx *app.Context) {
+	var userId int64
+	ctx.MustParseIndexValue(0, &userId)
+	if err := UnassignRole(ctx, userId, ctx.RequireIndexValue(1)); err != nil {
+		commands.Error(err)
+	}
+}
+
+func grantCommand(ctx *app.Context) {
+	var userId int64
+	ctx.MustParseIndexValue(0, &userId)
+	var obj interface{}
+	if o := ctx.IndexValue(2); o != "" {
+		obj = o
+	}
+	if err := GrantPermission(ctx, userId, ctx.RequireIndexValue(1), obj); err != nil {
+		commands.Error(err)
+	}
+}
+
+func revokeCommand(ctx *app.Context) {
+	var userId int64
+	ctx.MustParseIndexValue(0, &userId)
+	var obj interface{}
+	if o := ctx.IndexValue(2); o != "" {
+		obj = o
+	}
+	if err := RevokePermission(ctx, userId, ctx.RequireIndexValue(1), obj); err != nil {
+		commands.Error(err)
+	}
+}
+
+func init() {
+	commands.MustRegister(permissionsCommand, &commands.Options{
+		Name: "rbac-permissions",
+		Help: "List the registered permissions",
+	})
+	commands.MustRegister(rolesCommand, &commands.Options{
+		Name: "rbac-roles",
+		Help: "List the roles and their permissions",
+	})
+	commands.MustRegister(saveRoleCommand, &commands.Options{
+		Name:  "rbac-save-role",
+		Help:  "Create or update a role, replacing its permissions",
+		Usage: "<role> <permission>...",
+		Flags: commands.Flags(commands.StringFlag("description", "", "Role description")),
+	})
+	commands.MustRegister(deleteRoleCommand, &commands.Options{
+		Name:  "rbac-delete-role",
+		Help:  "Delete a role and remove it from all the users",
+		Usage: "<role>",
+	})
+	commands.MustRegister(userRolesCommand, &commands.Options{
+		Name:  "rbac-user",
+		Help:  "Show the roles and permissions of a user",
+		Usage: "<user-id>",
+	})
+	commands.MustRegister(assignCommand, &commands.Options{
+		Name:  "rbac-assign",
+		Help:  "Assign a role to a user",
+		Usage: "<user-id> <role>",
+	})
+	commands.MustRegister(unassignCommand, &commands.Options{
+		Name:  "rbac-unassign",
+		Help:  "Remove a role from a user",
+		Usage: "<user-id> <role>",
+	})
+	commands.MustRegister(grantCommand, &commands.Options{
+		Name:  "rbac-grant",
+		Help:  "Grant a permission to a user, optionally on a single object",
+		Usage: "<user-id> <permission> [object]",
+	})
+	commands.MustRegister(revokeCommand, &commands.Options{
+		Name:  "rbac-revoke",
+		Help:  "Revoke a permission granted to a user",
+		Usage: "<user-id> <permission> [object]",
+	})
+}
@@ -0,0 +1,228 @@
+package rbac
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/orm"
+)
+
+var (
+	userRoleType = reflect.TypeOf(UserRole{})
+)
+
+// Role is a named set of permissions which can be
+// assigned to users.
+type Role struct {
+	Name        string `orm:",primary_key"`
+	Description string `orm:",omitempty,nullempty"`
+	// Permissions are the permission names included in
+	// this role, which might contain wildcards.
+	Permissions []string `orm:",codec=json"`
+}
+
+// UserRole assigns a role to a user.
+type UserRole struct {
+	UserId  int64
+	Role    string `orm:",index"`
+	Created time.Time
+}
+
+// Grant is a permission granted directly to a user. If Object is
+// empty, the permission is granted on all the objects. Otherwise,
+// it's only granted on the object with the given key (see ObjectKey).
+// Empty objects are stored as empty strings rather than NULL, since
+// Object is part of the primary key.
+type Grant struct {
+	UserId     int64
+	Permission string
+	Object     string `orm:",notnullempty"`
+	Created    time.Time
+}
+
+func forgetPermissions(ctx *app.Context, userId int64) {
+	ctx.Set(fmt.Sprintf("%s.%d", permissionsKey, userId), nil)
+}
+
+// SaveRole creates or updates the given role. All the permissions in
+// the role must be registered (see RegisterPermission).
+func SaveRole(ctx *app.Context, role *Role) error {
+	if role.Name == "" {
+		return fmt.Errorf("role name can't be empty")
+	}
+	for _, v := range role.Permissions {
+		if !validPermission(v) {
+			return fmt.Errorf("unknown permission %q", v)
+		}
+	}
+	_, err := ctx.Orm().Save(role)
+	return err
+}
+
+// GetRole returns the role with the given name, or nil
+// if there's no such role.
+func GetRole(ctx *app.Context, name string) (*Role, error) {
+	var role Role
+	ok, err := ctx.Orm().One(orm.Eq("Name", name), &role)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// Roles returns all the roles, sorted by name.
+func Roles(ctx *app.Context) ([]*Role, error) {
+	var roles []*Role
+	err := ctx.Orm().All().Sort("Name", orm.ASC).All(&roles)
+	return roles, err
+}
+
+// DeleteRole removes the role with the given name, as
+// well as its assignments to users.
+func DeleteRole(ctx *app.Context, name string) error {
+	o := ctx.Orm()
+	if _, err := o.DeleteFrom(o.TypeTable(userRoleType), orm.Eq("Role", name)); err != nil {
+		return err
+	}
+	return o.Delete(&Role{Name: name})
+}
+
+// AssignRole assigns the role with the given name to the
+// given user id. Assigning a role twice is not an error.
+func AssignRole(ctx *app.Context, userId int64, role string) error {
+	r, err := GetRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return fmt.Errorf("no role named %q", role)
+	}
+	forgetPermissions(ctx, userId)
+	_, err = ctx.Orm().Save(&UserRole{UserId: userId, Role: role, Created: time.Now().UTC()})
+	return err
+}
+
+// UnassignRole removes the role with the given name from the
+// given user id.
+func UnassignRole(ctx *app.Context, userId int64, role string) error {
+	forgetPermissions(ctx, userId)
+	return ctx.Orm().Delete(&UserRole{UserId: userId, Role: role})
+}
+
+// UserRoles returns the names of the roles assigned to
+// the given user id.
+func UserRoles(ctx *app.Context, userId int64) ([]string, error) {
+	var assigned []*UserRole
+	if err := ctx.Orm().Query(orm.Eq("UserId", userId)).Sort("Role", orm.ASC).All(&assigned); err != nil {
+		return nil, err
+	}
+	var roles []string
+	for _, v := range assigned {
+		roles = append(roles, v.Role)
+	}
+	return roles, nil
+}
+
+// GrantPermission grants the given permission to the given user id. If
+// object is non-nil, the permission is only granted on that object. See
+// ObjectKey for the supported object types.
+func GrantPermission(ctx *app.Context, userId int64, permission string, object interface{}) error {
+	if !validPermission(permission) {
+		return fmt.Errorf("unknown permission %q", permission)
+	}
+	key, err := ObjectKey(object)
+	if err != nil {
+		return err
+	}
+	forgetPermissions(ctx, userId)
+	_, err = ctx.Orm().Save(&Grant{UserId: userId, Permission: permission, Object: key, Created: time.Now().UTC()})
+	return err
+}
+
+// RevokePermission revokes a permission previously granted with
+// GrantPermission. Note that permissions included in the roles
+// assigned to the user are not affected.
+func RevokePermission(ctx *app.Context, userId int64, permission string, object interface{}) error {
+	key, err := ObjectKey(object)
+	if err != nil {
+		return err
+	}
+	forgetPermissions(ctx, userId)
+	return ctx.Orm().Delete(&Grant{UserId: userId, Permission: permission, Object: key})
+}
+
+// UserPermissions returns the permissions the given user id has on all
+// the objects, either from their roles or granted directly. Note that
+// the returned permissions might contain wildcards.
+func UserPermissions(ctx *app.Context, userId int64) ([]string, error) {
+	o := ctx.Orm()
+	roles, err := UserRoles(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	var perms []string
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	for _, v := range roles {
+		var role Role
+		ok, err := o.One(orm.Eq("Name", v), &role)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			for _, p := range role.Permissions {
+				add(p)
+			}
+		}
+	}
+	var grants []*Grant
+	if err := o.Query(orm.And(orm.Eq("UserId", userId), orm.Eq("Object", ""))).All(&grants); err != nil {
+		return nil, err
+	}
+	for _, v := range grants {
+		add(v.Permission)
+	}
+	return perms, nil
+}
+
+// hasObjectGrant returns true iff the given user id has been granted
+// the permission on the given object. Objects which don't implement
+// Object (nor are strings) can't have any grants, so they're reported
+// as not granted rather than as an error, letting the Checkers decide.
+func hasObjectGrant(ctx *app.Context, userId int64, permission string, object interface{}) (bool, error) {
+	key, err := ObjectKey(object)
+	if err != nil || key == "" {
+		return false, nil
+	}
+	var grants []*Grant
+	if err := ctx.Orm().Query(orm.And(orm.Eq("UserId", userId), orm.Eq("Object", key))).All(&grants); err != nil {
+		return false, err
+	}
+	for _, v := range grants {
+		if matches(v.Permission, permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func init() {
+	orm.Register(&Role{}, &orm.Options{
+		Table: "rbac_role",
+	})
+	orm.Register(&UserRole{}, &orm.Options{
+		Table:      "rbac_user_role",
+		PrimaryKey: []string{"UserId", "Role"},
+	})
+	orm.Register(&Grant{}, &orm.Options{
+		Table:      "rbac_grant",
+		PrimaryKey: []string{"UserId", "Permission", "Object"},
+	})
+}
@@ -0,0 +1,203 @@
+package rbac
+
+import (
+	"sync"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/config"
+	_ "gnd.la/orm/driver/memory"
+)
+
+type testUser struct {
+	id    int64
+	admin bool
+}
+
+func (u *testUser) Id() int64     { return u.id }
+func (u *testUser) IsAdmin() bool { return u.admin }
+
+// post doesn't implement Object, so it can only be
+// checked by the registered Checkers.
+type post struct {
+	AuthorId int64
+}
+
+var (
+	testApp   *app.App
+	setupOnce sync.Once
+)
+
+func init() {
+	RegisterPermission("rbac-test.articles.edit", "Edit articles")
+	RegisterPermission("rbac-test.articles.publish", "Publish articles")
+	RegisterPermission("rbac-test.posts.edit", "Edit posts")
+	RegisterChecker("rbac-test.posts.edit", func(ctx *app.Context, user app.User, object interface{}) bool {
+		p, ok := object.(*post)
+		return ok && p.AuthorId == user.Id()
+	})
+}
+
+func newTestContext(t *testing.T) *app.Context {
+	setupOnce.Do(func() {
+		a := app.New()
+		a.Config().Database = config.MustParseURL("memory://rbac")
+		if err := a.Prepare(); err != nil {
+			panic(err)
+		}
+		testApp = a
+	})
+	return testApp.NewContext(nil)
+}
+
+func TestRoles(t *testing.T) {
+	ctx := newTestContext(t)
+	defer testApp.CloseContext(ctx)
+	if err := SaveRole(ctx, &Role{Name: "rbac-test-bad", Permissions: []string{"rbac-test.nope"}}); err == nil {
+		t.Error("expecting an error when saving a role with an unknown permission")
+	}
+	if err := SaveRole(ctx, &Role{Name: ""}); err == nil {
+		t.Error("expecting an error when saving a role without a name")
+	}
+	editor := &Role{Name: "rbac-test-editor", Description: "Editor", Permissions: []string{"rbac-test.articles.edit"}}
+	if err := SaveRole(ctx, editor); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again updates the role
+	editor.Permissions = append(editor.Permissions, "rbac-test.articles.publish")
+	if err := SaveRole(ctx, editor); err != nil {
+		t.Fatal(err)
+	}
+	role, err := GetRole(ctx, editor.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role == nil || len(role.Permissions) != 2 {
+		t.Fatalf("expecting role with 2 permissions, got %+v", role)
+	}
+	if err := AssignRole(ctx, 1, "rbac-test-nope"); err == nil {
+		t.Error("expecting an error when assigning an unknown role")
+	}
+	for ii := 0; ii < 2; ii++ {
+		// Assigning twice is not an error
+		if err := AssignRole(ctx, 1, editor.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	roles, err := UserRoles(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0] != editor.Name {
+		t.Errorf("expecting roles [%s], got %v", editor.Name, roles)
+	}
+	user := &testUser{id: 1}
+	if !PermissionFunc(ctx, user, "rbac-test.articles.publish", nil) {
+		t.Error("expecting permission from role")
+	}
+	if err := UnassignRole(ctx, 1, editor.Name); err != nil {
+		t.Fatal(err)
+	}
+	if PermissionFunc(ctx, user, "rbac-test.articles.publish", nil) {
+		t.Error("expecting no permission after unassigning role")
+	}
+	if err := DeleteRole(ctx, editor.Name); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := GetRole(ctx, editor.Name); role != nil {
+		t.Error("expecting role to be deleted")
+	}
+}
+
+func TestGrants(t *testing.T) {
+	ctx := newTestContext(t)
+	defer testApp.CloseContext(ctx)
+	const userId = 2
+	user := &testUser{id: userId}
+	if err := GrantPermission(ctx, userId, "rbac-test.nope", nil); err == nil {
+		t.Error("expecting an error when granting an unknown permission")
+	}
+	if err := GrantPermission(ctx, userId, "rbac-test.articles.edit", 42); err == nil {
+		t.Error("expecting an error when granting on an unsupported object")
+	}
+	if err := GrantPermission(ctx, userId, "rbac-test.articles.edit", article(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := GrantPermission(ctx, userId, "rbac-test.articles.*", article(2)); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		permission string
+		object     interface{}
+		expect     bool
+	}{
+		{"rbac-test.articles.edit", nil, false},
+		{"rbac-test.articles.edit", article(1), true},
+		{"rbac-test.articles.edit", "article:1", true},
+		{"rbac-test.articles.publish", article(1), false},
+		{"rbac-test.articles.edit", article(2), true},
+		{"rbac-test.articles.publish", article(2), true},
+		{"rbac-test.articles.edit", article(3), false},
+		// Objects which don't implement Object have no grants
+		{"rbac-test.articles.edit", &post{AuthorId: userId}, false},
+	}
+	for _, v := range cases {
+		if can := PermissionFunc(ctx, user, v.permission, v.object); can != v.expect {
+			t.Errorf("expecting PermissionFunc(%q, %v) = %v, got %v", v.permission, v.object, v.expect, can)
+		}
+	}
+	// Global wildcard grants
+	if err := GrantPermission(ctx, userId, "rbac-test.*", nil); err != nil {
+		t.Fatal(err)
+	}
+	perms, err := UserPermissions(ctx, userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 || perms[0] != "rbac-test.*" {
+		t.Errorf("expecting permissions [rbac-test.*], got %v", perms)
+	}
+	for _, v := range []string{"rbac-test.articles.edit", "rbac-test.view", "rbac-test.posts.edit"} {
+		if !PermissionFunc(ctx, user, v, article(3)) {
+			t.Errorf("expecting %s from wildcard grant", v)
+		}
+	}
+	if err := RevokePermission(ctx, userId, "rbac-test.*", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := RevokePermission(ctx, userId, "rbac-test.articles.edit", article(1)); err != nil {
+		t.Fatal(err)
+	}
+	if PermissionFunc(ctx, user, "rbac-test.articles.edit", article(1)) {
+		t.Error("expecting no permission after revoking grant")
+	}
+}
+
+func TestCheckers(t *testing.T) {
+	ctx := newTestContext(t)
+	defer testApp.CloseContext(ctx)
+	user := &testUser{id: 3}
+	cases := []struct {
+		object interface{}
+		expect bool
+	}{
+		{nil, false},
+		{&post{AuthorId: 3}, true},
+		{&post{AuthorId: 4}, false},
+		{article(3), false},
+	}
+	for _, v := range cases {
+		if can := PermissionFunc(ctx, user, "rbac-test.posts.edit", v.object); can != v.expect {
+			t.Errorf("expecting PermissionFunc(%v) = %v, got %v", v.object, v.expect, can)
+		}
+	}
+	admin := &testUser{id: 5, admin: true}
+	if !PermissionFunc(ctx, admin, "rbac-test.posts.edit", &post{AuthorId: 3}) {
+		t.Error("expecting admins to have all permissions")
+	}
+	defer func() { AdminsHaveAllPermissions = true }()
+	AdminsHaveAllPermissions = false
+	if PermissionFunc(ctx, admin, "rbac-test.posts.edit", &post{AuthorId: 3}) {
+		t.Error("expecting no permission for admins with AdminsHaveAllPermissions = false")
+	}
+}
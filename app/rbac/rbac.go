@@ -0,0 +1,205 @@
+// Package rbac implements role based access control for Gondola apps,
+// storing the roles and the permissions granted to each user in the ORM.
+//
+// Permissions are identified by their name, which is usually a dotted
+// string like "articles.edit". Apps should register their permissions
+// with RegisterPermission from an init function, and then create the
+// roles using SaveRole or the provided commands:
+//
+//  ./myapp rbac-save-role -description="Edits articles" editor articles.edit articles.publish
+//  ./myapp rbac-assign 42 editor
+//
+// Roles might include wildcard permissions: "articles.*" matches any
+// permission starting with "articles." while "*" matches all permissions.
+// Permissions can also be granted to a user directly, either globally or
+// on a single object (see Object and GrantPermission).
+//
+// To use this package for checking permissions with gnd.la/app.Context.Can,
+// the gnd.la/app.Permission transformer and the "can" template function,
+// set PermissionFunc as the App PermissionFunc:
+//
+//  myapp.SetPermissionFunc(rbac.PermissionFunc)
+//  myapp.Handle("^/articles/new/$", app.Permission("articles.edit")(newArticleHandler))
+//
+// And in the templates:
+//
+//  {{ if can "articles.edit" .Article }}<a href="...">Edit</a>{{ end }}
+//
+// For rules which can't be expressed as grants (e.g. "users can edit the
+// articles they wrote"), use RegisterChecker.
+package rbac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gnd.la/app"
+)
+
+const (
+	permissionsKey = "gnd.la/app/rbac.permissions"
+)
+
+var (
+	// AdminsHaveAllPermissions makes PermissionFunc return true for
+	// all the permissions when the user is an administrator (as reported
+	// by gnd.la/app.User.IsAdmin).
+	AdminsHaveAllPermissions = true
+
+	permissions = map[string]*Permission{}
+	checkers    = map[string][]Checker{}
+)
+
+// Permission represents a registered permission.
+type Permission struct {
+	Name        string
+	Description string
+}
+
+// Object is implemented by the types which users can be granted
+// permissions on. PermissionObject must return a string which
+// identifies the object among all the objects of all the types
+// e.g. "article:42".
+type Object interface {
+	PermissionObject() string
+}
+
+// Checker is a function which decides if a user has a permission, in
+// addition to the roles and grants stored in the ORM. Note that object
+// might be nil. See RegisterChecker.
+type Checker func(ctx *app.Context, user app.User, object interface{}) bool
+
+// RegisterPermission registers a new permission with the given name and
+// description. Registered permissions are listed by the rbac-permissions
+// command and they're the only ones that can be added to roles or
+// granted to users. This function should be called from an init function.
+func RegisterPermission(name string, description string) {
+	if name == "" || strings.ContainsAny(name, " *") {
+		panic(fmt.Errorf("invalid permission name %q", name))
+	}
+	if _, ok := permissions[name]; ok {
+		panic(fmt.Errorf("duplicate permission %q", name))
+	}
+	permissions[name] = &Permission{Name: name, Description: description}
+}
+
+// Permissions returns all the registered permissions, sorted by name.
+func Permissions() []*Permission {
+	var p []*Permission
+	for _, v := range permissions {
+		p = append(p, v)
+	}
+	sort.Sort(byName(p))
+	return p
+}
+
+type byName []*Permission
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+// RegisterChecker adds a Checker for the given permission. Checkers
+// are only called when the user doesn't have the permission from
+// a role or a grant. If any of the checkers for a permission returns
+// true, the user has the permission. This function should be
+// called from an init function.
+func RegisterChecker(permission string, c Checker) {
+	checkers[permission] = append(checkers[permission], c)
+}
+
+// validPermission returns true iff the permission is registered or
+// it's a wildcard which matches at least one registered permission.
+func validPermission(permission string) bool {
+	if _, ok := permissions[permission]; ok {
+		return true
+	}
+	if !strings.Contains(permission, "*") {
+		return false
+	}
+	for k := range permissions {
+		if matches(permission, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true iff the granted permission, which might
+// be a wildcard, includes the requested permission.
+func matches(granted string, permission string) bool {
+	if granted == permission || granted == "*" {
+		return true
+	}
+	if strings.HasSuffix(granted, ".*") {
+		return strings.HasPrefix(permission, granted[:len(granted)-1])
+	}
+	return false
+}
+
+// ObjectKey returns the key used for storing the grants on the given
+// object, which must be either an Object or a string. If obj is nil,
+// an empty string is returned.
+func ObjectKey(obj interface{}) (string, error) {
+	switch x := obj.(type) {
+	case nil:
+		return "", nil
+	case Object:
+		return x.PermissionObject(), nil
+	case string:
+		return x, nil
+	}
+	return "", fmt.Errorf("type %T does not implement rbac.Object", obj)
+}
+
+// PermissionFunc implements gnd.la/app.PermissionFunc using the roles
+// and grants stored in the ORM, as well as the registered Checkers.
+// Use gnd.la/app.App.SetPermissionFunc to enable it.
+func PermissionFunc(ctx *app.Context, user app.User, permission string, object interface{}) bool {
+	if AdminsHaveAllPermissions && user.IsAdmin() {
+		return true
+	}
+	perms, err := cachedPermissions(ctx, user.Id())
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range perms {
+		if matches(v, permission) {
+			return true
+		}
+	}
+	if object != nil {
+		ok, err := hasObjectGrant(ctx, user.Id(), permission, object)
+		if err != nil {
+			panic(err)
+		}
+		if ok {
+			return true
+		}
+	}
+	for _, c := range checkers[permission] {
+		if c(ctx, user, object) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedPermissions returns the permissions for the given user, caching
+// them in the context, so they're only loaded once per request.
+func cachedPermissions(ctx *app.Context, userId int64) ([]string, error) {
+	key := fmt.Sprintf("%s.%d", permissionsKey, userId)
+	if perms, ok := ctx.Get(key).([]string); ok {
+		return perms, nil
+	}
+	perms, err := UserPermissions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if perms == nil {
+		perms = []string{}
+	}
+	ctx.Set(key, perms)
+	return perms, nil
+}
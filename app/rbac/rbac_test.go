@@ -0,0 +1,72 @@
+package rbac
+
+import (
+	"strconv"
+	"testing"
+)
+
+type article int64
+
+func (a article) PermissionObject() string {
+	return "article:" + strconv.FormatInt(int64(a), 10)
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		granted    string
+		permission string
+		expect     bool
+	}{
+		{"articles.edit", "articles.edit", true},
+		{"articles.edit", "articles.publish", false},
+		{"articles.*", "articles.edit", true},
+		{"articles.*", "articlesfoo", false},
+		{"articles.*", "comments.edit", false},
+		{"*", "comments.edit", true},
+	}
+	for _, v := range cases {
+		if m := matches(v.granted, v.permission); m != v.expect {
+			t.Errorf("matches(%q, %q) = %v, want %v", v.granted, v.permission, m, v.expect)
+		}
+	}
+}
+
+func init() {
+	RegisterPermission("rbac-test.view", "View things")
+}
+
+func TestValidPermission(t *testing.T) {
+	for _, v := range []string{"rbac-test.view", "rbac-test.*", "*"} {
+		if !validPermission(v) {
+			t.Errorf("expecting %q to be valid", v)
+		}
+	}
+	for _, v := range []string{"rbac-test.edit", "other.*", ""} {
+		if validPermission(v) {
+			t.Errorf("expecting %q to be invalid", v)
+		}
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	cases := []struct {
+		obj interface{}
+		key string
+	}{
+		{nil, ""},
+		{"foo:1", "foo:1"},
+		{article(4), "article:4"},
+	}
+	for _, v := range cases {
+		key, err := ObjectKey(v.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != v.key {
+			t.Errorf("expecting key %q for %v, got %q", v.key, v.obj, key)
+		}
+	}
+	if _, err := ObjectKey(42); err == nil {
+		t.Error("expecting an error for int object")
+	}
+}
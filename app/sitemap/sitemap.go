@@ -0,0 +1,173 @@
+// Package sitemap implements handlers for generating sitemaps
+// (see http://www.sitemaps.org) from several sources of URLs.
+//
+// Sources can be provided by any app (e.g. gnd.la/apps/articles) and
+// this package also provides Handlers, which builds a Source from the
+// named handlers in an App:
+//
+//  myapp.Handle("^/sitemap\\.xml$", sitemap.Handler(
+//	sitemap.Handlers(myapp, "home", "about", "contact"),
+//	articles.SitemapSource(articlesApp),
+//  ))
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gnd.la/app"
+)
+
+const (
+	xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// ContentType is the Content-Type header sent by Handler.
+	ContentType = "application/xml; charset=utf-8"
+)
+
+// Values for URL.ChangeFreq
+const (
+	Always  = "always"
+	Hourly  = "hourly"
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+	Never   = "never"
+)
+
+// URL represents an URL in the sitemap. Only Loc is required,
+// the rest of the fields are omitted when they're empty.
+type URL struct {
+	// Loc is the URL. Relative URLs are resolved by Handler using
+	// the URL of the request.
+	Loc string
+	// LastMod is the last time the content at Loc was modified.
+	LastMod time.Time
+	// ChangeFreq indicates how frequently the content is likely
+	// to change. Use one of the constants declared in this package.
+	ChangeFreq string
+	// Priority is the priority of this URL relative to the
+	// rest of the URLs in the site, from 0 to 1.
+	Priority float64
+}
+
+// Source is a function which returns URLs to be
+// included in a sitemap.
+type Source func(ctx *app.Context) ([]*URL, error)
+
+// Handlers returns a Source which returns the URLs for the handlers with the
+// given names in the given App, obtained with gnd.la/app.App.Reverse. Note
+// that only handlers which don't take any arguments can be included. If a
+// is nil, the App which received the request is used.
+func Handlers(a *app.App, names ...string) Source {
+	return func(ctx *app.Context) ([]*URL, error) {
+		ap := a
+		if ap == nil {
+			ap = ctx.App()
+		}
+		var urls []*URL
+		for _, v := range names {
+			loc, err := ap.Reverse(v)
+			if err != nil {
+				return nil, err
+			}
+			urls = append(urls, &URL{Loc: loc})
+		}
+		return urls, nil
+	}
+}
+
+// URLs returns a Source which always returns the given URLs.
+func URLs(urls ...*URL) Source {
+	return func(ctx *app.Context) ([]*URL, error) {
+		return urls, nil
+	}
+}
+
+// Collect returns the URLs from all the given sources, in order,
+// with their Loc resolved using the URL of the current request. If
+// the same URL is returned multiple times, only its first occurrence
+// is included.
+func Collect(ctx *app.Context, sources ...Source) ([]*URL, error) {
+	base := ctx.URL()
+	seen := make(map[string]bool)
+	var urls []*URL
+	for _, s := range sources {
+		sourceURLs, err := s(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range sourceURLs {
+			u := *v
+			if base != nil {
+				resolved, err := base.Parse(u.Loc)
+				if err != nil {
+					return nil, err
+				}
+				u.Loc = resolved.String()
+			}
+			if seen[u.Loc] {
+				continue
+			}
+			seen[u.Loc] = true
+			urls = append(urls, &u)
+		}
+	}
+	return urls, nil
+}
+
+// Handler returns an app.Handler which serves a sitemap with
+// the URLs returned by the given sources (see Collect).
+func Handler(sources ...Source) app.Handler {
+	return func(ctx *app.Context) {
+		urls, err := Collect(ctx, sources...)
+		if err != nil {
+			panic(err)
+		}
+		ctx.SetHeader("Content-Type", ContentType)
+		if err := Write(ctx, urls); err != nil {
+			panic(err)
+		}
+	}
+}
+
+type xmlURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type xmlURLSet struct {
+	XMLName xml.Name  `xml:"urlset"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	URLs    []*xmlURL `xml:"url"`
+}
+
+// Write writes a sitemap with the given URLs to w. Note that
+// URLs are written verbatim, so they should be absolute.
+func Write(w io.Writer, urls []*URL) error {
+	set := &xmlURLSet{Xmlns: xmlns}
+	for _, v := range urls {
+		if v.Priority < 0 || v.Priority > 1 {
+			return fmt.Errorf("invalid priority %v for URL %s, must be in [0, 1]", v.Priority, v.Loc)
+		}
+		u := &xmlURL{Loc: v.Loc, ChangeFreq: v.ChangeFreq}
+		if !v.LastMod.IsZero() {
+			u.LastMod = v.LastMod.Format(time.RFC3339)
+		}
+		if v.Priority > 0 {
+			u.Priority = strconv.FormatFloat(v.Priority, 'f', 1, 64)
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(set)
+}
@@ -0,0 +1,67 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+)
+
+func TestWrite(t *testing.T) {
+	urls := []*URL{
+		{Loc: "http://example.com/"},
+		{Loc: "http://example.com/about/", LastMod: time.Date(2014, 5, 14, 10, 0, 0, 0, time.UTC), ChangeFreq: Monthly, Priority: 0.8},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, urls); err != nil {
+		t.Fatal(err)
+	}
+	var set xmlURLSet
+	if err := xml.Unmarshal(buf.Bytes(), &set); err != nil {
+		t.Fatal(err)
+	}
+	if set.Xmlns != xmlns {
+		t.Errorf("expecting xmlns %q, got %q", xmlns, set.Xmlns)
+	}
+	if len(set.URLs) != len(urls) {
+		t.Fatalf("expecting %d URLs, got %d", len(urls), len(set.URLs))
+	}
+	if u := set.URLs[0]; u.LastMod != "" || u.ChangeFreq != "" || u.Priority != "" {
+		t.Errorf("expecting only loc in first URL, got %+v", u)
+	}
+	expect := xmlURL{Loc: "http://example.com/about/", LastMod: "2014-05-14T10:00:00Z", ChangeFreq: "monthly", Priority: "0.8"}
+	if u := set.URLs[1]; *u != expect {
+		t.Errorf("expecting %+v, got %+v", expect, u)
+	}
+	if err := Write(&buf, []*URL{{Loc: "http://example.com/", Priority: 2}}); err == nil {
+		t.Error("expecting an error with priority > 1")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	a := app.New()
+	noop := func(ctx *app.Context) {}
+	a.HandleNamed("^/$", noop, "home")
+	a.HandleNamed("^/about/$", noop, "about")
+	child := app.New()
+	child.SetName("Child")
+	child.HandleNamed("^/$", noop, "child-index")
+	a.Include("/child/", child, "")
+	a.Handle("^/sitemap\\.xml$", Handler(
+		Handlers(nil, "home", "about"),
+		Handlers(child, "child-index"),
+		URLs(&URL{Loc: "/about/"}, &URL{Loc: "http://example.com/"}),
+	))
+	tt := tester.New(t, a)
+	tt.Get("/sitemap.xml", nil).
+		Contains("<loc>http://localhost/</loc>").
+		Contains("<loc>http://localhost/about/</loc>").
+		Contains("<loc>http://localhost/child/</loc>").
+		Contains("<loc>http://example.com/</loc>").
+		ExpectHeader("Content-Type", ContentType)
+	a.Handle("^/broken\\.xml$", Handler(Handlers(nil, "nonexistent")))
+	tt.Get("/broken.xml", nil).Expect(500)
+}
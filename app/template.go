@@ -24,6 +24,7 @@ var (
 		"!tn":  template_tn,
 		"!tc":  template_tc,
 		"!tnc": template_tnc,
+		"!can": template_can,
 		"app":  nop,
 		templateutil.BeginTranslatableBlock: nop,
 		templateutil.EndTranslatableBlock:   nop,
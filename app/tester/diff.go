@@ -0,0 +1,48 @@
+package tester
+
+import (
+	"bytes"
+	"strings"
+)
+
+// diff returns a line based diff between a and b, with lines
+// only in a prefixed by "-", lines only in b prefixed by "+"
+// and common lines prefixed by a space.
+func diff(a string, b string) string {
+	al := strings.Split(a, "\n")
+	bl := strings.Split(b, "\n")
+	// lcs[ii][jj] is the length of the longest common
+	// subsequence of al[ii:] and bl[jj:]
+	lcs := make([][]int, len(al)+1)
+	for ii := range lcs {
+		lcs[ii] = make([]int, len(bl)+1)
+	}
+	for ii := len(al) - 1; ii >= 0; ii-- {
+		for jj := len(bl) - 1; jj >= 0; jj-- {
+			if al[ii] == bl[jj] {
+				lcs[ii][jj] = lcs[ii+1][jj+1] + 1
+			} else if lcs[ii+1][jj] >= lcs[ii][jj+1] {
+				lcs[ii][jj] = lcs[ii+1][jj]
+			} else {
+				lcs[ii][jj] = lcs[ii][jj+1]
+			}
+		}
+	}
+	var buf bytes.Buffer
+	ii, jj := 0, 0
+	for ii < len(al) || jj < len(bl) {
+		switch {
+		case ii < len(al) && jj < len(bl) && al[ii] == bl[jj]:
+			buf.WriteString("  " + al[ii] + "\n")
+			ii++
+			jj++
+		case jj == len(bl) || (ii < len(al) && lcs[ii+1][jj] >= lcs[ii][jj+1]):
+			buf.WriteString("- " + al[ii] + "\n")
+			ii++
+		default:
+			buf.WriteString("+ " + bl[jj] + "\n")
+			jj++
+		}
+	}
+	return buf.String()
+}
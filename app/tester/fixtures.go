@@ -0,0 +1,39 @@
+package tester
+
+import (
+	"fmt"
+
+	"gnd.la/orm/fixtures"
+)
+
+// Fixtures loads the objects in the given fixture files (or all the
+// fixture files in the given directories) into the App ORM, and returns
+// a function which removes all the objects from the tables which
+// received them. It's intended to be called at the start of each
+// test, deferring the returned function:
+//
+//  tt := tester.New(t, App)
+//  defer tt.Fixtures("testdata/users.json", "testdata/articles.yaml")()
+//
+// See gnd.la/orm/fixtures for the format of the files. If the fixtures
+// can't be loaded, the test fails immediately. When running the tests
+// against a remote server, the fixtures are not loaded.
+func (t *Tester) Fixtures(files ...string) func() {
+	if *remoteHost != "" {
+		t.Reporter.Log(fmt.Sprintf("not loading fixtures %v into remote host %s", files, *remoteHost))
+		return func() {}
+	}
+	o, err := t.App.Orm()
+	if err != nil {
+		t.Reporter.Fatal(fmt.Errorf("error opening ORM for loading fixtures: %s", err))
+	}
+	tables, err := fixtures.LoadFiles(o.Orm, files...)
+	if err != nil {
+		t.Reporter.Fatal(fmt.Errorf("error loading fixtures: %s", err))
+	}
+	return func() {
+		if err := fixtures.Truncate(o.Orm, tables...); err != nil {
+			t.Reporter.Error(fmt.Errorf("error removing fixtures: %s", err))
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gnd.la/html"
+	"gnd.la/util/types"
+)
+
+type formField struct {
+	name  string
+	value string
+}
+
+type htmlForm struct {
+	method  string
+	action  string
+	enctype string
+	fields  []*formField
+	names   map[string]bool
+}
+
+func (f *htmlForm) has(names []string) bool {
+	for _, v := range names {
+		if !f.names[v] {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *htmlForm) set(name string, value string) {
+	// Replace the first field with the same name and remove
+	// the rest (e.g. multiple checkboxes).
+	fields := f.fields[:0]
+	found := false
+	for _, v := range f.fields {
+		if v.name == name {
+			if found {
+				continue
+			}
+			v.value = value
+			found = true
+		}
+		fields = append(fields, v)
+	}
+	if !found {
+		fields = append(fields, &formField{name: name, value: value})
+	}
+	f.fields = fields
+}
+
+func (f *htmlForm) setValues(name string, values []string) {
+	fields := f.fields[:0]
+	for _, v := range f.fields {
+		if v.name != name {
+			fields = append(fields, v)
+		}
+	}
+	for _, v := range values {
+		fields = append(fields, &formField{name: name, value: v})
+	}
+	f.fields = fields
+}
+
+func (f *htmlForm) values() url.Values {
+	values := make(url.Values)
+	for _, v := range f.fields {
+		values.Add(v.name, v.value)
+	}
+	return values
+}
+
+func parseForms(root *html.Node) []*htmlForm {
+	var forms []*htmlForm
+	root.Walk(func(n *html.Node) bool {
+		if n.Type != html.TAG_NODE || n.Tag != "form" {
+			return true
+		}
+		form := &htmlForm{
+			method:  strings.ToUpper(n.Attrs["method"]),
+			action:  n.Attrs["action"],
+			enctype: strings.ToLower(n.Attrs["enctype"]),
+			names:   make(map[string]bool),
+		}
+		if form.method == "" {
+			form.method = "GET"
+		}
+		n.Walk(func(c *html.Node) bool {
+			if c.Type != html.TAG_NODE {
+				return true
+			}
+			name := c.Attrs["name"]
+			if name == "" {
+				return true
+			}
+			switch c.Tag {
+			case "input", "select", "textarea", "button":
+				form.names[name] = true
+			default:
+				return true
+			}
+			if _, disabled := c.Attrs["disabled"]; disabled {
+				return false
+			}
+			switch c.Tag {
+			case "input":
+				switch strings.ToLower(c.Attrs["type"]) {
+				case "submit", "button", "image", "reset", "file":
+				case "checkbox", "radio":
+					if _, checked := c.Attrs["checked"]; checked {
+						value, ok := c.Attrs["value"]
+						if !ok {
+							value = "on"
+						}
+						form.fields = append(form.fields, &formField{name, value})
+					}
+				default:
+					form.fields = append(form.fields, &formField{name, c.Attrs["value"]})
+				}
+			case "select":
+				var first, selected *html.Node
+				c.Walk(func(o *html.Node) bool {
+					if o.Type == html.TAG_NODE && o.Tag == "option" {
+						if first == nil {
+							first = o
+						}
+						if _, ok := o.Attrs["selected"]; ok && selected == nil {
+							selected = o
+						}
+					}
+					return true
+				})
+				if selected == nil {
+					selected = first
+				}
+				if selected != nil {
+					value, ok := selected.Attrs["value"]
+					if !ok {
+						value = strings.TrimSpace(selected.Text())
+					}
+					form.fields = append(form.fields, &formField{name, value})
+				}
+			case "textarea":
+				form.fields = append(form.fields, &formField{name, strings.TrimPrefix(c.Text(), "\n")})
+			}
+			return false
+		})
+		forms = append(forms, form)
+		return false
+	})
+	return forms
+}
+
+// SubmitForm requests the page at the given path, looks for the first
+// form containing fields for all the keys in values and returns a Request
+// which submits it, like a browser would do. Fields not present in values
+// keep their default values (e.g. hidden fields, including the ones used
+// for CSRF protection by gnd.la/form, or checked checkboxes). Values are
+// converted to strings using types.ToString, except []string, which sets
+// multiple values for the same field. Submit, button, reset and file
+// inputs, as well as disabled fields, are ignored.
+//
+// The form is submitted to its action (or to the same page if it has no
+// action) using its method and encoding type. If there's an error retrieving
+// the page or no form matches the given values, the current test is aborted.
+func (t *Tester) SubmitForm(path string, values map[string]interface{}) *Request {
+	page := t.Get(path, nil).Follow()
+	if !page.do() {
+		return page
+	}
+	if page.resp.code != http.StatusOK {
+		page.err = fmt.Errorf("can't submit form, %s returned status code %d", path, page.resp.code)
+		page.Reporter.Fatal(page.err)
+		return page
+	}
+	root := page.parseHTML()
+	if root == nil {
+		return page
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var form *htmlForm
+	for _, v := range parseForms(root) {
+		if v.has(names) {
+			form = v
+			break
+		}
+	}
+	if form == nil {
+		page.err = fmt.Errorf("no form with fields %s found in %s", strings.Join(names, ", "), path)
+		page.Reporter.Fatal(page.err)
+		return page
+	}
+	for _, k := range names {
+		if s, ok := values[k].([]string); ok {
+			form.setValues(k, s)
+			continue
+		}
+		form.set(k, types.ToString(values[k]))
+	}
+	base, err := url.Parse(page.finalPath)
+	if err == nil {
+		var action *url.URL
+		action, err = base.Parse(form.action)
+		if err == nil {
+			return t.submit(form, action)
+		}
+	}
+	page.err = fmt.Errorf("invalid form action %q: %s", form.action, err)
+	page.Reporter.Fatal(page.err)
+	return page
+}
+
+func (t *Tester) submit(form *htmlForm, action *url.URL) *Request {
+	values := form.values()
+	if form.method != "POST" {
+		action.RawQuery = values.Encode()
+		return t.Request("GET", action.RequestURI(), nil)
+	}
+	path := action.RequestURI()
+	if form.enctype != "multipart/form-data" {
+		r := t.Request("POST", path, values.Encode())
+		r.AddHeader("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, v := range form.fields {
+		w.WriteField(v.name, v.value)
+	}
+	w.Close()
+	r := t.Request("POST", path, buf.Bytes())
+	r.AddHeader("Content-Type", w.FormDataContentType())
+	return r
+}
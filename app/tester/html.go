@@ -0,0 +1,131 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gnd.la/html"
+)
+
+// maxReportedNodes is the maximum number of matched
+// nodes included in error messages.
+const maxReportedNodes = 5
+
+// parseHTML parses the response body as HTML, caching the
+// result. If the body can't be parsed, the error is reported
+// and nil is returned.
+func (r *Request) parseHTML() *html.Node {
+	if !r.do() {
+		return nil
+	}
+	if r.htmlRoot == nil && r.htmlErr == nil {
+		r.htmlRoot, r.htmlErr = html.Parse(bytes.NewReader(r.resp.body.Bytes()))
+		if r.htmlErr != nil {
+			r.htmlErr = fmt.Errorf("error parsing response body as HTML: %s", r.htmlErr)
+		}
+	}
+	if r.htmlErr != nil {
+		r.setErr(r.htmlErr)
+		return nil
+	}
+	return r.htmlRoot
+}
+
+// selectHTML returns the nodes matching the given selector
+// in the HTML response body.
+func (r *Request) selectHTML(selector string) ([]*html.Node, bool) {
+	sel, err := html.Compile(selector)
+	if err != nil {
+		r.err = err
+		r.Reporter.Fatal(r.err)
+		return nil, false
+	}
+	root := r.parseHTML()
+	if root == nil {
+		return nil, false
+	}
+	return sel.Select(root), true
+}
+
+func describeNodes(nodes []*html.Node) string {
+	var lines []string
+	for ii, v := range nodes {
+		if ii == maxReportedNodes {
+			lines = append(lines, fmt.Sprintf("\t... and %d more", len(nodes)-ii))
+			break
+		}
+		lines = append(lines, "\t"+describeNode(v))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeNode(n *html.Node) string {
+	var buf bytes.Buffer
+	n.WriteTo(&buf)
+	s := buf.String()
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+	return s
+}
+
+// ExpectHTML parses the response body as HTML and checks that it
+// contains at least one element matching the given CSS selector.
+// See gnd.la/html.Selector for the supported syntax.
+func (r *Request) ExpectHTML(selector string) *Request {
+	if nodes, ok := r.selectHTML(selector); ok && len(nodes) == 0 {
+		r.errorf("expecting elements matching %q, found none", selector)
+	}
+	return r
+}
+
+// ExpectHTMLCount works like ExpectHTML, but checks that exactly count
+// elements match the given selector. Use a count of 0 to check that
+// no elements match it.
+func (r *Request) ExpectHTMLCount(selector string, count int) *Request {
+	if nodes, ok := r.selectHTML(selector); ok && len(nodes) != count {
+		if len(nodes) == 0 {
+			r.errorf("expecting %d elements matching %q, found none", count, selector)
+		} else {
+			r.errorf("expecting %d elements matching %q, found %d:\n%s", count, selector, len(nodes), describeNodes(nodes))
+		}
+	}
+	return r
+}
+
+// ExpectHTMLText checks the text (without any markup and with
+// leading and trailing whitespace removed) of the first element
+// matching the given selector. See Request.Expect for the accepted
+// types in what (e.g. use Contains or Match for partial matches).
+func (r *Request) ExpectHTMLText(selector string, what interface{}) *Request {
+	if nodes, ok := r.selectHTML(selector); ok {
+		if len(nodes) == 0 {
+			r.errorf("expecting elements matching %q, found none", selector)
+		} else {
+			r.expect(what, fmt.Sprintf("text of %q", selector), strings.TrimSpace(nodes[0].Text()))
+		}
+	}
+	return r
+}
+
+// ExpectHTMLAttr works like ExpectHTMLText, but checks the given
+// attribute of the first element matching the selector rather than
+// its text.
+func (r *Request) ExpectHTMLAttr(selector string, attr string, what interface{}) *Request {
+	if nodes, ok := r.selectHTML(selector); ok {
+		if len(nodes) == 0 {
+			r.errorf("expecting elements matching %q, found none", selector)
+		} else {
+			r.expect(what, fmt.Sprintf("attribute %s of %q", attr, selector), nodes[0].Attr(attr))
+		}
+	}
+	return r
+}
+
+// HTML parses the response body as HTML and returns its root node,
+// which can be used for doing more complex checks. If the body
+// can't be parsed, an error is reported and nil is returned.
+func (r *Request) HTML() *html.Node {
+	return r.parseHTML()
+}
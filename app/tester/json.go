@@ -0,0 +1,379 @@
+package tester
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// JSON types accepted by Request.ExpectJSONType.
+const (
+	JSONNull   = "null"
+	JSONBool   = "bool"
+	JSONNumber = "number"
+	JSONString = "string"
+	JSONArray  = "array"
+	JSONObject = "object"
+)
+
+// decodeJSON decodes the response body as JSON, caching
+// the result. If the body can't be decoded, the error is
+// reported and false is returned.
+func (r *Request) decodeJSON() bool {
+	if !r.do() {
+		return false
+	}
+	if !r.jsonDone {
+		r.jsonDone = true
+		if err := json.Unmarshal(r.resp.body.Bytes(), &r.jsonValue); err != nil {
+			r.jsonErr = fmt.Errorf("error decoding response body as JSON: %s (body was %q)", err, r.resp.body.String())
+		}
+	}
+	if r.jsonErr != nil {
+		r.setErr(r.jsonErr)
+		return false
+	}
+	return true
+}
+
+// jsonPath returns the value at the given path in the decoded
+// JSON body. See Request.ExpectJSON for the path syntax.
+func (r *Request) jsonPath(path string) (interface{}, bool) {
+	if !r.decodeJSON() {
+		return nil, false
+	}
+	value := r.jsonValue
+	cur := "$"
+	for _, v := range splitJSONPath(path) {
+		switch x := value.(type) {
+		case map[string]interface{}:
+			val, ok := x[v]
+			if !ok {
+				r.errorf("JSON path %s: no key %q in object with keys %s", cur, v, strings.Join(jsonKeys(x), ", "))
+				return nil, false
+			}
+			value = val
+		case []interface{}:
+			idx, err := strconv.Atoi(v)
+			if err != nil {
+				r.errorf("JSON path %s: can't index array with %q", cur, v)
+				return nil, false
+			}
+			if idx < 0 {
+				idx += len(x)
+			}
+			if idx < 0 || idx >= len(x) {
+				r.errorf("JSON path %s: index %s out of range (array has %d elements)", cur, v, len(x))
+				return nil, false
+			}
+			value = x[idx]
+		default:
+			r.errorf("JSON path %s: can't look up %q in %s", cur, v, jsonType(value))
+			return nil, false
+		}
+		cur += "." + v
+	}
+	return value, true
+}
+
+func splitJSONPath(path string) []string {
+	path = strings.Replace(path, "[", ".", -1)
+	path = strings.Replace(path, "]", "", -1)
+	var parts []string
+	for _, v := range strings.Split(path, ".") {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return parts
+}
+
+func jsonKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return JSONNull
+	case bool:
+		return JSONBool
+	case float64:
+		return JSONNumber
+	case string:
+		return JSONString
+	case []interface{}:
+		return JSONArray
+	case map[string]interface{}:
+		return JSONObject
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func indentJSON(v interface{}) string {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// ExpectJSON decodes the response body as JSON and checks that the
+// value at the given path is equal to value. Paths are formed by
+// object keys and array indexes, separated by dots (e.g. "items.0.name",
+// which might also be written as "items[0].name"). Negative indexes
+// count from the end of the array. An empty path selects the
+// whole document.
+//
+// value is encoded as JSON and decoded again before doing the
+// comparison, so any value which encodes to the same JSON will
+// be considered equal (e.g. an int and a float64 or a struct and a
+// map[string]interface{}). If the values are not equal, the reported
+// error includes a diff between them.
+func (r *Request) ExpectJSON(path string, value interface{}) *Request {
+	got, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.err = fmt.Errorf("error encoding expected JSON value: %s", err)
+		r.Reporter.Fatal(r.err)
+		return r
+	}
+	var expected interface{}
+	if err := json.Unmarshal(data, &expected); err != nil {
+		r.err = fmt.Errorf("error decoding expected JSON value: %s", err)
+		r.Reporter.Fatal(r.err)
+		return r
+	}
+	if !reflect.DeepEqual(expected, got) {
+		r.errorf("unexpected JSON value at %q (- expected, + got):\n%s", path, diff(indentJSON(expected), indentJSON(got)))
+	}
+	return r
+}
+
+// ExpectJSONType checks that the value at the given path has the
+// given JSON type, which must be one of JSONNull, JSONBool, JSONNumber,
+// JSONString, JSONArray or JSONObject. See ExpectJSON for the path syntax.
+func (r *Request) ExpectJSONType(path string, typ string) *Request {
+	switch typ {
+	case JSONNull, JSONBool, JSONNumber, JSONString, JSONArray, JSONObject:
+	default:
+		r.err = fmt.Errorf("invalid JSON type %q", typ)
+		r.Reporter.Fatal(r.err)
+		return r
+	}
+	if got, ok := r.jsonPath(path); ok {
+		if t := jsonType(got); t != typ {
+			r.errorf("expecting JSON %s at %q, got %s %s instead", typ, path, t, indentJSON(got))
+		}
+	}
+	return r
+}
+
+// ExpectJSONLen checks the length of the array, object or string at
+// the given path. See ExpectJSON for the path syntax.
+func (r *Request) ExpectJSONLen(path string, length int) *Request {
+	got, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+	var n int
+	switch x := got.(type) {
+	case []interface{}:
+		n = len(x)
+	case map[string]interface{}:
+		n = len(x)
+	case string:
+		n = len(x)
+	default:
+		r.errorf("can't check the length of JSON %s at %q", jsonType(got), path)
+		return r
+	}
+	if n != length {
+		r.errorf("expecting JSON %s with length %d at %q, got length %d instead", jsonType(got), length, path, n)
+	}
+	return r
+}
+
+// ExpectJSONSchema checks that the value at the given path matches the
+// structure of the given schema, which should usually be a struct or a
+// pointer to a struct (its value is ignored, only its type is used).
+// Struct fields are looked up using the same rules as encoding/json,
+// including the json tags. All fields must be present in the JSON object,
+// except the ones tagged with omitempty. Pointers, slices, maps and
+// interfaces accept null values. Keys not present in the schema are
+// ignored. See ExpectJSON for the path syntax.
+func (r *Request) ExpectJSONSchema(path string, schema interface{}) *Request {
+	got, ok := r.jsonPath(path)
+	if !ok {
+		return r
+	}
+	typ, ok := schema.(reflect.Type)
+	if !ok {
+		typ = reflect.TypeOf(schema)
+	}
+	if typ == nil {
+		r.err = fmt.Errorf("invalid JSON schema %v", schema)
+		r.Reporter.Fatal(r.err)
+		return r
+	}
+	var errs []string
+	matchSchema(&errs, "$"+strings.Join(append([]string{""}, splitJSONPath(path)...), "."), got, typ)
+	if len(errs) > 0 {
+		r.errorf("JSON at %q does not match %s:\n%s", path, typ, strings.Join(errs, "\n"))
+	}
+	return r
+}
+
+func matchSchema(errs *[]string, path string, value interface{}, typ reflect.Type) {
+	errorf := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	if reflect.PtrTo(typ).Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		data, _ := json.Marshal(value)
+		if err := json.Unmarshal(data, reflect.New(typ).Interface()); err != nil {
+			errorf("%s", err)
+		}
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if value != nil {
+			matchSchema(errs, path, value, typ.Elem())
+		}
+		return
+	case reflect.Interface:
+		return
+	}
+	if value == nil {
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Map:
+			return
+		}
+		errorf("expecting %s, got null", typ)
+		return
+	}
+	got := jsonType(value)
+	switch typ.Kind() {
+	case reflect.Bool:
+		if got != JSONBool {
+			errorf("expecting bool, got %s", got)
+		}
+	case reflect.String:
+		if got != JSONString {
+			errorf("expecting string, got %s", got)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f, ok := value.(float64); !ok || f != float64(int64(f)) {
+			errorf("expecting integer, got %s %s", got, indentJSON(value))
+		}
+	case reflect.Float32, reflect.Float64:
+		if got != JSONNumber {
+			errorf("expecting number, got %s", got)
+		}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			// []byte is encoded as a base64 string
+			if got != JSONString {
+				errorf("expecting string, got %s", got)
+			}
+			return
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			errorf("expecting array, got %s", got)
+			return
+		}
+		if typ.Kind() == reflect.Array && len(items) != typ.Len() {
+			errorf("expecting array with %d elements, got %d", typ.Len(), len(items))
+		}
+		for ii, v := range items {
+			matchSchema(errs, fmt.Sprintf("%s.%d", path, ii), v, typ.Elem())
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			errorf("expecting object, got %s", got)
+			return
+		}
+		for _, k := range jsonKeys(m) {
+			matchSchema(errs, path+"."+k, m[k], typ.Elem())
+		}
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			errorf("expecting object, got %s", got)
+			return
+		}
+		matchStructSchema(errs, path, m, typ)
+	default:
+		errorf("can't match JSON against %s", typ)
+	}
+}
+
+func matchStructSchema(errs *[]string, path string, m map[string]interface{}, typ reflect.Type) {
+	for ii := 0; ii < typ.NumField(); ii++ {
+		field := typ.Field(ii)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		var opts string
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, opts = tag[:comma], tag[comma:]
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				matchStructSchema(errs, path, m, ft)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		key := name
+		value, ok := m[key]
+		if !ok {
+			// encoding/json matches keys case insensitively
+			for _, k := range jsonKeys(m) {
+				if strings.EqualFold(k, name) {
+					key, value, ok = k, m[k], true
+					break
+				}
+			}
+		}
+		if !ok {
+			if !strings.Contains(opts, ",omitempty") {
+				*errs = append(*errs, fmt.Sprintf("%s: missing field %q", path, name))
+			}
+			continue
+		}
+		matchSchema(errs, path+"."+key, value, field.Type)
+	}
+}
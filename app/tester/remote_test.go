@@ -0,0 +1,43 @@
+package tester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gnd.la/app"
+)
+
+func TestRemoteRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/redirect-1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/redirect-2", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect-2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/hello", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	tt := New(t, app.New())
+	prev := *remoteHost
+	*remoteHost = srv.URL
+	defer func() { *remoteHost = prev }()
+	tt.Get("/redirect-1", nil).Expect(302).ExpectHeader("Location", "/redirect-2").ExpectPath("/redirect-1")
+	r := tt.Get("/redirect-1", nil).Follow().Expect("hello world").ExpectPath("/hello")
+	redirects := r.Redirects()
+	expected := []Redirect{
+		{Code: 302, From: "/redirect-1", To: "/redirect-2"},
+		{Code: 303, From: "/redirect-2", To: "/hello"},
+	}
+	if len(redirects) != len(expected) {
+		t.Fatalf("expecting %d redirects, got %d", len(expected), len(redirects))
+	}
+	for ii, v := range redirects {
+		if *v != expected[ii] {
+			t.Errorf("expecting redirect %d = %+v, got %+v", ii, expected[ii], *v)
+		}
+	}
+}
@@ -8,6 +8,11 @@
 // See Tester or this package's tests for a few examples of complete tests.
 // For benchmark, use Request.Bench. See its documentation for details.
 //
+// Besides checking the raw response body, headers and status code, JSON
+// responses might be checked using Request.ExpectJSON and its related
+// functions, while Request.ExpectHTML and its related functions check
+// HTML responses using CSS selectors.
+//
 // Additionaly, tests might be run against a remote server by using the
 // -H command line flag. e.g.
 //
@@ -37,6 +42,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"regexp"
 	"runtime"
@@ -46,12 +52,14 @@ import (
 	"time"
 
 	"gnd.la/app"
+	"gnd.la/html"
 	"gnd.la/internal"
 	"gnd.la/util/types"
 )
 
 const (
 	gaeLocalHost = "http://localhost:8080"
+	maxRedirects = 10
 )
 
 var (
@@ -185,9 +193,36 @@ type Request struct {
 	Body     []byte
 	err      error
 	resp     *response
+	// tester is non-nil when the request was created
+	// from a Tester, which holds the cookie jar.
+	tester    *Tester
+	follow    bool
+	redirects []*Redirect
+	finalPath string
+	// decoded body, see json.go and html.go
+	jsonValue interface{}
+	jsonErr   error
+	jsonDone  bool
+	htmlRoot  *html.Node
+	htmlErr   error
+}
+
+// Redirect represents a redirect received and followed
+// by a Request. See Request.Follow.
+type Redirect struct {
+	// Code is the HTTP status code of the redirect.
+	Code int
+	// From is the path which returned the redirect.
+	From string
+	// To is the path the redirect pointed to.
+	To string
 }
 
 func (r *Request) asHTTPRequest() (*http.Request, error) {
+	return newHTTPRequest(r.Method, r.Path, r.Header, r.Body)
+}
+
+func newHTTPRequest(method string, path string, header http.Header, body []byte) (*http.Request, error) {
 	var u *url.URL
 	var err error
 	var host string
@@ -200,36 +235,56 @@ func (r *Request) asHTTPRequest() (*http.Request, error) {
 		if base[len(base)-1] == '/' {
 			base = base[:len(base)-1]
 		}
-		u, err = url.Parse(base + r.Path)
+		u, err = url.Parse(base + path)
 		if u != nil {
-			r.Header.Add("Host", u.Host)
+			header.Add("Host", u.Host)
 			host = u.Host
 		}
 	} else {
-		host = r.Header.Get("Host")
+		host = header.Get("Host")
 		if host == "" {
 			host = "localhost"
 		}
-		u, err = url.Parse(r.Path)
-		requestURI = r.Path
+		u, err = url.Parse(path)
+		requestURI = path
 	}
 	if err != nil {
 		return nil, err
 	}
 	return &http.Request{
-		Method:        r.Method,
+		Method:        method,
 		URL:           u,
 		Proto:         "HTTP/1.1",
 		ProtoMajor:    1,
 		ProtoMinor:    1,
-		Header:        r.Header,
-		Body:          readCloser{bytes.NewReader(r.Body)},
-		ContentLength: int64(len(r.Body)),
+		Header:        header,
+		Body:          readCloser{bytes.NewReader(body)},
+		ContentLength: int64(len(body)),
 		Host:          host,
 		RequestURI:    requestURI,
 	}, nil
 }
 
+// cookieURL returns the URL used for storing and retrieving
+// the cookies for the given request in the cookie jar.
+func cookieURL(req *http.Request) *url.URL {
+	u := *req.URL
+	if u.Host == "" {
+		u.Scheme = "http"
+		u.Host = req.Host
+	}
+	return &u
+}
+
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
 func (r *Request) setErr(err error) {
 	if err != nil {
 		r.err = err
@@ -244,26 +299,139 @@ func (r *Request) errorf(format string, args ...interface{}) {
 func (r *Request) do() bool {
 	if r.resp == nil {
 		if r.err == nil {
-			req, err := r.asHTTPRequest()
-			if err != nil {
-				r.setErr(err)
+			r.setErr(r.send())
+		}
+	}
+	return r.err == nil
+}
+
+func (r *Request) send() error {
+	method, path, body := r.Method, r.Path, r.Body
+	header := cloneHeader(r.Header)
+	for {
+		req, err := newHTTPRequest(method, path, cloneHeader(header), body)
+		if err != nil {
+			return err
+		}
+		if r.tester != nil {
+			for _, v := range r.tester.jar.Cookies(cookieURL(req)) {
+				req.AddCookie(v)
+			}
+			r.tester.history = append(r.tester.history, path)
+		}
+		r.Reporter.Log(fmt.Sprintf("requesting %s", req.URL))
+		start := time.Now()
+		if *remoteHost != "" {
+			client := &http.Client{
+				CheckRedirect: func(*http.Request, []*http.Request) error {
+					// Let the Request handle the redirects
+					return http.ErrUseLastResponse
+				},
+			}
+			resp, err := client.Do(req)
+			r.resp = newRemoteResponse(resp, err)
+		} else {
+			r.resp = new(response)
+			r.App.ServeHTTP(r.resp, req)
+		}
+		r.Reporter.Log(fmt.Sprintf("received response (%d bytes) with code %d in %s", r.resp.body.Len(), r.resp.code, time.Since(start)))
+		r.finalPath = path
+		if r.resp.err != nil {
+			return r.resp.err
+		}
+		if r.tester != nil {
+			resp := &http.Response{Header: r.resp.header}
+			r.tester.jar.SetCookies(cookieURL(req), resp.Cookies())
+		}
+		if !r.follow || !isRedirect(r.resp.code) {
+			return nil
+		}
+		if len(r.redirects) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		location := r.resp.header.Get("Location")
+		if location == "" {
+			return fmt.Errorf("redirect %d from %s without Location header", r.resp.code, path)
+		}
+		loc, err := cookieURL(req).Parse(location)
+		if err != nil {
+			return fmt.Errorf("invalid redirect Location %q: %s", location, err)
+		}
+		to := loc.RequestURI()
+		r.redirects = append(r.redirects, &Redirect{Code: r.resp.code, From: path, To: to})
+		if r.resp.code != http.StatusTemporaryRedirect && r.resp.code != http.StatusPermanentRedirect {
+			method = "GET"
+			body = nil
+			header.Del("Content-Type")
+		}
+		path = to
+	}
+}
+
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// Follow makes the Request follow any redirects (with a maximum
+// of 10) received from the App, storing any cookies set
+// in the intermediate responses. Responses to 301, 302 and 303
+// redirects are requested using GET, while 307 and 308 redirects
+// preserve the original method and body. The followed redirects
+// might be retrieved using Redirects. Follow must be called before
+// Expect or any of its related functions.
+func (r *Request) Follow() *Request {
+	if r.resp != nil {
+		panic("can't follow redirects after sending request")
+	}
+	r.follow = true
+	return r
+}
+
+// Redirects returns the redirects followed by this Request,
+// in the order they were received. See Follow.
+func (r *Request) Redirects() []*Redirect {
+	r.do()
+	return r.redirects
+}
+
+// ExpectPath checks the path of the last request sent to the App,
+// which will be different from the initial path when redirects
+// are followed. See Request.Expect for the accepted types. Note
+// that the path includes the query string, if any.
+func (r *Request) ExpectPath(what interface{}) *Request {
+	if r.do() {
+		return r.expect(what, "path", r.finalPath)
+	}
+	return r
+}
+
+// ExpectUser checks that the user with the given id is signed in
+// after this Request. Use an id of 0 to check that no user is
+// signed in. See Tester.User for more details.
+func (r *Request) ExpectUser(id int64) *Request {
+	if r.tester == nil {
+		r.err = errors.New("can't check the signed in user in a Request not created by a Tester")
+		r.Reporter.Fatal(r.err)
+		return r
+	}
+	if r.do() {
+		var got int64
+		if user := r.tester.User(); user != nil {
+			got = user.Id()
+		}
+		if got != id {
+			if id == 0 {
+				r.errorf("expecting no signed in user, got user %d instead", got)
 			} else {
-				r.Reporter.Log(fmt.Sprintf("requesting %s", req.URL))
-				start := time.Now()
-				if *remoteHost != "" {
-					client := &http.Client{}
-					resp, err := client.Do(req)
-					r.resp = newRemoteResponse(resp, err)
-				} else {
-					r.resp = new(response)
-					r.App.ServeHTTP(r.resp, req)
-				}
-				r.Reporter.Log(fmt.Sprintf("received response (%d bytes) with code %d in %s", r.resp.body.Len(), r.resp.code, time.Since(start)))
-				r.setErr(r.resp.err)
+				r.errorf("expecting signed in user %d, got %d instead", id, got)
 			}
 		}
 	}
-	return r.err == nil
+	return r
 }
 
 // Bench performs a benchmark with this request. Note that Bench
@@ -306,6 +474,27 @@ func (r *Request) Err() error {
 	return r.err
 }
 
+// ResponseHeader sends the request, if it hasn't been sent yet,
+// and returns the headers in its response. It returns nil if the
+// request couldn't be sent.
+func (r *Request) ResponseHeader() http.Header {
+	if r.do() {
+		return r.resp.header
+	}
+	return nil
+}
+
+// ResponseBody sends the request, if it hasn't been sent yet, and
+// returns the body of its response, e.g. for using values generated
+// by the App in subsequent requests. It returns nil if the request
+// couldn't be sent.
+func (r *Request) ResponseBody() []byte {
+	if r.do() {
+		return r.resp.body.Bytes()
+	}
+	return nil
+}
+
 // AddHeader is a conveniency function which adds a new HTTP header
 // and returns the same *Request, to allow chaining. The value is
 // converted to an string using types.ToString.
@@ -468,9 +657,24 @@ func (r *Request) Match(what string) *Request {
 //	te.Get("/foo", nil).Expect(200)
 //  }
 //
+// Each Tester has its own cookie jar, which stores the cookies set
+// by the App and sends them back in subsequent requests, like a
+// browser would do. Use Tester.Client to simulate several clients
+// interacting with the App at the same time.
+//
+//  func TestSignIn(t *testing.T) {
+//	te := tester.New(t, App)
+//	te.SubmitForm("/sign-in/", map[string]interface{}{
+//		"username": "alice",
+//		"password": "secret",
+//	}).Follow().Expect(200).ExpectUser(1)
+//	te.Get("/account/", nil).Expect(200)
+//  }
 type Tester struct {
 	Reporter Reporter
 	App      *app.App
+	jar      http.CookieJar
+	history  []string
 }
 
 // New returns prepares the *app.App and then
@@ -500,7 +704,60 @@ func New(r Reporter, a *app.App) *Tester {
 		r.Fatal(fmt.Errorf("error preparing app: %s", err))
 	}
 	a.Logger = nil
-	return &Tester{r, a}
+	return newTester(r, a)
+}
+
+func newTester(r Reporter, a *app.App) *Tester {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		// Can't happen with nil options
+		panic(err)
+	}
+	return &Tester{Reporter: r, App: a, jar: jar}
+}
+
+// Client returns a new Tester for the same App and Reporter,
+// but with an empty cookie jar and history, which can be used
+// to simulate a different client.
+func (t *Tester) Client() *Tester {
+	return newTester(t.Reporter, t.App)
+}
+
+// Cookies returns the cookies which would be sent to the
+// App in a request to the given path.
+func (t *Tester) Cookies(path string) []*http.Cookie {
+	req, err := newHTTPRequest("GET", path, make(http.Header), nil)
+	if err != nil {
+		t.Reporter.Fatal(err)
+		return nil
+	}
+	return t.jar.Cookies(cookieURL(req))
+}
+
+// History returns the paths requested by this Tester,
+// in order, including any followed redirects.
+func (t *Tester) History() []string {
+	return t.history
+}
+
+// User returns the user currently signed in, or nil if there's
+// no signed in user. The user is retrieved using the App UserFunc
+// from the cookies stored in the Tester cookie jar. Note that when
+// running the tests against a remote server, the App must share
+// the same Secret with the remote server.
+func (t *Tester) User() app.User {
+	req, err := newHTTPRequest("GET", "/", make(http.Header), nil)
+	if err != nil {
+		t.Reporter.Fatal(err)
+		return nil
+	}
+	for _, v := range t.jar.Cookies(cookieURL(req)) {
+		req.AddCookie(v)
+	}
+	ctx := t.App.NewContext(nil)
+	defer t.App.CloseContext(ctx)
+	ctx.R = req
+	return ctx.User()
 }
 
 // Request returns a new request with the given method, path and body. Body
@@ -536,6 +793,7 @@ func (t *Tester) Request(method string, path string, body interface{}) *Request
 		Path:     path,
 		Body:     data,
 		err:      err,
+		tester:   t,
 	}
 }
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"gnd.la/app"
 	"gnd.la/app/tester"
+	"gnd.la/form"
 	"gnd.la/util/generic"
 	"gnd.la/util/stringutil"
 	"io/ioutil"
@@ -132,6 +133,175 @@ func TestExpectErrors(t *testing.T) {
 	}
 }
 
+func TestCookies(t *testing.T) {
+	tt := tester.New(t, testApp)
+	tt.Get("/get-cookie", nil).Expect("")
+	tt.Get("/set-cookie", map[string]interface{}{"value": "foo"}).Expect("")
+	tt.Get("/get-cookie", nil).Expect("foo")
+	if c := tt.Cookies("/"); len(c) != 1 || c[0].Name != "value" {
+		t.Errorf("expecting one cookie named value, got %v", c)
+	}
+	// Each Client has its own cookies
+	tt.Client().Get("/get-cookie", nil).Expect("")
+	tt.Get("/get-cookie", nil).Expect("foo")
+}
+
+func TestFollow(t *testing.T) {
+	tt := tester.New(t, testApp)
+	tt.Get("/redirect-1", nil).Expect(302).ExpectPath("/redirect-1")
+	r := tt.Post("/redirect-1", "data").Follow().Expect("hello world").ExpectPath("/hello")
+	redirects := r.Redirects()
+	expected := []tester.Redirect{
+		{Code: 302, From: "/redirect-1", To: "/redirect-2"},
+		{Code: 303, From: "/redirect-2", To: "/hello"},
+	}
+	if len(redirects) != len(expected) {
+		t.Fatalf("expecting %d redirects, got %d", len(expected), len(redirects))
+	}
+	for ii, v := range redirects {
+		if *v != expected[ii] {
+			t.Errorf("expecting redirect %d = %+v, got %+v", ii, expected[ii], *v)
+		}
+	}
+	// Cookies set while following redirects must be stored
+	tt.Get("/set-cookie-redirect", nil).Follow().Expect("bar")
+	// 307 preserves the method and the body
+	tt.Post("/redirect-307", "data").Follow().Expect("data").ExpectPath("/echo")
+	r2 := &reporter{T: t}
+	tt = tester.New(r2, testApp)
+	tt.Get("/redirect-loop", nil).Follow().Expect(200)
+	if r2.err == nil || !strings.Contains(r2.err.Error(), "redirects") {
+		t.Errorf("expecting too many redirects error, got %v", r2.err)
+	}
+}
+
+func TestUser(t *testing.T) {
+	tt := tester.New(t, testApp)
+	tt.Get("/hello", nil).ExpectUser(0)
+	tt.Get("/sign-in", map[string]interface{}{"id": 7}).Follow().ExpectPath("/hello").ExpectUser(7)
+	if u := tt.User(); u == nil || u.Id() != 7 {
+		t.Errorf("expecting user 7, got %v", u)
+	}
+	tt.Client().Get("/hello", nil).ExpectUser(0)
+	r := &reporter{T: t}
+	tt = tester.New(r, testApp)
+	tt.Get("/hello", nil).ExpectUser(7)
+	if r.err == nil {
+		t.Error("expecting an error")
+	}
+}
+
+func TestSubmitForm(t *testing.T) {
+	tt := tester.New(t, testApp)
+	tt.SubmitForm("/form", map[string]interface{}{"name": "Alice", "age": 27}).Expect("Alice - 27 - true")
+	// Without the CSRF fields, the form is not valid
+	tt.Form("/form", map[string]interface{}{"name": "Alice", "age": 27}).Expect("invalid")
+	tt.SubmitForm("/search", map[string]interface{}{"q": "gondola"}).
+		ExpectPath("/echo-form?lang=en&q=gondola").Expect("lang=en\nq=gondola\n")
+	tt.SubmitForm("/search", map[string]interface{}{"q": []string{"a", "b"}}).ExpectPath("/echo-form?lang=en&q=a&q=b")
+	tt.SubmitForm("/multipart", map[string]interface{}{"text": "hello"}).Expect("hello - world")
+	r := &reporter{T: t}
+	tt = tester.New(r, testApp)
+	tt.SubmitForm("/search", map[string]interface{}{"nothing": 1})
+	if r.fatal == nil || !strings.Contains(r.fatal.Error(), "no form") {
+		t.Errorf("expecting no form error, got %v", r.fatal)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tt := tester.New(t, testApp)
+	type item struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var schema struct {
+		Total int     `json:"total"`
+		Items []*item `json:"items"`
+		Next  string  `json:"next,omitempty"`
+	}
+	tt.Get("/json", nil).Expect(200).
+		ExpectJSON("total", 2).
+		ExpectJSON("items.0", map[string]interface{}{"id": 1, "name": "one"}).
+		ExpectJSON("items[1].name", "two").
+		ExpectJSON("items.-1", item{2, "two"}).
+		ExpectJSON("meta", nil).
+		ExpectJSONType("items", tester.JSONArray).
+		ExpectJSONType("meta", tester.JSONNull).
+		ExpectJSONLen("items", 2).
+		ExpectJSONLen("items.0", 2).
+		ExpectJSONSchema("", &schema).
+		ExpectJSONSchema("items.0", item{})
+	r := &reporter{T: t}
+	tt = tester.New(r, testApp)
+	checks := []struct {
+		check  func(*tester.Request)
+		expect string
+	}{
+		{func(req *tester.Request) { req.ExpectJSON("items.0.name", "uno") }, "- \"uno\"\n+ \"one\""},
+		{func(req *tester.Request) { req.ExpectJSON("items.2", nil) }, "out of range"},
+		{func(req *tester.Request) { req.ExpectJSON("nothing", nil) }, "no key \"nothing\""},
+		{func(req *tester.Request) { req.ExpectJSONType("total", tester.JSONString) }, "expecting JSON string"},
+		{func(req *tester.Request) { req.ExpectJSONLen("items", 3) }, "got length 2"},
+		{func(req *tester.Request) {
+			req.ExpectJSONSchema("items.0", struct {
+				Id   string
+				Rank int
+			}{})
+		}, "$.items.0.id: expecting string, got number\n$.items.0: missing field \"Rank\""},
+	}
+	for _, v := range checks {
+		r.err = nil
+		v.check(tt.Get("/json", nil))
+		if r.err == nil || !strings.Contains(r.err.Error(), v.expect) {
+			t.Errorf("expecting error containing %q, got %v", v.expect, r.err)
+		}
+	}
+	r.err = nil
+	tt.Get("/hello", nil).ExpectJSON("", "hello world")
+	if r.err == nil || !strings.Contains(r.err.Error(), "error decoding response body as JSON") {
+		t.Errorf("expecting JSON decoding error, got %v", r.err)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tt := tester.New(t, testApp)
+	tt.Get("/html", nil).Expect(200).
+		ExpectHTML("ul.items > li").
+		ExpectHTMLCount("li", 3).
+		ExpectHTMLCount("li.missing", 0).
+		ExpectHTMLText("h1", "Hello & welcome").
+		ExpectHTMLText("li:last-child", tester.Match("^th")).
+		ExpectHTMLAttr("a.next", "href", "/page/2")
+	if n := tt.Get("/html", nil).HTML(); n == nil || n.Tag != "html" {
+		t.Errorf("expecting root html node, got %v", n)
+	}
+	r := &reporter{T: t}
+	tt = tester.New(r, testApp)
+	tt.Get("/html", nil).ExpectHTMLCount("li", 1)
+	if r.err == nil || !strings.Contains(r.err.Error(), "found 3:\n\t<li>one</li>") {
+		t.Errorf("expecting count error, got %v", r.err)
+	}
+	r.err = nil
+	tt.Get("/html", nil).ExpectHTMLText("h1", "Goodbye")
+	if r.err == nil || !strings.Contains(r.err.Error(), "expecting text of \"h1\" = \"Goodbye\"") {
+		t.Errorf("expecting text error, got %v", r.err)
+	}
+	r.err = nil
+	tt.Get("/html", nil).ExpectHTML("table")
+	if r.err == nil {
+		t.Error("expecting an error")
+	}
+	tt.Get("/html", nil).ExpectHTML("li >")
+	if r.fatal == nil || !strings.Contains(r.fatal.Error(), "invalid selector") {
+		t.Errorf("expecting invalid selector error, got %v", r.fatal)
+	}
+}
+
+type testUser int64
+
+func (u testUser) Id() int64     { return int64(u) }
+func (u testUser) IsAdmin() bool { return false }
+
 func init() {
 	testApp = app.New()
 	testApp.Config().Secret = stringutil.Random(32)
@@ -173,4 +343,93 @@ func init() {
 		ctx.WriteHeader(200)
 		ctx.WriteHeader(300)
 	})
+	testApp.Handle("^/set-cookie$", func(ctx *app.Context) {
+		ctx.Cookies().Set("value", ctx.FormValue("value"))
+	})
+	testApp.Handle("^/get-cookie$", func(ctx *app.Context) {
+		var value string
+		ctx.Cookies().Get("value", &value)
+		ctx.WriteString(value)
+	})
+	testApp.Handle("^/set-cookie-redirect$", func(ctx *app.Context) {
+		ctx.Cookies().Set("value", "bar")
+		ctx.Redirect("/get-cookie", false)
+	})
+	testApp.Handle("^/redirect-1$", func(ctx *app.Context) {
+		ctx.Redirect("/redirect-2", false)
+	})
+	testApp.Handle("^/redirect-2$", func(ctx *app.Context) {
+		if ctx.R.Method != "GET" {
+			panic(fmt.Errorf("expecting GET, got %s", ctx.R.Method))
+		}
+		ctx.Header().Set("Location", "/hello")
+		ctx.WriteHeader(303)
+	})
+	testApp.Handle("^/redirect-307$", func(ctx *app.Context) {
+		ctx.Header().Set("Location", "/echo")
+		ctx.WriteHeader(307)
+	})
+	testApp.Handle("^/redirect-loop$", func(ctx *app.Context) {
+		ctx.Redirect("/redirect-loop", false)
+	})
+	testApp.Handle("^/json$", func(ctx *app.Context) {
+		ctx.WriteJSON(map[string]interface{}{
+			"total": 2,
+			"items": []map[string]interface{}{
+				{"id": 1, "name": "one"},
+				{"id": 2, "name": "two"},
+			},
+			"meta": nil,
+		})
+	})
+	testApp.Handle("^/html$", func(ctx *app.Context) {
+		ctx.WriteString(`<!DOCTYPE html><title>Test</title><h1>Hello &amp; welcome</h1>
+<ul class="items"><li>one</li><li>two</li><li>three</li></ul><a class="next" href="/page/2">Next</a>`)
+	})
+	testApp.SetUserFunc(func(ctx *app.Context, id int64) app.User {
+		return testUser(id)
+	})
+	testApp.Handle("^/sign-in$", func(ctx *app.Context) {
+		var id int64
+		ctx.ParseFormValue("id", &id)
+		if err := ctx.SignIn(testUser(id)); err != nil {
+			panic(err)
+		}
+		ctx.Redirect("/hello", false)
+	})
+	testApp.Handle("^/form$", func(ctx *app.Context) {
+		var person struct {
+			Name      string
+			Age       int
+			Subscribe bool
+		}
+		person.Subscribe = true
+		f := form.New(ctx, &person)
+		if f.Submitted() {
+			if f.IsValid() {
+				fmt.Fprintf(ctx, "%s - %d - %v", person.Name, person.Age, person.Subscribe)
+			} else {
+				ctx.WriteString("invalid")
+			}
+			return
+		}
+		html, err := f.Render()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(ctx, "<html><body><form method=\"post\">%s</form></body></html>", html)
+	})
+	testApp.Handle("^/search$", func(ctx *app.Context) {
+		ctx.WriteString(`<form action="/other"><input name="other"></form>
+<form action="echo-form"><input type="hidden" name="lang" value="en"><input type="text" name="q">
+<input type="text" name="disabled" value="1" disabled><input type="submit" name="go" value="Go"></form>`)
+	})
+	testApp.Handle("^/multipart$", func(ctx *app.Context) {
+		if ctx.R.Method == "POST" {
+			fmt.Fprintf(ctx, "%s - %s", ctx.R.FormValue("text"), ctx.R.FormValue("extra"))
+			return
+		}
+		ctx.WriteString(`<form method="POST" enctype="multipart/form-data"><input name="text">
+<textarea name="extra">world</textarea><input type="file" name="file"></form>`)
+	})
 }
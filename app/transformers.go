@@ -12,17 +12,29 @@ type Transformer func(Handler) Handler
 
 // SignedIn returns a new Handler which requires a signed in
 // user to be executed. If there's no signed in user, it returns
-// a redirect to the handler named "sign-in", indicating the
-// previous url in the "from" parameter. If there's no handler
-// named "sign-in", it panics. It also adds "Cookie" to the Vary
-// header, and "private" to the Cache-Control header.
+// a redirect to the handler named by SignInHandlerName ("sign-in"
+// by default), indicating the previous url in the "from" parameter.
+// When used in an included app, the handler is looked up starting
+// from the top level app. If there's no such handler, it panics.
+// It also adds "Cookie" to the Vary header, and "private" to the
+// Cache-Control header.
 func SignedIn(handler Handler) Handler {
 	return func(ctx *Context) {
 		h := ctx.Header()
 		h.Add("Vary", "Cookie")
 		h.Add("Cache-Control", "private")
 		if ctx.User() == nil {
-			signIn := ctx.MustReverse("sign-in")
+			// Reverse from the top level app, so handlers
+			// in included apps can also require a signed in
+			// user.
+			a := ctx.app
+			for a.parent != nil {
+				a = a.parent
+			}
+			signIn, err := a.Reverse(SignInHandlerName)
+			if err != nil {
+				panic(err)
+			}
 			u, err := url.Parse(signIn)
 			if err != nil {
 				panic(err)
@@ -0,0 +1,224 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/config"
+	"gnd.la/orm"
+	_ "gnd.la/orm/driver/memory"
+	"gnd.la/util/stringutil"
+
+	"gopkgs.com/vfs.v1"
+)
+
+var (
+	testApp   *app.App
+	setupOnce sync.Once
+)
+
+const (
+	testAdmin = 1
+	testUser  = 2
+)
+
+type user int64
+
+func (u user) Id() int64     { return int64(u) }
+func (u user) IsAdmin() bool { return u == testAdmin }
+
+type Book struct {
+	Id     int64  `orm:",primary_key,auto_increment"`
+	Title  string `orm:",index"`
+	Author string `form:",optional"`
+	Pages  int    `form:",optional"`
+}
+
+func (b *Book) AdminSave(ctx *app.Context) error {
+	if b.Title == "Forbidden" {
+		return errors.New("forbidden title")
+	}
+	return nil
+}
+
+func (b *Book) AdminDelete(ctx *app.Context) error {
+	if b.Author == "Keeper" {
+		return errors.New("books by Keeper can't be deleted")
+	}
+	return nil
+}
+
+type Secret struct {
+	Id    int64 `orm:",primary_key,auto_increment"`
+	Value string
+}
+
+func (s *Secret) AdminHidden() bool { return true }
+
+func init() {
+	orm.Register(&Book{}, &orm.Options{Table: "admin_test_book"})
+	orm.Register(&Secret{}, &orm.Options{Table: "admin_test_secret"})
+}
+
+func newTester(t *testing.T) *tester.Tester {
+	setupOnce.Do(func() {
+		a := app.New()
+		a.Logger = nil
+		a.Config().Secret = stringutil.Random(32)
+		a.Config().Database = config.MustParseURL("memory://admin")
+		a.SetUserFunc(func(ctx *app.Context, id int64) app.User {
+			return user(id)
+		})
+		a.HandleNamed("^/sign-in/$", func(ctx *app.Context) {
+			var id int64
+			ctx.ParseFormValue("u", &id)
+			if err := ctx.SignIn(user(id)); err != nil {
+				panic(err)
+			}
+			ctx.WriteString("ok")
+		}, app.SignInHandlerName)
+		fs, err := vfs.Map(map[string]*vfs.File{
+			"admin-base.html": &vfs.File{Data: []byte(`<html><body>{{ app }}</body></html>`)},
+		})
+		if err != nil {
+			panic(err)
+		}
+		a.SetTemplatesFS(fs)
+		a.Include("/admin/", App, "admin-base.html")
+		testApp = a
+	})
+	return tester.New(t, testApp)
+}
+
+func signIn(tt *tester.Tester, id int64) {
+	tt.Get("/sign-in/", map[string]interface{}{"u": id}).Expect(200)
+}
+
+// insertBook inserts a new book with a unique title
+// prefixed by the given one, since the ORM is shared
+// by all tests.
+func insertBook(t *testing.T, title string, author string) *Book {
+	ctx := testApp.NewContext(nil)
+	defer testApp.CloseContext(ctx)
+	b := &Book{Title: title + " " + stringutil.Random(8), Author: author}
+	if _, err := ctx.Orm().Insert(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func loadBook(t *testing.T, id int64) *Book {
+	ctx := testApp.NewContext(nil)
+	defer testApp.CloseContext(ctx)
+	var b Book
+	ok, err := ctx.Orm().One(orm.Eq("Id", id), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		return nil
+	}
+	return &b
+}
+
+func bookURL(handler string, b *Book) string {
+	return "/admin/admin_test_book/" + handler + "/?Id=" + strconv.FormatInt(b.Id, 10)
+}
+
+func TestPermissionDenied(t *testing.T) {
+	tt := newTester(t)
+	b := insertBook(t, "Denied", "")
+	paths := []string{
+		"/admin/",
+		"/admin/admin_test_book/",
+		"/admin/admin_test_book/add/",
+		bookURL("edit", b),
+		bookURL("delete", b),
+	}
+	// Signed out users are sent to the sign in page of the
+	// app which includes the admin
+	for _, v := range paths {
+		tt.Get(v, nil).Expect(302).ExpectHeader("Location", tester.Match("^/sign-in/\\?from="))
+	}
+	// Users without the permission are rejected
+	signIn(tt, testUser)
+	for _, v := range paths {
+		tt.Get(v, nil).Expect(403)
+	}
+	tt.Form("/admin/admin_test_book/add/", map[string]interface{}{"title": "Not added"}).Expect(403)
+	tt.Form(bookURL("edit", b), map[string]interface{}{"title": "Not edited"}).Expect(403)
+	tt.Form(bookURL("delete", b), nil).Expect(403)
+	if cur := loadBook(t, b.Id); cur == nil || cur.Title != b.Title {
+		t.Errorf("expecting book %+v to be unchanged, got %+v", b, cur)
+	}
+}
+
+func TestIndexAndList(t *testing.T) {
+	tt := newTester(t)
+	signIn(tt, testAdmin)
+	// Hidden models are not listed
+	tt.Get("/admin/", nil).Expect(200).Contains("Book").
+		ExpectHTMLCount("a[href='/admin/admin_test_secret/']", 0)
+	tt.Get("/admin/admin_test_secret/", nil).Expect(404)
+	tt.Get("/admin/nonexistent/", nil).Expect(404)
+	first := insertBook(t, "Listed", "First")
+	second := insertBook(t, "Listed", "Second")
+	tt.Get("/admin/admin_test_book/", nil).Expect(200).Contains(first.Title).Contains(second.Title)
+	// Indexed string fields filter by their contents
+	tt.Get("/admin/admin_test_book/", map[string]interface{}{"Title": first.Title[len("Listed "):]}).
+		Expect(200).Contains(first.Title).ExpectHTMLCount(".admin-list tbody tr", 1)
+	// Invalid filters are shown as errors
+	tt.Get("/admin/admin_test_book/", map[string]interface{}{"Id": "abc"}).Expect(200).ExpectHTML(".has-error")
+	// Sorting by a listed field in descending order
+	tt.Get("/admin/admin_test_book/", map[string]interface{}{"Id": second.Id, "sort": "-Title"}).
+		Expect(200).ExpectHTMLText(".admin-list tbody td", strconv.FormatInt(second.Id, 10))
+}
+
+func TestAddEditDelete(t *testing.T) {
+	tt := newTester(t)
+	signIn(tt, testAdmin)
+	title := "Added " + stringutil.Random(8)
+	tt.SubmitForm("/admin/admin_test_book/add/", map[string]interface{}{"title": title, "pages": 10}).
+		Expect(302).ExpectHeader("Location", "/admin/admin_test_book/")
+	ctx := testApp.NewContext(nil)
+	var b Book
+	ok := ctx.Orm().MustOne(orm.Eq("Title", title), &b)
+	testApp.CloseContext(ctx)
+	if !ok || b.Pages != 10 {
+		t.Fatalf("expecting a book with 10 pages titled %q, got %+v", title, b)
+	}
+	// Required fields
+	tt.SubmitForm("/admin/admin_test_book/add/", map[string]interface{}{"title": ""}).Expect(200)
+	// Edit
+	tt.SubmitForm(bookURL("edit", &b), map[string]interface{}{"author": "Someone"}).Expect(302)
+	if cur := loadBook(t, b.Id); cur == nil || cur.Author != "Someone" || cur.Title != title {
+		t.Errorf("expecting edited book, got %+v", cur)
+	}
+	// Saver errors prevent saving the object
+	tt.SubmitForm(bookURL("edit", &b), map[string]interface{}{"title": "Forbidden"}).
+		Expect(200).Contains("forbidden title")
+	if cur := loadBook(t, b.Id); cur == nil || cur.Title != title {
+		t.Errorf("expecting unchanged title %q, got %+v", title, cur)
+	}
+	tt.Get("/admin/admin_test_book/edit/?Id=0", nil).Expect(404)
+	tt.Get("/admin/admin_test_book/edit/?Id=abc", nil).Expect(404)
+	// Deleter errors prevent deleting the object
+	kept := insertBook(t, "Kept", "Keeper")
+	tt.SubmitForm(bookURL("delete", kept), nil).Expect(200).Contains("can&#39;t be deleted")
+	if loadBook(t, kept.Id) == nil {
+		t.Error("expecting book to not be deleted")
+	}
+	// Deleting requires the CSRF token
+	tt.Form(bookURL("delete", &b), nil).Expect(200)
+	if loadBook(t, b.Id) == nil {
+		t.Error("expecting book to not be deleted without a CSRF token")
+	}
+	tt.SubmitForm(bookURL("delete", &b), nil).Expect(302).ExpectHeader("Location", "/admin/admin_test_book/")
+	if loadBook(t, b.Id) != nil {
+		t.Error("expecting book to be deleted")
+	}
+}
@@ -0,0 +1,46 @@
+// Package admin implements an administration interface for the models
+// registered with the ORM, providing list views with sorting, filtering
+// and pagination, as well as edit forms and delete confirmations.
+//
+// To use this app, include it in your app:
+//
+//  myapp.Include("/admin/", admin.App, "admin-base.html")
+//
+// All the handlers require the current user to have the permission
+// named by Permission, checked with gnd.la/app.Context.Can. Unless
+// the app sets a PermissionFunc, only the administrators have it.
+//
+// Every model registered with the ORM is listed in the index page. The
+// list views and the edit forms are generated by inspecting the model
+// fields, but models might customize them by implementing any of the
+// following interfaces:
+//
+//  - Hider, for hiding the model from the admin.
+//  - ListFielder, Filterer and Sorter, for customizing the list view.
+//  - Fielder, for selecting the fields in the edit form. Validation
+//	can be performed by implementing gnd.la/form.Validator.
+//  - Saver and Deleter, for running code before an object is saved
+//	or deleted, or preventing the operation.
+//
+// The edit forms are generated with gnd.la/form, so the form struct tags
+// in the model are respected. Note that fields are required by default,
+// so fields which might be empty should be tagged with form:",optional".
+//
+// For example:
+//
+//  func (a *Article) AdminListFields() []string {
+//	return []string{"Id", "Title", "Published"}
+//  }
+//
+//  func (a *Article) AdminSort() string {
+//	return "-Published"
+//  }
+//
+//  func (a *Article) AdminSave(ctx *app.Context) error {
+//	a.Updated = time.Now().UTC()
+//	return nil
+//  }
+//
+// Objects are displayed using their String method, when they
+// implement fmt.Stringer, or their primary key otherwise.
+package admin
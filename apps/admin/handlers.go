@@ -0,0 +1,336 @@
+package admin
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gnd.la/app"
+	"gnd.la/bootstrap/paginator"
+	"gnd.la/form"
+	"gnd.la/orm"
+	"gnd.la/orm/query"
+)
+
+const (
+	IndexHandlerName  = "admin-index"
+	ListHandlerName   = "admin-list"
+	AddHandlerName    = "admin-add"
+	EditHandlerName   = "admin-edit"
+	DeleteHandlerName = "admin-delete"
+
+	sortParameterName = "sort"
+	pageParameterName = "p"
+)
+
+var (
+	// Permission is the permission required for using the admin,
+	// checked with gnd.la/app.Context.Can.
+	Permission = "admin"
+	// PerPage is the number of objects shown in each
+	// page of the list view.
+	PerPage = 50
+
+	// IndexTemplateName is the name of the template used
+	// to render the list of models.
+	IndexTemplateName = "index.html"
+	// ListTemplateName is the name of the template used to
+	// render the list of objects of a model.
+	ListTemplateName = "list.html"
+	// EditTemplateName is the name of the template used to
+	// render the form for adding or editing an object.
+	EditTemplateName = "edit.html"
+	// DeleteTemplateName is the name of the template used to
+	// render the confirmation for deleting an object.
+	DeleteTemplateName = "delete.html"
+
+	IndexHandler  = app.NamedHandler(IndexHandlerName, adminHandler(indexHandler))
+	ListHandler   = app.NamedHandler(ListHandlerName, adminHandler(listHandler))
+	AddHandler    = app.NamedHandler(AddHandlerName, adminHandler(addHandler))
+	EditHandler   = app.NamedHandler(EditHandlerName, adminHandler(editHandler))
+	DeleteHandler = app.NamedHandler(DeleteHandlerName, adminHandler(deleteHandler))
+)
+
+type column struct {
+	Label   string
+	SortURL string
+	// Sorted is "asc" or "desc" when the list
+	// is sorted by this column.
+	Sorted string
+}
+
+type row struct {
+	Values    []string
+	EditURL   string
+	DeleteURL string
+}
+
+type filter struct {
+	Name  string
+	Label string
+	Value string
+	Error error
+}
+
+func adminHandler(handler app.Handler) app.Handler {
+	return func(ctx *app.Context) {
+		// Check Permission on every request, since
+		// it might be changed after initialization.
+		app.Permission(Permission)(handler)(ctx)
+	}
+}
+
+func currentModel(ctx *app.Context) *model {
+	m := modelByKey(ctx, ctx.IndexValue(0))
+	if m == nil {
+		ctx.NotFound("model not found")
+	}
+	return m
+}
+
+// objectURL returns the URL for the handler with the given
+// name for the given object, which is identified by its
+// primary key values.
+func objectURL(ctx *app.Context, name string, m *model, obj reflect.Value) string {
+	return ctx.MustReverse(name, m.Key) + "?" + m.pkValues(obj).Encode()
+}
+
+func indexHandler(ctx *app.Context) {
+	data := map[string]interface{}{
+		"Models": models(ctx),
+	}
+	ctx.MustExecute(IndexTemplateName, data)
+}
+
+func listHandler(ctx *app.Context) {
+	m := currentModel(ctx)
+	if m == nil {
+		return
+	}
+	o := ctx.Orm()
+	base := ctx.MustReverse(ListHandlerName, m.Key)
+	params := make(url.Values)
+	var filters []*filter
+	var conditions []query.Q
+	for _, v := range m.filterFields() {
+		f := &filter{Name: v, Label: m.label(v), Value: ctx.FormValue(v)}
+		filters = append(filters, f)
+		if f.Value == "" {
+			continue
+		}
+		q, err := m.filterQuery(v, f.Value)
+		if err != nil {
+			f.Error = err
+			continue
+		}
+		conditions = append(conditions, q)
+		params.Set(v, f.Value)
+	}
+	var q query.Q
+	if len(conditions) > 0 {
+		q = orm.And(conditions...)
+	}
+	listFields := m.listFields()
+	sort := ctx.FormValue(sortParameterName)
+	if sort != "" {
+		valid := false
+		for _, v := range listFields {
+			if v == strings.TrimPrefix(sort, "-") {
+				valid = true
+				break
+			}
+		}
+		if valid {
+			params.Set(sortParameterName, sort)
+		} else {
+			sort = ""
+		}
+	}
+	if sort == "" {
+		sort = m.defaultSort()
+	}
+	count, err := o.Count(m.table, q)
+	if err != nil {
+		panic(err)
+	}
+	pages := (int(count) + PerPage - 1) / PerPage
+	page := 1
+	ctx.ParseFormValue(pageParameterName, &page)
+	if page < 1 || page > pages {
+		page = 1
+	}
+	qs := o.Table(m.table).Filter(q).Limit(PerPage).Offset((page - 1) * PerPage)
+	if sort != "" {
+		if strings.HasPrefix(sort, "-") {
+			qs.Sort(sort[1:], orm.DESC)
+		} else {
+			qs.Sort(sort, orm.ASC)
+		}
+	}
+	objects := reflect.New(reflect.SliceOf(reflect.PtrTo(m.table.Type())))
+	if err := qs.All(objects.Interface()); err != nil {
+		panic(err)
+	}
+	var columns []*column
+	for _, v := range listFields {
+		col := &column{Label: m.label(v)}
+		sortParams := copyValues(params)
+		switch sort {
+		case v:
+			col.Sorted = "asc"
+			sortParams.Set(sortParameterName, "-"+v)
+		case "-" + v:
+			col.Sorted = "desc"
+			sortParams.Set(sortParameterName, v)
+		default:
+			sortParams.Set(sortParameterName, v)
+		}
+		col.SortURL = base + "?" + sortParams.Encode()
+		columns = append(columns, col)
+	}
+	var rows []*row
+	objs := objects.Elem()
+	for ii := 0; ii < objs.Len(); ii++ {
+		obj := objs.Index(ii)
+		r := &row{}
+		for _, v := range listFields {
+			r.Values = append(r.Values, formatValue(m.value(obj, v)))
+		}
+		if m.HasPrimaryKey() {
+			r.EditURL = objectURL(ctx, EditHandlerName, m, obj)
+			r.DeleteURL = objectURL(ctx, DeleteHandlerName, m, obj)
+		}
+		rows = append(rows, r)
+	}
+	data := map[string]interface{}{
+		"Model":   m,
+		"Columns": columns,
+		"Rows":    rows,
+		"Filters": filters,
+		"Count":   int(count),
+	}
+	if pages > 1 {
+		data["Paginator"] = paginator.New(base, page, pages, "»", "«", "…", func(base string, page int) string {
+			p := copyValues(params)
+			if page > 1 {
+				p.Set(pageParameterName, strconv.Itoa(page))
+			}
+			if len(p) == 0 {
+				return base
+			}
+			return base + "?" + p.Encode()
+		})
+	}
+	ctx.MustExecute(ListTemplateName, data)
+}
+
+// loadObject loads the object identified by the primary key values
+// in the request. If there's no such object, it sends a 404 response
+// and returns an invalid reflect.Value.
+func loadObject(ctx *app.Context, m *model) reflect.Value {
+	q, err := m.pkQuery(ctx.R.URL.Query())
+	if err != nil {
+		ctx.NotFound(err.Error())
+		return reflect.Value{}
+	}
+	obj := m.newObject()
+	ok, err := ctx.Orm().Table(m.table).Filter(q).One(obj.Interface())
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		ctx.NotFound("object not found")
+		return reflect.Value{}
+	}
+	return obj
+}
+
+func addHandler(ctx *app.Context) {
+	editObject(ctx, true)
+}
+
+func editHandler(ctx *app.Context) {
+	editObject(ctx, false)
+}
+
+func editObject(ctx *app.Context, isNew bool) {
+	m := currentModel(ctx)
+	if m == nil {
+		return
+	}
+	var obj reflect.Value
+	if isNew {
+		obj = m.newObject()
+	} else {
+		if obj = loadObject(ctx, m); !obj.IsValid() {
+			return
+		}
+	}
+	f := form.NewOpts(ctx, &form.Options{Fields: m.editFields(isNew)}, obj.Interface())
+	data := map[string]interface{}{
+		"Model": m,
+		"Form":  f,
+		"IsNew": isNew,
+	}
+	if !isNew {
+		data["Object"] = m.display(obj)
+		data["DeleteURL"] = objectURL(ctx, DeleteHandlerName, m, obj)
+	}
+	if f.Submitted() && f.IsValid() {
+		var err error
+		if s, ok := obj.Interface().(Saver); ok {
+			err = s.AdminSave(ctx)
+		}
+		if err == nil {
+			_, err = ctx.Orm().Save(obj.Interface())
+		}
+		if err == nil {
+			ctx.MustRedirectReverse(false, ListHandlerName, m.Key)
+			return
+		}
+		data["Error"] = err
+	}
+	ctx.MustExecute(EditTemplateName, data)
+}
+
+func deleteHandler(ctx *app.Context) {
+	m := currentModel(ctx)
+	if m == nil {
+		return
+	}
+	obj := loadObject(ctx, m)
+	if !obj.IsValid() {
+		return
+	}
+	// Use an empty form for the CSRF protection
+	f := form.New(ctx)
+	data := map[string]interface{}{
+		"Model":  m,
+		"Object": m.display(obj),
+		"Form":   f,
+	}
+	if f.Submitted() && f.IsValid() {
+		var err error
+		if d, ok := obj.Interface().(Deleter); ok {
+			err = d.AdminDelete(ctx)
+		}
+		if err == nil {
+			err = ctx.Orm().Delete(obj.Interface())
+		}
+		if err == nil {
+			ctx.MustRedirectReverse(false, ListHandlerName, m.Key)
+			return
+		}
+		data["Error"] = err
+	}
+	ctx.MustExecute(DeleteTemplateName, data)
+}
+
+func copyValues(values url.Values) url.Values {
+	cpy := make(url.Values, len(values))
+	for k, v := range values {
+		cpy[k] = append([]string(nil), v...)
+	}
+	return cpy
+}
@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"gnd.la/app"
+)
+
+// Hider is implemented by models which should not be
+// shown in the admin when AdminHidden returns true.
+type Hider interface {
+	AdminHidden() bool
+}
+
+// ListFielder is implemented by models which customize the fields
+// shown in the list view. By default, the primary key and the first
+// editable fields are shown.
+type ListFielder interface {
+	AdminListFields() []string
+}
+
+// Filterer is implemented by models which customize the fields which
+// might be used to filter the list view. String fields are filtered
+// by their contents, while other types require an exact match. By
+// default, the primary key and the indexed fields can be used
+// as filters.
+type Filterer interface {
+	AdminFilterFields() []string
+}
+
+// Sorter is implemented by models which customize the default sorting
+// of the list view. AdminSort must return the name of the field to
+// sort by, prefixed with '-' for descending order (e.g. -Created). By
+// default, objects are sorted by their primary key.
+type Sorter interface {
+	AdminSort() string
+}
+
+// Fielder is implemented by models which customize the fields in the
+// edit form. By default, all the fields which can be edited using
+// gnd.la/form are included, excluding the auto increment primary key
+// and the fields which are encoded with a codec or a pipe. Note that
+// the primary key fields are never editable for existing objects.
+type Fielder interface {
+	AdminFields() []string
+}
+
+// Saver is implemented by models which need to run some code before
+// being saved from the admin. If AdminSave returns an error, the object
+// is not saved and the error is displayed to the user.
+type Saver interface {
+	AdminSave(ctx *app.Context) error
+}
+
+// Deleter is implemented by models which need to run some code before
+// being deleted from the admin. If AdminDelete returns an error, the
+// object is not deleted and the error is displayed to the user.
+type Deleter interface {
+	AdminDelete(ctx *app.Context) error
+}
@@ -0,0 +1,322 @@
+package admin
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gnd.la/app"
+	"gnd.la/form/input"
+	"gnd.la/orm"
+	"gnd.la/orm/driver"
+	"gnd.la/orm/query"
+	"gnd.la/util/stringutil"
+	"gnd.la/util/types"
+)
+
+const (
+	// Maximum number of fields shown by default in the list view
+	defaultListFields = 6
+	// Longer values are truncated in the list view
+	maxValueLength = 80
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// model wraps an *orm.Table with the information
+// required by the admin handlers.
+type model struct {
+	table *orm.Table
+	// Key is the name used for the model in the URLs
+	Key string
+	// Name is the name shown to the user
+	Name string
+	// Indexes of the fields which form the primary key
+	pk []int
+}
+
+func newModel(t *orm.Table) *model {
+	fields := t.Fields()
+	m := &model{
+		table: t,
+		Key:   t.TableName(),
+		Name:  stringutil.CamelCaseToWords(t.Type().Name(), " "),
+	}
+	if fields.PrimaryKey >= 0 {
+		m.pk = []int{fields.PrimaryKey}
+	} else {
+		m.pk = fields.CompositePrimaryKey
+	}
+	return m
+}
+
+// models returns all the models registered in the
+// ORM, excluding the ones hidden using Hider.
+func models(ctx *app.Context) []*model {
+	var models []*model
+	for _, v := range ctx.Orm().Tables() {
+		m := newModel(v)
+		if h, ok := m.hooks().(Hider); ok && h.AdminHidden() {
+			continue
+		}
+		models = append(models, m)
+	}
+	return models
+}
+
+// modelByKey returns the model with the given key
+// or nil if there's no such model.
+func modelByKey(ctx *app.Context, key string) *model {
+	for _, v := range models(ctx) {
+		if v.Key == key {
+			return v
+		}
+	}
+	return nil
+}
+
+func (m *model) fields() *driver.Fields {
+	return m.table.Fields()
+}
+
+// newObject returns a pointer to a new zero object.
+func (m *model) newObject() reflect.Value {
+	return reflect.New(m.table.Type())
+}
+
+// hooks returns a new object, to be type asserted to the
+// interfaces which allow customizing the admin.
+func (m *model) hooks() interface{} {
+	return m.newObject().Interface()
+}
+
+// HasPrimaryKey returns true iff the model has a primary key,
+// which is required for editing and deleting objects.
+func (m *model) HasPrimaryKey() bool {
+	return len(m.pk) > 0
+}
+
+func (m *model) isPk(idx int) bool {
+	for _, v := range m.pk {
+		if v == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) pkNames() []string {
+	fields := m.fields()
+	names := make([]string, len(m.pk))
+	for ii, v := range m.pk {
+		names[ii] = fields.QNames[v]
+	}
+	return names
+}
+
+// editable returns true iff the field at the given index can
+// be edited using gnd.la/form and parsed with gnd.la/form/input.
+func (m *model) editable(idx int) bool {
+	fields := m.fields()
+	tag := fields.Tags[idx]
+	if tag.CodecName() != "" || tag.PipeName() != "" {
+		return false
+	}
+	typ := fields.Types[idx]
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return typ == timeType
+}
+
+// editFields returns the fields in the edit form. The primary key is
+// only included for new objects without an auto increment primary key.
+func (m *model) editFields(isNew bool) []string {
+	fields := m.fields()
+	var names []string
+	if f, ok := m.hooks().(Fielder); ok {
+		for _, v := range f.AdminFields() {
+			if idx, ok := fields.QNameMap[v]; ok && m.isPk(idx) && (!isNew || fields.AutoincrementPk) {
+				continue
+			}
+			names = append(names, v)
+		}
+		return names
+	}
+	for ii, v := range fields.QNames {
+		if !m.editable(ii) || (m.isPk(ii) && (!isNew || fields.AutoincrementPk)) {
+			continue
+		}
+		names = append(names, v)
+	}
+	return names
+}
+
+// listFields returns the fields shown in the list view.
+func (m *model) listFields() []string {
+	if l, ok := m.hooks().(ListFielder); ok {
+		return l.AdminListFields()
+	}
+	names := m.pkNames()
+	fields := m.fields()
+	for ii, v := range fields.QNames {
+		if len(names) >= defaultListFields {
+			break
+		}
+		if !m.isPk(ii) && m.editable(ii) {
+			names = append(names, v)
+		}
+	}
+	return names
+}
+
+// filterFields returns the fields which can be used
+// to filter the list view.
+func (m *model) filterFields() []string {
+	if f, ok := m.hooks().(Filterer); ok {
+		return f.AdminFilterFields()
+	}
+	var names []string
+	fields := m.fields()
+	for ii, v := range fields.QNames {
+		if m.editable(ii) && (m.isPk(ii) || fields.Tags[ii].Has("index") || fields.Tags[ii].Has("unique")) {
+			names = append(names, v)
+		}
+	}
+	return names
+}
+
+// defaultSort returns the field used for sorting the list view
+// when the user hasn't selected one, prefixed with '-' for
+// descending order.
+func (m *model) defaultSort() string {
+	if s, ok := m.hooks().(Sorter); ok {
+		return s.AdminSort()
+	}
+	if len(m.pk) > 0 {
+		return m.fields().QNames[m.pk[0]]
+	}
+	return ""
+}
+
+func (m *model) label(name string) string {
+	return stringutil.CamelCaseToWords(name[strings.LastIndex(name, ".")+1:], " ")
+}
+
+// parse parses the given value into a value of the type of the
+// field with the given name.
+func (m *model) parse(name string, value string) (interface{}, error) {
+	idx, ok := m.fields().QNameMap[name]
+	if !ok {
+		return nil, fmt.Errorf("model %s has no field named %q", m.table.Name(), name)
+	}
+	val := reflect.New(m.fields().Types[idx])
+	if err := input.Parse(value, val.Interface()); err != nil {
+		return nil, err
+	}
+	return val.Elem().Interface(), nil
+}
+
+// filterQuery returns the query for filtering the list
+// view using the given field name and value.
+func (m *model) filterQuery(name string, value string) (query.Q, error) {
+	val, err := m.parse(name, value)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := val.(string); ok {
+		return orm.Contains(name, s), nil
+	}
+	return orm.Eq(name, val), nil
+}
+
+// pkQuery returns the query for selecting the object identified
+// by the primary key values in the given url.Values (see pkValues).
+func (m *model) pkQuery(values url.Values) (query.Q, error) {
+	if len(m.pk) == 0 {
+		return nil, fmt.Errorf("model %s has no primary key", m.table.Name())
+	}
+	var conditions []query.Q
+	for _, v := range m.pkNames() {
+		val, err := m.parse(v, values.Get(v))
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, orm.Eq(v, val))
+	}
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+	return orm.And(conditions...), nil
+}
+
+// pkValues returns the primary key values for the given object,
+// which can be encoded as query parameters to identify the object.
+func (m *model) pkValues(obj reflect.Value) url.Values {
+	values := make(url.Values)
+	for _, v := range m.pkNames() {
+		values.Set(v, types.ToString(m.value(obj, v).Interface()))
+	}
+	return values
+}
+
+// value returns the value of the field with the given name in
+// obj. If the field is inside a nil pointer, an invalid
+// reflect.Value is returned.
+func (m *model) value(obj reflect.Value, name string) reflect.Value {
+	idx, ok := m.fields().QNameMap[name]
+	if !ok {
+		panic(fmt.Errorf("model %s has no field named %q", m.table.Name(), name))
+	}
+	v := obj
+	for _, ii := range m.fields().Indexes[idx] {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(ii)
+	}
+	return v
+}
+
+// display returns the string used for representing
+// the given object to the user.
+func (m *model) display(obj reflect.Value) string {
+	if s, ok := obj.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	var values []string
+	for _, v := range m.pkNames() {
+		values = append(values, formatValue(m.value(obj, v)))
+	}
+	return fmt.Sprintf("%s %s", m.Name, strings.Join(values, ", "))
+}
+
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return ""
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05")
+	}
+	s := types.ToString(v.Interface())
+	if utf8.RuneCountInString(s) > maxValueLength {
+		s = string([]rune(s)[:maxValueLength-1]) + "…"
+	}
+	return s
+}
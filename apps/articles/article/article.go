@@ -29,6 +29,8 @@ var (
 	synopsisKey = "synopsis"
 	updatedKey  = "updated"
 	priorityKey = "priority"
+	tagsKey     = "tags"
+	authorKey   = "author"
 
 	timeFormats = []string{
 		"2006-01-02",
@@ -63,6 +65,11 @@ type Article struct {
 	// The priority to sort articles in the index. Articles with the same
 	// priority are sorted by title.
 	Priority int
+	// Tags contains the article tags, used for grouping
+	// articles in the tag listings and feeds.
+	Tags []string
+	// Author is the article author. Might be empty.
+	Author string
 	// Text contains the article text, with any properties stripped.
 	Text []byte
 	// Properties contains unknown properties, to allow forward
@@ -127,6 +134,17 @@ func (a *Article) LastUpdate() time.Time {
 	return t
 }
 
+// HasTag returns true iff the article has the given tag.
+// Tags are compared case insensitively.
+func (a *Article) HasTag(tag string) bool {
+	for _, v := range a.Tags {
+		if strings.EqualFold(v, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // Set sets a property in the article. For slice properties, it prepends the
 // new value to the existing ones.
 func (a *Article) Set(key string, value string) error {
@@ -176,6 +194,20 @@ func (a *Article) add(key string, value string, begin bool) error {
 		}
 	case priorityKey:
 		err = input.Parse(value, &a.Priority)
+	case tagsKey:
+		var tags []string
+		for _, v := range strings.Split(value, ",") {
+			if tag := strings.TrimSpace(v); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		if begin {
+			a.Tags = append(tags, a.Tags...)
+		} else {
+			a.Tags = append(a.Tags, tags...)
+		}
+	case authorKey:
+		a.Author = value
 	default:
 		if a.Properties == nil {
 			a.Properties = make(map[string][]string)
@@ -208,6 +240,12 @@ func (a *Article) WriteTo(w io.Writer) (int64, error) {
 	if a.Priority != 0 {
 		a.writeProperty(&buf, priorityKey, a.Priority)
 	}
+	if len(a.Tags) > 0 {
+		a.writeProperty(&buf, tagsKey, strings.Join(a.Tags, ", "))
+	}
+	if a.Author != "" {
+		a.writeProperty(&buf, authorKey, a.Author)
+	}
 	for k, v := range a.Properties {
 		for _, prop := range v {
 			a.writeProperty(&buf, k, prop)
@@ -22,6 +22,15 @@ func TestArticle(t *testing.T) {
 	if len(article.Properties) > 0 {
 		t.Fatalf("article should have no unknown properties, it has %v", article.Properties)
 	}
+	if tags := []string{"go", "web", "Gondola"}; !reflect.DeepEqual(article.Tags, tags) {
+		t.Errorf("expecting tags %v, got %v", tags, article.Tags)
+	}
+	if !article.HasTag("gondola") || article.HasTag("python") {
+		t.Errorf("invalid HasTag() results for tags %v", article.Tags)
+	}
+	if author := "Gondola Authors"; article.Author != author {
+		t.Errorf("expecting author %q, got %q", author, article.Author)
+	}
 	var buf bytes.Buffer
 	if _, err := article.WriteTo(&buf); err != nil {
 		t.Fatal(err)
@@ -1,12 +1,14 @@
 package articles
 
 import (
+	"fmt"
 	"path"
 	"strings"
 
 	"gnd.la/app"
 	"gnd.la/apps/articles/article"
 	"gnd.la/util/generic"
+	"gnd.la/util/stringutil"
 
 	"gopkgs.com/vfs.v1"
 )
@@ -30,6 +32,51 @@ func AppArticles(a *app.App) []*article.Article {
 	return articles
 }
 
+// Tags returns all the tags used by the articles loaded
+// into the given App, sorted alphabetically.
+func Tags(a *app.App) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, art := range AppArticles(a) {
+		for _, v := range art.Tags {
+			slug := tagSlug(v)
+			if !seen[slug] {
+				seen[slug] = true
+				tags = append(tags, v)
+			}
+		}
+	}
+	generic.SortFunc(tags, func(t1, t2 string) bool {
+		return strings.ToLower(t1) < strings.ToLower(t2)
+	})
+	return tags
+}
+
+// TaggedArticles returns the articles loaded into the given
+// App which have the given tag.
+func TaggedArticles(a *app.App, tag string) []*article.Article {
+	return taggedArticles(a, tagSlug(tag))
+}
+
+func taggedArticles(a *app.App, slug string) []*article.Article {
+	var articles []*article.Article
+	for _, art := range AppArticles(a) {
+		for _, v := range art.Tags {
+			if tagSlug(v) == slug {
+				articles = append(articles, art)
+				break
+			}
+		}
+	}
+	return articles
+}
+
+// tagSlug returns the slug used for the given tag in
+// the URLs.
+func tagSlug(tag string) string {
+	return stringutil.Slug(tag)
+}
+
 func setAppArticles(a *app.App, articles []*article.Article) {
 	a.Set(articlesKey, articles)
 }
@@ -60,6 +107,9 @@ func List(fs vfs.VFS, dir string) ([]*article.Article, error) {
 		if err != nil {
 			return nil, err
 		}
+		if slug := article.Slug(); strings.Contains(slug, "/") {
+			return nil, fmt.Errorf("article %s has slug %q, slugs can't contain slashes", p, slug)
+		}
 		articles = append(articles, article)
 	}
 	generic.SortFunc(articles, func(a1, a2 *article.Article) bool {
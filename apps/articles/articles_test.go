@@ -0,0 +1,203 @@
+package articles
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/app/sitemap"
+	"gnd.la/app/tester"
+
+	"gopkgs.com/vfs.v1"
+)
+
+const (
+	testArticle = `<p>First article</p>
+
+[title] = First
+[updated] = 2014-05-14 10:00:00
+[updated] = 2014-05-10 10:00:00
+[tags] = Go, Web
+[author] = Alice
+`
+	testDraft = `<p>Draft article</p>
+
+[title] = Draft
+[tags] = Go
+`
+)
+
+// newTestApp returns an App which includes a clone of the articles
+// App at /articles/, with the given files loaded as articles.
+func newTestApp(t *testing.T, files map[string]string) (*app.App, *app.App) {
+	fsFiles := make(map[string]*vfs.File)
+	for k, v := range files {
+		fsFiles[k] = &vfs.File{Data: []byte(v)}
+	}
+	fs, err := vfs.Map(fsFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	articlesApp := App.Clone()
+	if _, err := Load(articlesApp, fs, "/"); err != nil {
+		t.Fatal(err)
+	}
+	a := app.New()
+	a.Logger = nil
+	a.SetName("Test")
+	templates, err := vfs.Map(map[string]*vfs.File{
+		"articles-base.html": &vfs.File{Data: []byte(`<html><body>{{ app }}</body></html>`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetTemplatesFS(templates)
+	a.Include("/articles/", articlesApp, "articles-base.html")
+	return a, articlesApp
+}
+
+func TestArticleAndTagHandlers(t *testing.T) {
+	a, _ := newTestApp(t, map[string]string{"first.html": testArticle, "draft.html": testDraft})
+	tt := tester.New(t, a)
+	tt.Get("/articles/", nil).Expect(200).ExpectHTMLCount(".articles-article", 2)
+	tt.Get("/articles/first/", nil).Expect(200).Contains("First article").
+		ExpectHTMLText(".articles-author", "Written by Alice")
+	tt.Get("/articles/nonexistent/", nil).Expect(404)
+	// Tag pages are not handled as articles
+	tt.Get("/articles/tag/go/", nil).Expect(200).
+		ExpectHTMLText(".articles-list-title", "Go").
+		ExpectHTMLCount(".articles-article", 2).
+		ExpectHTML(".articles-feeds a[href='/articles/tag/go/atom.xml']")
+	tt.Get("/articles/tag/web/", nil).Expect(200).ExpectHTMLCount(".articles-article", 1)
+	tt.Get("/articles/tag/python/", nil).Expect(404)
+}
+
+func TestSlugWithSlash(t *testing.T) {
+	fs, err := vfs.Map(map[string]*vfs.File{
+		"slash.html": &vfs.File{Data: []byte("<p>Slash</p>\n\n[title] = Slash\n[slug] = with/slash\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := List(fs, "/"); err == nil {
+		t.Error("expecting an error when loading an article with a slash in its slug")
+	}
+}
+
+func TestAtomFeed(t *testing.T) {
+	a, _ := newTestApp(t, map[string]string{"first.html": testArticle, "draft.html": testDraft})
+	tt := tester.New(t, a)
+	body := tt.Get("/articles/atom.xml", nil).Expect(200).
+		ExpectHeader("Content-Type", atomContentType).ResponseBody()
+	var feed atomFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Articles" || feed.Id != "http://localhost/articles/atom.xml" {
+		t.Errorf("unexpected feed title %q or id %q", feed.Title, feed.Id)
+	}
+	if feed.Updated != "2014-05-14T10:00:00Z" {
+		t.Errorf("expecting updated 2014-05-14T10:00:00Z, got %q", feed.Updated)
+	}
+	// Articles without updates are not included
+	if len(feed.Entries) != 1 {
+		t.Fatalf("expecting 1 entry, got %d", len(feed.Entries))
+	}
+	e := feed.Entries[0]
+	if e.Title != "First" || e.Published != "2014-05-10T10:00:00Z" || e.Updated != "2014-05-14T10:00:00Z" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e.Id != "tag:localhost,2014-05-10:first" {
+		t.Errorf("unexpected entry id %q", e.Id)
+	}
+	if e.Author == nil || e.Author.Name != "Alice" || len(e.Categories) != 2 {
+		t.Errorf("unexpected entry author %v or categories %v", e.Author, e.Categories)
+	}
+	if e.Content == nil || !strings.Contains(e.Content.Text, "First article") {
+		t.Errorf("unexpected entry content %v", e.Content)
+	}
+	// Tag feeds
+	body = tt.Get("/articles/tag/web/atom.xml", nil).Expect(200).ResponseBody()
+	feed = atomFeed{}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "Articles - Web" || len(feed.Entries) != 1 {
+		t.Errorf("unexpected tag feed %q with %d entries", feed.Title, len(feed.Entries))
+	}
+	tt.Get("/articles/tag/python/atom.xml", nil).Expect(404)
+}
+
+func TestEmptyAtomFeed(t *testing.T) {
+	a, _ := newTestApp(t, map[string]string{"draft.html": testDraft})
+	tt := tester.New(t, a)
+	var feed atomFeed
+	if err := xml.Unmarshal(tt.Get("/articles/atom.xml", nil).Expect(200).ResponseBody(), &feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Entries) != 0 {
+		t.Errorf("expecting no entries, got %d", len(feed.Entries))
+	}
+	// updated is required, so it must be set even without entries
+	updated, err := time.Parse(time.RFC3339, feed.Updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(updated) > time.Minute {
+		t.Errorf("expecting updated to be the current time, got %s", updated)
+	}
+}
+
+func TestRSSFeed(t *testing.T) {
+	a, _ := newTestApp(t, map[string]string{"first.html": testArticle, "draft.html": testDraft})
+	tt := tester.New(t, a)
+	tt.Get("/articles/rss.xml", nil).Expect(200).
+		ExpectHeader("Content-Type", rssContentType).
+		Contains(`<rss version="2.0"`).
+		Contains("<title>First</title>").
+		Contains("<link>http://localhost/articles/first/</link>").
+		Contains(`<guid isPermaLink="false">tag:localhost,2014-05-10:first</guid>`).
+		Contains("<pubDate>Sat, 10 May 2014 10:00:00 +0000</pubDate>").
+		Contains("<lastBuildDate>Wed, 14 May 2014 10:00:00 +0000</lastBuildDate>").
+		Contains("<dc:creator>Alice</dc:creator>").
+		Contains("<category>Web</category>")
+	tt.Get("/articles/tag/web/rss.xml", nil).Expect(200).Contains("<title>Articles - Web</title>")
+	tt.Get("/articles/tag/python/rss.xml", nil).Expect(404)
+	// Without articles there's no lastBuildDate
+	a, _ = newTestApp(t, map[string]string{"draft.html": testDraft})
+	r := tester.New(t, a).Get("/articles/rss.xml", nil).Expect(200)
+	if strings.Contains(string(r.ResponseBody()), "lastBuildDate") {
+		t.Error("expecting no lastBuildDate in an empty feed")
+	}
+}
+
+func TestSitemap(t *testing.T) {
+	a, articlesApp := newTestApp(t, map[string]string{"first.html": testArticle, "draft.html": testDraft})
+	tt := tester.New(t, a)
+	tt.Get("/articles/sitemap.xml", nil).Expect(200).
+		ExpectHeader("Content-Type", sitemap.ContentType).
+		Contains("<loc>http://localhost/articles/</loc>").
+		Contains("<loc>http://localhost/articles/tag/go/</loc>").
+		Contains("<loc>http://localhost/articles/tag/web/</loc>").
+		Contains("<loc>http://localhost/articles/first/</loc>").
+		Contains("<lastmod>2014-05-14T10:00:00Z</lastmod>").
+		Contains("<loc>http://localhost/articles/draft/</loc>")
+	AddSitemapSources(articlesApp, sitemap.URLs(&sitemap.URL{Loc: "http://example.com/other/"}))
+	AddSitemapSources(articlesApp, sitemap.URLs(&sitemap.URL{Loc: "/about/"}))
+	tt.Get("/articles/sitemap.xml", nil).Expect(200).
+		Contains("<loc>http://localhost/articles/first/</loc>").
+		Contains("<loc>http://example.com/other/</loc>").
+		Contains("<loc>http://localhost/about/</loc>")
+}
+
+func TestSitemapSource(t *testing.T) {
+	_, articlesApp := newTestApp(t, map[string]string{"first.html": testArticle})
+	a := app.New()
+	a.Logger = nil
+	a.Handle("^/sitemap\\.xml$", sitemap.Handler(SitemapSource(articlesApp)))
+	tester.New(t, a).Get("/sitemap.xml", nil).Expect(200).
+		Contains("<loc>http://localhost/articles/first/</loc>").
+		Contains("<loc>http://localhost/articles/tag/go/</loc>")
+}
@@ -31,17 +31,38 @@
 //  - updated (optional): Indicates an update to the file.
 //  - priority (optional): When listing the articles, the ones with lower priority
 //	are shown first.
+//  - tags (optional): A comma separated list of tags. Each tag gets a page listing
+//	its articles as well as its own feeds. This property might appear multiple times.
+//  - author (optional): The article author, shown in the article page and in the feeds.
 //
 // The title, slug and updated field might appear multiple times. It's recommended that if you
 // change the title or the slug of an article, you do so by adding a new property BEFORE the
 // previous one without deleting the old one. This allows the articles app to redirect users
 // from the old URL to the new one.
 //
+// Besides the article pages, this app provides the following handlers (relative to
+// the prefix the app is included at):
+//
+//  - tag/<tag>/: Lists the articles with the given tag.
+//  - atom.xml, rss.xml: Atom and RSS 2.0 feeds with the most recent articles (see FeedLength).
+//	Only articles with at least one updated property are included in the feeds.
+//  - tag/<tag>/atom.xml, tag/<tag>/rss.xml: Feeds for the articles with the given tag.
+//  - sitemap.xml: A sitemap with the list, the tags and all the articles. URLs from other
+//	apps can be added to it with AddSitemapSources, while SitemapSource can be used to
+//	include the articles in a sitemap served by another app (see gnd.la/app/sitemap).
+//
+// Note that article slugs can't contain slashes. Older versions of this app routed
+// articles with the pattern ^/(.+)/$, which allowed them, but it also matched the
+// tag pages. Articles are now routed with ^/([^/]+)/$ and loading an article with
+// a slash in its slug returns an error, so it can be given a new slug.
+//
 // This package also adds a template function named reverse_article. It can be used to find the
 // URL of an article from its id. e.g.
 //
 //  {{ reverse_article "article-id" }}
 //
+// And another one named reverse_article_tag, which returns the URL of the page for a tag.
+//
 // The typical usage of this application is as follows:
 //
 //  myapp.Include("/articles/", articles.App, "articles-base.html")
@@ -0,0 +1,262 @@
+package articles
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/apps/articles/article"
+	"gnd.la/util/generic"
+)
+
+const (
+	atomContentType = "application/atom+xml; charset=utf-8"
+	rssContentType  = "application/rss+xml; charset=utf-8"
+	atomNamespace   = "http://www.w3.org/2005/Atom"
+	dcNamespace     = "http://purl.org/dc/elements/1.1/"
+)
+
+var (
+	// FeedLength is the maximum number of articles
+	// included in the Atom and RSS feeds.
+	FeedLength = 20
+)
+
+// feed contains the data shared by the Atom and RSS
+// feeds. All the URLs are absolute.
+type feed struct {
+	Title    string
+	Link     string
+	Self     string
+	Updated  time.Time
+	Articles []*article.Article
+}
+
+// newFeed returns the feed for the current request, with all the
+// articles or just the ones with the tag in the first argument.
+// Only the articles with at least one [updated] property are included,
+// sorted from newest to oldest. If there's no such tag, it sends a 404
+// and returns nil.
+func newFeed(ctx *app.Context) *feed {
+	a := ctx.App()
+	f := &feed{
+		Title: a.Name(),
+		Link:  absURL(ctx, ctx.MustReverse(ArticleListHandlerName)),
+	}
+	self := ctx.URL()
+	self.RawQuery = ""
+	f.Self = self.String()
+	articles := AppArticles(a)
+	if slug := ctx.IndexValue(0); slug != "" {
+		if articles = taggedArticles(a, slug); len(articles) == 0 {
+			ctx.NotFound("tag not found")
+			return nil
+		}
+		f.Title = fmt.Sprintf("%s - %s", f.Title, tagName(articles, slug))
+		f.Link = absURL(ctx, ctx.MustReverse(TagHandlerName, slug))
+	}
+	for _, v := range articles {
+		if len(v.Updated) > 0 {
+			f.Articles = append(f.Articles, v)
+		}
+	}
+	generic.SortFunc(f.Articles, func(a1, a2 *article.Article) bool {
+		return a1.Created().Sub(a2.Created()) > 0
+	})
+	if len(f.Articles) > FeedLength {
+		f.Articles = f.Articles[:FeedLength]
+	}
+	for _, v := range f.Articles {
+		if up := v.LastUpdate(); up.Sub(f.Updated) > 0 {
+			f.Updated = up
+		}
+	}
+	return f
+}
+
+// absURL returns the absolute URL for the given
+// path, using the current request as the base.
+func absURL(ctx *app.Context, p string) string {
+	u, err := ctx.URL().Parse(p)
+	if err != nil {
+		panic(err)
+	}
+	return u.String()
+}
+
+func articleURL(ctx *app.Context, art *article.Article) string {
+	return absURL(ctx, ctx.MustReverse(ArticleHandlerName, art.Slug()))
+}
+
+// articleTagURI returns a tag URI (RFC 4151) identifying the
+// article, which doesn't change when the article slug does.
+func articleTagURI(ctx *app.Context, art *article.Article) string {
+	return fmt.Sprintf("tag:%s,%s:%s", ctx.URL().Host, art.Created().Format("2006-01-02"), articleId(art))
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	Id         string         `xml:"id"`
+	Links      []*atomLink    `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Title   string       `xml:"title"`
+	Id      string       `xml:"id"`
+	Links   []*atomLink  `xml:"link"`
+	Updated string       `xml:"updated"`
+	Author  *atomPerson  `xml:"author"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+func atomHandler(ctx *app.Context) {
+	f := newFeed(ctx)
+	if f == nil {
+		return
+	}
+	// updated is required by Atom, even for empty feeds
+	updated := f.Updated
+	if updated.IsZero() {
+		updated = time.Now().UTC()
+	}
+	af := &atomFeed{
+		Xmlns: atomNamespace,
+		Title: f.Title,
+		Id:    f.Self,
+		Links: []*atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.Self},
+			{Rel: "alternate", Type: "text/html", Href: f.Link},
+		},
+		Updated: updated.Format(time.RFC3339),
+		// Atom requires an author for the feed when
+		// not all the entries have one.
+		Author: &atomPerson{Name: ctx.App().Name()},
+	}
+	for _, v := range f.Articles {
+		entry := &atomEntry{
+			Title:     v.Title(),
+			Id:        articleTagURI(ctx, v),
+			Links:     []*atomLink{{Rel: "alternate", Type: "text/html", Href: articleURL(ctx, v)}},
+			Published: v.Created().Format(time.RFC3339),
+			Updated:   v.LastUpdate().Format(time.RFC3339),
+			Summary:   v.Synopsis,
+			Content:   &atomText{Type: "html", Text: string(renderArticle(ctx, v))},
+		}
+		if v.Author != "" {
+			entry.Author = &atomPerson{Name: v.Author}
+		}
+		for _, t := range v.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t})
+		}
+		af.Entries = append(af.Entries, entry)
+	}
+	ctx.SetHeader("Content-Type", atomContentType)
+	writeFeed(ctx, af)
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Guid        string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        *rssGuid `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	AtomLink      *atomLink  `xml:"atom:link"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName   xml.Name    `xml:"rss"`
+	Version   string      `xml:"version,attr"`
+	XmlnsAtom string      `xml:"xmlns:atom,attr"`
+	XmlnsDc   string      `xml:"xmlns:dc,attr"`
+	Channel   *rssChannel `xml:"channel"`
+}
+
+func rssHandler(ctx *app.Context) {
+	f := newFeed(ctx)
+	if f == nil {
+		return
+	}
+	channel := &rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Title,
+		AtomLink:    &atomLink{Rel: "self", Type: "application/rss+xml", Href: f.Self},
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	for _, v := range f.Articles {
+		item := &rssItem{
+			Title:       v.Title(),
+			Link:        articleURL(ctx, v),
+			Guid:        &rssGuid{Guid: articleTagURI(ctx, v)},
+			PubDate:     v.Created().Format(time.RFC1123Z),
+			Creator:     v.Author,
+			Categories:  v.Tags,
+			Description: string(renderArticle(ctx, v)),
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	ctx.SetHeader("Content-Type", rssContentType)
+	writeFeed(ctx, &rssFeed{
+		Version:   "2.0",
+		XmlnsAtom: atomNamespace,
+		XmlnsDc:   dcNamespace,
+		Channel:   channel,
+	})
+}
+
+func writeFeed(w io.Writer, feed interface{}) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		panic(err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		panic(err)
+	}
+}
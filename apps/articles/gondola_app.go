@@ -21,9 +21,21 @@ func init() {
 	App.AddTemplateVars(map[string]interface{}{
 		"Article": ArticleHandlerName,
 		"List":    ArticleListHandlerName,
+		"Atom":    AtomHandlerName,
+		"RSS":     RSSHandlerName,
+		"Sitemap": SitemapHandlerName,
+		"TagAtom": TagAtomHandlerName,
+		"Tag":     TagHandlerName,
+		"TagRSS":  TagRSSHandlerName,
 	})
+	App.HandleOptions("^/([^/]+)/$", ArticleHandler.Handler, ArticleHandler.Options)
 	App.HandleOptions("^/$", ArticleListHandler.Handler, ArticleListHandler.Options)
-	App.HandleOptions("^/(.+)/$", ArticleHandler.Handler, ArticleHandler.Options)
-	templatesFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\tn\x88\x02\xff\xecU]k\xdb0\x14\xf5s\u007f\xc5\xc5t\xd0\xc1\xecJ\x8e\xa3@q\f\xdb`O}\xdb\xf6<T[\x99\xc5T۳\x94\x8eR\xfa\xdfwe\xc9J۴\xdd\xc6\xc8X\xc1\xe7%\xd2\xfd8\xf7\xd8V\x8e\xf8`d\xa5DژK\x15\x1d\b\x84\x12\xc2X\x1e\x11B\xe8jI\xee\xfeZP\x9ae\x11\xcd\x16,_-\x19[`\x9cR\x86! \xd1?\xc0V\x1b>\xa0\x94\xbf~H\x87\xf0\xfbBps\x03\xb5\xd8\xc8V@\xfcI\x1a%b\xb8\xbd\xc5X:n\xdcZ\xb45.\x8epu\xa1\xba\xea\x1b\xc4oݙ\t\xf5EC\xa1R\\\xebu쏓N̘,\xefR\x15\xa7\r-w|E-\xaf\xf6\xda\xfc\"\xb9\xe8\xea\xeb\xb8<\x02\xb0\x04\xefp3v\x9cbKi\x85\x1cW\x83\xe0F\xd4p\xb6\x86\xd4\xcbI\xdf\xfb\x98\xd3*7\xbb\xaaǧ%۾ƴ\x9e\xe6\x1c\xbb\xfd}\xd2s\xae\xcd\xe71nY\xc6:d\x16\xdfw\xe4\xa1m\xcc\x03\x14\xbd}\xc8~\x90\xad\xd9\xc0\x89\x81x\xd2յ\xf0Jǯ\xe1djM?t\xc3%Ǌ\f\xff\x1f\t\xa1\tɀ.\xcfH\x8eE\xf6u\xf5^\x98PZ<\xc9n\x05\x82\x93\xe0&\xbc\x81jo\xa0\x97\xf8\xdc\xc0?W\xe5?c\xf8(>\x10\xcdxAPR\x9b\x83\x9a\xff\xaf\xfd\x9f\xb0\x9cY\xff_\xac\xd8*_:\xff\xc7\v`\xf6\xff\xff\xdc\xff\xcf\xf1\xe8<{\aس\xf5;\x17\xc1V=\xda:\xf9\xf2\xc0ۯ\"8\xb2\x0eN\xa8\xe4S\xd7\xc7\xd89\xd64YYph\x06\xb1YǖJ\\\x89A\x8b/\xbe\x0eR${\xa8\x8d\x97\xa8/\x9b\x180\xf7C\x9a\x06ҏ\xd7m\xd7k\x19\xc6;3\xdeS\xa0}Y\x90\xe0I\xd2{}\xceD}ʿ\x06\x97P\xf2\xa1\xbdnU9{\xea\x8c\x193\x0e\x80\x9f\x01\x00\x00\xff\xffN)\xd3\xcd\x00\x0e\x00\x00")
+	App.HandleOptions("^/atom\\.xml$", AtomHandler.Handler, AtomHandler.Options)
+	App.HandleOptions("^/rss\\.xml$", RSSHandler.Handler, RSSHandler.Options)
+	App.HandleOptions("^/sitemap\\.xml$", SitemapHandler.Handler, SitemapHandler.Options)
+	App.HandleOptions("^/tag/([^/]+)/atom\\.xml$", TagAtomHandler.Handler, TagAtomHandler.Options)
+	App.HandleOptions("^/tag/([^/]+)/$", TagHandler.Handler, TagHandler.Options)
+	App.HandleOptions("^/tag/([^/]+)/rss\\.xml$", TagRSSHandler.Handler, TagRSSHandler.Options)
+	templatesFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xecUQ\x8b\xa3<\x14\xf5y~\xc5E\xe6\x83\x19\xf8\xaa\x89\x9d\xba0X\xa1\xbb\xb0O}jg\xd8\xc7!\xad\xb1\x86\xb5ꚴK\x91\xfe\xf7%1Ѷv\xba\xb3\xcc\x16f\xc1k\xc1\x98\xdc{\xce1\xa6\xf7\x90R\xb0eJ\x9dD\xacS\xebJ\x810B\xbe\xff`!\x84\xf0\xa7\x11\x92wy\x99;\x1e!\xdf\xc2#\xcf\x1fa\x1f\r\xb1g!\x8c}\x84,@\x06\xe0\x9a\xb1႔\x16z7\xd7\xc9K\x99\xe9\x8f\x1eU\x05\x11\x8dYF\xc1~b\"\xa56\xec\xf7U\x05\x8ez\xa8\xc74\x8b`\xbf\xbf\xa9*X\xa4\xf9\xf2;ؓ\xfa\xcc4\xf9A\x82a\x99\x12\xceǶ>N| \xd4bx\b\x15\xb8\t\x0e[\xbc b\xdbN\x99\x1e\f\x16y\xb4\xb3\xc3\x1b\x00\t\xf09\x8fvRA\xe0Fl\x1bJ!?\x99H\xc0\xd1:\x9c\xc9F$y\xa92\x8a.\xa2Z\xb4%qQ\xb2L\xc4p'\xc0\xfeV2!h\x06\x8b\x1d\xfc\xc7\xed{p\xe4k\xb8Ex\xd3\xea\xeb\xd0<\x91\x15\x7f\x85D\x90\x157rK\x92\xadh\rH )i<\xb6\xab\nJ\xba\xa5%\xa7/\xba\xe4E\x90\x95\xcaQ\xc2\xe4 pI\b\a\xbb\xd3\x11s\xbb,)\x114\x82\xc7q\xab鋞\xab\xbf\x0f\x8b۬\xf3;<\xd8\x14\x11\x11\xb4\x11{[?\x1f\x83N\t\x17\xcfj^R\xab<\x16\x03\xfdт7ej\x1d (N\xf6\xd7\xe8ʳz\x7f\xefL\xa9\xf35/\xd7D\x80\xed!\xe4\x0f\x10\x1e \x0f\xf0\xe8\x11=\xd8\xf7\xe6\x13(B\x9ar\xfa*\xba\x14\b\xb5\x84\x9a\xe1\x7fXv\b\xb5\xc4K\x84\x7f\xae*\x8b\x8e\x0f\xa2\x9e0\xff\xa6\x7f/R\xc6\xc5U\x9b\xff\x1b\xfa\xbf\xe7\x0fO\xfb?\x1e\xf6\xfd\xff\xa3\xf7\xff)\xe3\xe2\xa2\aȳ\xf5\x16#ؤgKO\x1a\xaaf\xe5MWHY\xa7L\x0fT\xa5\xcaI\xbc\xf0B\x17>\xe8\xc0\xad6\x12\x06n\xe2\x19\x84\xc6\x05\xe6\xbb,/8k\xe8\xe5\xef\x8c\x13p\x9d\xd6H\xd0 \xceQ\x9d[\x1c\xe0\xebmh\x9ek>\xe36\x17\xb8\x1a\xd71\xd7_s\x9f\xdf\n\rܔ\x9d\xf6\xc4M\x1a\x9e\xf3ƘҨ\xf6\x9bCE\xceD\xe4\xeb\xe7\xd9T\x89\x90c\xa5\xe0(c6\x9f\x9b\x84\xd9|.\xd7kW4G\xb7\x8f>\xfa裏wį\x01\x007&\xfe\x95\x00\x10\x00\x00")
 	App.SetTemplatesFS(templatesFS)
 }
@@ -16,11 +16,23 @@ import (
 const (
 	ArticleHandlerName     = "articles-article"
 	ArticleListHandlerName = "articles-list"
+	TagHandlerName         = "articles-tag"
+	AtomHandlerName        = "articles-atom"
+	RSSHandlerName         = "articles-rss"
+	TagAtomHandlerName     = "articles-tag-atom"
+	TagRSSHandlerName      = "articles-tag-rss"
+	SitemapHandlerName     = "articles-sitemap"
 )
 
 var (
 	ArticleHandler     = app.NamedHandler(ArticleHandlerName, articleHandler)
 	ArticleListHandler = app.NamedHandler(ArticleListHandlerName, articleListHandler)
+	TagHandler         = app.NamedHandler(TagHandlerName, tagHandler)
+	AtomHandler        = app.NamedHandler(AtomHandlerName, atomHandler)
+	RSSHandler         = app.NamedHandler(RSSHandlerName, rssHandler)
+	TagAtomHandler     = app.NamedHandler(TagAtomHandlerName, atomHandler)
+	TagRSSHandler      = app.NamedHandler(TagRSSHandlerName, rssHandler)
+	SitemapHandler     = app.NamedHandler(SitemapHandlerName, sitemapHandler)
 )
 
 func articleHandler(ctx *app.Context) {
@@ -45,6 +57,17 @@ func articleHandler(ctx *app.Context) {
 		ctx.NotFound("article not found")
 		return
 	}
+	data := map[string]interface{}{
+		"Article": art,
+		"Title":   art.Title(),
+		"Body":    renderArticle(ctx, art),
+	}
+	ctx.MustExecute("article.html", data)
+}
+
+// renderArticle executes the article text as a template
+// and returns the result.
+func renderArticle(ctx *app.Context, art *article.Article) template.HTML {
 	fs := vfs.Memory()
 	filename := path.Base(art.Filename)
 	if filename == "" {
@@ -62,19 +85,44 @@ func articleHandler(ctx *app.Context) {
 	if err := tmpl.ExecuteTo(&buf, ctx, nil); err != nil {
 		panic(err)
 	}
-	body := buf.String()
-	data := map[string]interface{}{
-		"Article": art,
-		"Title":   art.Title(),
-		"Body":    template.HTML(body),
-	}
-	ctx.MustExecute("article.html", data)
+	return template.HTML(buf.String())
 }
 
 func articleListHandler(ctx *app.Context) {
 	data := map[string]interface{}{
 		"Articles": AppArticles(ctx.App()),
 		"Title":    ctx.App().Name(),
+		"AtomURL":  ctx.MustReverse(AtomHandlerName),
+		"RSSURL":   ctx.MustReverse(RSSHandlerName),
 	}
 	ctx.MustExecute("list.html", data)
 }
+
+func tagHandler(ctx *app.Context) {
+	slug := ctx.IndexValue(0)
+	articles := taggedArticles(ctx.App(), slug)
+	if len(articles) == 0 {
+		ctx.NotFound("tag not found")
+		return
+	}
+	data := map[string]interface{}{
+		"Articles": articles,
+		"Title":    tagName(articles, slug),
+		"AtomURL":  ctx.MustReverse(TagAtomHandlerName, slug),
+		"RSSURL":   ctx.MustReverse(TagRSSHandlerName, slug),
+	}
+	ctx.MustExecute("list.html", data)
+}
+
+// tagName returns the tag as written in the first of the given
+// articles which has a tag with the given slug.
+func tagName(articles []*article.Article, slug string) string {
+	for _, art := range articles {
+		for _, v := range art.Tags {
+			if tagSlug(v) == slug {
+				return v
+			}
+		}
+	}
+	return slug
+}
@@ -0,0 +1,59 @@
+package articles
+
+import (
+	"gnd.la/app"
+	"gnd.la/app/sitemap"
+)
+
+const (
+	sitemapSourcesKey = "articles-sitemap-sources"
+)
+
+// SitemapSource returns a gnd.la/app/sitemap.Source with the URLs
+// for the article list, the tags and the articles loaded into the
+// given App. If a is nil, the App which received the request is used.
+func SitemapSource(a *app.App) sitemap.Source {
+	return func(ctx *app.Context) ([]*sitemap.URL, error) {
+		ap := a
+		if ap == nil {
+			ap = ctx.App()
+		}
+		list, err := ap.Reverse(ArticleListHandlerName)
+		if err != nil {
+			return nil, err
+		}
+		urls := []*sitemap.URL{{Loc: list, ChangeFreq: sitemap.Daily}}
+		for _, v := range Tags(ap) {
+			loc, err := ap.Reverse(TagHandlerName, tagSlug(v))
+			if err != nil {
+				return nil, err
+			}
+			urls = append(urls, &sitemap.URL{Loc: loc, ChangeFreq: sitemap.Weekly})
+		}
+		for _, v := range AppArticles(ap) {
+			loc, err := ap.Reverse(ArticleHandlerName, v.Slug())
+			if err != nil {
+				return nil, err
+			}
+			urls = append(urls, &sitemap.URL{Loc: loc, LastMod: v.LastUpdate()})
+		}
+		return urls, nil
+	}
+}
+
+// AddSitemapSources adds additional sources to the sitemap served by
+// the given articles App, so it can also include the URLs from other
+// apps (e.g. using gnd.la/app/sitemap.Handlers).
+func AddSitemapSources(a *app.App, sources ...sitemap.Source) {
+	a.Set(sitemapSourcesKey, append(appSitemapSources(a), sources...))
+}
+
+func appSitemapSources(a *app.App) []sitemap.Source {
+	sources, _ := a.Get(sitemapSourcesKey).([]sitemap.Source)
+	return sources
+}
+
+func sitemapHandler(ctx *app.Context) {
+	sources := append([]sitemap.Source{SitemapSource(nil)}, appSitemapSources(ctx.App())...)
+	sitemap.Handler(sources...)(ctx)
+}
@@ -62,9 +62,14 @@ func reverseAppsArticle(a *app.App, art interface{}, checked map[*app.App]bool)
 	return "", fmt.Errorf("can't reverse Article from %T, must be *Article or string (article id)", art)
 }
 
+func reverseArticleTag(ctx *app.Context, tag string) (string, error) {
+	return ctx.Reverse(TagHandlerName, tagSlug(tag))
+}
+
 func init() {
 	template.AddFuncs(template.FuncMap{
-		"!reverse_article":    reverseArticle,
-		"reverse_app_article": reverseAppArticle,
+		"!reverse_article":     reverseArticle,
+		"reverse_app_article":  reverseAppArticle,
+		"!reverse_article_tag": reverseArticleTag,
 	})
 }
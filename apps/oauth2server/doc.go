@@ -0,0 +1,40 @@
+// Package oauth2server implements an OAuth 2 authorization server, which
+// allows other applications (e.g. mobile clients or third party integrations)
+// to access the app API on behalf of the users registered with gnd.la/apps/users.
+//
+// The following grants are supported:
+//
+//  - authorization_code, optionally with PKCE (RFC 7636). PKCE is required
+//	for public clients (the ones without a secret). Only the S256 method
+//	is accepted, unless AllowPlainPKCE is set.
+//  - refresh_token, rotating the refresh token every time it's used.
+//  - client_credentials, for clients with AllowClientCredentials set.
+//
+// Additionally, token introspection (RFC 7662) and revocation (RFC 7009)
+// endpoints are provided. To use this app, include it in your app and
+// register the scopes and clients:
+//
+//  myapp.Include("/oauth2/", oauth2server.App, "oauth2server-base.html")
+//  oauth2server.RegisterScope("read", "Read your profile and posts")
+//  oauth2server.RegisterScope("write", "Publish posts in your name")
+//
+// Clients are stored in the ORM and they're usually created from a command
+// or an admin handler using RegisterClient.
+//
+// Handlers exposing the API are protected using the transformer returned by
+// Scoped, which requires a valid access token with the given scopes. The token
+// can be retrieved from the handler using AccessToken:
+//
+//  myapp.Handle("^/api/posts/$", oauth2server.Scoped("read")(postsHandler))
+//  ...
+//  func postsHandler(ctx *app.Context) {
+//	token := oauth2server.AccessToken(ctx)
+//	userId := token.UserId
+//	...
+//  }
+//
+// The consent page shown to the users is rendered using the template named
+// by ConsentTemplateName (consent.html by default), which receives the
+// *Client, the requested []*Scope and the *form.Form with the CSRF
+// protection fields.
+package oauth2server
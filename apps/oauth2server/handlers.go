@@ -0,0 +1,418 @@
+package oauth2server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/form"
+	"gnd.la/orm"
+	"gnd.la/util/stringutil"
+)
+
+const (
+	AuthorizeHandlerName  = "oauth2server-authorize"
+	TokenHandlerName      = "oauth2server-token"
+	IntrospectHandlerName = "oauth2server-introspect"
+	RevokeHandlerName     = "oauth2server-revoke"
+)
+
+var (
+	// ConsentTemplateName is the name of the template used to
+	// render the consent page.
+	ConsentTemplateName = "consent.html"
+	// CodeExpiry is the lifetime of the authorization codes.
+	CodeExpiry = 10 * time.Minute
+	// AccessTokenExpiry is the lifetime of the access tokens.
+	AccessTokenExpiry = time.Hour
+	// RefreshTokenExpiry is the lifetime of the refresh tokens. If
+	// zero, refresh tokens never expire.
+	RefreshTokenExpiry = 30 * 24 * time.Hour
+	// AllowPlainPKCE allows clients to use the plain code_challenge_method.
+	// Plain challenges don't protect the code if the authorization request
+	// is intercepted, so only S256 is accepted by default.
+	AllowPlainPKCE = false
+
+	AuthorizeHandler  = app.NamedHandler(AuthorizeHandlerName, app.SignedIn(authorizeHandler))
+	TokenHandler      = app.NamedHandler(TokenHandlerName, tokenHandler)
+	IntrospectHandler = app.NamedHandler(IntrospectHandlerName, introspectHandler)
+	RevokeHandler     = app.NamedHandler(RevokeHandlerName, revokeHandler)
+)
+
+// oauthError is an error as defined in RFC 6749, section 5.2.
+type oauthError struct {
+	Code        string
+	Description string
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return e.Code + ": " + e.Description
+	}
+	return e.Code
+}
+
+func errorf(code string, description string) *oauthError {
+	return &oauthError{Code: code, Description: description}
+}
+
+func redirectWithParams(ctx *app.Context, redirectURI string, params url.Values) {
+	sep := "?"
+	if strings.Contains(redirectURI, "?") {
+		sep = "&"
+	}
+	ctx.Redirect(redirectURI+sep+params.Encode(), false)
+}
+
+func redirectError(ctx *app.Context, redirectURI string, state string, err *oauthError) {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	redirectWithParams(ctx, redirectURI, params)
+}
+
+func authorizeHandler(ctx *app.Context) {
+	client, err := GetClient(ctx, ctx.FormValue("client_id"))
+	if err != nil {
+		panic(err)
+	}
+	if client == nil {
+		ctx.BadRequest("invalid client_id")
+		return
+	}
+	redirectURI, ok := client.validRedirectURI(ctx.FormValue("redirect_uri"))
+	if !ok {
+		// Never redirect to an unregistered URI
+		ctx.BadRequest("invalid redirect_uri")
+		return
+	}
+	state := ctx.FormValue("state")
+	if ctx.FormValue("response_type") != "code" {
+		redirectError(ctx, redirectURI, state, errorf("unsupported_response_type", ""))
+		return
+	}
+	requested, ok := parseScopes(ctx.FormValue("scope"), client)
+	if !ok {
+		redirectError(ctx, redirectURI, state, errorf("invalid_scope", ""))
+		return
+	}
+	challenge := ctx.FormValue("code_challenge")
+	method := ctx.FormValue("code_challenge_method")
+	if challenge != "" && method == "" {
+		method = "plain"
+	}
+	if method != "" && method != "plain" && method != "S256" {
+		redirectError(ctx, redirectURI, state, errorf("invalid_request", "unsupported code_challenge_method"))
+		return
+	}
+	if method == "plain" && !AllowPlainPKCE {
+		redirectError(ctx, redirectURI, state, errorf("invalid_request", "code_challenge_method must be S256"))
+		return
+	}
+	if challenge == "" && client.IsPublic() {
+		redirectError(ctx, redirectURI, state, errorf("invalid_request", "code_challenge is required"))
+		return
+	}
+	if !client.Trusted {
+		frm := form.New(ctx)
+		if !frm.Submitted() || !frm.IsValid() {
+			data := map[string]interface{}{
+				"Client": client,
+				"Scopes": scopesByName(requested),
+				"Form":   frm,
+				"Action": ctx.URL().String(),
+			}
+			ctx.MustExecute(ConsentTemplateName, data)
+			return
+		}
+		if ctx.FormValue("allow") == "" {
+			redirectError(ctx, redirectURI, state, errorf("access_denied", ""))
+			return
+		}
+	}
+	code := stringutil.Random(codeLength)
+	ctx.Orm().MustInsert(&AuthorizationCode{
+		Hash:                hashSecret(code),
+		ClientId:            client.ClientId,
+		UserId:              ctx.User().Id(),
+		RedirectURI:         ctx.FormValue("redirect_uri"),
+		Scopes:              requested,
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: method,
+		Expires:             time.Now().UTC().Add(CodeExpiry),
+	})
+	params := url.Values{"code": {code}}
+	if state != "" {
+		params.Set("state", state)
+	}
+	redirectWithParams(ctx, redirectURI, params)
+}
+
+func writeTokenError(ctx *app.Context, err *oauthError) {
+	status := http.StatusBadRequest
+	if err.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+		ctx.SetHeader("WWW-Authenticate", "Basic realm=\"oauth2\"")
+	}
+	data := map[string]string{"error": err.Code}
+	if err.Description != "" {
+		data["error_description"] = err.Description
+	}
+	ctx.SetHeader("Cache-Control", "no-store")
+	ctx.SetHeader("Pragma", "no-cache")
+	// serialize.WriteJSON can't set the header after WriteHeader
+	ctx.SetHeader("Content-Type", "application/json; charset=utf-8")
+	ctx.WriteHeader(status)
+	ctx.WriteJSON(data)
+}
+
+// authenticateClient returns the client making the request, using either
+// HTTP basic authentication or the client_id and client_secret parameters.
+// Public clients are only authenticated when allowPublic is true.
+func authenticateClient(ctx *app.Context, allowPublic bool) (*Client, *oauthError) {
+	clientId, secret, hasAuth := ctx.R.BasicAuth()
+	if hasAuth {
+		// RFC 6749, section 2.3.1 says these must be form encoded
+		if id, err := url.QueryUnescape(clientId); err == nil {
+			clientId = id
+		}
+		if s, err := url.QueryUnescape(secret); err == nil {
+			secret = s
+		}
+	} else {
+		clientId = ctx.FormValue("client_id")
+		secret = ctx.FormValue("client_secret")
+	}
+	client, err := GetClient(ctx, clientId)
+	if err != nil {
+		panic(err)
+	}
+	if client == nil {
+		return nil, errorf("invalid_client", "")
+	}
+	if client.IsPublic() {
+		if !allowPublic || secret != "" {
+			return nil, errorf("invalid_client", "")
+		}
+		return client, nil
+	}
+	if !client.Secret.Matches(secret) {
+		return nil, errorf("invalid_client", "")
+	}
+	return client, nil
+}
+
+func tokenHandler(ctx *app.Context) {
+	if ctx.R.Method != "POST" {
+		writeTokenError(ctx, errorf("invalid_request", "token requests must use POST"))
+		return
+	}
+	client, oerr := authenticateClient(ctx, true)
+	if oerr != nil {
+		writeTokenError(ctx, oerr)
+		return
+	}
+	var resp map[string]interface{}
+	switch ctx.FormValue("grant_type") {
+	case "authorization_code":
+		resp, oerr = exchangeCode(ctx, client)
+	case "refresh_token":
+		resp, oerr = refreshToken(ctx, client)
+	case "client_credentials":
+		resp, oerr = clientCredentials(ctx, client)
+	case "":
+		oerr = errorf("invalid_request", "missing grant_type")
+	default:
+		oerr = errorf("unsupported_grant_type", "")
+	}
+	if oerr != nil {
+		writeTokenError(ctx, oerr)
+		return
+	}
+	ctx.SetHeader("Cache-Control", "no-store")
+	ctx.SetHeader("Pragma", "no-cache")
+	ctx.WriteJSON(resp)
+}
+
+func verifyChallenge(code *AuthorizationCode, verifier string) bool {
+	if code.CodeChallenge == "" {
+		return true
+	}
+	if verifier == "" {
+		return false
+	}
+	expected := verifier
+	if code.CodeChallengeMethod == "S256" {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.URLEncoding.EncodeToString(sum[:])
+		expected = strings.TrimRight(expected, "=")
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(code.CodeChallenge)) == 1
+}
+
+func exchangeCode(ctx *app.Context, client *Client) (map[string]interface{}, *oauthError) {
+	var code AuthorizationCode
+	o := ctx.Orm()
+	ok, err := o.One(orm.Eq("Hash", hashSecret(ctx.FormValue("code"))), &code)
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		return nil, errorf("invalid_grant", "invalid code")
+	}
+	// Codes can only be used once
+	o.MustDelete(&code)
+	if code.ClientId != client.ClientId || code.Expires.Before(time.Now()) {
+		return nil, errorf("invalid_grant", "invalid code")
+	}
+	if code.RedirectURI != ctx.FormValue("redirect_uri") {
+		return nil, errorf("invalid_grant", "redirect_uri does not match")
+	}
+	if !verifyChallenge(&code, ctx.FormValue("code_verifier")) {
+		return nil, errorf("invalid_grant", "invalid code_verifier")
+	}
+	return issueTokens(ctx, client, code.UserId, code.Scopes, "", true), nil
+}
+
+func refreshToken(ctx *app.Context, client *Client) (map[string]interface{}, *oauthError) {
+	tok, err := findToken(ctx, ctx.FormValue("refresh_token"))
+	if err != nil {
+		panic(err)
+	}
+	if tok == nil || tok.Type != TokenTypeRefresh || tok.ClientId != client.ClientId {
+		return nil, errorf("invalid_grant", "invalid refresh_token")
+	}
+	scopes := tok.Scopes
+	if s := ctx.FormValue("scope"); s != "" {
+		// Requested scopes must be a subset of the original ones
+		requested, _ := parseScopes(s, nil)
+		for _, v := range requested {
+			if !tok.HasScope(v) {
+				return nil, errorf("invalid_scope", "")
+			}
+		}
+		scopes = requested
+	}
+	// Rotate the refresh token
+	ctx.Orm().MustDelete(tok)
+	return issueTokens(ctx, client, tok.UserId, scopes, tok.GrantId, true), nil
+}
+
+func clientCredentials(ctx *app.Context, client *Client) (map[string]interface{}, *oauthError) {
+	if client.IsPublic() || !client.AllowClientCredentials {
+		return nil, errorf("unauthorized_client", "")
+	}
+	requested, ok := parseScopes(ctx.FormValue("scope"), client)
+	if !ok {
+		return nil, errorf("invalid_scope", "")
+	}
+	return issueTokens(ctx, client, 0, requested, "", false), nil
+}
+
+func issueTokens(ctx *app.Context, client *Client, userId int64, scopes []string, grantId string, refresh bool) map[string]interface{} {
+	if grantId == "" {
+		grantId = stringutil.Random(tokenLength)
+	}
+	now := time.Now().UTC()
+	o := ctx.Orm()
+	access := stringutil.Random(tokenLength)
+	o.MustInsert(&Token{
+		Hash:     hashSecret(access),
+		Type:     TokenTypeAccess,
+		GrantId:  grantId,
+		ClientId: client.ClientId,
+		UserId:   userId,
+		Scopes:   scopes,
+		Created:  now,
+		Expires:  now.Add(AccessTokenExpiry),
+	})
+	resp := map[string]interface{}{
+		"access_token": access,
+		"token_type":   "bearer",
+		"expires_in":   int(AccessTokenExpiry / time.Second),
+		"scope":        strings.Join(scopes, " "),
+	}
+	if refresh {
+		rt := stringutil.Random(tokenLength)
+		t := &Token{
+			Hash:     hashSecret(rt),
+			Type:     TokenTypeRefresh,
+			GrantId:  grantId,
+			ClientId: client.ClientId,
+			UserId:   userId,
+			Scopes:   scopes,
+			Created:  now,
+		}
+		if RefreshTokenExpiry > 0 {
+			t.Expires = now.Add(RefreshTokenExpiry)
+		}
+		o.MustInsert(t)
+		resp["refresh_token"] = rt
+	}
+	return resp
+}
+
+func introspectHandler(ctx *app.Context) {
+	if _, oerr := authenticateClient(ctx, false); oerr != nil {
+		writeTokenError(ctx, oerr)
+		return
+	}
+	tok, err := findToken(ctx, ctx.FormValue("token"))
+	if err != nil {
+		panic(err)
+	}
+	ctx.SetHeader("Cache-Control", "no-store")
+	if tok == nil {
+		ctx.WriteJSON(map[string]interface{}{"active": false})
+		return
+	}
+	resp := map[string]interface{}{
+		"active":     true,
+		"scope":      strings.Join(tok.Scopes, " "),
+		"client_id":  tok.ClientId,
+		"token_type": tok.Type + "_token",
+		"iat":        tok.Created.Unix(),
+	}
+	if tok.UserId != 0 {
+		resp["sub"] = strconv.FormatInt(tok.UserId, 10)
+	}
+	if !tok.Expires.IsZero() {
+		resp["exp"] = tok.Expires.Unix()
+	}
+	ctx.WriteJSON(resp)
+}
+
+func revokeHandler(ctx *app.Context) {
+	client, oerr := authenticateClient(ctx, true)
+	if oerr != nil {
+		writeTokenError(ctx, oerr)
+		return
+	}
+	tok, err := findToken(ctx, ctx.FormValue("token"))
+	if err != nil {
+		panic(err)
+	}
+	// As per RFC 7009, invalid tokens don't cause an error
+	if tok != nil && tok.ClientId == client.ClientId {
+		o := ctx.Orm()
+		if tok.Type == TokenTypeRefresh {
+			// Revoke the whole grant
+			if _, err := o.DeleteFrom(o.TypeTable(tokenType), orm.Eq("GrantId", tok.GrantId)); err != nil {
+				panic(err)
+			}
+		} else {
+			o.MustDelete(tok)
+		}
+	}
+	ctx.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,229 @@
+package oauth2server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/crypto/password"
+	"gnd.la/orm"
+	"gnd.la/util/stringutil"
+)
+
+const (
+	// TokenTypeAccess is the Type for access tokens.
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh is the Type for refresh tokens.
+	TokenTypeRefresh = "refresh"
+
+	clientIdLength     = 24
+	clientSecretLength = 48
+	codeLength         = 40
+	tokenLength        = 48
+)
+
+var (
+	tokenType = reflect.TypeOf(Token{})
+)
+
+// Client represents an application which might request
+// access to the API on behalf of the users.
+type Client struct {
+	// ClientId is the client identifier. It's generated
+	// by RegisterClient.
+	ClientId string `orm:",primary_key"`
+	// Secret is the hashed client secret. Public clients
+	// (e.g. mobile or JS apps) don't have a secret.
+	Secret password.Password `orm:",omitempty,nullempty"`
+	// Name is the client name, shown to the users in the
+	// consent page.
+	Name string
+	// RedirectURIs are the allowed redirection URIs. The redirect_uri
+	// in the authorization requests must match one of them exactly.
+	RedirectURIs []string `orm:",codec=json"`
+	// Scopes are the scopes this client might request. If empty,
+	// the client can request any registered scope.
+	Scopes []string `orm:",codec=json"`
+	// Trusted clients (e.g. your own mobile app) are granted
+	// access without showing the consent page.
+	Trusted bool
+	// AllowClientCredentials allows the client to use the
+	// client_credentials grant. It's only honored for
+	// confidential clients.
+	AllowClientCredentials bool
+	// UserId is the id of the user which owns this client, if any.
+	UserId  int64 `orm:",index"`
+	Created time.Time
+}
+
+// IsPublic returns true iff the client has no secret.
+func (c *Client) IsPublic() bool {
+	return c.Secret == ""
+}
+
+// CanRequest returns true iff the client is allowed to
+// request the given scope.
+func (c *Client) CanRequest(scope string) bool {
+	if _, ok := scopes[scope]; !ok {
+		return false
+	}
+	if len(c.Scopes) == 0 {
+		return true
+	}
+	return contains(c.Scopes, scope)
+}
+
+func (c *Client) validRedirectURI(uri string) (string, bool) {
+	if uri == "" {
+		if len(c.RedirectURIs) == 1 {
+			return c.RedirectURIs[0], true
+		}
+		return "", false
+	}
+	return uri, contains(c.RedirectURIs, uri)
+}
+
+// AuthorizationCode is an authorization code issued to a client
+// after a user has approved its request. Codes can only be used once
+// and they expire after CodeExpiry.
+type AuthorizationCode struct {
+	// Hash is the SHA-256 of the code, hex encoded.
+	Hash                string `orm:",primary_key"`
+	ClientId            string
+	UserId              int64
+	RedirectURI         string
+	Scopes              []string `orm:",codec=json"`
+	CodeChallenge       string   `orm:",omitempty,nullempty"`
+	CodeChallengeMethod string   `orm:",omitempty,nullempty"`
+	Expires             time.Time
+}
+
+// Token is an access or refresh token issued to a client. Tokens are
+// stored hashed, so a leaked database can't be used to access the API.
+type Token struct {
+	// Hash is the SHA-256 of the token, hex encoded.
+	Hash string `orm:",primary_key"`
+	// Type is either TokenTypeAccess or TokenTypeRefresh.
+	Type string
+	// GrantId identifies the authorization this token was issued
+	// for. It's shared by all the tokens obtained by refreshing
+	// the original ones, so they can be revoked together.
+	GrantId  string `orm:",index"`
+	ClientId string `orm:",index"`
+	// UserId is the id of the user who authorized the client. It's
+	// zero for tokens obtained with the client_credentials grant.
+	UserId  int64    `orm:",index"`
+	Scopes  []string `orm:",codec=json"`
+	Created time.Time
+	// Expires is the token expiration. If zero, the token
+	// never expires.
+	Expires time.Time
+}
+
+// Expired returns true iff the token has expired.
+func (t *Token) Expired() bool {
+	return !t.Expires.IsZero() && t.Expires.Before(time.Now())
+}
+
+// HasScope returns true iff the token includes the given scope.
+func (t *Token) HasScope(scope string) bool {
+	return contains(t.Scopes, scope)
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func hashSecret(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+// RegisterClient registers a new client, generating its ClientId. If
+// public is false, a secret is generated and returned. Note that only the
+// hash of the secret is stored, so this is the only chance to obtain it.
+func RegisterClient(ctx *app.Context, client *Client, public bool) (secret string, err error) {
+	client.ClientId = stringutil.Random(clientIdLength)
+	client.Secret = ""
+	if !public {
+		secret = stringutil.Random(clientSecretLength)
+		client.Secret = password.New(secret)
+	}
+	client.Created = time.Now().UTC()
+	if _, err := ctx.Orm().Insert(client); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// GetClient returns the client with the given id, or nil if
+// there's no such client.
+func GetClient(ctx *app.Context, clientId string) (*Client, error) {
+	if clientId == "" {
+		return nil, nil
+	}
+	var client Client
+	ok, err := ctx.Orm().One(orm.Eq("ClientId", clientId), &client)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// DeleteClient removes the client with the given id, as well as
+// all the tokens issued to it.
+func DeleteClient(ctx *app.Context, clientId string) error {
+	o := ctx.Orm()
+	if _, err := o.DeleteFrom(o.TypeTable(tokenType), orm.Eq("ClientId", clientId)); err != nil {
+		return err
+	}
+	return o.Delete(&Client{ClientId: clientId})
+}
+
+// RevokeUserTokens revokes all the tokens issued on behalf of the given
+// user to the given client. If clientId is empty, the tokens issued to
+// all the clients are revoked.
+func RevokeUserTokens(ctx *app.Context, userId int64, clientId string) error {
+	q := orm.Eq("UserId", userId)
+	if clientId != "" {
+		q = orm.And(q, orm.Eq("ClientId", clientId))
+	}
+	o := ctx.Orm()
+	_, err := o.DeleteFrom(o.TypeTable(tokenType), q)
+	return err
+}
+
+func findToken(ctx *app.Context, token string) (*Token, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var tok Token
+	ok, err := ctx.Orm().One(orm.Eq("Hash", hashSecret(token)), &tok)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if tok.Expired() {
+		ctx.Orm().Delete(&tok)
+		return nil, nil
+	}
+	return &tok, nil
+}
+
+func init() {
+	orm.Register(&Client{}, &orm.Options{
+		Table: "oauth2server_client",
+	})
+	orm.Register(&AuthorizationCode{}, &orm.Options{
+		Table: "oauth2server_code",
+	})
+	orm.Register(&Token{}, &orm.Options{
+		Table: "oauth2server_token",
+	})
+}
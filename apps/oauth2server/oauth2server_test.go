@@ -0,0 +1,401 @@
+package oauth2server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/config"
+	_ "gnd.la/orm/driver/memory"
+	"gnd.la/util/stringutil"
+)
+
+const testRedirectURI = "https://client.example.com/callback"
+
+var (
+	testApp   *app.App
+	setupOnce sync.Once
+)
+
+type testUser int64
+
+func (u testUser) Id() int64     { return int64(u) }
+func (u testUser) IsAdmin() bool { return false }
+
+func newTester(t *testing.T) *tester.Tester {
+	setupOnce.Do(func() {
+		a := app.New()
+		a.Config().Secret = stringutil.Random(32)
+		a.Config().Database = config.MustParseURL("memory://oauth2server")
+		a.SetUserFunc(func(ctx *app.Context, id int64) app.User {
+			return testUser(id)
+		})
+		a.HandleNamed("^/sign-in/$", func(ctx *app.Context) {
+			var id int64
+			ctx.ParseFormValue("u", &id)
+			if err := ctx.SignIn(testUser(id)); err != nil {
+				panic(err)
+			}
+			ctx.WriteString("ok")
+		}, app.SignInHandlerName)
+		a.Handle("^/api/read/$", Scoped("read")(func(ctx *app.Context) {
+			ctx.WriteString(strings.Join(AccessToken(ctx).Scopes, " "))
+		}))
+		a.Include("/oauth2/", App, "")
+		testApp = a
+	})
+	return tester.New(t, testApp)
+}
+
+func registerTestClient(t *testing.T, client *Client, public bool) string {
+	ctx := testApp.NewContext(nil)
+	defer testApp.CloseContext(ctx)
+	if len(client.RedirectURIs) == 0 {
+		client.RedirectURIs = []string{testRedirectURI}
+	}
+	secret, err := RegisterClient(ctx, client, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func s256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(sum[:]), "=")
+}
+
+func decodeResponse(t *testing.T, r *tester.Request) map[string]interface{} {
+	var resp map[string]interface{}
+	if err := json.Unmarshal(r.ResponseBody(), &resp); err != nil {
+		t.Fatalf("error decoding response %q: %s", string(r.ResponseBody()), err)
+	}
+	return resp
+}
+
+// authorize signs in the given user and requests an authorization
+// code with the given parameters, returning the redirect URL.
+func authorize(t *testing.T, tt *tester.Tester, userId int64, params map[string]interface{}) *url.URL {
+	tt.Get("/sign-in/", map[string]interface{}{"u": userId}).Expect(200)
+	if _, ok := params["response_type"]; !ok {
+		params["response_type"] = "code"
+	}
+	r := tt.Get("/oauth2/authorize/", params).Expect(302)
+	u, err := url.Parse(r.ResponseHeader().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	cases := []struct {
+		challenge string
+		method    string
+		verifier  string
+		valid     bool
+	}{
+		{"", "", "", true},
+		{"", "", "anything", true},
+		{"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", "S256", verifier, true},
+		{s256(verifier), "S256", verifier, true},
+		{s256(verifier), "S256", verifier + "x", false},
+		{s256(verifier), "S256", "", false},
+		// plain challenge can't be matched by sending its hash
+		{verifier, "plain", verifier, true},
+		{verifier, "plain", s256(verifier), false},
+		// S256 challenge can't be matched by sending it as the verifier
+		{s256(verifier), "S256", s256(verifier), false},
+	}
+	for ii, v := range cases {
+		code := &AuthorizationCode{CodeChallenge: v.challenge, CodeChallengeMethod: v.method}
+		if valid := verifyChallenge(code, v.verifier); valid != v.valid {
+			t.Errorf("case %d: expecting verifyChallenge = %v, got %v", ii, v.valid, valid)
+		}
+	}
+}
+
+func TestAuthorizationCode(t *testing.T) {
+	tt := newTester(t)
+	client := &Client{Name: "public", Trusted: true}
+	registerTestClient(t, client, true)
+	verifier := stringutil.Random(48)
+	params := func() map[string]interface{} {
+		return map[string]interface{}{
+			"client_id":             client.ClientId,
+			"redirect_uri":          testRedirectURI,
+			"scope":                 "read write",
+			"state":                 "xyz",
+			"code_challenge":        s256(verifier),
+			"code_challenge_method": "S256",
+		}
+	}
+	// Unregistered redirect_uri is never redirected to
+	p := params()
+	p["redirect_uri"] = "https://evil.example.com/"
+	tt.Get("/sign-in/", map[string]interface{}{"u": 1}).Expect(200)
+	tt.Get("/oauth2/authorize/", p).Expect(400)
+	// Public clients must use PKCE
+	p = params()
+	delete(p, "code_challenge")
+	delete(p, "code_challenge_method")
+	u := authorize(t, tt, 1, p)
+	if e := u.Query().Get("error"); e != "invalid_request" {
+		t.Errorf("expecting invalid_request without code_challenge, got %q", e)
+	}
+	// plain requires AllowPlainPKCE, including when no method is specified
+	for _, method := range []string{"plain", ""} {
+		p = params()
+		p["code_challenge"] = verifier
+		p["code_challenge_method"] = method
+		u = authorize(t, tt, 1, p)
+		if e := u.Query().Get("error"); e != "invalid_request" {
+			t.Errorf("expecting invalid_request with method %q, got %q", method, e)
+		}
+		AllowPlainPKCE = true
+		u = authorize(t, tt, 1, p)
+		AllowPlainPKCE = false
+		code := u.Query().Get("code")
+		if code == "" {
+			t.Fatalf("expecting a code with method %q and AllowPlainPKCE, got %s", method, u)
+		}
+		tt.Form("/oauth2/token/", map[string]interface{}{
+			"grant_type":    "authorization_code",
+			"client_id":     client.ClientId,
+			"code":          code,
+			"redirect_uri":  testRedirectURI,
+			"code_verifier": verifier,
+		}).Expect(200)
+	}
+	// Unknown scope
+	p = params()
+	p["scope"] = "read admin"
+	u = authorize(t, tt, 1, p)
+	if e := u.Query().Get("error"); e != "invalid_scope" {
+		t.Errorf("expecting invalid_scope, got %q", e)
+	}
+
+	u = authorize(t, tt, 1, params())
+	if s := u.Scheme + "://" + u.Host + u.Path; s != testRedirectURI {
+		t.Fatalf("expecting redirect to %s, got %s", testRedirectURI, u)
+	}
+	if s := u.Query().Get("state"); s != "xyz" {
+		t.Errorf("expecting state xyz, got %q", s)
+	}
+	code := u.Query().Get("code")
+	if code == "" {
+		t.Fatalf("no code in redirect %s", u)
+	}
+	exchange := func(code string, redirectURI string, verifier string) *tester.Request {
+		return tt.Form("/oauth2/token/", map[string]interface{}{
+			"grant_type":    "authorization_code",
+			"client_id":     client.ClientId,
+			"code":          code,
+			"redirect_uri":  redirectURI,
+			"code_verifier": verifier,
+		})
+	}
+	// Wrong verifier consumes the code
+	exchange(code, testRedirectURI, "wrong").Expect(400).ExpectJSON("error", "invalid_grant")
+	exchange(code, testRedirectURI, verifier).Expect(400).ExpectJSON("error", "invalid_grant")
+
+	// redirect_uri must match the one used in the authorization request
+	code = authorize(t, tt, 1, params()).Query().Get("code")
+	exchange(code, "https://client.example.com/other", verifier).Expect(400).
+		ExpectJSON("error_description", "redirect_uri does not match")
+
+	code = authorize(t, tt, 1, params()).Query().Get("code")
+	r := exchange(code, testRedirectURI, verifier).Expect(200).
+		ExpectHeader("Cache-Control", "no-store").
+		ExpectJSON("token_type", "bearer").
+		ExpectJSON("scope", "read write")
+	resp := decodeResponse(t, r)
+	// Codes can only be used once
+	exchange(code, testRedirectURI, verifier).Expect(400).ExpectJSON("error", "invalid_grant")
+
+	access, _ := resp["access_token"].(string)
+	refresh, _ := resp["refresh_token"].(string)
+	if access == "" || refresh == "" {
+		t.Fatalf("expecting access and refresh tokens, got %v", resp)
+	}
+	tt.Get("/api/read/", nil).AddHeader("Authorization", "Bearer "+access).Expect("read write")
+}
+
+func TestRefreshToken(t *testing.T) {
+	tt := newTester(t)
+	client := &Client{Name: "confidential", Trusted: true}
+	secret := registerTestClient(t, client, false)
+	other := &Client{Name: "other", Trusted: true}
+	otherSecret := registerTestClient(t, other, false)
+	code := authorize(t, tt, 2, map[string]interface{}{
+		"client_id":    client.ClientId,
+		"redirect_uri": testRedirectURI,
+		"scope":        "read write",
+	}).Query().Get("code")
+	resp := decodeResponse(t, tt.Form("/oauth2/token/", map[string]interface{}{
+		"grant_type":    "authorization_code",
+		"client_id":     client.ClientId,
+		"client_secret": secret,
+		"code":          code,
+		"redirect_uri":  testRedirectURI,
+	}).Expect(200))
+	refresh := resp["refresh_token"].(string)
+	refreshWith := func(clientId string, secret string, token string, scope string) *tester.Request {
+		return tt.Form("/oauth2/token/", map[string]interface{}{
+			"grant_type":    "refresh_token",
+			"client_id":     clientId,
+			"client_secret": secret,
+			"refresh_token": token,
+			"scope":         scope,
+		})
+	}
+	// Refresh tokens are bound to their client
+	refreshWith(other.ClientId, otherSecret, refresh, "").Expect(400).ExpectJSON("error", "invalid_grant")
+	// Scopes can't be widened
+	refreshWith(client.ClientId, secret, refresh, "read admin").Expect(400).ExpectJSON("error", "invalid_scope")
+	// But they can be narrowed
+	resp = decodeResponse(t, refreshWith(client.ClientId, secret, refresh, "read").Expect(200).ExpectJSON("scope", "read"))
+	rotated := resp["refresh_token"].(string)
+	if rotated == "" || rotated == refresh {
+		t.Fatalf("expecting a new refresh token, got %q", rotated)
+	}
+	// The old refresh token has been rotated
+	refreshWith(client.ClientId, secret, refresh, "").Expect(400).ExpectJSON("error", "invalid_grant")
+	// The new one keeps the narrowed scopes
+	refreshWith(client.ClientId, secret, rotated, "write").Expect(400).ExpectJSON("error", "invalid_scope")
+	tt.Get("/api/read/", map[string]interface{}{"access_token": resp["access_token"]}).Expect("read")
+}
+
+func TestClientAuthentication(t *testing.T) {
+	tt := newTester(t)
+	public := &Client{Name: "public", AllowClientCredentials: true}
+	registerTestClient(t, public, true)
+	confidential := &Client{Name: "confidential"}
+	secret := registerTestClient(t, confidential, false)
+	allowed := &Client{Name: "service", AllowClientCredentials: true, Scopes: []string{"read"}}
+	allowedSecret := registerTestClient(t, allowed, false)
+	token := func(params map[string]interface{}) *tester.Request {
+		params["grant_type"] = "client_credentials"
+		return tt.Form("/oauth2/token/", params)
+	}
+	// Unknown client and wrong secret
+	token(map[string]interface{}{"client_id": "nope"}).Expect(401).ExpectJSON("error", "invalid_client")
+	token(map[string]interface{}{"client_id": confidential.ClientId, "client_secret": "wrong"}).
+		Expect(401).ExpectHeader("WWW-Authenticate", "Basic realm=\"oauth2\"")
+	// Public clients can't send a secret
+	token(map[string]interface{}{"client_id": public.ClientId, "client_secret": "foo"}).Expect(401)
+	// Public clients can't use client_credentials, even if allowed
+	token(map[string]interface{}{"client_id": public.ClientId}).Expect(400).ExpectJSON("error", "unauthorized_client")
+	// Confidential clients need AllowClientCredentials
+	token(map[string]interface{}{"client_id": confidential.ClientId, "client_secret": secret}).
+		Expect(400).ExpectJSON("error", "unauthorized_client")
+	// Scopes are limited to the ones allowed for the client
+	token(map[string]interface{}{"client_id": allowed.ClientId, "client_secret": allowedSecret, "scope": "write"}).
+		Expect(400).ExpectJSON("error", "invalid_scope")
+	// HTTP basic authentication
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(allowed.ClientId+":"+allowedSecret))
+	resp := decodeResponse(t, tt.Form("/oauth2/token/", map[string]interface{}{
+		"grant_type": "client_credentials",
+		"scope":      "read",
+	}).AddHeader("Authorization", auth).Expect(200).ExpectJSON("scope", "read"))
+	if _, ok := resp["refresh_token"]; ok {
+		t.Error("client_credentials must not issue a refresh token")
+	}
+	// Token requests must use POST
+	tt.Get("/oauth2/token/", map[string]interface{}{
+		"grant_type":    "client_credentials",
+		"client_id":     allowed.ClientId,
+		"client_secret": allowedSecret,
+	}).Expect(400).ExpectJSON("error", "invalid_request")
+}
+
+func TestIntrospectAndRevoke(t *testing.T) {
+	tt := newTester(t)
+	client := &Client{Name: "confidential", Trusted: true}
+	secret := registerTestClient(t, client, false)
+	public := &Client{Name: "public"}
+	registerTestClient(t, public, true)
+	code := authorize(t, tt, 3, map[string]interface{}{
+		"client_id":    client.ClientId,
+		"redirect_uri": testRedirectURI,
+		"scope":        "read",
+	}).Query().Get("code")
+	resp := decodeResponse(t, tt.Form("/oauth2/token/", map[string]interface{}{
+		"grant_type":    "authorization_code",
+		"client_id":     client.ClientId,
+		"client_secret": secret,
+		"code":          code,
+		"redirect_uri":  testRedirectURI,
+	}).Expect(200))
+	access := resp["access_token"].(string)
+	refresh := resp["refresh_token"].(string)
+	introspect := func(token string) *tester.Request {
+		return tt.Form("/oauth2/introspect/", map[string]interface{}{
+			"client_id":     client.ClientId,
+			"client_secret": secret,
+			"token":         token,
+		})
+	}
+	// Public clients can't introspect
+	tt.Form("/oauth2/introspect/", map[string]interface{}{"client_id": public.ClientId, "token": access}).Expect(401)
+	introspect(access).Expect(200).
+		ExpectJSON("active", true).
+		ExpectJSON("scope", "read").
+		ExpectJSON("client_id", client.ClientId).
+		ExpectJSON("token_type", "access_token").
+		ExpectJSON("sub", "3")
+	introspect("invalid").Expect(200).ExpectJSON("active", false)
+	revoke := func(clientId string, secret string, token string) *tester.Request {
+		return tt.Form("/oauth2/revoke/", map[string]interface{}{
+			"client_id":     clientId,
+			"client_secret": secret,
+			"token":         token,
+		})
+	}
+	// Invalid tokens and tokens from other clients don't
+	// cause errors, but they're not revoked either.
+	revoke(client.ClientId, secret, "invalid").Expect(200)
+	revoke(public.ClientId, "", refresh).Expect(200)
+	introspect(refresh).ExpectJSON("active", true)
+	// Revoking the refresh token revokes the whole grant
+	revoke(client.ClientId, secret, refresh).Expect(200)
+	introspect(refresh).ExpectJSON("active", false)
+	introspect(access).ExpectJSON("active", false)
+	tt.Get("/api/read/", nil).AddHeader("Authorization", "Bearer "+access).Expect(401)
+}
+
+func TestScoped(t *testing.T) {
+	tt := newTester(t)
+	client := &Client{Name: "service", AllowClientCredentials: true}
+	secret := registerTestClient(t, client, false)
+	tokenFor := func(scope string) string {
+		resp := decodeResponse(t, tt.Form("/oauth2/token/", map[string]interface{}{
+			"grant_type":    "client_credentials",
+			"client_id":     client.ClientId,
+			"client_secret": secret,
+			"scope":         scope,
+		}).Expect(200))
+		return resp["access_token"].(string)
+	}
+	tt.Get("/api/read/", nil).Expect(401).ExpectHeader("WWW-Authenticate", `Bearer realm="api"`)
+	tt.Get("/api/read/", nil).AddHeader("Authorization", "Bearer invalid").
+		Expect(401).ExpectHeader("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+	write := tokenFor("write")
+	tt.Get("/api/read/", nil).AddHeader("Authorization", "Bearer "+write).
+		Expect(403).ExpectHeader("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="read"`)
+	read := tokenFor("read write")
+	tt.Get("/api/read/", nil).AddHeader("Authorization", "Bearer "+read).Expect(200).Expect("read write")
+	tt.Get("/api/read/", map[string]interface{}{"access_token": read}).Expect(200)
+}
+
+func init() {
+	RegisterScope("read", "Read your data")
+	RegisterScope("write", "Modify your data")
+}
@@ -0,0 +1,78 @@
+package oauth2server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gnd.la/app"
+)
+
+const accessTokenKey = "gnd.la/apps/oauth2server.access-token"
+
+// AccessToken returns the access token used to authenticate the
+// current request. It returns nil if the handler was not wrapped
+// with the transformer returned by Scoped.
+func AccessToken(ctx *app.Context) *Token {
+	tok, _ := ctx.Get(accessTokenKey).(*Token)
+	return tok
+}
+
+// bearerToken returns the access token sent in the request, either
+// in the Authorization header or in the access_token parameter, as
+// described in RFC 6750.
+func bearerToken(ctx *app.Context) string {
+	if auth := ctx.GetHeader("Authorization"); auth != "" {
+		if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+			return strings.TrimSpace(auth[7:])
+		}
+		return ""
+	}
+	return ctx.FormValue("access_token")
+}
+
+func writeBearerError(ctx *app.Context, status int, code string, scopes []string) {
+	value := `Bearer realm="api"`
+	if code != "" {
+		value += fmt.Sprintf(`, error="%s"`, code)
+	}
+	if len(scopes) > 0 {
+		value += fmt.Sprintf(`, scope="%s"`, strings.Join(scopes, " "))
+	}
+	ctx.SetHeader("WWW-Authenticate", value)
+	ctx.WriteHeader(status)
+}
+
+// Scoped returns an app.Transformer which requires the requests to
+// include a valid access token (either in the Authorization header or in
+// the access_token parameter) with all the given scopes. Requests without
+// a token or with an invalid one are rejected with 401, while the ones
+// with a token lacking any of the scopes are rejected with 403. Use
+// AccessToken to retrieve the token from the wrapped handler.
+func Scoped(scopes ...string) app.Transformer {
+	return func(handler app.Handler) app.Handler {
+		return func(ctx *app.Context) {
+			raw := bearerToken(ctx)
+			if raw == "" {
+				writeBearerError(ctx, http.StatusUnauthorized, "", nil)
+				return
+			}
+			tok, err := findToken(ctx, raw)
+			if err != nil {
+				panic(err)
+			}
+			if tok == nil || tok.Type != TokenTypeAccess {
+				writeBearerError(ctx, http.StatusUnauthorized, "invalid_token", nil)
+				return
+			}
+			for _, v := range scopes {
+				if !tok.HasScope(v) {
+					writeBearerError(ctx, http.StatusForbidden, "insufficient_scope", scopes)
+					return
+				}
+			}
+			ctx.Set(accessTokenKey, tok)
+			handler(ctx)
+		}
+	}
+}
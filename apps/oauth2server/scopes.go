@@ -0,0 +1,75 @@
+package oauth2server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	scopes = map[string]*Scope{}
+)
+
+// Scope represents a permission that clients might request.
+type Scope struct {
+	// Name is the scope identifier, used in the requests.
+	Name string
+	// Description is shown to the users in the consent page.
+	Description string
+}
+
+// RegisterScope registers a new scope with the given name and
+// description. Clients can only request registered scopes. This
+// function should be called from an init function.
+func RegisterScope(name string, description string) {
+	if name == "" || strings.ContainsAny(name, " \"\\") {
+		panic(fmt.Errorf("invalid scope name %q", name))
+	}
+	if _, ok := scopes[name]; ok {
+		panic(fmt.Errorf("duplicate scope %q", name))
+	}
+	scopes[name] = &Scope{Name: name, Description: description}
+}
+
+// Scopes returns all the registered scopes, sorted by name.
+func Scopes() []*Scope {
+	var s []*Scope
+	for _, v := range scopes {
+		s = append(s, v)
+	}
+	sort.Sort(byName(s))
+	return s
+}
+
+type byName []*Scope
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+// parseScopes splits the given space separated scopes, removing
+// duplicates. If client is non-nil, it returns false if the client
+// is not allowed to request any of them.
+func parseScopes(s string, client *Client) ([]string, bool) {
+	var res []string
+	for _, v := range strings.Fields(s) {
+		if contains(res, v) {
+			continue
+		}
+		if client != nil && !client.CanRequest(v) {
+			return nil, false
+		}
+		res = append(res, v)
+	}
+	return res, true
+}
+
+func scopesByName(names []string) []*Scope {
+	var s []*Scope
+	for _, v := range names {
+		if sc := scopes[v]; sc != nil {
+			s = append(s, sc)
+		}
+	}
+	return s
+}
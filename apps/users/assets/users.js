@@ -304,6 +304,13 @@
         return !ns._isMobile();
     }
     ns._onSignedIn = function(user) {
+        if (user && user.redirect) {
+            // The sign in must be completed in another page
+            // (e.g. for entering the two-factor authentication
+            // code or after signing up with an unverified email).
+            window.location.href = user.redirect;
+            return;
+        }
         var modal = $('#sign-in-modal');
         if (modal.length) {
             modal.on('hidden.bs.modal', function () {
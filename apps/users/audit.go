@@ -0,0 +1,137 @@
+package users
+
+import (
+	"sync"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/log"
+	"gnd.la/orm"
+)
+
+const (
+	// AuditSignIn is recorded when a user signs in. For social
+	// sign ins, the event Provider is the social type name.
+	AuditSignIn = "sign-in"
+	// AuditSignInFailed is recorded when a user enters a wrong
+	// password or two-factor authentication code.
+	AuditSignInFailed = "sign-in-failed"
+	// AuditSignUp is recorded when a user signs up.
+	AuditSignUp = "sign-up"
+	// AuditSignOut is recorded when a user signs out.
+	AuditSignOut = "sign-out"
+	// AuditPasswordReset is recorded when a user resets their password.
+	AuditPasswordReset = "password-reset"
+	// AuditEmailVerified is recorded when a user verifies their email.
+	AuditEmailVerified = "email-verified"
+	// AuditSocialLink is recorded when a social account is linked
+	// to an existing user. The event Provider is the social type name.
+	AuditSocialLink = "social-link"
+
+	ActivityHandlerName = "users-activity"
+)
+
+var (
+	// ActivityTemplateName is the template used for displaying
+	// the recent events in the user's account.
+	ActivityTemplateName = "activity.html"
+	// ActivityLimit is the maximum number of events displayed
+	// by the handler named ActivityHandlerName.
+	ActivityLimit = 50
+
+	ActivityHandler = app.NamedHandler(ActivityHandlerName, app.SignedIn(activityHandler))
+
+	auditEnabled  bool
+	registerAudit sync.Once
+)
+
+// AuditEvent is an entry in the audit log. It's registered with
+// the ORM by EnableAuditLog.
+type AuditEvent struct {
+	Id     int64 `orm:",primary_key,auto_increment"`
+	UserId int64 `orm:",index"`
+	// Type is the event type e.g. AuditSignIn.
+	Type string
+	// Provider is the social type name for the
+	// events which involve a social account.
+	Provider      string `orm:",omitempty,nullempty"`
+	RemoteAddress string `orm:",omitempty,nullempty"`
+	UserAgent     string `orm:",omitempty,nullempty"`
+	Created       time.Time
+}
+
+// EnableAuditLog enables recording sign ins, sign outs, password resets
+// and other account related events to the ORM. Users can view the events
+// for their account in the handler named ActivityHandlerName. This function
+// should be called from an init function.
+func EnableAuditLog() {
+	registerAudit.Do(func() {
+		orm.Register(&AuditEvent{}, &orm.Options{
+			Table: "users_audit_event",
+		})
+		auditEnabled = true
+	})
+}
+
+// Audit records an event of the given type for the given user id, using
+// the remote address and user agent from the current request. Apps might
+// use it to record their own events. If the audit log is not enabled,
+// this function does nothing.
+func Audit(ctx *app.Context, userId int64, typ string, provider string) error {
+	if !auditEnabled {
+		return nil
+	}
+	ev := &AuditEvent{
+		UserId:   userId,
+		Type:     typ,
+		Provider: provider,
+		Created:  time.Now().UTC(),
+	}
+	if ctx.R != nil {
+		ev.RemoteAddress = ctx.RemoteAddress()
+		ev.UserAgent = ctx.R.UserAgent()
+	}
+	_, err := ctx.Orm().Insert(ev)
+	return err
+}
+
+// AuditEvents returns the most recent events for the given
+// user id, up to limit (0 means no limit).
+func AuditEvents(ctx *app.Context, userId int64, limit int) ([]*AuditEvent, error) {
+	if !auditEnabled {
+		return nil, nil
+	}
+	q := ctx.Orm().Query(orm.Eq("UserId", userId)).Sort("Id", orm.DESC)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var events []*AuditEvent
+	if err := q.All(&events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// audit works like Audit, but logs any errors rather than returning
+// them, so failing to record an event doesn't prevent the user from
+// e.g. signing in.
+func audit(ctx *app.Context, userId int64, typ string, provider string) {
+	if err := Audit(ctx, userId, typ, provider); err != nil {
+		log.Errorf("error recording %s event for user %d: %s", typ, userId, err)
+	}
+}
+
+func activityHandler(ctx *app.Context) {
+	if !auditEnabled {
+		ctx.NotFound("")
+		return
+	}
+	events, err := AuditEvents(ctx, ctx.User().Id(), ActivityLimit)
+	if err != nil {
+		panic(err)
+	}
+	data := map[string]interface{}{
+		"Events": events,
+	}
+	ctx.MustExecute(ActivityTemplateName, data)
+}
@@ -0,0 +1,53 @@
+package users
+
+import (
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	ctx := newTestContext(t, "192.0.2.20:1234")
+	defer testApp.CloseContext(ctx)
+	ctx.R.Header.Set("User-Agent", "gondola-test")
+	user := newTestUser(t, ctx, "audited", "secret", true)
+	// A failed sign in records an event
+	s := &SignIn{Username: user.Username, Password: "wrong"}
+	if err := s.ValidateUsername(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidatePassword(ctx); err != ErrInvalidPassword {
+		t.Fatalf("expecting ErrInvalidPassword, got %v", err)
+	}
+	if err := Audit(ctx, user.Id(), AuditSocialLink, "github"); err != nil {
+		t.Fatal(err)
+	}
+	audit(ctx, user.Id(), AuditSignIn, "")
+	// Events for other users are not returned
+	audit(ctx, user.Id()+1, AuditSignIn, "")
+	events, err := AuditEvents(ctx, user.Id(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{AuditSignIn, AuditSocialLink, AuditSignInFailed}
+	if len(events) != len(expect) {
+		t.Fatalf("expecting %d events, got %d", len(expect), len(events))
+	}
+	for ii, v := range expect {
+		ev := events[ii]
+		if ev.Type != v {
+			t.Errorf("expecting event %d of type %q, got %q", ii, v, ev.Type)
+		}
+		if ev.UserId != user.Id() || ev.RemoteAddress != "192.0.2.20" || ev.UserAgent != "gondola-test" {
+			t.Errorf("unexpected event %d %+v", ii, ev)
+		}
+	}
+	if events[1].Provider != "github" {
+		t.Errorf("expecting provider github, got %q", events[1].Provider)
+	}
+	limited, err := AuditEvents(ctx, user.Id(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 1 || limited[0].Type != AuditSignIn {
+		t.Errorf("expecting only the most recent event with limit 1, got %d", len(limited))
+	}
+}
@@ -58,8 +58,7 @@ func signInFacebookTokenHandler(ctx *app.Context, client *oauth2.Client, token *
 	if err != nil {
 		panic(err)
 	}
-	ctx.MustSignIn(asGondolaUser(user))
-	redirectToFrom(ctx)
+	signInAndRedirect(ctx, user, SocialTypeFacebook)
 }
 
 func jsSignInFacebookHandler(ctx *app.Context) {
@@ -77,8 +76,7 @@ func jsSignInFacebookHandler(ctx *app.Context) {
 	if err != nil {
 		panic(err)
 	}
-	ctx.MustSignIn(asGondolaUser(user))
-	writeJSONEncoded(ctx, user)
+	writeSignedIn(ctx, user, SocialTypeFacebook)
 }
 
 func fetchFacebookUser(ctx *app.Context, token *oauth2.Token) (*Facebook, error) {
@@ -112,8 +110,18 @@ func userFromFacebookToken(ctx *app.Context, token *oauth2.Token) (reflect.Value
 	if err != nil {
 		return reflect.Value{}, err
 	}
-	user, err := fetchFacebookUser(ctx, extended)
-	return userWithSocialAccount(ctx, SocialTypeFacebook, user)
+	fbUser, err := fetchFacebookUser(ctx, extended)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	user, err := userWithSocialAccount(ctx, SocialTypeFacebook, fbUser)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := storeToken(ctx, user, SocialTypeFacebook, extended); err != nil {
+		return reflect.Value{}, err
+	}
+	return user, nil
 }
 
 func facebookChannelHandler(ctx *app.Context) {
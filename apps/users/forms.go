@@ -58,6 +58,9 @@ type SignIn struct {
 }
 
 func (s *SignIn) ValidateUsername(ctx *app.Context) error {
+	if isLockedOut(ctx, 0) {
+		return ErrLockedOut
+	}
 	norm := Normalize(s.Username)
 	_, userVal := newEmptyUser()
 	var ok bool
@@ -73,6 +76,7 @@ func (s *SignIn) ValidateUsername(ctx *app.Context) error {
 		}
 	}
 	if !ok {
+		recordFailure(ctx, 0)
 		return ErrNoUser
 	}
 	s.User = userVal
@@ -81,13 +85,24 @@ func (s *SignIn) ValidateUsername(ctx *app.Context) error {
 
 func (s *SignIn) ValidatePassword(ctx *app.Context) error {
 	if s.User != nil {
-		pw := getUserValue(reflect.ValueOf(s.User), "Password").(password.Password)
+		user := reflect.ValueOf(s.User)
+		userId := asGondolaUser(user).Id()
+		if isLockedOut(ctx, userId) {
+			return ErrLockedOut
+		}
+		pw := getUserValue(user, "Password").(password.Password)
 		if !pw.IsValid() {
 			return ErrNoPassword
 		}
 		if pw.Check(s.Password) != nil {
+			recordFailure(ctx, userId)
+			audit(ctx, userId, AuditSignInFailed, "")
 			return ErrInvalidPassword
 		}
+		clearFailures(ctx, userId)
+		if RequireVerifiedEmail && !getUserValue(user, "EmailVerified").(bool) {
+			return ErrEmailNotVerified
+		}
 	}
 	return nil
 }
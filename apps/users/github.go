@@ -57,7 +57,7 @@ func signInGithubTokenHandler(ctx *app.Context, client *oauth2.Client, token *oa
 			panic(err)
 		}
 	}
-	windowCallbackHandler(ctx, user, callback)
+	windowCallbackHandler(ctx, user, SocialTypeGithub, callback)
 }
 
 func userFromGithubToken(ctx *app.Context, token *oauth2.Token) (reflect.Value, error) {
@@ -88,5 +88,12 @@ func userFromGithubToken(ctx *app.Context, token *oauth2.Token) (reflect.Value,
 	if gh.Email == "" && len(emails) > 0 {
 		gh.Email = emails[0].Address
 	}
-	return userWithSocialAccount(ctx, SocialTypeGithub, gh)
+	user, err := userWithSocialAccount(ctx, SocialTypeGithub, gh)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := storeToken(ctx, user, SocialTypeGithub, token); err != nil {
+		return reflect.Value{}, err
+	}
+	return user, nil
 }
@@ -19,57 +19,65 @@ var (
 func init() {
 	App.SetName("Users")
 	var manager *assets.Manager
-	assetsFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\tn\x88\x02\xff\xec;\u007fs۶\x92\xf9۟\x02I}\x115\x91(Ɏ\xd3F\xae_\xf3\xb3\xad;i\x9c\xab\x93\xe9\u0378~\x1a\x88\x84$\xda\x14ɐ\x90e7\xd6w\xbf]\x00\xa4@\x10\xa4d\xe7\uef79\xb9(\x19[\x06w\x17\x8b\xc5\xfe\x06\xb8\xc8X\x9a\xb9\x17ك\xff\xc5O\u007f\xd0\xef?{\xf6\xf4A\xbf\xdf\x1f|\u007f\xd0\xd7\u007f\xc3g\xff\xa0\xbf?x0\xd8\xdb\u007f\xba\xb7\xbf\xf7\xec\xfb\xa7\xfb\x00?\x00\x94\a\xa4\xff\xe0_\xf0Yd\x9c\xa6\xc0\xcaW/R~\x8a\xdf\xffG>\xced\x11y<\x88#g\xb7C\":gm\xf2e\x87\x90+\x9a\x92Q\x16L\xa3 \"G\xa4\xf5\xe5\vI\xd9\x15h\n#/Na\xf48\"\xabU\xebP\a\x8c\x17\xdc\x06y\x02\xc3\n\x14\x1e\x05\x13\xf2\xe2g\xea\xb1q\x1c_\xbeL\x12x\x02\xe3\x8a\xc8d\f#Ǿ\"\xa2\x83\xb90\xaah\x14\xb0\x1fX:\xcf\x00V\aű \xcb`1\xd9\xedE\x16#\x8f\x1a\xceE\x96é\x15\x18\xdc\xfev*\xc7s\xa8ʔ\xafg4\x8aX\xf8)\rM\xd4\x1cEAh\vf\x91/\x17\xa9\x16\xffK\x1cOCf.\xfd\"\x93\xe3͌I\x18\x1bqI&By\xec\x9e\xe1&\x9ek\xdfnoɮ\xf3eՖk\x892\xf7\xe7W\xa3?\x8f\u07fd\x1b\xbd;y\xf9\x06\xe7\x92.`2\xee.\x830\xec\x861\xf5[:,\x82\xbd-\x03\"\fӠ~99\xf9\xe5\xdd[\x1bթ\xe0\xd9FY\xe1\x98\xd4\x15\x829\xc3\xe9\xf1/\xef\u07fe\x19\x1d\xbf_C\xa2\xd21\xbf\x1bD\x15\xa8\x93O\x1f+`\xa0\x9e\x00\x97\x03\x8e|6\xa1\x8b\x90\v\r\x12\x83\xf8\xa1\v\x1eO\xc6C2\xa1a\xc6:\xa4\xd7\x13#\xc0\xcb\x14\xac@P\x83\x9fA4%\xf9~\x17\x98\x17ِ\xf0t!\x91\x00\x92\xfcvJxL2ܡI\f\x9aZ@&q\xb2HLh9\x88\x90$\x8b\xbd\x80\x86\x04\xb9\x86\xa5\xad\xf1\xe6\xf18\bY\x8e\xbd\xe6\xb0\x19\x9d\x80\x11HL\x8d\x90OC9?\xe2\xfbA\x96\x84\xf4\x86P\xf9@\x12\x11v?%A$\xb0V\x9a\xdc\xe4\xf6\x8c\xe4\xf6\x80\xf0\x04#\xeb\xe7A\x14\xa0\x17(|J\x9c௬\xad\t\x19\xac\xc0AJ\b\n\xac\x06\u007f3_\u007f\x8c\x9f\x94\xf1E\x1a\x1d\x16c\xab\xe2\x1b\"*\x9a\xa8\xe1.\xbb\xe6 b\xd0\xeeNiW;$\x9f\xf8\xb0\x84\xaa\xcd\t\xe8(\x82\xc3\x12_\xfc&a\xf1d\xed\x8a\x1e\x1e\xa1\x1aE@6\x00\x1dj\x91Ǐ\x8bg&\xcf\xcb \xf2\xe3%\x18\xc7\xcb\xec&\xf2\x8e\r9\x98\xd0\xf8\xf9\xf9\x95\x10\x97S}\"t\x11'\x19\x16\xd3u\xac@^ᑆe\ae\a\x87H\xc7\x17\xb9\xee\xd9\t\x82R\a\xac\t\xe2z2\x9e+\xf5\xa9<_i\xe2\xd6V\xf9\xf6\x8aE\xdc\xcd\x16\xe3\xccK\x831sZ`U3\x17\u007f\xfc\xc1\xb2\x04v\x89!\xe3S\xd6\xea\xac\x05\x96\xaa'6\xc1\xe9Z\xa4\xf6ٕ\xa6[\a]\xa8NT\x0e\x01\xebi\x0e\xadx+\xc2@\xbd7\x10\xf5f̻\xd4CPV\xc4T\xe24q\x94\x13\x98\x8cG9\x1f\xa03\xf9\xd7\xc3Z\xbcU\x1d\xb7[m\b\xcc\xc8\xd3`:e\xa9\xa3\xfbw\x03rU\xfe\xb3\x82T\xb8\xfav\x05p\x84ۜp\xa7\xd5\xeby1\xe8\xa3\xc7݉\x92\x8e\x1b1\xdec\xd1\xe8\xd3i\x8f\x86!d\x9d\xad\xb6\xcd\xcauS4B\xa3a\x915V8\x1a\to}\x12\xadq\x99/\x02kI\xc1l\x9b\x03.\xf1\xe3,\xc8\n@\x02\xdf=`\x16<\x06\xcd\xc0\xbd\xc2\b\xfc\xe63F\xc6\v\xce\xc5s\x1b\x8d\x14\xdc\x12K\x01\tւ\xc0\xc8\x0f\x92\xa2aʨ\u007fCdT\x02\x0f\xeb\x92\xdf \x03%\x98@\xa5\xccF\t\x1c\x89NcF\xb3\xa8ŉ\x17\x06\xde%\x10\xd0\x18q\x18\xda\xd9\x02x\xbd!K+\xa9y0\x9dqB}XJD\xd0\nc\xe9\xce[\xca]\xb6\xdd\x1d\x9b\x9d\xa9\xadX\xbb\xffS\x11\x1f^+\x16\x8e\f'\t\xf9\xc6\xc3\x1a\xd8:k0\x1d~\xbdF#?\xb8u\xae\x17\xfb\xb5\xcea\xd7M\xe2\x8c;\x86\xeetH\v\x91\x8eZ\xe4\t)Hh.ǧ\x9cv\b\x87\x90r*\xfcd\x87\\|\xfe\xaf_\xff\xd8\xecSr\xf5\x12\x14\xeal\xb3\xbdiu\x86\xc5\x15\x9a,UY.\xe4$z\a\x81wS`\xb1\xc5\xe9r\xac\xb3\x9bu)#3\xfd\xc1\xceF\xbcm\\\u008csH_z\xbd$\\\xe4\xa9\x1elüw\x91\xf5@\xa3A}\x87\xf8$\x8e\x18\xf8\x86\x9f\xe2\b\xd9?\xb2\x89\xc0\xee7p&\xca9\xf5f\"\xe4d\x8e\x82Z\xed\x94\xf9\xd0\x05\x98\xa5\x9e.C̣1\xb5\xf0co1ǰ偽r\xf66d\xf8\x97Ӓ\x04\xf4\xe9A\x14`\x1f\x98o\xa2\xea\xf4.\xe8\x15U@:\fŬ\xa0\xb2\x0f\x90\x9d\xa68\n?\x0fK,$:\vS\xc6\xd5\xfc٫\x9b\x8ft\xfa\x1e\xd2\xfa5'g\xfd\xf35n\xe2&\x14\\\x0f\u007f\x0f\xaa\r\xd9\x05\x88\x8d\xbfb\x90\xd21\a\xd49\xa9\x88\x03\xe4\xfaA\xe6\x8e5*%\rSO\xbb\\\x95lB*$Ӹ\xec\r\xcb.y\x9c\x00\x1eX\xbe\x0e\xa9\xa7\xac\x95\xa9\xf5}\xd2g\x87U\xeb\f\xc0\x9fR\x14\xb2\x8c)d2\x8e\xfd\x9bv%\xa7,\xd6\xe3\xb4M\xc3\xf8\xca\xf4N\xf1\xe2\x02\xa4\xef\xb4\\\x99fwU\x9aMh\x11\xe1ZmW\xb8f-\x05\xa8uS\xccMR\xe1\xb3\xdfȴթ\xf1\x1d\xb0\xb2I9m\xb19\x93\x8a\xc1\xeel˻\n\x02m\x97\xc1\x8el\xc8]P9)\xea1\xc4\xc8C\xeb\xd3 \xe2,\xbd\xa2X#g\x8c\x1f\xab\xbf\xb6H\x89\xf2M,\xb9\xae&\uf2f3\xad\x8b\x81/\xab\xfa\x8ciס.(\x1cd\x9f\v\xce2c\xa1\x0e\x88\x85]w\b\x02lJאG\x84s\xb1\xb4\x16)-O\x9d~\x87\x1c\xb4\t\xea\x12\x06\x80nk\x13\x11\xfc(\xbe\xcf*\xc4\x0e\xdaX\xbb\x8ba\x10ۢ!\r\xb4\a\xc8MI\xa2\x12\xc7\x1d\xd4\xf4\xae\xea\xba\xc5욗\xaa2\xb2M\xcal\xcdCj\x82\xdc6<Mi\x12\xb8\xb2\xecue\xeaVb\xd2VOVJ\xa7\x90ѴP\xf7\xdc\n\xea\xd2\x01\x8b\x01\x83\x16\xf5M#n\x1fnm\xc6\xdb\xdb\xef.\x1a/\x96\xcf\x0e~\xb1p\x88Z.\x80\\Th\xa7%\\x\xab]\x9bn\t\xd0;\xe9\x13\xb2\xb1\x10}4\x89\x8c\xfa\x0e\xd9A\xca&\xad\x06\x11\x8b&\x17\xd8J\x81&\xd9áMh\xcb\xc0\xe73\x03O,\xab+\x9elB\x9f1\x91:\xdb\xf0\xe5\xa3&\x02\xa0\xb0q¢\x97P\xf0\x82>\x05\x1eE\x01\xfd)\xf2;\a\x85\xf0\x84\xb4~\x92\xe9\xdeѠ%\x9b\xb0\x1d\xc9pGM\xdc@|[\xab\xdc\"\x01\xad\xad\xc5\xf4\xa0~\x91\x99\xfb*ě\xd2$\x81\xea\xe4H\xd3\xd0\xef\x84j\x82\x84\xb0\x01\xd6U\x10\xa6\x9c\x90\xbcz\xe4\x86,\x9a\xf2Y\x9d\xd6fyoԜ!\x88\xc4\f\xb6\x1dX\x83b\x94\xeb*\x86\xee\x12\xa3\xe5\xac\x10\u007f}v\xb2\xe0%Id\tc~\xa79\x15/\xb2y\xb5D\xa4\x03\xf1\xbbB\xe6\x0e\x9b\xb6\xed\xa6\xaf\xb6\x94G\x10\xddE\x1e\xfaB\xbeF \x9a\\\xffM\xf2X\xd58V\xd4%⊊\xa3\v1y\x1e\xf0B>\xaa\x85\x04\x00\xa7\xe2\x81AQ\x93\xee\x19\xba\xb2\xa3GXj\x16\x87#`\xe6\x8fη\x95\xf56+\xca\xcb\\5\x01x\x8eE\x18\u07bf\xa0E#cW\xa2\xad*\xd2r\xa7\xd4P\xaf\x91|\xb9L\x05d\xe4\xa16\x8d͋FvUC\x0e\xdd\xc1Cv\xe5bU!\xd6\xfdAJ\x81\xf9N\xbbѾ$\x1ba,\x9d+\xc4pL\x1f\x9d{7ʶ\xf3\x85\xa2cn\xb2\u0560\x05\x10\xa6\xffǕ \xcaO8\xaaeAu\x11\xeb\xf2\xcb섚\xad1\xb3\xf7\x9a\xf7]pܕ}d\x91\xf1\xaa\x0e\x9f\xaa\x9e\xb0\xef\x13dE?\xa4m\v\x15\xa8\x95X-\xa7\xecsъ\x11T\xf5\x86\xb0Z\xd3\x1f\xec\xf3\x82e\xbc\xbc\xacuw\xa7\xbc\x84\x0e\x91\n\u007f\x1f\xfd\xdf\xdc\xc8i\x12f\xb5\x03\\\xea0,<\x8fe\x953\x90\xfc\f\xd3d\xe7\xe7W.$\xa3N\xab7g\xbdd}\xa0i6\xc7\xeb\xc2d\xa2\xa6\x17\x1d.\\G\xa9?\x90\u007f\xf0\x94\xc7\x11\xd5Z\x00\xb0\xfdC\xfc\xfdcq\xac\xaa\"1\x8e>y\xd2T\xae=\x14\xb3\x9d\xe5xgAp~\xded\xa6\xb2\x9d\xb0\xadMVF\x94$\x1b\xf5\xdcֱ\xef\xf5\xc8\xfb\x18&\xff\xbc\b\xb0%\xabIug\x03yc\xa7'\xb56c\xf6\x90\xc0E\x98EiӦ#\xf8Y+\xf3 Ola\xfdW\xec\xc4E\x1cDN\xa33\xd2O\x0f\xc0\x04\x8d!\xbb\xad\x9a\xd3[\xcfF\fJ\x96\xae^\xf9\xe0\xa2h\xa4l\xd8\rPpq\xa2\xea\xd8f\x15\x95VV\xbf\x03\xd3\xf29\xb9U\xfeuG\xed\xe6ޔ7\x1fe?\x14\xd7\tT\xd3\x18\a\x8a{\x04r#Z\xa4\xd5.\x1f\x8bɮe\xe0\x0f奅bNue\xa1c\x9ck\xfa\xa0\x80\x1e\xd8@\x00\xf0\xe8\xc5\xe6\xa0o\x14O\xbeJpT\xe8!v\xab\x00,\x9eLB\x90\xaa\x01\"\x0f\xea\x92\x18\xc2\xc8\r\x00\xe1\xa9t\xc8F3 9\x8a!\xc8BRg\xc0\xd30\x1cS\xef\x12`k\x0eG\f\x84`>\an)g\xea\xb0\x19\x8d(\x86\x02&]\x06\xf0\a\xfaj\x82\x87\xfa\xf00\x90\x87Ƌ\b\aar<\\\x153\xecX\xaa\xdd{u\x88\xb6\xee\f\x01\x8f\xa7\x8c\x81p\xe6\xd8$\x84\x89H\xcdbw\xbe\xb6\xa9 \x1a\x06\xe2\x1cSf\xb2\x8e\xa1\xb6U\a\xb6\xb1?`6\x00\xca\x17<\n\v\xa8+%uk\x80\xaa\xd2^G\x1a^j\x82\x81X\x89h\x84\xc1Xt\xa2x\xfc.^\xb2\xf45\xcd\x18l\x05d,#\x19\x8dG\xc5U\v%\xebO\xa0\t\xa2\x97\xc0cB\xaf\xe2\xc0\a\r\x9f\xb0\x94E\x1e\xde\x1e\xc0\xa3)\xa4\x97%`\xe1:\xda$\x8d\xe7\xe2\xa9Т\x19\x8d\xfc\x90\xa5;\xe5T\xeelr^Z\x10phwZE\xcc\x16 FB\xceB\xc6ٚ\xe2a\x9dF.\xc5-'%\xdcue^_\x85\x03\x82[\xf4\xa2y\xc0Cq\x00\xf0Z:X\xb1\xf8\x98\xe4\xf2D\t\xba\xae\xab\x89NuՁ\xc8auokv\xb3v\x1f\xf3\xce\xc6\xef\x14\x94q\x0eNJ\x0e\xdcޒg\xfd~\a\xfcZ\xcaX\xe4\xca\xc1.\x19\xf4u\x15+\xda\x1a\x05\xb2\x1a\x01\xec\x83\xc1\x1a[\x8d\x9a\xe8(\xba\x90M\x90\x80\xa3\xcf\xd3\xdbkw\x9d\xfc[\x19\x9cǉ\x06-\xe9\n\xf0\xe2\xabML\x98ۣl\xa4(Z\x90\v\xb5$\xbc\xc8 \x15w 掘T\f\xca\x05\xe3\x18\xec\xd1bLӣ~\a\x82U\xf07\x1d\x87\f\xbe\xf38\x0e\xe5(\xb0$0\x905\x84\xc7\x15\x89\x01\xfcb\x9e[d\x96\xa3\xe4ܷZn\xb94%\xc39\x1a¡\xf2:\xed\x8et\xb5\x9b\xd2\x19\x91r*d\xe0#\xffZ\x89\xcfx\xb1\x91Kwv\x1c5d5%\xb8\xe6\xa0\n\xb6\n\xeb\x8a9y\xf12\f\xe3%8\x80T\xc6\xe26\x940\x9f\x89\x03\x89#yq*:\x93\x1f!|A\x9e3h\xeb\xe1WQ\xda\xcd8\x19\x1e\x11\xd1x/\xc1\x93\xbe\x05\x1a\xe6\x05ڀ\xe3\xe2\xc9\x17y\x94g\f\x8fLح\x0eKЭ\xa2LM\xaa2@\xd4\xd1\xd4\xf3\x8e:\x8a\x16\xccu\x9fS\xbfч\x93\xfe*ݞ\x98\xbb\xb8h\xf8\x15]Ö\x14\xab\x9b\x13\xec(9\xbb\xe2@\xecOa\r\xabUy\xf4Wi8\xab\x95eA\xeb\xc8SwH_\x85\xc2\xd5\xca[d\xd8\\^\xb7\xe7Ęޟ\x13\xb5\x83\x18\xadi\xfa\xed⡧\xa3\x8afX\x9c\x80\x15\xeb\\g\tw(\xefDkCr\xb5\x8bM\x88\xb9\xac\xf0\xec\xe5t\xf9\b\x99\xd9Z\xf8,t=(\x14Z\xea\xf2\x1c\x8a?\x8a#k\x13\x1a@\xb1c\x16\xf9\x1fc\xe3\xf4\xd2\xd2Щ\xcaL\xf6\x18\x9cV6\x8b\x97&\xf9\x15\xbaA>\x0f\xcd\xf1\xfa\xebsR\xe4\x16\x92\x85/X;,\xcd\x11\x10ۙ\xf0Ç\x85\xdf2h\xa8N\x90\x1d[?\x87UM\xa3\rwz\xd4|\nڶ\xac\xfc\x94\xbah=\x15\xac\xb04\x8dS\b˜\x02\xe9tӒ@\xfe?B\xb6\x12A\xaeF\xb3\xec\xe8ь\x85Iw\x1c\xc6\xe0f\x05\xa1\xaeJ\xd7\xe5_\x8f\xfe\xf1c\x0f\xa1\xffQ\x11\xe2(\xc8~\x17gߛe\xd8{\x19\xf9)dN\xb7K6>9\xbd\r>\xcc@\x8f\xe0\x17\xf5\xe1G\xec\u07fe\n\xc1\xb3\xbf\x82\xf9nn\x8f\xdfJ\xa2\xb7'P\xc0R\xf2{\x10\x05\xbd\xc0\xe5,\xe3ND\xaf\x82)\xe5q*d\xffr\n:f\xe1I\x1d\xd4odJ_A\xf5\n\xc5:\xe5jJ\xd0\xee\xe4\n\x9a<\x81|\x063\xb4f\x81\xefC\xce0V}\xb7\x92/\xb0\x19\xbd\xc4L\xd9<\xbebM-\x83\x8aa\xc0D\xcc~\xc1\xa4\xd2\xed,kdC\a\xf5\xf8}\xf9*\xea\xba\x13\xda!gH\xe5ܐ\x89\x1e\xe4\xdb\r\t\x80\x90|GT)\x96\n]K\x13\xb6-\xcfE]\x80\x19\xbaH\x88G\x9f\xc1\xcd៦\x93\xc1{r'oN\x86\x90\"\xce\u061cuS\x16B\x84\xbab\x18\xee\xb2J\xf5&\b\xe2\x95\x11\xfcr\xd6?\x17\xfd\x88^+\xbf1\x82u\xabx$\x8eы\x11\xb3\xab\x8b\xedԶ\xb5\x0fl\x83D\xf5\x04\x92\xc6\xc6\x17\xc1\xffN}fX\xed\xf1D]\xb0\x86\xf8\x9a2\x8am\x81%k\xa5P\xc5pQ̠\x86\xe77\xa6\r\xd4\x04}\xc62\x80\xeax\xc1\t\x95\xd2Mh\n\x91\x9a\xe3\xde\xc9f5T\r5\xd7\xffD\x9d\xedQ\xbc\xe9M\x8b\x1e\x02V\x18=w\xe7~\x8d\xf0Um+\xf3sVr\x0f\x97\xa6Mc\xeb֜#\x83\xc2֛\xe5\x17\x18\x06m\x17\x82b\xc0\x9d\xd6c]g\xec\x1dGZn5V5\x11\xbd\x15žbN\xf4\xc8v\x82\x98(2\x10JȞm\xff<\b\x01Ad\x16\xf2\xab*!\xa5\x9b\x976\"\xca?\xfa\f/\x0e~\xfa\xe3\xf8u<O\xc0[\x83\x9d'g\x83s\x105\xe4\x02\x1esz\u007f=\xe9M;\xe4\x11y\xd4\xde \xf6\xe6\xd8%l\xc0\xa8\x03+.\x16\r;\x05\xcfE\xb97sz\xff\x14\xf7\xeb~\x1a\xfe\xd5\xfb\xabw\xf6\xcf\xde\xf9\x93^\xb5\x9c\x9a\x93\x9f\x880\xc1\xa1\x9a\xb4z2P\x1cp\x95t\x81UtA>8\x12o8\xa83\xf1\x8b\xac+\x87+\xe9\x9e\x1c\xde\xfe\xaa\u007f\xd3\xe1\aήn\xbdZ\xae1\b\x0f\x86\xa7xG\nH\xdd\xe4\xc8\xe4ឡ\x95\xf3\xfcB\x858\xa6\x9e\xa6\xb18 \x96a\xe35\xe6\x01\x10\x10h\xd6\x15!\xbf\x0e\xb5\x94\x1d\x00v~:Z\x1f\xa0\x14Ӗ𤇦\x9c\xfd4ND\xb2\x89\xb5\xabߪx{\xedXEp\x05\x11A\xbe\xe5\xa0\xd3\xddu\xe9\x05\xbd6\xde6P=\xc6\x0f'\xa7\x1f\x8d\xfe\xdf\x02\xdf)\x90{V~\x80\x13\r\xe5Q\x8b\xad{>\xbc\xd7\xf9\vj\b\x82KIfug\b\x85\x13\xb9\xc4\xde\xde\x16\b\xeb\x96c\"^O\xd36M\f\x89W\xa4D\xb1\u007f\x89\x05\xd5\xf9\xa6{ ~\x80\xe1]\xa0\xae\x95\xaa\xac9\x93 \xcd\x1a\xefF\x01\r\x97\xfa~\x93n٦\xf6\xb4,\xb6\x92\xd96\xcdW \xba\xb8\x15\xba\x98\xcf.Ϸ\xc2۶֩b\xaeK\x9f\xe0j+\x04u!`\xeb\x83\xdb&+1\x9b)[\xbdձݕn\xe3\xe6L\xd9\x12\x84p5;\x10Z_6\x04\x01\xf2q\x96\xc6\xcbȦ\xba4d)ĺ\xb7\x92\x90hN\x15\xc8\x16n\xee\"\x80U\xc5ѬvVm\xe7\xe2?\x17,\xbd騗\xe6ps\x1f|\xfb\xfc\u007f\xf8ȗ$C\xf0\xdc\xff\xae\xf7\xbf\xfb\xcf\xf6\xf7\x9f\xaa\xf7\xbf\x9f\x0e\x9e\x0e\xc4\xfb\xdf\xfb\xfb\a\xdf\xde\xff\xfeW|^L\xc6^\x1c\xa2\x9b\xf9n\u007f|\xf0\xfc\xf9\x0f\x87;/\xa6\xf9\x88\xef?\x1d\xef?\x87\x11\xbe,\x80\xf6=o2A\xa0Y1D\xf1\x1f\fI?4J\xa1\x98\xc1\xd7\f\x9f&א]\xba\x10\x17\xa1\xf4\xf4\xbb^\x9cF\xf8\n\xad\xf3\"\a8H\xae\xa5\xf7\xeb.\xd9\xf82\xe0\xddq\x9c\xfa,\xed\xe6\xcf\x15 \xba\xa9\xee<\xfe\xbb\xe1qݓ\x956?\xb6\U000db630M\xd2\xe5q\x82x\x02L\xbaK\x83W\x00\x90\x945z\x12p#\x80e\xbaq\f\x12\x9c7\xcf(a\x9a'\xad\x85A\x81\xa8\x9b\xc3\xf9kc/\xc4>\xe620\xb7\xcb)慠\x19\xd8S\x98\nH\x10\xb6\xc0\xaf\x8e\xe7l\xe3\xe95M!?\x02\x02X,Ix\xbc\xad\x93^\xb2\xa8\xf8s\xd0\xff\x8f\xb6\x85zw\x9e}%\x85{`\v!\x0e\xc9 \xb9\x86z?\f|\x13v\x0f`K\xb2\f\xbc\xaa$\xb1\xf0O\x19\xc8q\x0e\xd9\"\x13=\x02\xc8<)\x94\xf2\x92H\x16\x93%S\xe7\xa63z\x85\xa7\x864\"\xe9tL%DN\x042]\xc4}}z\xeaVֆ\xe9\x923\x0f\xae\v\xce\x10\xdd\xd9;8\x00\x1e\x8b\x1f}wp\xd0n\xe3\n\x05ۚ\x9a\xa4\xd8\xffoX\xe7\xc0\\\xa7j\xe3u\x881\x00\xffQ\xccj\xe5s\x9aNaL*!\x90/\xb4\xf3\xb1\xe8O\x16w\xd6\u05c9OImu\x86\xbec\a\xf8O/\x8d\x8b\xf3\x1d\r\xbf\xac\xd3N\xee\xd4\xf4\xeb\xf3\xaeأr\xae\xa5o\x9f\x8ed뎨#\xa0\x86I\xa7\xf7\x98s\xda8%_\x06\x9cC\xc6_?\xa7r\xccw\x9bt\x8dd_h\xc0g\x8bq\xd3Bg\xf7Y\xe9\xaciR\xaa\xbf\xf3%+\x8d!\x11\xadw\xed\xe56\xa8\x9a\xc0J@=\xfa\xa0\x1fk\xad\x12\x9a\xd5!\x8f\x87WA\x16p\xbc\x82\xfcx8\x83\xaa:5\x0f\\U\xbc\x9a`\xfc*\x97\x95\x11\ao%\x8da\x1c\x87\xbe\xe5q\x06\xe54\xcc\xfcL\x9fT0\x15Ü\x90\xe5\x0fI\xde\x015\x9eK\xa6\xbbʧ\xf7M|qN=${\xdf?7\x9fH+\x1a\x92>\xfc+/v\xb5A\ued88g\xf7\xe3\x15\x91K;\xee\x1a\x92//\x94\x8e\xc16\xc1\xa5\x95\x9f\xcb\x05\xf6˃\x10\xfc*cj\xc5O+\xa2\x98\xa9\x1d@?e\x90\x81\xea\xa7KCp\x1bC\xe2a\x9b65ދ\t,E\x94\xb6m\xfb\x95\xb9\xf4\xf9\xecO\xc1\xf3\xaa\x8b\x05s|A[t\xd8\xd64\xd1+\v\x9fe\xc3\xc36\x05\xbalPA<\xb8\r\x15\xcf\xea^\xc82N/ݺ\xabK\xd8\xc0\x1b\xe2\x8b\xf3\xa84n6\xa7\xd9\f\xbe\xcc\xe9\x94\xfe\r\xfb\"ޕ\xdd\xeb\x0f\xf6{\xfd\x1fz\xfd\xe7\xbd|'\xba\xe2\xde\x15\x16\xd1a7\x9f\xb4[Lڅ\xfa\xbd\xf7\xdd\xdbk\xd8\xe1Htl\xab\xef\x05l\xde~]#\xf1\xcd\xf1\xea\xd3M\nR\xa7\x0f\xb5\xca#\xfa\x83r\x8b,O\xf2X\xd1\xdf\xd4ڮ\x86\xafr\xb42\x16\xaf4\xcbꐪ\xe2Q\xe4U@\x83\x18\xa1\xd0\xea܂\x10@w\xafH\xc5\x14Cx\xd2h\xb2\xa3͒\v\x1e\xdd\x00x\x03\xf1S\x10(\x9f\xab\x01\x05\xe9\xc1\xf3Qy3ɲ\x10\xec\xe0\xe4\v\x90(\x82@\xd7\a)\xc5S\rcN\xaf\xbb\xca`\xf7\u007f(\xec$G2\xdfA3\xaf%\xe9\xb6,$\xf5\x9d|\xaf\x02\fd\x1a\x8b\xcc0\x0f⺏\xdd˧\xd1\xcd^(\xc2a\xad\x84,.|\xf5\xad\x85\xf1\xed\xf3\xed\xf3\xed\xf3\xed\xd3\xf0\xf9\xef\x00\x00\x00\xff\xff\x15g\xef\xd1\x00R\x00\x00")
+	assetsFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xec{}s\xdb6\xf2p\xfe֧@\x12\x9fIM$R\x8e\xe3\xdcU\xaa\xaeyiҺ\x93\xc6y\xead\xfa̸>\x0fDB\x12l\n`\x00\xd0r\x1a\xeb\xbb\xfffA\x90\x04ARrһ\xde\xdcLE\x8f-\x03\xfb\x86\xc5b\xb1\xd8\x053I\x84\f.\xe5\xbd\xff\xe0gt0\x1a=}\xfa\xe4\xdeh4:\xf8\xfb\xd1\b\xfe\xc2c\xfe\x1e\x1e\x1d\x1d<\xbdwp\xf4\xf8\xe9\xd1\xc1\xd1\xd3\xc3ÿ\xdf\x1b\x1d\x1c\x8c\x0e\x1e\xdfC\xa3\x82\xc0\x7f\xf2\x93I\x85Ž\xd1\x1f\xe6U\x1fԟ\"\xfa\xbf\xe3\xf1\xe7\x19\x8b\x14\xe5\xcc\xdf\x1b \x86W\xa4\x8f>\xf7\x10\xba\xc6\x02]H\xba`\x94\xa1)\xf2>\x7fF\x82\\\x13!\tzvJ\x17옡\xcdƛ\u0600<Sm\x90'\x99*@?\x7fFt\x8e\x9e\xbd\xc6\x11\x99q~\xf5<M\xd1f\xd3C\xc8\x10\x99Ϟ\xa7\xe9ql\x88\xd8`\xc1q\\\xd0(a\xdf\x11\xb1\x92h\x8alPh\xa3RR\xce\xe4\xed\xa5\xe4 \xa3\x85s)\v83\x02GڟN\xf3\xf6\x02\xaa\xc1\xf2\xe5\x123F\x92\x0f\"q\aZ\xa0\x18\x88\x02\xf3\xf3gD\x18\x88^\r\xfe\a\xce\x17\tq\x87~)\xf3\xf6\xed\x82\xe50m\xc4s\x11\x19\xe8c\xef\f&\xf1\xdc\xfav{\x8b\xf6\xfcϛ> !\xc4d\xf0\xfa\xc5ů\xc7o\xde\\\xbc9y\xfe=\xf0\xca]\xc0|6\\\xd3$\x19&\x1c\xc7f\xdc9,\x80\xbd\xaa\x03\x02\f\xb1\xa0~89\xf9\xe1ͫ6\xaa\v-s\x1be\x83\xe3R7\b.\x87\xd3\xe3\x1f\u07be\xfa\xfe\xe2\xf8m\x05\tFG\xe2!e\r\xa8\x93\x0f\xef\x1b`<SޤW\x00^\xc4d\x8e\xb3D\x81\xc6\xc0\xdc\xf3\ag\x8a\xcfgc4ǉ$\x03\x14\x86\xbae\x98\xf0\x05eHSC\x99\xa4l\x81\x8a\xf9.1/\xe5\x18)\x91\xe5H\x99$\xe8\xa7S\xa48\x920\xfds.V\xb2\x84Ly\x9a\xa5.tވ\xe6\\ \xc9#\x8a\x13\x04R\x0f)\xab\xf0V|F\x13R`W\x12nGG\x9c\x19L\x8bP\x8c\x93\x9c?\xe0\xc7T\xa6\t\xfe\x84pޑ\x13\xd1\xeb~\x81(\xd3X\x1bKo\xf9\xf4\\\xe4Ӄ\xa6\xb9\xaa\xaa~\xca(x\x81ҧ\xf0\x14\xfeȾ\xa5d:G>\x93\xc1\x05\x80R\x9c\xd0\xdfIlw\xc3#\x88\xca\x04\x9b\x94m\x9b\xf2\x1b \x1a\x9a`\xe1\x01\xb9Q\x84\xc5\xfe\xe7͠6\xab\x03T0\xae\x888<\xd1T\xab\xa0\xea\a\xb9ԧ\x94\xf0y\xe5\x8a\xeeO\xc1\x8cXL攑\xd8C\xfb\xfbe\x9f+\U000dac98\xaf\x83\xf9\xec\xb9\xfcĢcG\x0f.4<\xaf_\x04 \x8f\xdf\xec\x81\a\x83/\x1c\x97\xec\x06\xad@Q\xe9\x91\xc6u\a\xd5\x0e.\x15VYa{\xed\x049\xbf\xa2d\x1b\xc4\xcd|\xb62\xe6\xd3\xe8/|\x8c\xfdy\xfd\"xuM\x98\nd6\x93\x91\xa03\xe2{8S\xcb\x00~\xfdBdʙ$\xe07\x17\xc4\x1bT\n\x13\xa6\xa7Mq\xb6\x15\x99y\x06j|>\xeb\x82.M\xa7t\xee\xb9G\xad\xd84\x05\x87g\x83H\"\xc9\x0e\xa2ђDW\x05]\u0602d\xb9\xa7\"\x7f\x9bD\x05\x81\xf9좐\x03MQ\xf1\xb5]\xa2.5ח\xc96H&\x03%\xe8bA\x84o\xfbw\arS\xff\xb7\x81T\xbaz\a\x0f\xc6\x03Ӝ*\xdf\vÈ3F\"\x15̍v\x02FTH\xd8Ň\xd3\x10'Ip)\xbd~\xdb*\xb7\x97\xa2\xb35:+\xb2c\x15^\\ho}b\xb6L\xc0%\xb1\xdeXk\x06\xd669a\x88\xde/\xa9,-\x11Q\x89\"\x9c$$FX\"\xc99\x83\xbfjI\xd0,S\x8a3De\xcf!\x01nU\x10\x16\x13Ab\x88x\x00\x18\xe4\x01R8\x11\x04ǟ\xb4\x8b\x85^\x16\xa0\x9f2\xa9\x10]0.H\x1b%\xaaj4\x96X2O\xa1(\xa1\xd1\x15\x89mA|\x02\xeb,\xc3I\xf2\t\xad[I\xad\xe8b\xa9\x10\x8ec\x84\x19\x82U\xc8sw\xee\x19w\xd9\x0f\x1aX\xd6TT\xee\x1f\xf4I\xd9K#\xc2\xd4q\x92\xb7\xb7\xe8~\al\x9b\xc2\xdb\x1c~\xd3$\x8a\x0f\xc8\x03+$\x88xlB\xd5\xe6\xb3\x17\xa4\\*߱\x9d\x01\xf2\x00i\xea\xa1G\xa8$a\xb9\x9c\x18+<@\x8aܨS\xed'\a\xe8\xf2\xe3\xff\xff\xf1\x97.&\x85\xb5sV\x98\x97\xa6ଇm+q\xb3mŕ\x96\x9c\x9brn\xc8'\xec\r\xc7\U0006e365m\x9f\xae\xefu\xed˺\x16\x919\xf2nz;\xf1\xee\xe2\x12\x96J\xa5r\x1c\x86i\x92\x15\xa1^\x10\xf1Ux)\xc3(\xa1\x84\xa91\xf4pF\x82K\xf9\x1dg\x10fL\xdbT\xd0\xee7\x80\x13V\nGK\xbd\xe5H\xdf@m\xca\xf0%\x97\xc3V\xa0\x14\x91\xadC\x88\xa3!(\x8cy\x94\xad`ۊ\x04\xc1\x8a\xbcJ\b\xfc\xe7{9\x01\x9b\xbd\f`}@\xbc\t\xa6\x13^\xe2kl\x80l\x18\fQAc\x1ed E\x84\xa6H\x8a\xa8j\x04\x11R[\x84\x05Q\x86\xbf|\xf1\xe9=^\xbc\xc5+RIr6:\xafp\xd3 ł0\xf5\x96\xc7$\xa0L\x12\xa1^\x909\x17ė\x03\x946ԑI\xf2NG\x94]&\x95/L;\xec\nL\xb0\xb9\xbfo\xc28\xf9=\x91W\x8a\xa7~\x1f\xdd\xde\xd6 퐵\xc1ڞ'\x9b{\xcck\xf3\x11s\xd0\x0f\xfc\xd6ǘR'3\x1e\x7f\xea\xf7\xdch\xa0\x1c\x8f߷\x898\x8e\xeck\xc2;#K0\xa7,\xf6\xbd \x8f҇&\xccF\xb8\xdc\xe1\xbc~\xa0]\xb3\x15\x02t\xba)\x12\xa4p\xf8d\xea\xfb<l-\xcc\xd5\xfd0\x19\xcc\xebaK\x7f\xb2s\xab\xdf\xf4zw\x95\xddl\x02\xfd\x80\xe0h\xb9#v\x01\xe3\xc4`\xc7K*\x9bB@/e\x8a\x88k\fgdIԱ\xf9o\aY{\x12k\xae\xab\v\xb8\xe0fl\r\x8eq\x8e\x03\xb5\x9f=\x1f\aX)Ag\x99\"\xd2\x19\xa8OYLn\x06\b\x00\xb6\xb1+d\x04\xb8\x00\x0e\xd9:\xa4U\xc2\x1f\r\xd0Q\x1f\x81-\xc1\x060\xf4v\x11\x81\xc7\xc8}\xd6 vԇ\xb3\xbbn\xbe\xc6I\x9b\xd3\xeev\xcb\xdb\xec\xc1\xfe\xec\xf9\xf8\v\xcc\xf4K\xcd\xf5\x0e\xdc-/\xd5\x14d\x97\x1c\x1d\xb1E\xe7&w\x17\x99\x168\xa5\x01\xac\bʂ<t\xab\t\xd9v\x9et?QB\xb0(ͽX\x05\x1d\b-\xb6\xba\x19\xa0\xa3\x91\x03\x0e\"\xdfu\x19\xdf}\xfd\xee)\bp\xa7hχ/-\x12\x82\x95k\xa0\x00\f\xda\xf7\xb4\v\xf7\x1a.\xb5xr\xd0/\xb2'X\xba\x99Σ\xe5\xc8`ﾷ\x14dno\xad\xee\a\xb0`\xe1\x95h\xb9xд\vmMc\xb5t\xf0\xf4\xb0\x86\xbag\x17\xfa\x92\xe8й\r?\xef\xdaF\x80ɀ\xa7\x84=\xcfԒ0E#\f\n\xfaU\xc7w>(\xe1\x11\xf2\xbe\xcbý遗'a\aH\x8b50\x8c\xb7\x10\xbf\xeb\xaal\xb3\xfeM\xaf\vb\xd3s\xbd\xb2Y\x00\xc1e-\x99S\xaaW\xe04%\x02M-\v}\b\xebi\x98\xa5CH\x80\r\r\x84\xab' o\xba\x82\x84\xb0\x85Z\xb6\x99\r\xb0\x90En\xd4\xe5@\x99\xe6\xd06\x03\x15(\xecrC#З\xecрr̂9\x8e\xc9I\xa6j\x9a\x90)!\xb1u~\xe8\"\x01O1D\xa0s̚d\xbe`\xd2\xee:\xe9\x9b;ꃲ/ч=\x90?\xa2\x10K\xaf\xff%}lz\xed\x9a\x01[B\x81>t\re6[QU\xeaǤ\x90\xb8X\x9d\xea\x8e\xfe\xa4\x83\x06>\x03W6}\x00GͲ8\xf2\by\x0f\xce\xef\xaa뻌\xa88\xe6\x1a\x06\x03Ĳ$\xf9\xfa\x03-,2r\r>2\xcf\xd8\xf9\xb5\x84z\v\xff\xe61\x15M\xb5\f\xed\xa0֡\x91\\w\x90\x03wp\x9f\\\aT\x9aq\xbf\xcb\xe7\x95\xc4͈\xbeE\x8c\x84\xe7\xce5\x10\x04\x8e\x8emJkN}\xabqX\xffw\xfbB\x9d1w\xc5\xdab\x05\x94\xfd\xfb\x8d\x80\x15\x15\x8e\xe6\xb1\xc0\x1e\xd4\xc69~\xb9\x99P\xfb\bVd m\x91\x8a\xbc\v\xb4\ay\x1eYG\xbc&\xc3gNO\x90\xf7\xa1\xb2̇4\xa6\fL\f\xf6N8-\v\xf2\xb1L\xc5h\xaavB،\xe9\x17\xf21#RMzmf\xef\xd6\xf3\x06\x9a\xf4\xd7\xd9\xff\xeeD\xce6e63\xc0\xb6:e\x16ED6j E\r\xd3\x15\xe7\xf5\x8b\x00\xa7\xd4\xf7\xc2\x15\tӪ\xa0\xe9&\xc7]\xb4B\xbd\xa9a\x0f0:Ω\xe5\a\x8a\a\xaa<>\x80S\x8a\xa6h4A\x94\xa2o˲\xaaى\xa1\xf5ѣ6F\xc5\x18\xeekng\x05\xde\x19\xa5\xe7\xe7]\xf0U:\xe1\xaek\xb2\xd1b4\xb9\xd5\xce\xdb2\xf6a\x88\xder$\xc8ǌBJ\xd6\xd2jo\ayg\xa6\xe7\x9dk\xc6\x1e\xb59\x97\xba\x87\xd2m\x93\x0e\xe0g\x9e\x8cxJ<8\xff\x15p\xc1%\xa7\xb5u\xddtFv\xf5`\x7f\xdf-(\xb4\xafU\x97}\x9bGp\x89\xf7'\r\x14\xab\x17MQ\x99H\xd91\x1b\xaf_\x04\xba\xa2\xea\xb7q\xd5'-\xd9=\x03\xd5\xc1\xaf[\xff]\xa5vwn\xcaF\xf8Ѻ\x1fC\x99\xde \x9eBCy\x8f \x9f\b\x0fy\xfdzY,\xcfZ\xd2x\x8c\xbc\n\xb5\xba\xb2P\a\x16$\xa6\x82D*\x13t\x8c<\xf0b+\"%^\x10\a\x0ek3\x87l\xd5\x18y|>O(sA\xf2B]\xca\x13\x1a}\x1a#\x0f\xaa\xd2\t\xb9Xr\xa9.\xb8\xa0\v\xca\\x\x9c$3\x1c]\x8d\x91\xd7Q\x1cq\x10\xe8jEb\x8a\x151\xc5f(Ep\xb5$bM%\x81\xba\xf8\x12AQ\x1f\xcd1͋\xc6\x19\x83F.\xa0\xa0\x9b\x17\xcb{-\xa7ݯ\xca\x10\xdd93\x14\x86\xe8\x94\x10\x14\xf1\x15$\t\x11e\xa8c\xb0\xbd?\x9aT\xd0\t\x03\x18\xb0ީ\x8e\x99\xef\x98me\xb4w\xce\x0f\xb8\t\x80\xfa\x05\x8fMo\xc7Q\xd2^\r\x99H\xdaϑ\x96\xce`&\xe6hZ\xda\xc3\x05lƀ\x13(\xfe\x86\xaf\x89x\x89%\xf1\xfb\x10\xb1\xe8\xe0\x85\xc4\x17\xe5U\v\xa3\xeb\x0f\x92\xe8D \\u\xc0ל\xc6H\x909\x11\x84EpI\x02JS@O\xa68\xaa\xcaQa\x88悯t\xe1\n\U00107598\xc5\t\x11%@\x1eʝ\xcd\xcfk\x03\x92\xa4\x91\x9bs\xf6l\x18\x84\xa5=\xf8\x89IB\x141\xc1\xe1\xd9\xfc|\xd2e\x91k}\xcb\xc9(\xb7:\x99w\x9f\xc2ה\x05e.ZQ\x95\x10\xd0\xe4\xcb<\x18҃\xe7\xa8\xd0'h0\b\x02o\xe2f\xd5הM\x9as\xdb1\x9b\x9d\xf3Xd6~\xc6j\x19\xac(\xf3\xf3\x86\xdb[\xf4t4\x1a \x19\tBX\x907\x0e\xd1\xc1\xc86\xb12\xadQ\"\x9b\x96\xdb[ttPa\x9bV\x17\x1dT\x97\x909\xdct\xf0m>\xe1\xe3\xfe\xd0/\xbe\xd5\xc1\x15O-蜮\x06/\xbf\xb6\xa9\tnV\x80n\xf2I\xf1\xbc\x01\xf2rx\x1dA\x1a\xe9\x1e!o\xa0\x99\xeaF\xfd\rT?X\x11\x96Ͱ\x98\x8e\x06\x82H\xfa;\x9e%d:\x1a(Γ\xbcU\xf1Tc\x80h\x00\x0f#\xd2\r\xf0\xa5\xef\xccP\x11k\xdb\xf3S\xf8V{V\x8c\xcb\xda\x16\f\x17h\xb0\x0f\x82\xf1\xfa\xfdA\xeej\xfb;6P0\xfc\x02\x19MK:\x13\x17\b.6*\xe0O\x9d\x9a\x81\xe3Qjp\xf6\xc0l\x89\xf3M\x15\xb3\x18\xf9\x8c+\xf4\xecy\x92\xf0\xf5\aI\x0408f}䓏\xc8O\bC\xcfNu\x81\xe1\xfd\xa7\x94\xc8>:\xe8\xdbۯ\xa1\xb4'\x15\x1aO\x91N\xbc\xd7\xe0Ѩ\x05\x9a\xce\x11\xf9\x88\xf6\xa4\n\xa0\xf2\x85\x1e\x14\x11\xc3\x03\x17\xb65J\xb3\an\b\xea\xa0ĥ\x9ao\xdd]4\xed\xb8\xa3\x8bb\vf\x95\xe7\xb4o\xf4\x01\xd3\x1fs\xb7\xa7y\x9b\x1b}\xf5g\x8b\xabo\xcb\x1a\x02\xfdr0\x9b\x8d70z\x0et\x81\xefW\xbd\x1a6\x9bz\xeb\x8f\xf9\xc2\xd9lZ\x06T\xed<]E\xfa&\x14\x8cV\x9f\x89!\x89`\xa5\xe7t\x9b\x9d\x9f\x83\xd5q_\xb7v$\xfd\xf6\x82\x051\x99\r\xca\x06\xc8Ӱ:;Z\x18'\xfa\x92\xe3\x1d\bFr\xa9\xf6 \t\xb1\xd2\xc8\xfd~\xab\xd6\xeb%dҖ\xc2'I\x10I\xe9{\xe6\xf2\x1c\xf8#\xc6Yk\x12\x9a$\x01d\xccX\xfc\x9e;\xd5\xcb&l\x8b\xce\x02\xfd\xd7\xf7䒯]\xf2\x1bp\x83j\x95\xb8\xed\xeeDU\x13\xa4\x89\xb5\x91,}A\xe5\xb0,GP\x8fȌ[\xbe\x7f\xbf\xf4[\x0e\r\x93\tjǶ\xeb\xb0&i\xb4\xe3N\x8f\xe1g\xa0ۆe \xaa\xd4S)\n\x11\x82\x8b\x97\x9c)L\xd9\x16\xa1\f\x81=\xdf\xfbV\xa6\x98\xa1(\xc1RN\x1f,I\x92\x0eg\t\x8f\xae\x90&44\xe1z\xfe߃\x7f~\x1b\x02\xf4?\x1bJ\xbc\xa0\xf2g]\xfb\xde\xc5\xf0\xfe\xfd\xf09\x8b\x05\xa7\xf1\xed\x9a\xccNNo\xe9\xbb%g䖾\xc3\xf1-}\xc7\xe3\xdb\x17\t\x8e\xae^\x10!>\xdd\x1e\xbfʉޞ\xa4D`\xf43e4\xa4\x81\"R\xf9\f_\xd3\x05V\\\xe8\xbc\xe5\xf3\x05a\x8d\x8d\xab*\xd4\xef\x14\xca\x1eAcr\xad\x90k[\x80\x06\xd3\fmh\x7f_\x1f\x06\x82\xe2\xecc\x03\x99x\xf0\xfd\x92\xe8\xfbP\x10\xaf\xaf2\xa9\xd0L\x87\xf0)\x04opE\na\xa6O\x1e(ŋ\xfa\xbd\xa60D>\t\x16\x81>\x80\x10\x88\xad\x8b\xc0S\xad\xf9p\x8e#\xc5\x05\xc25\x0f\xea\xe2\xc3% \x04PsE\xaa\x9b\xafY\x8a\xd6T-\xe1\x9eTƮ\x89\xa0sJbDV\x98&\xce%)7\xf3\b)?4\xad\x8f\xb9\xb2\xd9J\xcdmv\xfcE\xfes\x9b\xfb\xcc\xfb8\xf3\xbd%\x8dc\u0082\x99IV\xd6\x1c\xa8\x8bUa\n\xb2\xe2\xd7\xe5ܷ\xe5Y\x1a\xdedI\xe3\x9a\xff\xdb\xf4\x1c\r\x15\xab=\xd7ͤw\x87\xb4\xf3\xf1[\x8b`-}<@g@\xe5\xbc?q#\xae22\xeao\x89\x9a\xf4yb\xa0\x8fv\xfdI'\xd4\x17\xe44`\x10\xfaX3\xd5\x14>J߃\x7fm\x85\x18{{\x7f\xf2\xfd\xc9\x18\xc9hIVd(H\x82\x15\xbd&(\x13I\xfd\x1a!\xac\x1f\xa0\xa0\xef\xd9\xc0\x97\xb3ѹN\xb8\x86^q\xcdf\twܠK\xdf=([\xda\f\xb2\xdf\b>\xb7\x98.\x90\xac\x8bm\x86\xfc\xa5\xc9\xf90D\xc7ss+}E\x90 \x18\xde\xc9X\x13O\x10\x84\x15RղoC\x85Ů\x17!\xbcX\x82\xf3Cc\x8a\x05^\x11E\xc4\x00\xe5\x19~\xca\x16m\xb8T\xe5ɉ\b\xc3\xf5x\\&^\xe0\xa0\x1a\x06;\x15\xd1^=\xd8t\xc5\xcf\x17\x1f\xed|/\xf2k\a\x81\xe2\u008c\xcbC\x12,\xa2eq\xeb\xe3\xa0\x1f\xc84\xa1\xca\xf7\xf6m\x9biO\xd3\xe2z~\xb6\xae\xf8\xe2\xf6\x18\x86dlAt\xea\x1a\"LejȠ\xfbS\xf4\xb8m\xfe\"\xce\x14e\x19\xe9RCI\xc8\xd8fm\xdcΦ\x12\x13p\xb4\x1f~9~\xc9W)gp\x97.=;8\x0f\x04I\x13\x1c\x11?\xfc\xedQ\xb8\x18\xa0\a\xe8A\xbf\xdf\xc5o\xd3s\x88\xb6l\xf8:\x05VۘDb\x8b\x05\xeaY\xc1\xc2\x16I\xb0\xc2*Z\xfa\xe1\xbf\xf4\xa5\xc4\xefƿ\x85\xbf\x85g\xff\n\xcf\x1f\x85\xfd\x89\xcbi\x85\xbeC+\x18\xe6\xd8\x14\xb8\x1c\xaevU\xb0f\v\xb5\"\n0\xc7y\xc7\x14\xd6\xc5\xca\\$\xb8\x94üٞ&\xd0\xec\xfd\xbc٦Q\t5iQ̶\x8a\x11p7W\x85[\xee~@/\x88\x84\xa6\x06\xc8\\\x7f\x01O\xe6T\xd7\x01̔\xb8\x02\xf8>\\\b\xae\xab\xea\xf9\xb6\xf1\x12\x82'\xdf[b9\xd4qR\x17j-\xa4\xf2\xfaeI\xb9R\x9e;n#t\xcb\xf6doM\x85\xf8\x82\xa7:B\x87\x03\x7f\xec5\xbc\xbdU\x8b\xd2RI\"\xf2WCl\xba{\x01\xbe\xc47\xce+\x1a&1\xfb\xee\xe4\xf4\xbd\x934\xcdD263\\\xef\x00F\xe3\xbc>\xd5Vr\x18\x7fU\xd1\n,\x04\xc0sM6j\n\r'r\x05\xb1\xd4\x1d\x10\n\xedP\x96\xeaw\xfa\xacI\xd3M\xfa\xbd2\x9d!\xb9\x82S\xe8\xb9=\xc1\xee\a\xe8\xc4\x14\xaa\xca\x1a\xb52\xaa\xba\xe5̩\x90\xca\xdfB'\xa6\xd7\x01\x8e\xe3m\xb6\xe5~\x80ud\x85\xfe\x8d\xe3\xc06~%b\x00Sa\xab\xf9\xecʎ:\xba\xf1\xeez@lbV\xe7Ez}'\x04s\x8b\xa2\x03v\xd3s\x1a\xb6\xae\x127\x03\xe5\xc4\x00\xed\x16\xb3\xbb|\xda\x14dS_\tZ\xb9\xd6:\xd0V__\b\x1a\xe4\xfdR\xf0u\xc3#\xc2\x0fN\x88P\xbe\xf7\n\xa0\xc6:\xfbZ!\xb7H\xf3%\n\xd84\x1cͦ\xb7\xe9\xfb\x97\xff/#\xe2\xd3\xc0\xbci\b\x93[\xbcU\xfb\xbf\xf3Ѣ\a\t\x91\xf2\xbf\xf5\xfe\xf7\xe8\xe9\xe1\xe1\x13\xf7\xfd\xef\xc3'\xa3\xbf\xde\xff\xfe3\xde\xff~6\x9fE<\x81\x15\xf3\xf0pv\xf4\xcd7\xff\x98\xf4\x9e-\x8a\x968~2;\xfcf\xd2{\xa6\xd6%\xd0a\x14\xcd\xe7\x00\xb4,\x9b\xf0!>ē\u07b3|I]\b\x1cSx\xcd\xf0Iz3\xe9\xf5\x02\xc1\xa12\x1c\x0f#.\x18\xbcB\xeb?+\x00\x8eқ|!\x0f\xd7dvE\xd5p\xc6ELİ\xe87\x80\xb0\xe2\x86+\xfe\xfb\x96\ueb9e\x8d\xc5\x1f\xd2\xf9ۄhc2T<\x05<-+\xc8ѐU\xf1t\b\x10%\xef\x12p'@\v\xbb\x19W\x8a\xaf\xb6s\xcca\xb63\xed\x84\x01\x85\x98\x9b\xc3\xc5kc\xcf\xf4<\x16:p\xa7˯Ϫq\x7fp\x88\x86\xbd\x9b\xc5c\x94\xe37\xdb\v\xb1\xa1z\x8d\xc5p\x01\x04 \xee\xcf\xe1ᶎ\xb8\"\xac\xfc\xf7`\xf4\xb7~\xbf\x85\xcaJ\xfeA\n_\x81\xad\x958F\a\xe9\r\x92<\xa1\xb1\v\xfbx\xf4\xb7~M\x974jj2\fQ*\xc8\x102Y\x99\x82B)As\xcap\x82\f\x11\xc9њ\x98\xba\xe9\x12_C\xda\t3$\x163\x9cC\x14D(Ӹ/OO\x83\xc6\xd8`\xe7\xf7W\xf4\xa6\x94\f\xd0\xfd\xc7GG\x03T\xfd\x1a\x05\aG\xfd>\xe8g\xf4\xb7\xda\b\x87\x02\xf2\xff[\xc6y\xe0\x8e\xd3d\xa4\x06\xc8i\x18R\x06j6#_a\xb1\xa0\xcc\x18\xe1\x18\x1d\x94ֹ\x1f\xc0vS \x19hK\x9e\x12\xa3\x14\xe8!9\x82\xc7>\xe5\x95\xf5\x1d\v\xbf\x10'\xb7U\xbfpj\xf6\xf5\xf9@\xcfQ\x85b\xa3Ѩ\x8e\xd4v\xd07%\xa0-L\x17_\xc1\xb3\xc4ie\xa9\xd6T)\"\xb6\xf0T\xeb\xaf`Z!\xb5r]P\xb5\xccf[\x98.\x96_\xc1\xb4Bjc\x8a-\\\x134\x8f\x91N\xbdW\xf0)\x8ec\xca\x16ct0Jo,\xab\x82\x9f\xfd\x01\xda\x1f_SI\x15\xdc\xc9\xde\x1f/\xf9uMo\xf0S\xecWsؿ\x8aFx朩\xe1Z\x17\xc3\xc6hƓ\xb8\xa5[\xd2\xdf\xc9\x18\x1d<\xb5\x99\u0093rI\x15\xe5l\x8c\x8ad\x9eӟ\v=4>}\xe4⯡27F\x8f\xff\xfe\x8dۓ\xaf\xa21\x1a\xa1\x913\xd8\xcd\x0e\xbd\xb7\xedx\xed~\xbc\xa1\xf2|\x1d\x0f\x1d\xcd\xd7\a\x8ag\x92'\x99r\xf2B\xc0l\x8cF\xf5F\xc5\xd3F\x9b\x19\xf1\x93\x86*\x96f\x06\xc0O\xd5{ \x90\x1f\xe2\x84.\xd8\x18E:\xcfo\x99\x1e\xfcPG\x05δ\x1d6x\xd9\xfc\xda{ð\xb8\x1e\xa1k\x12:YTф\xa3\xb4\xf6\xa1mxp\xe2\x06\x97}M\x04\xbc\xee\x91\x18\x99ͽ\x905\x17WA\xd7\xd5%\xc8E\x8d\xe1\xc5y0\x9a@\xae\xb0\\R\xb6X\xe1\x05\xfe\x9d\xb2\xfc]\xd9ǣ\x83\xc3p\xf4\x8fp\xf4MX\xcc\xc4P\u07fb\x82#k2,\x98\x0eK\xa6\xc3H\xca\xf0᫛4\xc1\xacY\x03\xb9\xe3\xf4\xdb\x16\x893ś\xbd\xbb\f\xa4\xcb\x1e:\x8d\a~\xcc\x0e\xd5\xd2S\xec\x15\xa3Σ\x9aq/\xcd\xed\xab\xbe[9\x83\x9f\xf4\xba\x1dRS=\x86\xbc\xd9Ђ\x057h]nA+`\xf8\xb8\fŌ@Pitű\xb8\x14\x8a\a7\x80F\x10\x8b\x82\x127\xbd\xde\xc3bD\xba\n\x83>粗d\xf3\x9bI-\x03\x81dD1\x00\xfd'/\x0f\rc\x8a\x13\xbe\xb00V\xf8fh\x16\xec\xe1?\xcauR \xd5\xd5ZәA\xaaֲ\xd6\xd4C\xd8\xff%\xbc\xaf\xb3\xe0\n\xe2\xba+\x83b-փ\xc7\xc5b\xb5\x97\xbd6\x84I\xa7\x86Z\\\xf8\xe6\x7f\xf04\xfe\xd7\xf3\xd7\xf3\xd7\xf3\xd7\xf3\xe7=\xff7\x00&\xd6\xc9t\x00R\x00\x00")
 	const prefix = "/assets/"
 	manager = assets.New(assetsFS, prefix)
 	App.SetAssetsManager(manager)
 	App.Handle("^"+prefix, app.HandlerFromHTTPFunc(manager.Handler()))
 	App.AddTemplateVars(map[string]interface{}{
-		"SignInFacebook":      SignInFacebookHandlerName,
+		"Activity":            ActivityHandlerName,
+		"AllowUserSignIn":     func() bool { return AllowUserSignIn },
 		"User":                Current,
-		"SocialTypes":         enabledSocialTypes,
+		"FacebookApp":         func() interface{} { return FacebookApp },
+		"FacebookChannel":     FacebookChannelHandlerName,
+		"FacebookPermissions": func() []string { return FacebookPermissions },
+		"Forgot":              ForgotHandlerName,
+		"GithubApp":           func() interface{} { return GithubApp },
+		"GoogleApp":           func() interface{} { return GoogleApp },
+		"GoogleScopes":        func() []string { return GoogleScopes },
+		"JSSignInFacebook":    JSSignInFacebookHandlerName,
 		"JSSignInGoogle":      JSSignInGoogleHandlerName,
-		"SignIn":              func() string { return SignInHandlerName },
-		"TwitterApp":          func() interface{} { return TwitterApp },
 		"JSSignIn":            JSSignInHandlerName,
-		"JSSignInFacebook":    JSSignInFacebookHandlerName,
+		"JSSignUp":            JSSignUpHandlerName,
 		"Reset":               ResetHandlerName,
-		"SignInGoogle":        SignInGoogleHandlerName,
+		"SignInFacebook":      SignInFacebookHandlerName,
 		"SignInGithub":        SignInGithubHandlerName,
-		"FacebookPermissions": func() []string { return FacebookPermissions },
-		"GoogleScopes":        func() []string { return GoogleScopes },
-		"AllowUserSignIn":     func() bool { return AllowUserSignIn },
+		"SignInGoogle":        SignInGoogleHandlerName,
+		"SignIn":              func() string { return SignInHandlerName },
+		"SignInTwitter":       SignInTwitterHandlerName,
 		"SignOut":             SignOutHandlerName,
-		"FacebookChannel":     FacebookChannelHandlerName,
-		"FacebookApp":         func() interface{} { return FacebookApp },
 		"SignUp":              SignUpHandlerName,
-		"GithubApp":           func() interface{} { return GithubApp },
-		"JSSignUp":            JSSignUpHandlerName,
-		"Forgot":              ForgotHandlerName,
-		"SignInTwitter":       SignInTwitterHandlerName,
 		"SiteName":            func() string { return SiteName },
-		"GoogleApp":           func() interface{} { return GoogleApp },
+		"TwitterApp":          func() interface{} { return TwitterApp },
+		"TwoFactor":           TwoFactorHandlerName,
+		"TwoFactorSetup":      TwoFactorSetupHandlerName,
+		"VerifyEmail":         VerifyEmailHandlerName,
+		"SocialTypes":         enabledSocialTypes,
 	})
-	App.HandleOptions("^/sign-in/$", SignInHandler.Handler, SignInHandler.Options)
-	App.HandleOptions("^/sign-in/facebook/$", SignInFacebookHandler.Handler, SignInFacebookHandler.Options)
+	App.HandleOptions("^/activity/$", ActivityHandler.Handler, ActivityHandler.Options)
+	App.HandleOptions("^/fb-channel/$", FacebookChannelHandler.Handler, FacebookChannelHandler.Options)
+	App.HandleOptions("^/forgot/$", ForgotHandler.Handler, ForgotHandler.Options)
+	App.HandleOptions("^/js/sign-in/facebook/$", JSSignInFacebookHandler.Handler, JSSignInFacebookHandler.Options)
 	App.HandleOptions("^/js/sign-in/google/$", JSSignInGoogleHandler.Handler, JSSignInGoogleHandler.Options)
 	App.HandleOptions("^/js/sign-in/$", JSSignInHandler.Handler, JSSignInHandler.Options)
-	App.HandleOptions("^/js/sign-in/facebook/$", JSSignInFacebookHandler.Handler, JSSignInFacebookHandler.Options)
+	App.HandleOptions("^/js/sign-up/$", JSSignUpHandler.Handler, JSSignUpHandler.Options)
+	App.HandleOptions("^/reset/$", ResetHandler.Handler, ResetHandler.Options)
+	App.HandleOptions("^/sign-in/facebook/$", SignInFacebookHandler.Handler, SignInFacebookHandler.Options)
+	App.HandleOptions("^/sign-in/github/$", SignInGithubHandler.Handler, SignInGithubHandler.Options)
 	App.HandleOptions("^/sign-in/google/$", SignInGoogleHandler.Handler, SignInGoogleHandler.Options)
+	App.HandleOptions("^/sign-in/$", SignInHandler.Handler, SignInHandler.Options)
 	App.HandleOptions("^/sign-in/twitter/$", SignInTwitterHandler.Handler, SignInTwitterHandler.Options)
-	App.HandleOptions("^/sign-in/github/$", SignInGithubHandler.Handler, SignInGithubHandler.Options)
+	App.HandleOptions("^/sign-out/$", SignOutHandler.Handler, SignOutHandler.Options)
 	App.HandleOptions("^/sign-up/$", SignUpHandler.Handler, SignUpHandler.Options)
+	App.HandleOptions("^/two-factor/$", TwoFactorHandler.Handler, TwoFactorHandler.Options)
+	App.HandleOptions("^/two-factor/setup/$", TwoFactorSetupHandler.Handler, TwoFactorSetupHandler.Options)
 	App.HandleOptions("^/image/(\\w+)\\.(\\w{3})$", UserImageHandler.Handler, UserImageHandler.Options)
-	App.HandleOptions("^/js/sign-up/$", JSSignUpHandler.Handler, JSSignUpHandler.Options)
-	App.HandleOptions("^/fb-channel/$", FacebookChannelHandler.Handler, FacebookChannelHandler.Options)
-	App.HandleOptions("^/sign-out/$", SignOutHandler.Handler, SignOutHandler.Options)
-	App.HandleOptions("^/forgot/$", ForgotHandler.Handler, ForgotHandler.Options)
-	App.HandleOptions("^/reset/$", ResetHandler.Handler, ResetHandler.Options)
+	App.HandleOptions("^/verify-email/$", VerifyEmailHandler.Handler, VerifyEmailHandler.Options)
 	template.AddFuncs(template.FuncMap{
 		"__users_get_social": getSocial,
 		"user_image":         Image,
 	})
-	templatesFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\tn\x88\x02\xff\xec[ks\xdb6\xd6\xf6g\xfd\n\x84\x93\xf7\x8d\xdd1iݵ\xe3\xc8J\xb2i\xbaqgg\x9b\xb1\x9dv:;;\x1eH\x84$\xd8\x14\xc1\x12\x90\x15M\xeb\xff\xbe\xe7\x00 ś\xac\\j;ْm,\n\x00\xc1\x03\xe2\\\x9e\xe7\x1cj*\xe2\x99P\xde\\-\x82\xbd\xfb:\x9a\xadf\xb3\xdf\xef\xee5\x9b\xcd֠\xd7\xcc~\xe2\xd1\xea\x0f\xfa{\xadv\xa7\xdd\xeb\xb5:\x83V\x1fƷz\xcd\xfe\x1ei\xee=\xc0\xb1\x94\x8a\xc6 \xca\x17/\xd2\x1c\xe9\xe77r\xfc\xfe;\xf1ٔ\x87\x8c8\x17\\\x05\xcc!\xb7\xb7Ц\x88\xf3\x83\xd6\f\xb2\x16˘DTʕ\x88\xfd\x17\xb6\x9b\x85>\x9c4\x86>\xbf!\x93\x00:O\x9cX\xac\x9cQ\x83\x90l\xdbD\x04\xee\xc2w\xfbĞ\x88\xe9T2\xe5v\xf4w\xb9p\xff\x96\x9c؎6\x91|\x16\xba\xcb\xc8\x05\xb5\\\xe8\xe9\xec\x84\xdc?q\x8c\xaaf\xbb\b\x01Y\xf8\x94x\xe7,T(\x10!\xb9\xe6S\xf9fAy\x90\xed\x81\xf9\xa2\x11tG1\x0fՔ\xec\xc3B\u007fa\xcfn\x18\x918\x05\r\t\xd3W(A\xfe\xef\xc6#\xef\x02F%;$S\x11\x04bEԜ\x11\x1eJ\x15/'\x8a\x8bP\xc2\x17\xc2\x15\x0e\x8e\x19ȟ\u007fV\x9es@\xbc\xf7\x92\xc5^\"\xc4\xf0(\x1aeEd\x81d\x9f'\x1b\nB}\x1f\xee*\xe1\xd63.\x15\x8b\x99\xff\xe7J\x8d\u007fB\xba`U\x82\x9b\xedߺ\x90\xe1\xbc;ڡD\xc3#\x18\xd3ȯ\x1b\xc6\xff*\x96ς\x00\x04\x9b0\x0e\vO\u05fc\xe2jN(\txx\x8d\x82\xa3ؔ\x84l\x95N\xea\x14\xc5\x1c\xa2\x9e\x90\x05Ss\x01\xba\x13\t\xa9\x1cB\xf5\x038q\xe0^1\xbba1H\xfd\xd2Jx{\xeb\x8c2\x1b\x01#<\xd3\x03\u007f\x17\xde\x19\xac\x98Ņ\xbd\x1a/\x95\x12a\xa2\xebKx\\ҕ\xcb\xf1\x02\x9e\xedX\x85\xf8υ\x9d\\\xd0x\xed\xd8՝\xeb^#\xaa\xb9:#\xef\x11\n\xbc\xed1\x0f\x8f\xc0\n\xb4y\x99\x13\xfb\xf1\xe5\xf6\xcf'\xa0\x11\xf7\xeb\xfew\xf9\u007f\xec6\xfe\xbf\xdf\xef\xb7:=\xf4\xff\x9df\xbb\xf6\xff\x0f\xec\xff\xcfń\xd3\xe0\x14\x14Bk(O4{Jɔ\xbah\x11\xdaJ\x86G|\xb4Qν\xfa\xf8\xa6\x8f+\xe9Nh\x10\x8c\xe9\xe4\xfa\u07bc\xc0.\xfb\xef\xb6;h\xff\xdd6 \xc0n\xab\r\xe3\u06ddN\xb7\xb6\xff\x87\xb1\xff\xa3\xef \xac\xb0\x0f\n\fZ\x1e\x93P\x84\xac\xf1\xdd\x11\x82\xbb'\xdf\xff\xf4\xfa\xe2\xd7wo\b\xea\x05\x84\x1c\xf3\x81\x81O\xf8k}\"'1\x8f\x00I\xac#v\xe2(\x98\xe3\xe8\x8a\xdeP\xd3jéEb\xef\xe8:\x104\x83\x1aV<\xf4\xc5\xca\x13\x11\vY\xfcot.\xaf\xad\x1a\u0098\xff\xecc\xc3暃\xe7\x8drH\xf4Y\xc0\x14\xdb1\x91\xb9Ў\x99\x04B\xb2}=\xd9\xf0\xc8HiB\xaaY\x10@\x12\xbd¿\x94\xfdk\b\xf8\xb8\xf1\xbf\xdd췋\xfc\xafӪ\xf9\xdf#\xf3\xbf\xb329xL\xf6\xa7\x15\xb5\x92\xfc\xbd\xf9\x10qd?\x95\x04\xe4\x8c\xfd\xb6dR\x919\x95\xe0\xe4\xf4\xc0;\xe8\xc7Ŝ\xcbt\xb5\x96\x1e\xc5\xe5\x19\x0eI\xa4)V\xdag\xb8\b\xb8N\xaf\xc0C\x12r\x04r\x86\xc02\xbc\x9fi\xc0wH\n\x12\xe0\xd0\x1b\x1c\xf9\x99\xa2f\xa7\xf0\xc8\xe9\x14w\x11v\x85O\xae\x91$\x02\x1d\xd4<j\x1a\x8b\x85\xd9_ñ`\x00\xf0\xccC\xa2\xe25\xecL\xb4\xe6\xe1\f\b\x98\x8f7Qx\xce\xd5\x1d\xab\xf3\xbe\x87\xd5W/\f{\x9e\xdc\xcd\xf96*\xa6\x1f\U000d8c50,#\x9f*\x90\x17E\x00)\x91\a\xebvT\x12h\xe6\xe16i*\x858Ot\xb9L\x1asB}$k4\xc6Q \x8dC\x1eFKE\x904õ\x8e\x8d\x8bs\xee\xfb\f 5\xecƒ\xe9Y2\x91\xadD:\xdfY\xd1\xfeT\xdaɾ.\xceY\x1f_Y\xfc\xbfL\xb3?\xea\x83zx\xfc\xdfkv1\xfew\xfa\x83~\xb7\xd3F\xfc\xdfi\xb5zu\xfc\u007f@\xfc_\xc5\x00\xc0\x1b\x8cٌ\x87*\xa6\xa1D\xa7\xf0\x96\x1f6\x1a\xaf\xb6\x05\x1dp%\xc6\xc3\xd2\xc9D,1a\xa9С\xbc<\xe7\x8a\xfd\xcb\xe4\x11ɊJ\x9d\xdb\vU\xb0N\x92|\xbeװ\x11\xca\xe7~\xf8\f\xa2W\xc8\x15W\xe0\xfb!Tq\x99̟\xc6\\p\xff\"\xb6}:py\x8d\xc6EUJ\xf0\x90 )\xd0\xf1\xcedB1\x88\xe9\xc8\xc7C#\xe9\x18`\v\xf8\xcf\xe3F\x03]\xef\xfb\xb3\u007f\xe2*\x1bglFc_\x1e6.\xe0\xca\xe2\n.\x18]4\x8c\x9fL\x9f˷l\xff\x1ar\xf1P\x03\xab\xfb\xa2\x01;\xec\xbf\xdb6\xf6߅\xff[\xf0\x1f\xf2\xff\xee\xa0\xce\xff=\xa4\xfd\xf3p\x12,}v\x9c\x83\xe0Z\x1f\x0e\xc9&E|H\xa4\xce\x11\xba\x06H\xe86\x9b,\xc8s\x01b\xd5j\x1b'h\xb5\x13x?\xef&]\x89&*MC\nu\x90s\xe8$\xa7\xa1\xaepH\xe7 g\x93\x1b\x04\a\xd7<\xfd\x01Q\xed\xf1\t\xf1\xf4\x89\x852\xd8\xf1\n]\x00\x165p.\x98\n\xc7\x14\xdb6\xc3u\xc1\xc13)\xd1\v\xc0qr\x83²\xeb\xb1\xcf\xc3\xcan\b\xc9\xd3\xf2\xac\x04aZf\x94AYN\x0ey\x817\x991\x9dc\xcdCB\x98\x91\xfdF<\xbdX\xe7\x1fB\xcc\fI\xcb\f2\"\x8d\x86O\\\x97\xcch\xc4=\xbc\x11\xe0\xe3\xd8\xc0G\xf0\x85\xd7\x12\xc8\t\xb8\\\xc0\x8d\xe3@L\xae\x01(\xb3\x05xaI\\w\x94\x9b\t\xe6\xa2\xc9\xe2f\xf6f\x80é+\x81\x12\x18\xf8z%\xc0\u007f\xbe4\x92\x9cc\xab$\x0eA\x91\x9c\xc2L\xc4\\ix\x05r8t\xa6\xe6\xbaWQ\xe4\x9d\xeag`\xc6\x00\xa9\x02b5Q˘\x1bؽ`R\xd2\x19\xdb2%\x84\x18\xe87\xf8\x1a\xd8#8\xf5D̉\x10לE\x02\xc8\xce\x1au*\x84\x9b]\xcea\xc2K\x11s\bf\xdbd\xb49\xa3\x13\xe7\xf2RC\xea\x9fB\xbb@\xcd6N+\xae\x9b\xc7l\x9a'\x05f\xbb\xcdu\x86)k-zj5\xf1\x05\x12\xae\x13\x9bG\xcf*Aiꡌh\x8a\xf0\xd1\xfc\f\x92g\x8b(\xc0\xb0\x98\xcb\xd4\xdb]r\xa3`)\r\xcaǋG\xa59\xab\xedI\v\xa8-j\x9bj!\xfc\xa7\xf9\xe9Rbpg-S7\x17e\xfe\xbbv\x1cN\x85\x9a\xe7j\x8a\xe5\x96\xcc=\xf3]6\t\xb0Œ\v֚\tv\x19\xe3Ks\f[\xfa\xabi\xa1֛+\xe9V\xd1\xc3\x1f\xcfS1\xaa\xf9\xe3\xa6{T|d\x9e\xe9\xdbJ\x00?\x8f\x02\x9a\xfd\xae\xa6\x81\xc5\xc7x\xa6+\xca1E\xb1+\xd4!\xab\x9c\xc9\x13\x9b\xb3 r6|\xff\x19\xa6KL\xfd֢\xc1\x17\x15\x8a\x95\xf75d&\\\x1b}\xd2U\xf0p*\xf4\xc9\a\xd0\xedj\x83{\x1feL\xcd\xdbajF\xc0\xd71C\x95\x14!3σ\x8e\x88\xe9\x00\xbf&\x19X\x95/\x9fT\xdaAٲʊ\xbbyD\xa8Qfo\xec\x9b\v\x88=\x9d\x11\xac\xb9\xbc\x92L\x15zgᜎʒ\x14\x99|\xc1D\xf3\x1anû\xbb\x8ai\x14\xb1\xd8!\xe0I\x03\xf0\xa4>\x97`\xac\xebcd\x00\xc5\xc4\xc4ƐK\b\xa1`\u0379[o1\xdb:\xa3\xf0\xb5\xe1\xff\x85\xf0ipO\x04`\xd7\xfb_\xcd\xfe\xa0\x90\xffo\xf7;u\xfe\xff\xf1\xea\u007f)\x1f\x90ǤD\x103\x80?\x1b3\xb5\x029\x89;\xd7\xdfȔ\xfa\xacD\x01t\x97\xeb\x03\x18\x10\xb3l\x92?\xd7\rN\x18\x04R\xa9\x17*\x8f\x983\x98<\xceFq\x1b\x15\r*\x1c[\xa0\x91\xf0\x0e,\xfd٘\rnn\xc1Ӊ >ǜ\xba&K{\xe2\xa8x\t\"\xff\xbf\xe2\x00@\x9fWdL7|ň\xf1\x05l\xa5\xe8*KKĲd\x9e#\x14\xbcpv[r^83\xb19\xd5\xdc\xe0\xc8\xcb=\\\x8b\xfd+\x06\x98\xcd\xd1\xfd\xe5^\xdd\\;\xce\xff-\xff\u007f\xaf\x15\xe0]\xf9\xdfN\xbfUz\xff\xb7۬\xfd\xff\xc3\xe7\u007f\xb6\xfb\xfb\xedu\xe2\f\xb5\xf8\xe4\xdap\xb7P\x1b\xee&%\xe1~\xa16\xdc!\x15\xdc\xec#\x1db\r6w\xd9\u007f\x16\xcd?\x82\xfd\x0fz\xbd\"\xfe\xeb\xb5k\xfc\xf7 G%34\xe6jj\xe7\x19X\xf3#&\xfe\xb6\x02\x9a\xcfˑh\xfa\xbe=Gb\xbaSs\xf7L[En\xe43r\"\xb8\x9crBdgz\xe3U\x10\x03\xf4\\W&8\xaa\x13\x1a<\xfc\x94\x84\x86N\v}ZB#\x97\xddɦ\a\x92\xb4\xc0v\xdfg\xb7\xfdq\xe3\u007f\xc9\xfe[=\x80\x04\xb5\xfd\u007f\r\xf5\x9f]\xe1?\xb1\xa2{\x8f\xfd\xf9\xf7\xc2>2%U\xc7\xfe]\xf1\xbfT\xd0{\x84\xfcOk`\xeb\xbf\xe0\tz\x1d\x8c\xff\x83^\x1d\xff\x1f\xc5\xfe7\xc5^c\xf8\x8d\xd2\x0fD\x92\x12\x8e\xb6\xaf\xad%\x9e\x9f9[9d\xdfV\xd3.gL]\x1aU#\xde\x011\x0eź\x8bmw03\xa0?Ic\xaa~\xbb\x1bO-\xf4\xb0\x00#\x12\xd12\xb2o\xd6\xe1\xa9-En\xfa\xdc\x15\xf7\xd5<3\xe2\x17\xfc^\x98\x00\x82<\x9f\xcdUf\xd4[ݐ\x9dͼڇ\x03\x12\t\x1a\x15\x85@\xef-\r\xfd\x80\xc5vL\x12\xcb\xf7ohL\x1c\f\xe8\xce\xc1]\xe5\xc0O\xab\xffy\xf8\x91B\xb1$\xf0\xef\xaa\xf6%\xf27tq\xaf\xfe9\xcf_\xf60@y.\xc4\xfd\xfd\xfcg'\xfekw\x8b\xef\xff\xb7ۃ\x1a\xff=\xa4\xff\xd7\x15@\xf9\xc7x\x19\xfat\x1c@\x1c\xd0z\xe1A\x9b\xc4^\xfd[\x99l\xf7\xbe\u9fd2\a)>\xd4\xce\xe5%\x96\xe0\xd1\x13\xdd\xfd\xd3 \xf4\x84\x97&6\x90\x13pV\xde\x1fWR\x84\xb7\xb7\xcf\xd3\xdf\xe5\xd4.\xa9>\xea\xa3>\xea\xe3~\x8f\xff\x06\x00\x00\xff\xff\x14\x92\x82\x9d\x00F\x00\x00")
+	templatesFS := vfsutil.OpenBaked("\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xec<\xfds\xdb6\x96\xf9Y\x7f\xc5+g{IvL\xeaÖ}\xe3\xcan\xb3\xf9\xd8z\xe7\ue6b3\x9d\xeetnn<0\tI\x88)\x80\x05 +\x1a\xad\xff\xf7\x9b\a\x80$H\x91\x96\x9d\xc6vz\x11\xe5D\"\x00><\x00\xef\xfb\x81 \xb1f\xd7L/\xa3\xa9\x9e\xa5\xcf\x1e\xe6\xea\xf5{\xbd\xfd\xfd\xbdg\xbd^\xaf\x7f0\xec\xe17~\xf2\xef\xdd^o\xf7Y\x7f8\xd8\x1f\xf6\x87\xfb\xbb\xbb\a\xcfz\xfdA\xaf\x7f\xf0\fz9\x80\x87\xbc\xe6J\x13\xf9\xac\xf7\x87\xfb\xaa\r*/\xfeگ\xd5\n\x12:f\x9cBp\xcetJ\x03\xb8\xb9Y\xad@C\xf0*\x8eŜk\xc8)\xc4\xd5P\x9e\xc0\xcdMg\x94\xb0k\x88S\xa2\xd4Q \xc5\"8\xee\x00\xf8e\xb1H\xc3Y\x12\xfe;\xb8\x1fb<VT\x87\x03\xd3\x10`4\xdd;n\xeffԝ\xee\xd9v\xab\x15\xb01Do\xaf)\xd7\n\xfb\xc5B\x80\x91&\x97)\xcd\xfb\xb27\xe6\xffPi\xc92\x9a\xc0\\Q\xa9\xc2\x02\xe8\xb1{\x10\x1f\x9dR\x92\x94\xf7X\"\xfd[,\x98:\xe4\xde\x10mfd\xd4\xd5Ӷ6\x06\xb7M\x8dN\xde\x03I\x12I\x95\xda\xd4\xf2oR,\x14\x95M\xcdF]\x1f\xd3Q\xb76\x92\x91\xbe\x14ɲ\xbc7\xb3'\t\x9f\xd0\xf5\t̟\x90\xf9\x1c\xaeV\x10\x9d/3\n77\xc1q\xa5\x116Kp>\xa2ג\x12M\x93蝐3\xa2!\x18\xf4z\xfba\xaf\x1f\xf6\x06\xd0\x1f\x1e\xf6\xf6\x0e{C\xf8ϳs\x87z\xd2\x04\xa6V\x94/0\xfd\xdd\xf5\x1e(6\xe1!\xe3%\x19\x9e\xb1\t\xa7\tآ\xa6\xc7i\xaah#\x8cpLXJ\x93\x12\xd4;s\x0fX}\x7fx\xf3l\r\xa7yvO\x18b\xae׀\xb8\xb2\xbbAɈR\v!\x93PRE=X\xef]9\x14\xe5w\x83Gg\x84\xa5\xe15\x95l\xcc\xfc\x99z\x8b\xe5\xe0\x97\xdf\r\x9e\x121#i\x982~\xb5.H\xb0t\x03\xb0\x9b\x1b\x8f\x0eKa\x937\xcb/'\x15\xdeKq\xcd\x12*\xe1\xe6\xe6\xc5jU\xb9\x7f\xd9\xf6p\x1ba\xe2\xf3\xa7t&4}e\xf9\xf4\x16\"ƶ\x1f\x14\x95\xaf&\x94\xeb\xc6vUN\x05X\xc7fԭp\xeb\xa8k\xc4\xd7q\xa7:\x1bym\x86\xfc\xa7!8\x9fRI\x9f+\xe0\x02$\x8d\x85LhR\bN\x18\v\tK1\x97@\xac\xe4\x8ep\xaaG\xdd\xec\xb8SGa\xd4M\xd8\xf5q\xc7}\xe5\xaa`{}\x83\xd7Xȉ\xd0\x0fi\xfdm\xb4\xff\xfa\xfb\a\xfb5\xfb\xaf?\xec\xf5\xb7\xf6\xdf\xd3\xda\x7f\xef\feX\x89\x92\xeb\x9d\x1f]\xf5\xfd\x8c\xc0\xfd\x9a\x11\xb8k\xee\xd5\xccY\x87j\x96W\f\xc0\xa9\xdap,\xe4,7\x15\x11 K\x8e\x02K\xaa~U\xa1\tά \xee\xd4\x14ĉ\xb2\x8a̫Ʌi&\x19\xd7cx\xa1!\xf8'}~MA!\b\xc2\xc1\xa8D\xd0\x02\xbe\xbf\x8e\xe0}J\x89\xa2;0\x16i*\x16\xa0\xa7\x14\x18WZ\xcec\xcd\x04W\xc080\x8d\x8d\x8dޭ\xceU\x14\xbc\xb4j\"ʑ(dq\xa3\x90\xbf\x0fn\x88\x88\xb3'A\xd2\tS\x9aJ\x9a|Y\xac\x11uNf\xb4\tq_\x975\f\xa4\xb4\xee[\x89\xa84\xf1\xcbqk\b~\x13\xf3\xe7i\x8aʍ\xb2kZ\x8ey\xc1\xf4\x14\x881!p\x948\xd9\x048]\x14@+\xba\x0e\xffFH'0\xa3z*\x92\xa3 \x13J\aFU\nn\x8c]I\xaf\xa9T\x14~r\x18\xd6\xcc^T\xf1\xb6\x06\x8d\xdd\xe8\x94rkgxMF\x97s\xad\x05\xcfi\xdd:\x1cj~9c\x1a.5\xc7\x7fa&ٌ\xc8e\xe0Fwfj-\xaa\xf6i\x0f\xdf.\"\xdc6\xcdNU\x7fy\xd5\xcdb\xc1\xd5Ê\xffM\xf2\x1f\xab\xeb\xf2\x7fp\xb0\x95\xff\x8f-\xffό\xf1~\x12\v\xe3\x1a\x8dXN\xd9c\x02c\x12\"G\x18\xe7p\xd4e\xc7%q\xe6p\xb6ן\xf3\xfa\xa8\u0098\xa4\xe9%\x89\xaf\x1eL\nl\xe2\xff\xbd\xc1Z\xfcowwo\xcb\xff\x8f\xc3\xffݿv\x00\xe8'My\xa2\x0e\x81\vN;\x7f\xed\xa2q\xf7ݛ_^\x9f\xff\xf6\xfe- ]\x1cwF\xf6\v`\x94;\xae#\x15K\x96i\xd0ˌ\x1e\x05\x9a~\xd2ݏ\xe4\x9a\xd8R\xa7N\x9d%\xf6\x9e,SA<\xaba\xc1x\"\x16\x91\xc8(\xa7\xf2\x7fP\xb8\xbcvd\b77\xffk\xdd\xf9♗?\xac\xb9\xb0\x00\tM\xa9\xa6\x1b\x00\xfd\xd0\xf1:\x8bS\xa1\xe8\v\x03lԵX\"\x92\xa3\xae\x1dШkG\x98O\xcd7q\x19\xc3\xf5i\xf5\xff\xa0\xb7?\xa8\xeb\xff\xdd\xfe\xd6\xff{b\xff\xeft\xdd9xJ\xef\xcf\x10j\xa3\xf3\xf7\xf6S\xc6$-\xe4B\xd5\x019\xa5\xbfϩ\xd20%\n\xa8mx\x8b\xfbq>e\xaa\x18\xads\x8f\xe4:\x84\x1dȌcX\xd4Y_DpZ\x8d\xb9\x95\xce\x11\x1b\x03\x17\x1a\xa2_I\xca6`\xca0\xbc\xa7\xe1\x1a[~&\xaa>\x88\bNƸ\x8a\x10\xa7,\xbeB'qJ\xad\x1f5\x96b\x865\xd2\xf9Xq\xca(\xd7;\xa0\xe5\x12b\x91-\x19\x9f\x00\xe1\t·\xc6\xdfL\xdf2\xba\xe8\x8d\xe0-> \xd6|w\xcbH~\xf3I\xccL\xf3%\xa5\x1c\xe6Y\x82\xd9\x06\x83\xc2R\xcc\xd1G7\xe5*O\b\xb4aӈ\xc4YN\xcb\xebN\xe3t\xef\xfe^\xa3e\x8e\x9a\xd38b<\x9bk@\xa7\xf9(\xc8\x02\xa7\x17\xa7,I(\x0fp5\xe6\xf4(\xa8j\xb6\xca\xf3\xb6ʢ\xf6E\xddN\xfau\xf9\x9c\xdb\xcf\xd7\xf312\xee\"g\x88H\x7f\xd2yͣ\xe9\xffް\xb7W\xd3\xff\xbb\xfd\xdep\xab\xff\x1fG\xff\x1b\xfb\xbf\xc9\x03X\xad\xe0\x92N\x18גp\x93{\xff\x99\xedt:\xafڔN=\xfd\x04D\xa3\x82\xf8\xe9\x8ci\xfa_6\x8e\b\v\xa2Ll\x8f\xebt\x99\a\xf9\x92\xa8\xe34T\xc2\x12\xfe\\\x03\xe3L3M4\x05\x8d:\xce\xc1/t.\x9bp!]\x9dQ\\Q\xa7s\xde\x14\x12\xdc\x01\xf4.\x8c\xbe\xb3\x91PTbF\xf31\x8e\xfdI\xb8\xb4\xb9\xf6\xc3N\aE\xef\x87\xd3\xff\xc0QvN\xe9\x84\xc8D\xedtΧtm\x04\xe7\x94̰5\xe5I1/\xf9\\\xfe\x19\xaf\"W\x8e\xfa\xe6\x81܀\r\xfc\xbf7X\xe3\xff\xc1\xde\xc1\xd6\xff\x7fL\xff\x9f\xf18\x9d'\xf4\xb0b\x82\x1b\xb7p\a\xca\x10\xf1\x0e\xb8\x04\xbf5$L\xbd\v\x16T}\x01pd\xd5\xe6\x13\xf4\xbd}@yUN\x89ڤ\xa1jy\x10\xdc,\x01'\x1cc\xff߫\xe0e\x85'K\vn\xb5\x82\xbf\xbcC\xab\xf6\xf0\b\"\xf3Ù2X\xf1\nE\x00&5\x10\xd6\t7m\xeaees\x93p\x88\xce\xccpϗ\x19\xf57\x1fy\xe3q\xf3\xe1p\xb7\x0e\xc9ZO77f;\x92\u05ca\xf2\x9a\xf1W\xee\xd4)\xfbq\xe5n\x83\x85\x19l\xf0w!&6I\xe75\xb2n\xd7\xf1\xe8\xbb0\x84\t\xc9X\x84\x1d1\x1eIk>.\x84\xbcR x\xba\x04\xc1\xe12\x15\xf1\x15Д\xce̎\xa00,\x91p\xb0H>\xb8\x89\xeb,!\x9a\x84*\x16\x18\xe5Y\xad\xe0\xa3`\x1c~\xb2\x98\x9ca\xa9\x82\x00\x10\xa5\xa0\x06\t\xec\x93֯@\x1f\x0e\x85\xa9}\xeeU\x96E'f\x0el\x1bI\x13&i\xac\xe7\x92Y\xb3{F\x95\"\x13\xda\x02\x92\xc41U\xca\xda\xd7b<N\x19\xcfь\x85\xb8b4\x13)\x8b\x97HS|\x92ҋ\xa9P\xfaBH6a\xbc\rG\x173:\n..p\xad\xd4/\xdc\r\xd0x\x1b'\r\xcfM%\x1dW\x9d\x02\xbb\xdc\xf69\xeb)\x1b*\xfa\x8b\xa3\xc4\x1f\xd1\xe1:rqt\x9f\b\xd6@\x8fTF\n\v\x1f\xd9\xcfZ\xf2t\x96\xa5D\xd7\"\xf5n\x95\xc2,\x9d\xbb\xbde\xf8p}Q\x01\x9a\xf9\xc9 h8\xaa\x8d\xb4\xd0\x0f Up\xce\x11\xc8o\x1b=/W\\\xc7\xf9oFp\x04\rd\xee9\x1eM%^\x9f\xd5*\x17\x04h\xe1\xe4\x1a\xb7z\xca\xcec\xbe\"\xc6\xd0R\xdf\xec\x16\x1a\xba\xf9\xa8\xc2&\xf7\xf0\x1fg\x05\x1a\xcd\xfecY\xedwcF\x13ٺV\a\xf0\xf3\\@+?\x9b\xdd\xc0\xfa4\x9e\x9a\x8c\xb2$\x88v\xbd\xef\x1aq\xe636\xa5i\x96\xf7\xf5F\xa0\x157%6\x7f\xeb\xac\xc1\x1f\x1b\b\xab*k`\"B\xa7}\x8aQ0>\x16\xe6\xc7'\x15\xb40܇\xccc\xb5h\x03\xabY\x04\xed>F\x10\xdc\xed\xed$\xc78\xef\x1a\x02\xc6A\xd1X\xf0D}\xd7\xc8\a\xeb\x9cU\xa5\xc5\xfc\xb2S\x84\x12\xcfȒ\xd0\xed\\0\xdb\xe2\x8eG\xa4a$^\x16zc\xe2\x9c\x1c\xafcR\xf7\xe4k,Z\xa5p\xa7\xdeÅ$Y\x86[M\x95^\xa6\xf4(H\x98\xcaR\xb2<\xc4\x1c@\x85.+\x8c\xec\x830\x16@\x8d\x9b+]{7\xfeT\xb9b\xf7\x95\xdb\"\xdb\xeb\U0006f701g\"!\xe9\x039\x00\x1b\xec\xff~o\xff\xa0n\xff\xef\xef\xf6\xb7\xf9\xbf'\xcb\xff\x15\xfe\x80:\x04_'\xd6\r~_g\x1a\x02\nrqn\xee`L\x12\xba\xe6\x02\x98\xaa0a$\x15\x13'e֫c\xc15n\xab/\xe4\xc8Z\v\xdc\xc5OeѠԊ\xd6*\xbct\x86\x86{Ƥ\xfe\x9c\xceN\x98\x9a\xb1\x02P\x00D2\x12\xda(\xedQ\xa0\xe5\x9c\x06\xc7\xff\xa6ٌ\xaa\x1f\x1a\"\xa6\xa5\xbfb\x9e\xfe#\xdeJE:6\r\x11Ӓ\xde\x00ץ0\xe3-R\xd8\x03l\x7f\x1aߠ\x1bU&\xd7\xd9\xfe\r\r\xec\xe2\x98\xfa\xf5ZS\x9c\x93\xcf\xf6\xfa\x93_\x8e\x8e\x1e*\xf4s\x17\xf9\xdf\xdb\xdd\xef\xd7\xe4\x7f\x7f\xb8\xbb\xbb\x8d\xff<~\xfc\xa7]\u07b7\xe7\x89=ע4\xf1\xee\x98\x1bޫ\xe5\x86\xf7\xf2\xdc\xf0~-7\xbc[\xc1\xcb\t\xc5;\nD'\f\xddW>\xf0\xede\xae5k>\xafxD\xfe?\x18\x0e\xeb\xf6\xdfp\xb0\xb5\xff\x1e\xc5\xfek\xf4\f\r\x7f\xb9ܹg\xd6\xfc\x03\x03\x7f\xad\x06\xcd\xe7\xc5H\x8c\xfb\xde\x1e#\xb1\xd5\x05\xbb\x9b\xd8ȇ\xac!6\xf2\x191\x11\x1c\xcez@dcx\xe3U*)I\x96\x8d\x01\x8e\xe6\x80\x06\xe3\xf7\th\x9c\xf0{\a4<\x11\\\r\x0f\xe4a\x81v\xd9\xe7\x96\xfdi\xf5\xff\x1a\xff\xf7\x87\xc3m\xfe\xe7\xeb\xc8\xfflR\xff9\x17=\xb8\xee\x9fg\xb7\xeb\xfey\xb6\xd5\xfd\xf7\xd3\xfd\xf8YO\xe8\xe55\x8f\xc6\xff}<졦\xff\x0f\x86\xfd-\xff?\x05\xff\x97\xc9^\xcb\xf8>\xe7WS8\x86\xbfZS<\xbf2\xba\b\xe0\x85˦]L\xa8\xbe\xb0\xa4\x06\xd1K\x14\x12\xa5\xb8h\xeb\xc1B\xc0PS\xa1S\xd1\x02x\x8dBę\x1e\xce\xc0\xc8D6\xcf\xdc\xce:\xfc\xe9R\x91e]\xb8`\x89\x9ez-\xfe\x89\xf75\x00ᔲ\xc9T{\xad~6\x05>4\xbb\xb5\x0f\x1b\xe4\x18t\x1a\x12\x81\xd1τ')\x95\xaeM\xae\xcb_\\\x13\t\x01*\xf4\xe0\xe5-*\xbds\xbf\xfc_\x84/츞Jſ)ۗ\xe3\xdfA{\xc1[\x8c\x9c,\xb6\xd77r\xe9\x85\b\xc7$\xd6B\x86\x8a\xea\x871\x047\xc8\xff\xbd\xc1\xee\xda\xfe\xbf\xbd\xfev\xff\xdf#\xed\xffk3\xec\xce\v\xca\x002\xd7S\xca5\x8bMJ\xf8)\xdf\x02\xa8\x93\xab\xab\xf7wzߊ\xb7\x1f}w)\xefS\x1a\x8bk*\x97\xafE\xe2\xef\xf2\xf1\xb7ڷA\x04܂\xc8\xf1\xe0\x8e$\x82\xf3)U\x14\x88\xa46i+\x1dX\x88\x11n\x04oI<Ŕ3Ą\xc3%\xc5\x1dA\t\b\x1e\xdb7\xca)I@\x8c\x81\x98\xd6\xde\xce|\xaf?\x1cO\x96\xed\xe0;\xe71\xbe{\x80{\xfa1\xab\x81\x0e(\xbe\a\xae\x050\x1d\xc1\x99\xb6\xbb#\xe9\f\x94\x98\xd1\x05\x9e\x18\x02\x8a\x8c\xe9\x0e(\x86\xdd\xe9)]\xc2\xc2\xe4\xe8/)\xa8\xa9Xp \x13\xb2\xbe\x9f\x1e`4O\x8b\xa5u\xc3\t\x11A\x05)S:\x9cs\x938N\x8aE\xa8\xeedj\x9dX\xfc\x1b\xa5\xecx\x84\xb0\x8e\x9d\x06\x1cu\xcdݨ\x9b\xb2\x1a8Ghyɨ;O\xcb\x16\xa5qP\xf7\xf2\x1b\xdc\xeb\xf3\x85xg\xd6\xf1\fi\xc7s\xa0\xdfx{\x01:\xd5=-x\x8e\xc0[\xbbƟM\x1c\x1b\x8ef)\xe1\x15:\x9b\x9bW\xe1m\x8c\xe1\xfb\x04\xe6ܐK\x85\xa6 \xa5c\x04\xb4\xb1\xa1\xca[F\xa7\xb8_\x963>1K\xf2\xb2^Ќ\x13\x8eQ@\xc2\x14\x0e\x05t\xebp\x85\x84\t\xbė\x16\nní\xe2\xb0\x03\x94k*\xef@\xe1\rs\xd3\x10Yj\n\x185,o\x01\xc3PRT\xb4h\xdd]S\x8b#\xe5D\x95\xd01\x99\xa7:p/w\xd8ދw:\xf2\xa1\xe6\xe4\xf4\xf7\xf6yhہ\xd3\xd6/\xb2\x92l\xe9֭IA\xc4\uedb1\x87\xea\x06\x91\x86\r[\xc5b\x9fŤ\xbei\xfa\xbfO\r\xf2\u058cm^5\x10r\a\xc5)\xd3(\xe0\x9ekP\b\xa6\xb2\xf4\bS\xd1XR\r3\xc2\xe7$M\x97\rk\xed\xa9\x0eO\xd8\xff.\x9d\xa9n6\b\x16\x87=)&\x90\x9c?\x9c\x9e\x98\xd5\xf6\xb7\xd646h.G\xaew\x11\x82\xf5\xd90\b\x1f\"\x9eP\xca+[\xeaK\xad\xac\xe1\xd9\xf3)\xe5\xfe\xe0\xf1qp\x9blh\x02\x97K\xb37\x9dd\x19nk\xa5\xfcv\xfe\xfa*٢\x16Q\xb5r\xf2\xae\x04X\x8a\xf5b\xf2\xdd\x0f\xf7\x95\xdb(\xdb\xebᮒ\xde\x1e(\xf8s\x97\xf8ϰ~\xfe\xc7`0\xecm\xed\xff\xffO\xf6\xff\x1f\xcb\xf46\xd9\xff^\xf5g\x99\xff\x85\x98\xfe\x05\xdf\x12j\xd1j\xf8\xf2iM\x803\x9d\xcbpcs\x8f\x19gjj\x90FU\xb9nF\xdf[LW%\xf4]\xe4\xf3ݤ\xf3\xafx\x9c\xe3\xb2I:\xfb\xb2y+\x8b\x9fL\x16?\xc5Ǆgé\x10WO&\xff{\x83\xbd\xfa\xf9\x0f\x83\xc1\xfe6\xff\xf7\x98\xf9?\xe3ȫ\x7f]\xcey\x82&\xdc!\x86'\xa4\x8aR\xaaT\a\xc0\x9e\x95\xe2W\xbf\xb0\xf5\x1f\xd5\xcb\"?h\x9c\x83\x9f\xf0\xb5'\x940\xb7\x1f\r\x83\x91\xf0\v\x9b\x1b\x80#X\xad\xa2\x7f}T\x82\xdf\xdc\xfcP\x9c\xcbR*\x98\x1c\xd3\xed\xf5\x10\x979\xe3w\x19b`ࡶ\x7fo\xe2\xff\xc1A\x7f\xcd\xfe\x1b\xeeo\xed\xbf'\xb6\xff\xac\xb9\xe0\x1d\x10\xf2\x94q_\x9fL\xab\x96\xdfj\xf5%\x8e\x1f1\x03,\x8e\xd4,\xce \xc9O\xc0\xae\xdbt~p\xb0~\xf6h\xd9\xf3\xeb)\x8d\xafj\x13XE\xc2!\xff\xe5O)\xcd_s\xb7\xa7\x93^{K\xe9\xc6h\xce\xf9\xbc\xef\xc1\xa4U40\x00\x9d\xa3\xf2\xb9H4u\xee\x87{\x1b\x90i?\xf5\xa7\xe94\x1d\xefԞ\xf5\xe9\xf7\xc6eN\xfe1\xf3\xe4\xdc\x05\x8b\xfdg\x1d\xfaS\xa2\x8d\xf4q¯\xeb\x87\xfe\xdc\xff؟͘>֙?\x8dk\xe2\x0f\xa8Qn\xb4\x8e\xe6\x8e\a\xbe6\x10\xcfFڹ\xbb\xebeQι\xa1\x10-\x0eh\xe4U\xdf5@v\xa7w2)O\x9a\xbc\xb1G\x8d\x95ى\xbd0\x93\xfe0\xa7\xbfl\xb6\xff\xf7\xd6\xf2\xbf\x83a\x7f\x7f{\xfe\xcbWy\xfe\xcb\xf9\x94\xf0+e2Zy\xccc\x9e5\x9c\xf5Rj\x04\x9fw\xabz\xf6r\xd9\xc1#Z\x10ƽOi\xa9\x9e\x19\x83\xa8\xc0<\xbb\xf5\x90\x98o\xea\\\x97\xedg\xfb\xd9~\xb6\x9fM\x9f\xff\x1b\x00U\xf0\xadj\x00n\x00\x00")
 	App.SetTemplatesFS(templatesFS)
 	tmpl_users_hook_html := template.New(templatesFS, manager)
 	tmpl_users_hook_html.Funcs(map[string]interface{}{
@@ -52,8 +52,7 @@ func signInGoogleTokenHandler(ctx *app.Context, client *oauth2.Client, token *oa
 	if err != nil {
 		panic(err)
 	}
-	ctx.MustSignIn(asGondolaUser(user))
-	redirectToFrom(ctx)
+	signInAndRedirect(ctx, user, SocialTypeGoogle)
 }
 
 func jsSignInGoogleHandler(ctx *app.Context) {
@@ -67,8 +66,7 @@ func jsSignInGoogleHandler(ctx *app.Context) {
 	if err != nil {
 		panic(err)
 	}
-	ctx.MustSignIn(asGondolaUser(user))
-	writeJSONEncoded(ctx, user)
+	writeSignedIn(ctx, user, SocialTypeGoogle)
 }
 
 func userFromGoogleToken(ctx *app.Context, token *oauth2.Token) (reflect.Value, error) {
@@ -87,5 +85,12 @@ func userFromGoogleToken(ctx *app.Context, token *oauth2.Token) (reflect.Value,
 		Expires:  token.Expires,
 		Refresh:  token.Refresh,
 	}
-	return userWithSocialAccount(ctx, SocialTypeGoogle, guser)
+	user, err := userWithSocialAccount(ctx, SocialTypeGoogle, guser)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := storeToken(ctx, user, SocialTypeGoogle, token); err != nil {
+		return reflect.Value{}, err
+	}
+	return user, nil
 }
@@ -19,8 +19,14 @@ import (
 	"gnd.la/util/stringutil"
 )
 
+const (
+	payloadActionReset  = "reset"
+	payloadActionVerify = "verify"
+)
+
 var (
-	errResetExpired = errors.New("password reset expired")
+	errPayloadExpired = errors.New("payload expired")
+	errInvalidPayload = errors.New("invalid payload")
 )
 
 const (
@@ -59,7 +65,7 @@ var (
 	SignInTwitterHandler    = app.NamedHandler(SignInTwitterHandlerName, app.Anonymous(signInTwitterHandler))
 	SignInGithubHandler     = app.NamedHandler(SignInGithubHandlerName, app.Anonymous(signInGithubHandler))
 	SignUpHandler           = app.NamedHandler(SignUpHandlerName, app.Anonymous(signUpHandler))
-	SignOutHandler          = app.NamedHandler(SignOutHandlerName, app.SignOutHandler)
+	SignOutHandler          = app.NamedHandler(SignOutHandlerName, signOutHandler)
 	ForgotHandler           = app.NamedHandler(ForgotHandlerName, app.Anonymous(forgotHandler))
 	ResetHandler            = app.NamedHandler(ResetHandlerName, resetHandler)
 	JSSignInHandler         = app.NamedHandler(JSSignInHandlerName, app.Anonymous(jsSignInHandler))
@@ -82,7 +88,10 @@ func signInHandler(ctx *app.Context) {
 	signIn := SignIn{From: from}
 	form := form.New(ctx, &signIn)
 	if AllowUserSignIn && form.Submitted() && form.IsValid() {
-		ctx.MustSignIn(asGondolaUser(reflect.ValueOf(signIn.User)))
+		if verify := beginSignIn(ctx, reflect.ValueOf(signIn.User), "", ""); verify != "" {
+			ctx.Redirect(verify, false)
+			return
+		}
 		ctx.RedirectBack()
 		return
 	}
@@ -110,9 +119,7 @@ func jsSignInHandler(ctx *app.Context) {
 	signIn := SignIn{}
 	form := form.New(ctx, &signIn)
 	if form.Submitted() && form.IsValid() {
-		user := reflect.ValueOf(signIn.User)
-		ctx.MustSignIn(asGondolaUser(user))
-		writeJSONEncoded(ctx, user)
+		writeSignedIn(ctx, reflect.ValueOf(signIn.User), "")
 		return
 	}
 	FormErrors(ctx, form)
@@ -127,7 +134,10 @@ func signUpHandler(ctx *app.Context) {
 	user, _ := newEmptyUser()
 	form := SignUpForm(ctx, user)
 	if form.Submitted() && form.IsValid() {
-		saveNewUser(ctx, user)
+		if !saveNewUser(ctx, user) {
+			ctx.Redirect(verificationSentURL(ctx), false)
+			return
+		}
 		ctx.RedirectBack()
 		return
 	}
@@ -146,7 +156,10 @@ func jsSignUpHandler(ctx *app.Context) {
 	user, _ := newEmptyUser()
 	form := SignUpForm(ctx, user)
 	if form.Submitted() && form.IsValid() {
-		saveNewUser(ctx, user)
+		if !saveNewUser(ctx, user) {
+			ctx.WriteJSON(map[string]interface{}{"redirect": verificationSentURL(ctx)})
+			return
+		}
 		writeJSONEncoded(ctx, user)
 		return
 	}
@@ -188,31 +201,16 @@ func forgotHandler(ctx *app.Context) {
 	}
 	f := form.New(ctx, &fields)
 	if f.Submitted() && f.IsValid() {
-		se, err := ctx.App().EncryptSigner(Salt)
+		p, err := encodeUserPayload(ctx, user.Id(), payloadActionReset, nil)
 		if err != nil {
 			panic(err)
 		}
-		values := make(url.Values)
-		values.Add("u", strconv.FormatInt(user.Id(), 36))
-		values.Add("t", strconv.FormatInt(time.Now().Unix(), 36))
-		values.Add("n", stringutil.Random(64))
-		payload := values.Encode()
-		p, err := se.EncryptSign([]byte(payload))
-		if err != nil {
-			panic(err)
-		}
-		abs := ctx.URL()
-		reset := fmt.Sprintf("%s://%s%s?p=%s", abs.Scheme, abs.Host, ctx.MustReverse(ResetHandlerName), p)
 		data := map[string]interface{}{
-			"URL": reset,
-		}
-		from := mail.DefaultFrom()
-		if from == "" {
-			from = fmt.Sprintf("no-reply@%s", abs.Host)
+			"URL": absoluteURL(ctx, ResetHandlerName, p),
 		}
 		msg := &mail.Message{
 			To:      user.Email,
-			From:    from,
+			From:    mailFrom(ctx),
 			Subject: fmt.Sprintf(ctx.T("Reset your %s password"), SiteName),
 		}
 		ctx.MustSendMail("reset_password.txt", data, msg)
@@ -227,36 +225,79 @@ func forgotHandler(ctx *app.Context) {
 	ctx.MustExecute(ForgotTemplateName, data)
 }
 
-func decodeResetPayload(ctx *app.Context, payload string) (reflect.Value, error) {
+// encodeUserPayload returns an encrypted and signed payload for the given
+// user id and action, which can be sent to the user by email and later
+// decoded with decodeUserPayload. Additional values might be included
+// in the payload by setting extra.
+func encodeUserPayload(ctx *app.Context, userId int64, action string, extra url.Values) (string, error) {
 	se, err := ctx.App().EncryptSigner(Salt)
 	if err != nil {
-		return reflect.Value{}, err
+		return "", err
+	}
+	values := make(url.Values)
+	for k, v := range extra {
+		values[k] = v
+	}
+	values.Set("u", strconv.FormatInt(userId, 36))
+	values.Set("t", strconv.FormatInt(time.Now().Unix(), 36))
+	values.Set("n", stringutil.Random(64))
+	values.Set("a", action)
+	return se.EncryptSign([]byte(values.Encode()))
+}
+
+// decodeUserPayload decodes a payload created by encodeUserPayload, checking
+// that it was generated for the given action and that it hasn't expired. It
+// returns the user the payload was generated for and the payload values.
+func decodeUserPayload(ctx *app.Context, payload string, action string, expiry time.Duration) (reflect.Value, url.Values, error) {
+	se, err := ctx.App().EncryptSigner(Salt)
+	if err != nil {
+		return reflect.Value{}, nil, err
 	}
 	value, err := se.UnsignDecrypt(payload)
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, nil, err
 	}
 	qs, err := url.ParseQuery(string(value))
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, nil, err
+	}
+	// Payloads generated before actions were introduced
+	// don't have an action and were always for resets.
+	if a := qs.Get("a"); a != action && (a != "" || action != payloadActionReset) {
+		return reflect.Value{}, nil, errInvalidPayload
 	}
 	userId, err := strconv.ParseInt(qs.Get("u"), 36, 64)
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, nil, err
 	}
 	ts, err := strconv.ParseInt(qs.Get("t"), 36, 64)
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, nil, err
 	}
-	if time.Since(time.Unix(ts, 0)) > PasswordResetExpiry {
-		return reflect.Value{}, errResetExpired
+	if time.Since(time.Unix(ts, 0)) > expiry {
+		return reflect.Value{}, nil, errPayloadExpired
 	}
 	user, userVal := newEmptyUser()
 	ok := ctx.Orm().MustOne(orm.Eq("User.UserId", userId), userVal)
 	if !ok {
-		return reflect.Value{}, errNoSuchUser
+		return reflect.Value{}, nil, errNoSuchUser
 	}
-	return user, nil
+	return user, qs, nil
+}
+
+// absoluteURL returns the absolute URL for the handler with the
+// given name, including the given payload in the p parameter.
+func absoluteURL(ctx *app.Context, handlerName string, payload string) string {
+	abs := ctx.URL()
+	return fmt.Sprintf("%s://%s%s?p=%s", abs.Scheme, abs.Host, ctx.MustReverse(handlerName), payload)
+}
+
+func mailFrom(ctx *app.Context) string {
+	from := mail.DefaultFrom()
+	if from == "" {
+		from = fmt.Sprintf("no-reply@%s", ctx.URL().Host)
+	}
+	return from
 }
 
 func resetHandler(ctx *app.Context) {
@@ -272,11 +313,11 @@ func resetHandler(ctx *app.Context) {
 	var err error
 	var done bool
 	if payload != "" {
-		user, err = decodeResetPayload(ctx, payload)
+		user, _, err = decodeUserPayload(ctx, payload, payloadActionReset, PasswordResetExpiry)
 		if err == nil && user.IsValid() {
 			valid = true
 		} else {
-			if err == errResetExpired {
+			if err == errPayloadExpired {
 				expired = true
 			}
 		}
@@ -285,8 +326,17 @@ func resetHandler(ctx *app.Context) {
 		passwordForm := &PasswordForm{User: user}
 		f = form.New(ctx, passwordForm)
 		if f.Submitted() && f.IsValid() {
+			// The user received the reset link by email,
+			// so their address is verified too.
+			setUserValue(user, "EmailVerified", true)
 			ctx.Orm().MustSave(user.Interface())
-			ctx.MustSignIn(asGondolaUser(user))
+			userId := asGondolaUser(user).Id()
+			audit(ctx, userId, AuditPasswordReset, "")
+			clearFailures(ctx, userId)
+			if verify := beginSignIn(ctx, user, "", ""); verify != "" {
+				ctx.Redirect(verify, false)
+				return
+			}
 			done = true
 		}
 	}
@@ -314,11 +364,30 @@ func FormErrors(ctx *app.Context, frm *form.Form) {
 	ctx.WriteJSON(data)
 }
 
-func saveNewUser(ctx *app.Context, user reflect.Value) {
+// saveNewUser saves a user who just signed up and signs them in, unless
+// RequireVerifiedEmail is true. It returns true iff the user was signed in.
+func saveNewUser(ctx *app.Context, user reflect.Value) bool {
 	setUserValue(user, "Password", password.New(string(getUserValue(user, "Password").(password.Password))))
 	setUserValue(user, "Created", time.Now().UTC())
 	ctx.Orm().MustInsert(user.Interface())
+	audit(ctx, asGondolaUser(user).Id(), AuditSignUp, "")
+	if verifyEmails() && getUserValue(user, "Email").(string) != "" {
+		if err := SendVerificationEmail(ctx, user.Interface()); err != nil {
+			panic(err)
+		}
+		if RequireVerifiedEmail {
+			return false
+		}
+	}
 	ctx.MustSignIn(asGondolaUser(user))
+	return true
+}
+
+func signOutHandler(ctx *app.Context) {
+	if u := ctx.User(); u != nil {
+		audit(ctx, u.Id(), AuditSignOut, "")
+	}
+	app.SignOutHandler(ctx)
 }
 
 func delayedHandler(f func() app.Handler) app.Handler {
@@ -340,24 +409,20 @@ func delayedHandler(f func() app.Handler) app.Handler {
 	}
 }
 
-func windowCallbackHandler(ctx *app.Context, user reflect.Value, callback string) {
+func windowCallbackHandler(ctx *app.Context, user reflect.Value, provider string, callback string) {
 	inWindow := ctx.FormValue("window") != ""
 	if user.IsValid() {
-		ctx.MustSignIn(asGondolaUser(user))
+		var cb string
+		if inWindow {
+			cb = callback
+		}
+		if verify := beginSignIn(ctx, user, provider, cb); verify != "" {
+			ctx.Redirect(verify, false)
+			return
+		}
 	}
 	if inWindow {
-		var payload []byte
-		if user.IsValid() {
-			var err error
-			payload, err = JSONEncode(user.Interface())
-			if err != nil {
-				panic(err)
-			}
-		}
-		ctx.MustExecute("js-callback.html", map[string]interface{}{
-			"Callback": callback,
-			"Payload":  payload,
-		})
+		writeWindowCallback(ctx, user, callback)
 	} else {
 		if user.IsValid() {
 			redirectToFrom(ctx)
@@ -367,6 +432,23 @@ func windowCallbackHandler(ctx *app.Context, user reflect.Value, callback string
 	}
 }
 
+// writeWindowCallback renders the page which passes the signed
+// in user to the window which opened the sign in popup.
+func writeWindowCallback(ctx *app.Context, user reflect.Value, callback string) {
+	var payload []byte
+	if user.IsValid() {
+		var err error
+		payload, err = JSONEncode(user.Interface())
+		if err != nil {
+			panic(err)
+		}
+	}
+	ctx.MustExecute("js-callback.html", map[string]interface{}{
+		"Callback": callback,
+		"Payload":  payload,
+	})
+}
+
 func allowRegistration() bool {
 	return AllowUserSignIn && AllowRegistration
 }
@@ -0,0 +1,125 @@
+package users
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/i18n"
+)
+
+const (
+	// Failures are forgotten after this time without
+	// any new failed attempts.
+	lockoutExpiry = 24 * time.Hour
+)
+
+var (
+	// LockoutThreshold is the number of consecutive failed sign in attempts
+	// (including two-factor authentication codes) for an account after which
+	// it's temporarily locked. Set it to 0 to disable account lockouts.
+	LockoutThreshold = 5
+	// LockoutIPThreshold works like LockoutThreshold, but counts the failed
+	// attempts from each IP address, regardless of the account. Set it to 0
+	// to disable IP lockouts.
+	LockoutIPThreshold = 20
+	// LockoutDelay is the time an account or IP is locked for after
+	// reaching its threshold. The delay is doubled with every additional
+	// failed attempt, up to LockoutMaxDelay.
+	LockoutDelay = 30 * time.Second
+	// LockoutMaxDelay is the maximum time an account or IP
+	// might be locked for.
+	LockoutMaxDelay = time.Hour
+
+	// ErrLockedOut is returned when trying to sign in into a locked
+	// account or from a locked IP address.
+	ErrLockedOut = i18n.NewError("too many failed attempts, please try again later")
+)
+
+// lockoutMu serializes the updates to the lockouts made by
+// this process. See recordLockoutFailure.
+var lockoutMu sync.Mutex
+
+// lockout is stored in the cache for each account and IP
+// address with failed sign in attempts. Note that lockouts
+// only work if the app has a cache configured.
+type lockout struct {
+	Failures int
+	Until    int64
+}
+
+func lockoutAccountKey(userId int64) string {
+	return fmt.Sprintf("users-lockout-account-%d", userId)
+}
+
+func lockoutIPKey(ctx *app.Context) string {
+	return "users-lockout-ip-" + ctx.RemoteAddress()
+}
+
+func isLocked(ctx *app.Context, key string, threshold int) bool {
+	if threshold <= 0 {
+		return false
+	}
+	var l lockout
+	if err := ctx.Cache().Get(key, &l); err != nil {
+		return false
+	}
+	return l.Failures >= threshold && time.Now().Unix() < l.Until
+}
+
+// recordLockoutFailure increments the failures stored at the given
+// key. Since the cache doesn't provide an atomic increment, updates
+// are serialized only within the current process. Concurrent failures
+// from several instances of the app sharing the same cache might
+// overwrite each other, counting them as a single one.
+func recordLockoutFailure(ctx *app.Context, key string, threshold int) {
+	if threshold <= 0 {
+		return
+	}
+	lockoutMu.Lock()
+	defer lockoutMu.Unlock()
+	c := ctx.Cache()
+	var l lockout
+	c.Get(key, &l)
+	l.Failures++
+	if l.Failures >= threshold {
+		delay := LockoutMaxDelay
+		if shift := uint(l.Failures - threshold); shift < 32 {
+			if d := LockoutDelay << shift; d > 0 && d < LockoutMaxDelay {
+				delay = d
+			}
+		}
+		l.Until = time.Now().Add(delay).Unix()
+	}
+	c.Set(key, &l, int((lockoutExpiry+LockoutMaxDelay)/time.Second))
+}
+
+// isLockedOut returns true iff the account with the given user id or
+// the IP address of the request are locked because of too many failed
+// attempts. If userId is zero, only the IP address is checked.
+func isLockedOut(ctx *app.Context, userId int64) bool {
+	if isLocked(ctx, lockoutIPKey(ctx), LockoutIPThreshold) {
+		return true
+	}
+	return userId != 0 && isLocked(ctx, lockoutAccountKey(userId), LockoutThreshold)
+}
+
+// recordFailure records a failed attempt for the given user id
+// and the IP address of the request. If userId is zero, the
+// failure is only recorded for the IP address.
+func recordFailure(ctx *app.Context, userId int64) {
+	recordLockoutFailure(ctx, lockoutIPKey(ctx), LockoutIPThreshold)
+	if userId != 0 {
+		recordLockoutFailure(ctx, lockoutAccountKey(userId), LockoutThreshold)
+	}
+}
+
+// clearFailures forgets the failed attempts for the given account
+// and the IP address of the request, after a successful sign in or
+// password reset.
+func clearFailures(ctx *app.Context, userId int64) {
+	c := ctx.Cache()
+	c.Delete(lockoutIPKey(ctx))
+	c.Delete(lockoutAccountKey(userId))
+}
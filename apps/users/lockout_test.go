@@ -0,0 +1,146 @@
+package users
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gnd.la/app"
+)
+
+func lockoutDelay(t *testing.T, c *app.Cache, key string) (int, time.Duration) {
+	var l lockout
+	if err := c.Get(key, &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.Until == 0 {
+		return l.Failures, 0
+	}
+	return l.Failures, time.Unix(l.Until, 0).Sub(time.Now())
+}
+
+func TestLockout(t *testing.T) {
+	defer func(delay, maxDelay time.Duration) {
+		LockoutDelay = delay
+		LockoutMaxDelay = maxDelay
+	}(LockoutDelay, LockoutMaxDelay)
+	LockoutDelay = 30 * time.Second
+	LockoutMaxDelay = time.Hour
+	cases := []struct {
+		threshold int
+		previous  int
+		locked    bool
+		delay     time.Duration
+	}{
+		{0, 10, false, 0},
+		{3, 0, false, 0},
+		{3, 1, false, 0},
+		{3, 2, true, 30 * time.Second},
+		{3, 3, true, time.Minute},
+		{3, 4, true, 2 * time.Minute},
+		{3, 8, true, 32 * time.Minute},
+		// 64 minutes, clamped to LockoutMaxDelay
+		{3, 9, true, time.Hour},
+		// 30s << 31 overflows
+		{3, 33, true, time.Hour},
+		// Shift is >= 32
+		{3, 50, true, time.Hour},
+	}
+	ctx := newTestContext(t, "192.0.2.1:1234")
+	defer testApp.CloseContext(ctx)
+	c := ctx.Cache()
+	c.Flush()
+	for ii, v := range cases {
+		key := fmt.Sprintf("test-lockout-%d", ii)
+		if v.previous > 0 {
+			c.Set(key, &lockout{Failures: v.previous}, 0)
+		}
+		recordLockoutFailure(ctx, key, v.threshold)
+		if locked := isLocked(ctx, key, v.threshold); locked != v.locked {
+			t.Errorf("%d: expecting locked = %v, got %v", ii, v.locked, locked)
+		}
+		if v.threshold <= 0 {
+			continue
+		}
+		failures, delay := lockoutDelay(t, c, key)
+		if failures != v.previous+1 {
+			t.Errorf("%d: expecting %d failures, got %d", ii, v.previous+1, failures)
+		}
+		// Until is stored with second precision
+		if delay < v.delay-time.Second || delay > v.delay+time.Second {
+			t.Errorf("%d: expecting delay %s, got %s", ii, v.delay, delay)
+		}
+	}
+}
+
+func TestLockoutMaxDelay(t *testing.T) {
+	defer func(delay, maxDelay time.Duration) {
+		LockoutDelay = delay
+		LockoutMaxDelay = maxDelay
+	}(LockoutDelay, LockoutMaxDelay)
+	LockoutDelay = time.Minute
+	LockoutMaxDelay = 90 * time.Second
+	ctx := newTestContext(t, "192.0.2.1:1234")
+	defer testApp.CloseContext(ctx)
+	ctx.Cache().Flush()
+	const key = "test-lockout-max-delay"
+	var delays []float64
+	for ii := 0; ii < 3; ii++ {
+		recordLockoutFailure(ctx, key, 1)
+		_, delay := lockoutDelay(t, ctx.Cache(), key)
+		delays = append(delays, delay.Seconds())
+	}
+	expect := []float64{60, 90, 90}
+	for ii, v := range expect {
+		if d := delays[ii]; d < v-1 || d > v+1 {
+			t.Errorf("failure %d: expecting delay %vs, got %vs", ii+1, v, d)
+		}
+	}
+}
+
+func TestLockoutKeys(t *testing.T) {
+	defer func(threshold, ipThreshold int) {
+		LockoutThreshold = threshold
+		LockoutIPThreshold = ipThreshold
+	}(LockoutThreshold, LockoutIPThreshold)
+	LockoutThreshold = 2
+	LockoutIPThreshold = 3
+	ctx := newTestContext(t, "192.0.2.2:1234")
+	defer testApp.CloseContext(ctx)
+	other := newTestContext(t, "192.0.2.3:1234")
+	defer testApp.CloseContext(other)
+	ctx.Cache().Flush()
+	const userId = 1001
+	// Failures without a user only count for the IP
+	recordFailure(ctx, 0)
+	if isLockedOut(ctx, userId) {
+		t.Error("expecting no lockout after 1 failure")
+	}
+	// This locks the account, but not the IP
+	recordFailure(ctx, userId)
+	recordFailure(other, userId)
+	if !isLockedOut(ctx, userId) || !isLockedOut(other, userId) {
+		t.Error("expecting account to be locked from any IP")
+	}
+	if isLockedOut(ctx, 0) {
+		t.Error("expecting IP not to be locked after 2 failures")
+	}
+	recordFailure(ctx, 0)
+	if !isLockedOut(ctx, 0) {
+		t.Error("expecting IP to be locked after 3 failures")
+	}
+	if isLockedOut(other, 0) {
+		t.Error("expecting other IP not to be locked")
+	}
+	// Clearing the failures unlocks both the account and the IP
+	clearFailures(ctx, userId)
+	if isLockedOut(ctx, userId) || isLockedOut(other, userId) {
+		t.Error("expecting account and IP to be unlocked after clearing failures")
+	}
+	// Without clearing the failures from the other IP
+	recordFailure(other, 0)
+	recordFailure(other, 0)
+	if !isLockedOut(other, 0) {
+		t.Error("expecting other IP to keep its failures")
+	}
+}
@@ -0,0 +1,298 @@
+package users
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/net/oauth2"
+	"gnd.la/net/oidc"
+	"gnd.la/orm"
+	"gnd.la/util/stringutil"
+)
+
+const (
+	// SignInOIDCHandlerName is the prefix for the sign in handler names
+	// of the OpenID Connect providers. The handler for each provider is
+	// named SignInOIDCHandlerName + "-" + lowercase(provider name).
+	SignInOIDCHandlerName = "users-sign-in-oidc"
+
+	oidcStateCookieName    = "state"
+	oidcNonceCookieName    = "nonce"
+	oidcVerifierCookieName = "verifier"
+	oidcRedirCookieName    = "redir"
+)
+
+var (
+	oidcProviderNameRe = regexp.MustCompile("^[A-Za-z0-9]+$")
+	oidcAccountType    = reflect.TypeOf(OIDCAccount{})
+	registerOIDCModel  sync.Once
+)
+
+// OIDCProvider represents an OpenID Connect provider which users
+// can sign in with. See RegisterOIDCProvider.
+type OIDCProvider struct {
+	// Name is the provider name, which is displayed in the sign in
+	// button and must be unique among all the social sign in types
+	// (it can't be e.g. "Google"). It can only contain ASCII letters
+	// and digits.
+	Name string
+	// Provider is the OpenID Connect provider. See oidc.New.
+	Provider *oidc.Provider
+	// ClassName is the class name for the sign in button. If empty,
+	// it defaults to the lowercase provider name.
+	ClassName string
+	// IconName is the icon for the sign in button. If empty, it
+	// defaults to "sign-in".
+	IconName string
+	// UsernameClaim is the claim used for generating the username
+	// when a new user is created. If empty, it defaults to
+	// "preferred_username". If the token doesn't include the
+	// claim, the local part of the email is used.
+	UsernameClaim string
+	// EmailClaim is the claim which contains the user email. If
+	// empty, it defaults to "email".
+	EmailClaim string
+	// PictureClaim is the claim which contains the user picture URL.
+	// If empty, it defaults to "picture".
+	PictureClaim string
+	// TrustEmail indicates if the emails returned by this provider
+	// can be trusted without the email_verified claim. Accounts are
+	// only linked to existing users with the same email address when
+	// the email is verified or TrustEmail is true. Otherwise, a new user
+	// is created without an email.
+	TrustEmail bool
+	// MapUser, if non-nil, is called with the user (a pointer to the
+	// type set with SetType) and the ID token every time a user signs
+	// in with this provider, before saving the user. It can be used for
+	// mapping additional claims to the user fields. If it returns an
+	// error, the sign in fails with it.
+	MapUser func(ctx *app.Context, user reflect.Value, token *oidc.IDToken) error
+}
+
+// HandlerName returns the name of the sign in handler for
+// this provider, which can be reversed to obtain its URL.
+func (p *OIDCProvider) HandlerName() string {
+	return SignInOIDCHandlerName + "-" + strings.ToLower(p.Name)
+}
+
+func (p *OIDCProvider) claim(name string, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
+
+func (p *OIDCProvider) cookieName(name string) string {
+	return "oidc-" + strings.ToLower(p.Name) + "-" + name
+}
+
+// OIDCAccount links an OpenID Connect account to a user. It's
+// registered with the ORM by RegisterOIDCProvider.
+type OIDCAccount struct {
+	Provider string
+	Subject  string
+	UserId   int64  `orm:",index"`
+	Email    string `orm:",omitempty,nullempty"`
+	Updated  time.Time
+}
+
+// RegisterOIDCProvider adds a new OpenID Connect provider that users can
+// sign in with. Its sign in button is displayed after the ones in SocialOrder
+// (which it's appended to), and its sign in handler is added to the users App.
+// The accounts are linked to the users via the OIDCAccount model, so no
+// additional fields are required in the user type. This function
+// should be called from an init function.
+func RegisterOIDCProvider(p *OIDCProvider) {
+	if !oidcProviderNameRe.MatchString(p.Name) {
+		panic(fmt.Errorf("invalid OpenID Connect provider name %q", p.Name))
+	}
+	if p.Provider == nil {
+		panic(fmt.Errorf("OpenID Connect provider %s has no oidc.Provider", p.Name))
+	}
+	if _, ok := socialTypesByName[p.Name]; ok {
+		panic(fmt.Errorf("duplicate social type %s", p.Name))
+	}
+	registerOIDCModel.Do(func() {
+		orm.Register(&OIDCAccount{}, &orm.Options{
+			Table:      "users_oidc_account",
+			PrimaryKey: []string{"Provider", "Subject"},
+		})
+	})
+	className := p.ClassName
+	if className == "" {
+		className = strings.ToLower(p.Name)
+	}
+	iconName := p.IconName
+	if iconName == "" {
+		iconName = "sign-in"
+	}
+	socialTypesByName[p.Name] = &socialType{
+		Name:        p.Name,
+		ClassName:   className,
+		HandlerName: p.HandlerName(),
+		IconName:    iconName,
+		oidc:        p,
+	}
+	SocialOrder = append(SocialOrder, p.Name)
+	pattern := "^/sign-in/oidc/" + strings.ToLower(p.Name) + "/$"
+	App.HandleNamed(pattern, app.Anonymous(oidcSignInHandler(p)), p.HandlerName())
+}
+
+func oidcSignInHandler(p *OIDCProvider) app.Handler {
+	return func(ctx *app.Context) {
+		provider := p.Provider.Clone(ctx)
+		cookies := ctx.Cookies()
+		code := ctx.FormValue(oauth2.Code)
+		if code == "" {
+			if ctx.FormValue("error") != "" {
+				// User denied the authorization
+				ctx.MustRedirectReverse(false, app.SignInHandlerName)
+				return
+			}
+			state := stringutil.Random(32)
+			nonce := stringutil.Random(32)
+			verifier := oauth2.NewVerifier()
+			redir := ctx.URL().String()
+			auth, err := provider.Authorization(redir, state, nonce, verifier)
+			if err != nil {
+				panic(err)
+			}
+			cookies.Set(p.cookieName(oidcStateCookieName), state)
+			cookies.Set(p.cookieName(oidcNonceCookieName), nonce)
+			cookies.Set(p.cookieName(oidcVerifierCookieName), verifier)
+			cookies.Set(p.cookieName(oidcRedirCookieName), redir)
+			ctx.Redirect(auth, false)
+			return
+		}
+		var state, nonce, verifier, redir string
+		for _, v := range []struct {
+			name string
+			dst  *string
+		}{
+			{oidcStateCookieName, &state},
+			{oidcNonceCookieName, &nonce},
+			{oidcVerifierCookieName, &verifier},
+			{oidcRedirCookieName, &redir},
+		} {
+			name := p.cookieName(v.name)
+			cookies.Get(name, v.dst)
+			cookies.Delete(name)
+		}
+		if state == "" || ctx.FormValue("state") != state || nonce == "" {
+			ctx.Forbidden("invalid state")
+			return
+		}
+		_, idToken, err := provider.Exchange(redir, code, nonce, verifier)
+		if err != nil {
+			panic(err)
+		}
+		user, err := userWithOIDCAccount(ctx, p, idToken)
+		if err != nil {
+			panic(err)
+		}
+		signInAndRedirect(ctx, user, p.Name)
+	}
+}
+
+func oidcUsername(p *OIDCProvider, token *oidc.IDToken, email string) string {
+	username := token.String(p.claim(p.UsernameClaim, "preferred_username"))
+	if username == "" {
+		if at := strings.IndexByte(email, '@'); at > 0 {
+			username = email[:at]
+		}
+	}
+	if username == "" {
+		username = token.String("name")
+	}
+	username = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, username)
+	if username == "" {
+		username = strings.ToLower(p.Name)
+	}
+	return username
+}
+
+func userWithOIDCAccount(ctx *app.Context, p *OIDCProvider, token *oidc.IDToken) (reflect.Value, error) {
+	o := ctx.Orm()
+	tbl := o.TypeTable(oidcAccountType)
+	if tbl == nil {
+		return reflect.Value{}, fmt.Errorf("users.OIDCAccount is not registered with the orm")
+	}
+	email := token.String(p.claim(p.EmailClaim, "email"))
+	picture := token.String(p.claim(p.PictureClaim, "picture"))
+	var account OIDCAccount
+	ok, err := o.Table(tbl).Filter(orm.And(orm.Eq("Provider", p.Name), orm.Eq("Subject", token.Subject))).One(&account)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	var linked bool
+	verified := email != "" && (p.TrustEmail || token.Bool("email_verified"))
+	user, userVal := newEmptyUser()
+	if ok {
+		if ok, err = o.One(ById(account.UserId), userVal); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	if !ok && verified {
+		// Link the account to the user with the same email
+		if ok, err = o.One(ByEmail(email), userVal); err != nil {
+			return reflect.Value{}, err
+		}
+		linked = ok
+	}
+	if !ok {
+		userEmail := email
+		if userEmail != "" && !verified {
+			// Unverified emails are not linked to the user which
+			// already has them, but they can't be assigned to the
+			// new user either.
+			_, otherVal := newEmptyUser()
+			taken, err := o.One(ByEmail(email), otherVal)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if taken {
+				userEmail = ""
+			}
+		}
+		// This is a bit racy, but we'll live with it for now
+		user = newUser(FindFreeUsername(ctx, oidcUsername(p, token, email)))
+		setUserValue(user, "AutomaticUsername", true)
+		setUserValue(user, "Email", userEmail)
+		setUserValue(user, "EmailVerified", verified)
+	}
+	if picture != "" && getUserValue(user, "Image").(string) == "" {
+		image, imageFormat, _ := fetchImage(ctx, picture)
+		setUserValue(user, "Image", image)
+		setUserValue(user, "ImageFormat", imageFormat)
+	}
+	if p.MapUser != nil {
+		if err := p.MapUser(ctx, user, token); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	o.MustSave(user.Interface())
+	account = OIDCAccount{
+		Provider: p.Name,
+		Subject:  token.Subject,
+		UserId:   asGondolaUser(user).Id(),
+		Email:    email,
+		Updated:  time.Now().UTC(),
+	}
+	if _, err := o.Save(&account); err != nil {
+		return reflect.Value{}, err
+	}
+	if linked {
+		audit(ctx, account.UserId, AuditSocialLink, p.Name)
+	}
+	return user, nil
+}
@@ -0,0 +1,236 @@
+package users
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gnd.la/app/tester"
+	"gnd.la/net/oauth2"
+	"gnd.la/net/oidc"
+	"gnd.la/orm"
+	"gnd.la/util/stringutil"
+)
+
+const testOIDCClientId = "client"
+
+// The issuer is set by the tests, once the provider is running.
+var testOIDCProvider = &OIDCProvider{
+	Name:     "TestIdP",
+	Provider: oidc.New("", testOIDCClientId, "secret"),
+}
+
+func init() {
+	RegisterOIDCProvider(testOIDCProvider)
+}
+
+// testOIDCIdP is a minimal in-process OpenID Connect provider.
+// Since the authorization happens in the user's browser, tests
+// call authorize to obtain the redirection back to the app.
+type testOIDCIdP struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	claims    map[string]interface{}
+	nonce     string
+	challenge string
+}
+
+func newTestOIDCIdP(t *testing.T) *testOIDCIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testOIDCIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, map[string]interface{}{
+			"issuer":                                idp.URL,
+			"authorization_endpoint":                idp.URL + "/authorize",
+			"token_endpoint":                        idp.URL + "/token",
+			"jwks_uri":                              idp.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, map[string]interface{}{
+			"keys": []map[string]interface{}{{
+				"kty": "RSA",
+				"use": "sig",
+				"n":   encodeTestSegment(idp.key.N.Bytes()),
+				"e":   encodeTestSegment(big.NewInt(int64(idp.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || oauth2.Challenge(r.FormValue("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		claims := map[string]interface{}{
+			"iss":   idp.URL,
+			"aud":   testOIDCClientId,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"iat":   time.Now().Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		writeTestJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "bearer",
+			"expires_in":   3600,
+			"id_token":     idp.sign(claims),
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	return idp
+}
+
+func (idp *testOIDCIdP) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256"})
+	payload, _ := json.Marshal(claims)
+	signed := encodeTestSegment(header) + "." + encodeTestSegment(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + encodeTestSegment(sig)
+}
+
+// authorize checks the authorization URL the app redirected to and
+// returns the path in the app the provider would redirect back to,
+// with the given state.
+func (idp *testOIDCIdP) authorize(t *testing.T, auth string, state string) string {
+	if !strings.HasPrefix(auth, idp.URL+"/authorize?") {
+		t.Fatalf("expecting a redirect to the provider, got %q", auth)
+	}
+	u, err := url.Parse(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("client_id") != testOIDCClientId || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization parameters %v", q)
+	}
+	idp.nonce = q.Get("nonce")
+	idp.challenge = q.Get("code_challenge")
+	if state == "" {
+		state = q.Get("state")
+	}
+	redir, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := redir.Query()
+	values.Set("code", "code")
+	values.Set("state", state)
+	return redir.Path + "?" + values.Encode()
+}
+
+func encodeTestSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// oidcSignIn goes through the sign in flow with the test provider
+// and returns the Request which handles the redirection back from
+// the provider, with the given state. If state is empty, the one
+// sent by the app is used.
+func oidcSignIn(t *testing.T, tt *tester.Tester, idp *testOIDCIdP, state string) *tester.Request {
+	path := "/sign-in/oidc/" + strings.ToLower(testOIDCProvider.Name) + "/"
+	auth := tt.Get(path, map[string]interface{}{"from": "/welcome/"}).Expect(302).ResponseHeader().Get("Location")
+	return tt.Get(idp.authorize(t, auth, state), nil)
+}
+
+func oidcAccountUser(t *testing.T, subject string) *testUser {
+	ctx := testApp.NewContext(nil)
+	defer testApp.CloseContext(ctx)
+	var account OIDCAccount
+	q := orm.And(orm.Eq("Provider", testOIDCProvider.Name), orm.Eq("Subject", subject))
+	if !ctx.Orm().MustOne(q, &account) {
+		t.Fatalf("no account for subject %q", subject)
+	}
+	var user testUser
+	if !ctx.Orm().MustOne(ById(account.UserId), &user) {
+		t.Fatalf("no user for account %+v", account)
+	}
+	return &user
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	idp := newTestOIDCIdP(t)
+	defer idp.Close()
+	testOIDCProvider.Provider.Issuer = idp.URL
+	tt := newTester(t)
+	// New users are created from the token claims
+	subject := stringutil.Random(8)
+	idp.claims = map[string]interface{}{
+		"sub":                subject,
+		"email":              "oidc-" + subject + "@example.com",
+		"email_verified":     true,
+		"preferred_username": "oidc.user",
+	}
+	oidcSignIn(t, tt, idp, "").Expect(302).ExpectHeader("Location", "/welcome/")
+	user := oidcAccountUser(t, subject)
+	if !strings.HasPrefix(user.Username, "oidcuser") || user.Email != idp.claims["email"] || !user.EmailVerified {
+		t.Errorf("unexpected user %+v", user)
+	}
+	tt.Get("/", nil).ExpectUser(user.Id())
+	// Signing in again uses the same user
+	tt = newTester(t)
+	oidcSignIn(t, tt, idp, "").Expect(302)
+	tt.Get("/", nil).ExpectUser(user.Id())
+	// Accounts with a verified email are linked to the
+	// user with the same email
+	ctx := newTestContext(t, "192.0.2.30:1234")
+	defer testApp.CloseContext(ctx)
+	existing := newTestUser(t, ctx, "oidclinked", "secret", true)
+	subject = stringutil.Random(8)
+	idp.claims = map[string]interface{}{"sub": subject, "email": existing.Email, "email_verified": true}
+	tt = newTester(t)
+	oidcSignIn(t, tt, idp, "").Expect(302)
+	tt.Get("/", nil).ExpectUser(existing.Id())
+	if linked := oidcAccountUser(t, subject); linked.Id() != existing.Id() {
+		t.Errorf("expecting account linked to user %d, got %d", existing.Id(), linked.Id())
+	}
+	events, err := AuditEvents(ctx, existing.Id(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var linkedEvent bool
+	for _, v := range events {
+		linkedEvent = linkedEvent || (v.Type == AuditSocialLink && v.Provider == testOIDCProvider.Name)
+	}
+	if !linkedEvent {
+		t.Errorf("expecting a %s event, got %+v", AuditSocialLink, events)
+	}
+	// Unverified emails create a new user instead, without
+	// the email since it belongs to another user
+	subject = stringutil.Random(8)
+	idp.claims = map[string]interface{}{"sub": subject, "email": existing.Email}
+	tt = newTester(t)
+	oidcSignIn(t, tt, idp, "").Expect(302)
+	if unverified := oidcAccountUser(t, subject); unverified.Id() == existing.Id() || unverified.Email != "" {
+		t.Errorf("expecting a new user without email, got %+v", unverified)
+	}
+	// Invalid states are rejected without signing in
+	tt = newTester(t)
+	oidcSignIn(t, tt, idp, "invalid").Expect(403)
+	tt.Get("/", nil).ExpectUser(0)
+}
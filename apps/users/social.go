@@ -25,9 +25,13 @@ type socialType struct {
 	Popup       bool         // Wheter the JS sign in uses a manual pop-up window
 	PopupWidth  int
 	PopupHeight int
+	oidc        *OIDCProvider // Non-nil for OpenID Connect providers
 }
 
 func (s *socialType) IsEnabled() bool {
+	if s.oidc != nil {
+		return true
+	}
 	val := reflect.ValueOf(s.App)
 	return !val.Elem().IsNil()
 }
@@ -101,6 +105,7 @@ type socialAccount interface {
 }
 
 func userWithSocialAccount(ctx *app.Context, name string, acc socialAccount) (reflect.Value, error) {
+	var linked bool
 	user, userVal := newEmptyUser()
 	ok, err := ctx.Orm().One(orm.Eq(name+".Id", acc.accountId()), userVal)
 	if err != nil {
@@ -141,6 +146,7 @@ func userWithSocialAccount(ctx *app.Context, name string, acc socialAccount) (re
 			}
 			if ok {
 				setUserValue(user, name, acc)
+				linked = true
 			}
 		}
 		if !ok {
@@ -154,6 +160,9 @@ func userWithSocialAccount(ctx *app.Context, name string, acc socialAccount) (re
 		}
 	}
 	ctx.Orm().MustSave(user.Interface())
+	if linked {
+		audit(ctx, asGondolaUser(user).Id(), AuditSocialLink, name)
+	}
 	return user, nil
 }
 
@@ -0,0 +1,77 @@
+package users
+
+import (
+	"fmt"
+	"reflect"
+
+	"gnd.la/app"
+	"gnd.la/net/oauth2"
+)
+
+// ORMTokenStore is a function which can be assigned to TokenStore for
+// storing the tokens in the app ORM. Note that apps using it must call
+// oauth2.RegisterTokenModel from an init function.
+func ORMTokenStore(ctx *app.Context) oauth2.TokenStore {
+	return oauth2.NewORMTokenStore(ctx.Orm().Orm)
+}
+
+// oauth2Client returns the *oauth2.Client for the
+// given social type, or nil if the social type does
+// not use oAuth 2 or it's not enabled.
+func oauth2Client(name string) *oauth2.Client {
+	switch name {
+	case SocialTypeFacebook:
+		if FacebookApp != nil {
+			return FacebookApp.Client
+		}
+	case SocialTypeGoogle:
+		if GoogleApp != nil {
+			return GoogleApp.Client
+		}
+	case SocialTypeGithub:
+		if GithubApp != nil {
+			return GithubApp.Client
+		}
+	}
+	return nil
+}
+
+// storeToken saves the token obtained when the given user signed
+// in with the given social type, if TokenStore has been set.
+func storeToken(ctx *app.Context, user reflect.Value, name string, token *oauth2.Token) error {
+	if TokenStore == nil || token == nil {
+		return nil
+	}
+	return TokenStore(ctx).SaveToken(asGondolaUser(user).Id(), name, token)
+}
+
+// SocialToken returns the oAuth 2 token stored for the given user id and
+// social type (one of SocialTypeFacebook, SocialTypeGoogle or SocialTypeGithub).
+// If the token has expired and it can be refreshed, a new token is requested
+// and stored before returning it. If there's no stored token, nil is returned.
+// Note that TokenStore must be set for this function to work.
+func SocialToken(ctx *app.Context, userId int64, name string) (*oauth2.Token, error) {
+	if TokenStore == nil {
+		return nil, fmt.Errorf("users.TokenStore is not set")
+	}
+	client := oauth2Client(name)
+	if client == nil {
+		return nil, fmt.Errorf("social type %s does not use oAuth 2 or it's not enabled", name)
+	}
+	store := TokenStore(ctx)
+	token, err := store.Token(userId, name)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if token.Expired() && token.Refresh != "" {
+		refreshed, err := client.Clone(ctx).Refresh(token)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.SaveToken(userId, name, refreshed); err != nil {
+			return nil, err
+		}
+		token = refreshed
+	}
+	return token, nil
+}
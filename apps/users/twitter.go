@@ -50,7 +50,7 @@ func signInTwitterUserHandler(ctx *app.Context, twUser *twitter.User, token *twi
 			panic(err)
 		}
 	}
-	windowCallbackHandler(ctx, user, callback)
+	windowCallbackHandler(ctx, user, SocialTypeTwitter, callback)
 }
 
 var signInTwitterHandler = twitter.AuthHandler(TwitterApp, signInTwitterUserHandler)
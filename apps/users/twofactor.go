@@ -0,0 +1,522 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/app/cookies"
+	"gnd.la/commands"
+	"gnd.la/crypto/totp"
+	"gnd.la/encoding/base64"
+	"gnd.la/form"
+	"gnd.la/i18n"
+	"gnd.la/orm"
+)
+
+const (
+	TwoFactorHandlerName      = "users-two-factor"
+	TwoFactorSetupHandlerName = "users-two-factor-setup"
+
+	twoFactorPendingCookieName  = "users-tf-pending"
+	twoFactorRememberCookieName = "users-tf-remember"
+	twoFactorSecretCookieName   = "users-tf-secret"
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var (
+	// TwoFactorTemplateName is the template used for asking the
+	// user for their authentication code while signing in.
+	TwoFactorTemplateName = "two-factor.html"
+	// TwoFactorSetupTemplateName is the template used for enabling
+	// and disabling two-factor authentication.
+	TwoFactorSetupTemplateName = "two-factor-setup.html"
+	// TwoFactorTimeout is the maximum time between entering the
+	// password and entering the authentication code.
+	TwoFactorTimeout = 10 * time.Minute
+	// TwoFactorRememberDuration is the time a device is remembered
+	// for when the user checks "Remember this device".
+	TwoFactorRememberDuration = 30 * 24 * time.Hour
+
+	TwoFactorHandler      = app.NamedHandler(TwoFactorHandlerName, twoFactorHandler)
+	TwoFactorSetupHandler = app.NamedHandler(TwoFactorSetupHandlerName, app.SignedIn(twoFactorSetupHandler))
+
+	twoFactorEnabled  bool
+	registerTwoFactor sync.Once
+)
+
+// TwoFactor contains the two-factor authentication settings for a user.
+// Users with a TwoFactor must enter a TOTP code generated by their
+// authenticator app (or one of their recovery codes) after their password
+// or social sign in. It's registered with the ORM by EnableTwoFactor.
+type TwoFactor struct {
+	UserId int64 `orm:",primary_key"`
+	// Secret is the TOTP secret, encrypted with the app
+	// encryption key.
+	Secret string
+	// RecoveryCodes are the SHA-256 hashes of the recovery
+	// codes which haven't been used yet, hex encoded.
+	RecoveryCodes []string `orm:",codec=json"`
+	// LastCounter is the TOTP counter of the last accepted code,
+	// used for preventing the same code from being used twice.
+	LastCounter int64
+	// Created is truncated to seconds, since that's the
+	// precision used for identifying remembered devices.
+	Created time.Time
+}
+
+type twoFactorPending struct {
+	UserId   int64
+	Started  int64
+	Provider string
+	Callback string
+}
+
+// twoFactorRemember identifies a remembered device. Created is the Unix
+// time (in seconds) of the TwoFactor creation, so devices remembered
+// before disabling and re-enabling two-factor authentication are forgotten.
+type twoFactorRemember struct {
+	UserId  int64
+	Created int64
+}
+
+// EnableTwoFactor allows users to enable two-factor authentication
+// from the handler named TwoFactorSetupHandlerName, registering the
+// TwoFactor model with the ORM and a command for resetting a user's
+// two-factor authentication:
+//
+//  ./myapp users-reset-two-factor <username, email or id>
+//
+// The app must have an encryption key. This function should be called
+// from an init function.
+func EnableTwoFactor() {
+	registerTwoFactor.Do(func() {
+		orm.Register(&TwoFactor{}, &orm.Options{
+			Table: "users_two_factor",
+		})
+		commands.MustRegister(resetTwoFactorCommand, &commands.Options{
+			Name:  "users-reset-two-factor",
+			Help:  "Disables two-factor authentication for the given user",
+			Usage: "<username, email or id>",
+		})
+		twoFactorEnabled = true
+	})
+}
+
+// GetTwoFactor returns the TwoFactor for the given user id, or
+// nil if the user hasn't enabled two-factor authentication.
+func GetTwoFactor(ctx *app.Context, userId int64) (*TwoFactor, error) {
+	if !twoFactorEnabled {
+		return nil, nil
+	}
+	var tf TwoFactor
+	ok, err := ctx.Orm().One(orm.Eq("UserId", userId), &tf)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// ResetTwoFactor disables two-factor authentication for the given user,
+// removing their secret and recovery codes. It also invalidates any
+// remembered devices. It's intended to be used by administrators when
+// a user has lost access to their authenticator app and recovery codes.
+func ResetTwoFactor(ctx *app.Context, userId int64) error {
+	if !twoFactorEnabled {
+		return nil
+	}
+	return ctx.Orm().Delete(&TwoFactor{UserId: userId})
+}
+
+func resetTwoFactorCommand(ctx *app.Context) {
+	var arg string
+	ctx.MustParseIndexValue(0, &arg)
+	_, userVal := newEmptyUser()
+	o := ctx.Orm()
+	ok := o.MustOne(ByUsername(arg), userVal)
+	if !ok {
+		ok = o.MustOne(ByEmail(arg), userVal)
+	}
+	if !ok {
+		if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			ok = o.MustOne(ById(id), userVal)
+		}
+	}
+	if !ok {
+		commands.Errorf("no user matches %q", arg)
+	}
+	if err := ResetTwoFactor(ctx, asGondolaUser(reflect.ValueOf(userVal)).Id()); err != nil {
+		panic(err)
+	}
+}
+
+func (tf *TwoFactor) secret(ctx *app.Context) (string, error) {
+	data, err := base64.Decode(tf.Secret)
+	if err != nil {
+		return "", err
+	}
+	enc, err := ctx.App().Encrypter()
+	if err != nil {
+		return "", err
+	}
+	secret, err := enc.Decrypt(data)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func (tf *TwoFactor) setSecret(ctx *app.Context, secret string) error {
+	enc, err := ctx.App().Encrypter()
+	if err != nil {
+		return err
+	}
+	data, err := enc.Encrypt([]byte(secret))
+	if err != nil {
+		return err
+	}
+	tf.Secret = base64.Encode(data)
+	return nil
+}
+
+// verify checks the given code, which might be either a TOTP code or
+// an unused recovery code. Accepted codes can't be used again.
+func (tf *TwoFactor) verify(ctx *app.Context, code string) (bool, error) {
+	secret, err := tf.secret(ctx)
+	if err != nil {
+		return false, err
+	}
+	counter, err := totp.Validate(secret, code, time.Now(), 1)
+	if err == nil {
+		if counter <= tf.LastCounter {
+			return false, nil
+		}
+		tf.LastCounter = counter
+		_, err = ctx.Orm().Save(tf)
+		return err == nil, err
+	}
+	if err != totp.ErrInvalidCode {
+		return false, err
+	}
+	hash := hashRecoveryCode(code)
+	for ii, v := range tf.RecoveryCodes {
+		if v == hash {
+			tf.RecoveryCodes = append(tf.RecoveryCodes[:ii], tf.RecoveryCodes[ii+1:]...)
+			_, err = ctx.Orm().Save(tf)
+			return err == nil, err
+		}
+	}
+	return false, nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code))
+	h := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(h[:])
+}
+
+// newRecoveryCodes returns a new set of recovery codes, as
+// well as their hashes.
+func newRecoveryCodes() ([]string, []string, error) {
+	var codes []string
+	var hashes []string
+	b := make([]byte, recoveryCodeLength)
+	for ii := 0; ii < recoveryCodeCount; ii++ {
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		for jj, v := range b {
+			b[jj] = recoveryCodeAlphabet[int(v)%len(recoveryCodeAlphabet)]
+		}
+		code := string(b[:recoveryCodeLength/2]) + "-" + string(b[recoveryCodeLength/2:])
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func twoFactorCookieOptions(ctx *app.Context, expires time.Time) *cookies.Options {
+	opts := *cookies.Defaults()
+	if o := ctx.App().CookieOptions; o != nil {
+		opts = *o
+	}
+	opts.Expires = expires
+	opts.MaxAge = 0
+	opts.HttpOnly = true
+	return &opts
+}
+
+// isRemembered returns true iff the current device was remembered
+// by the user after entering their authentication code.
+func isRemembered(ctx *app.Context, tf *TwoFactor) bool {
+	var r twoFactorRemember
+	if err := ctx.Cookies().GetEncrypted(twoFactorRememberCookieName, &r); err != nil {
+		return false
+	}
+	return r.UserId == tf.UserId && r.Created == tf.Created.Unix()
+}
+
+func rememberDevice(ctx *app.Context, tf *TwoFactor) error {
+	r := &twoFactorRemember{UserId: tf.UserId, Created: tf.Created.Unix()}
+	opts := twoFactorCookieOptions(ctx, time.Now().Add(TwoFactorRememberDuration))
+	return ctx.Cookies().SetEncryptedOpts(twoFactorRememberCookieName, r, opts)
+}
+
+// beginSignIn signs in the given user, unless they have enabled two-factor
+// authentication and the current device hasn't been remembered. In that
+// case, the sign in is left pending and the URL of the page which asks
+// for the authentication code is returned. Provider is the social type
+// name for social sign ins, and it's recorded in the audit log. If callback
+// is non-empty, the sign in was started from a popup window and the callback
+// is called after the user enters the code (see windowCallbackHandler).
+func beginSignIn(ctx *app.Context, user reflect.Value, provider string, callback string) string {
+	u := asGondolaUser(user)
+	tf, err := GetTwoFactor(ctx, u.Id())
+	if err != nil {
+		panic(err)
+	}
+	if tf == nil || isRemembered(ctx, tf) {
+		ctx.MustSignIn(u)
+		audit(ctx, u.Id(), AuditSignIn, provider)
+		return ""
+	}
+	pending := &twoFactorPending{
+		UserId:   u.Id(),
+		Started:  time.Now().Unix(),
+		Provider: provider,
+		Callback: callback,
+	}
+	opts := twoFactorCookieOptions(ctx, time.Time{})
+	if err := ctx.Cookies().SetEncryptedOpts(twoFactorPendingCookieName, pending, opts); err != nil {
+		panic(err)
+	}
+	verify := ctx.MustReverse(TwoFactorHandlerName)
+	if from := ctx.FormValue(app.SignInFromParameterName); from != "" {
+		verify += "?" + app.SignInFromParameterName + "=" + url.QueryEscape(from)
+	}
+	return verify
+}
+
+// signInAndRedirect works like beginSignIn, but redirects either to the
+// page which asks for the authentication code or to the page the
+// user was at before starting the sign in.
+func signInAndRedirect(ctx *app.Context, user reflect.Value, provider string) {
+	if verify := beginSignIn(ctx, user, provider, ""); verify != "" {
+		ctx.Redirect(verify, false)
+		return
+	}
+	redirectToFrom(ctx)
+}
+
+// writeSignedIn is used by the JS sign in handlers. If the user needs
+// to enter an authentication code, it writes a JSON object with the URL
+// of the page which asks for it in the "redirect" key. Otherwise, it
+// writes the JSON encoded user.
+func writeSignedIn(ctx *app.Context, user reflect.Value, provider string) {
+	if verify := beginSignIn(ctx, user, provider, ""); verify != "" {
+		ctx.WriteJSON(map[string]interface{}{"redirect": verify})
+		return
+	}
+	writeJSONEncoded(ctx, user)
+}
+
+func twoFactorHandler(ctx *app.Context) {
+	if !twoFactorEnabled {
+		ctx.NotFound("")
+		return
+	}
+	var pending twoFactorPending
+	if err := ctx.Cookies().GetEncrypted(twoFactorPendingCookieName, &pending); err != nil ||
+		time.Since(time.Unix(pending.Started, 0)) > TwoFactorTimeout {
+		ctx.MustRedirectReverse(false, app.SignInHandlerName)
+		return
+	}
+	user, userVal := newEmptyUser()
+	if !ctx.Orm().MustOne(ById(pending.UserId), userVal) {
+		ctx.Cookies().Delete(twoFactorPendingCookieName)
+		ctx.MustRedirectReverse(false, app.SignInHandlerName)
+		return
+	}
+	tf, err := GetTwoFactor(ctx, pending.UserId)
+	if err != nil {
+		panic(err)
+	}
+	var fields struct {
+		Code         string `form:",singleline,label=Authentication code,help=Enter the code from your authenticator app or one of your recovery codes."`
+		Remember     bool   `form:",optional,label=Remember this device"`
+		From         string `form:",optional,hidden"`
+		ValidateCode func(*app.Context) error
+	}
+	fields.From = ctx.FormValue(app.SignInFromParameterName)
+	fields.ValidateCode = func(c *app.Context) error {
+		if isLockedOut(c, pending.UserId) {
+			return ErrLockedOut
+		}
+		ok, err := tf.verify(c, fields.Code)
+		if err != nil {
+			panic(err)
+		}
+		if !ok {
+			recordFailure(c, pending.UserId)
+			audit(c, pending.UserId, AuditSignInFailed, pending.Provider)
+			return i18n.Errorf("invalid authentication code")
+		}
+		clearFailures(c, pending.UserId)
+		return nil
+	}
+	f := form.New(ctx, &fields)
+	// tf might be nil if two-factor authentication was
+	// reset after the user entered their password.
+	if tf == nil || (f.Submitted() && f.IsValid()) {
+		ctx.Cookies().Delete(twoFactorPendingCookieName)
+		if tf != nil && fields.Remember {
+			if err := rememberDevice(ctx, tf); err != nil {
+				panic(err)
+			}
+		}
+		ctx.MustSignIn(asGondolaUser(user))
+		audit(ctx, pending.UserId, AuditSignIn, pending.Provider)
+		if pending.Callback != "" {
+			writeWindowCallback(ctx, user, pending.Callback)
+			return
+		}
+		ctx.RedirectBack()
+		return
+	}
+	data := map[string]interface{}{
+		"User":          user,
+		"TwoFactorForm": f,
+	}
+	ctx.MustExecute(TwoFactorTemplateName, data)
+}
+
+func twoFactorSetupHandler(ctx *app.Context) {
+	if !twoFactorEnabled {
+		ctx.NotFound("")
+		return
+	}
+	user := reflect.ValueOf(ctx.User())
+	userId := asGondolaUser(user).Id()
+	tf, err := GetTwoFactor(ctx, userId)
+	if err != nil {
+		panic(err)
+	}
+	var fields struct {
+		Code         string `form:",singleline,label=Authentication code"`
+		ValidateCode func(*app.Context) error
+	}
+	data := map[string]interface{}{}
+	if tf != nil {
+		// Disabling 2FA or generating new recovery codes
+		// requires a valid code.
+		fields.ValidateCode = func(c *app.Context) error {
+			ok, err := tf.verify(c, fields.Code)
+			if err != nil {
+				panic(err)
+			}
+			if !ok {
+				return i18n.Errorf("invalid authentication code")
+			}
+			return nil
+		}
+		f := form.New(ctx, &fields)
+		if f.Submitted() && f.IsValid() {
+			switch ctx.FormValue("action") {
+			case "disable":
+				if err := ResetTwoFactor(ctx, userId); err != nil {
+					panic(err)
+				}
+				ctx.MustRedirectReverse(false, TwoFactorSetupHandlerName)
+				return
+			case "recovery":
+				codes, hashes, err := newRecoveryCodes()
+				if err != nil {
+					panic(err)
+				}
+				tf.RecoveryCodes = hashes
+				ctx.Orm().MustSave(tf)
+				data["RecoveryCodes"] = codes
+			}
+		}
+		data["Enabled"] = true
+		data["RemainingCodes"] = len(tf.RecoveryCodes)
+		data["TwoFactorForm"] = f
+	} else {
+		// The secret is kept in a cookie until the user
+		// enters a valid code, confirming that their app
+		// has been correctly configured.
+		var secret string
+		cookies := ctx.Cookies()
+		if err := cookies.GetEncrypted(twoFactorSecretCookieName, &secret); err != nil || secret == "" {
+			secret, err = totp.NewSecret()
+			if err != nil {
+				panic(err)
+			}
+			if err := cookies.SetEncryptedOpts(twoFactorSecretCookieName, secret, twoFactorCookieOptions(ctx, time.Time{})); err != nil {
+				panic(err)
+			}
+		}
+		var counter int64
+		fields.ValidateCode = func(c *app.Context) error {
+			var err error
+			counter, err = totp.Validate(secret, fields.Code, time.Now(), 1)
+			if err != nil {
+				return i18n.Errorf("invalid authentication code, please check your authenticator app")
+			}
+			return nil
+		}
+		f := form.New(ctx, &fields)
+		if f.Submitted() && f.IsValid() {
+			codes, hashes, err := newRecoveryCodes()
+			if err != nil {
+				panic(err)
+			}
+			tf = &TwoFactor{
+				UserId:        userId,
+				RecoveryCodes: hashes,
+				LastCounter:   counter,
+				Created:       time.Now().UTC().Truncate(time.Second),
+			}
+			if err := tf.setSecret(ctx, secret); err != nil {
+				panic(err)
+			}
+			ctx.Orm().MustInsert(tf)
+			cookies.Delete(twoFactorSecretCookieName)
+			// Remember the device the user just
+			// enabled 2FA from.
+			if err := rememberDevice(ctx, tf); err != nil {
+				panic(err)
+			}
+			data["Enabled"] = true
+			data["RecoveryCodes"] = codes
+		} else {
+			account := getUserValue(user, "Email").(string)
+			if account == "" {
+				account = getUserValue(user, "Username").(string)
+			}
+			issuer := SiteName
+			if issuer == "" {
+				issuer = ctx.R.Host
+			}
+			data["Secret"] = secret
+			data["ProvisioningURI"] = totp.ProvisioningURI(secret, issuer, account)
+			data["TwoFactorForm"] = f
+		}
+	}
+	ctx.MustExecute(TwoFactorSetupTemplateName, data)
+}
@@ -0,0 +1,137 @@
+package users
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gnd.la/app/tester"
+	"gnd.la/crypto/totp"
+	"gnd.la/html"
+)
+
+func init() {
+	EnableTwoFactor()
+}
+
+// htmlTexts returns the text of the elements with the
+// given class in the response to the given request.
+func htmlTexts(r *tester.Request, class string) []string {
+	var texts []string
+	r.HTML().Walk(func(n *html.Node) bool {
+		if n.Type == html.TAG_NODE && n.Attr("class") == class {
+			texts = append(texts, strings.TrimSpace(n.Text()))
+		}
+		return true
+	})
+	return texts
+}
+
+// signIn starts the sign in for the given user and returns
+// the JSON response from writeSignedIn.
+func signIn(tt *tester.Tester, user *testUser) *tester.Request {
+	return tt.Get("/sign-in/", map[string]interface{}{"u": user.Id()}).Expect(200)
+}
+
+// counterCode returns the TOTP code for the given counter.
+func counterCode(t *testing.T, secret string, counter int64) string {
+	code, err := totp.Code(secret, time.Unix(counter*30, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestTwoFactor(t *testing.T) {
+	tt := newTester(t)
+	ctx := newTestContext(t, "192.0.2.30:1234")
+	defer testApp.CloseContext(ctx)
+	ctx.Cache().Flush()
+	user := newTestUser(t, ctx, "twofactor", "secret", true)
+	// Users without two-factor authentication are signed in right away
+	signIn(tt, user).ExpectJSON("username", user.Username).ExpectUser(user.Id())
+	// Setting up two-factor authentication requires a valid code
+	page := tt.Get("/two-factor/setup/", nil).Expect(200)
+	secrets := htmlTexts(page, "secret")
+	if len(secrets) != 1 || secrets[0] == "" {
+		t.Fatalf("expecting a secret, got %v", secrets)
+	}
+	secret := secrets[0]
+	tt.SubmitForm("/two-factor/setup/", map[string]interface{}{"code": "000000"}).Expect(200)
+	if tf, err := GetTwoFactor(ctx, user.Id()); err != nil || tf != nil {
+		t.Fatalf("expecting no TwoFactor after an invalid code, got %v (error %v)", tf, err)
+	}
+	code, err := totp.Code(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabled := tt.SubmitForm("/two-factor/setup/", map[string]interface{}{"code": code}).Expect(200)
+	recovery := htmlTexts(enabled, "recovery-code")
+	if len(recovery) != recoveryCodeCount {
+		t.Fatalf("expecting %d recovery codes, got %v", recoveryCodeCount, recovery)
+	}
+	tf, err := GetTwoFactor(ctx, user.Id())
+	if err != nil || tf == nil {
+		t.Fatalf("expecting a TwoFactor, got %v (error %v)", tf, err)
+	}
+	if tf.Created.Nanosecond() != 0 {
+		t.Errorf("expecting Created to be truncated to seconds, got %v", tf.Created)
+	}
+	// The device used for enabling 2FA is remembered
+	signIn(tt, user).ExpectJSON("username", user.Username).ExpectUser(user.Id())
+
+	// Other devices must enter a code
+	other := tt.Client()
+	signIn(other, user).ExpectJSON("redirect", "/two-factor/").ExpectUser(0)
+	other.SubmitForm("/two-factor/", map[string]interface{}{"code": "000000"}).
+		Expect(200).ExpectUser(0)
+	// The code used for enabling 2FA can't be used again
+	other.SubmitForm("/two-factor/", map[string]interface{}{"code": counterCode(t, secret, tf.LastCounter)}).
+		Expect(200).ExpectUser(0)
+	other.SubmitForm("/two-factor/", map[string]interface{}{
+		"code":     counterCode(t, secret, tf.LastCounter+1),
+		"remember": true,
+	}).Expect(302).ExpectUser(user.Id())
+	// The device was remembered, even after reloading the TwoFactor
+	other.Get("/sign-in/", map[string]interface{}{"u": user.Id()}).Expect(200).ExpectJSON("username", user.Username)
+
+	// Recovery codes can be used once
+	lost := tt.Client()
+	signIn(lost, user).ExpectJSON("redirect", "/two-factor/")
+	lost.SubmitForm("/two-factor/", map[string]interface{}{"code": strings.ToUpper(recovery[0])}).
+		Expect(302).ExpectUser(user.Id())
+	lost = tt.Client()
+	signIn(lost, user).ExpectJSON("redirect", "/two-factor/")
+	lost.SubmitForm("/two-factor/", map[string]interface{}{"code": recovery[0]}).
+		Expect(200).ExpectUser(0)
+
+	// Generating new recovery codes invalidates the old ones
+	page = tt.Get("/two-factor/setup/", nil).Expect(200)
+	if remaining := htmlTexts(page, "remaining-codes"); len(remaining) != 1 || remaining[0] != strconv.Itoa(recoveryCodeCount-1) {
+		t.Errorf("expecting %d remaining codes, got %v", recoveryCodeCount-1, remaining)
+	}
+	regenerated := tt.SubmitForm("/two-factor/setup/", map[string]interface{}{"code": recovery[1], "action": "recovery"}).Expect(200)
+	newRecovery := htmlTexts(regenerated, "recovery-code")
+	if len(newRecovery) != recoveryCodeCount {
+		t.Fatalf("expecting %d new recovery codes, got %v", recoveryCodeCount, newRecovery)
+	}
+	tt.SubmitForm("/two-factor/setup/", map[string]interface{}{"code": recovery[2], "action": "disable"}).Expect(200)
+	if tf, err := GetTwoFactor(ctx, user.Id()); err != nil || tf == nil {
+		t.Fatalf("expecting a TwoFactor after disabling it with an old code, got %v (error %v)", tf, err)
+	}
+	// And disabling it removes the TwoFactor
+	tt.SubmitForm("/two-factor/setup/", map[string]interface{}{"code": newRecovery[0], "action": "disable"}).
+		Expect(302)
+	if tf, err := GetTwoFactor(ctx, user.Id()); err != nil || tf != nil {
+		t.Fatalf("expecting no TwoFactor after disabling it, got %v (error %v)", tf, err)
+	}
+	signIn(tt.Client(), user).ExpectJSON("username", user.Username).ExpectUser(user.Id())
+}
+
+func TestTwoFactorSetupSignedOut(t *testing.T) {
+	tt := newTester(t)
+	tt.Get("/two-factor/setup/", nil).Expect(302).ExpectHeader("Location", tester.Contains("/sign-in/"))
+	// Without a pending sign in, users are sent back to the sign in page
+	tt.Get("/two-factor/", nil).Expect(302).ExpectHeader("Location", "/sign-in/")
+}
@@ -87,6 +87,7 @@ type User struct {
 	Created            time.Time         `json:"-" form:"-"`
 	AutomaticUsername  bool              `form:"-" json:"-"`
 	Admin              bool              `form:"-" orm:",default=false" json:"admin"`
+	EmailVerified      bool              `form:"-" orm:",default=false" json:"-"`
 	Image              string            `form:"-" orm:",omitempty,nullempty" json:"-"`
 	ImageFormat        string            `form:"-" orm:",omitempty,nullempty" json:"-"`
 }
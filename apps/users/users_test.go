@@ -0,0 +1,141 @@
+package users
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/config"
+	"gnd.la/crypto/password"
+	"gnd.la/orm"
+	_ "gnd.la/orm/driver/memory"
+	"gnd.la/util/stringutil"
+
+	"gopkgs.com/vfs.v1"
+)
+
+type testUser struct {
+	User
+}
+
+var (
+	testApp   *app.App
+	setupOnce sync.Once
+	testUsers int
+)
+
+func init() {
+	orm.Register(&testUser{}, nil)
+	SetType(&testUser{})
+	EnableAuditLog()
+}
+
+// testTemplates are simplified versions of the templates used
+// by the handlers under test, without any assets nor translations.
+var testTemplates = map[string]string{
+	TwoFactorTemplateName: `<form method="post">{{ .TwoFactorForm.Render }}<button>Verify</button></form>`,
+	TwoFactorSetupTemplateName: `{{ if .RecoveryCodes }}
+		{{ range .RecoveryCodes }}<code class="recovery-code">{{ . }}</code>{{ end }}
+	{{ else if .Enabled }}
+		<p class="remaining-codes">{{ .RemainingCodes }}</p>
+		<form method="post">
+			{{ .TwoFactorForm.Render }}
+			<button name="action" value="recovery">Generate new recovery codes</button>
+			<button name="action" value="disable">Disable</button>
+		</form>
+	{{ else }}
+		<code class="secret">{{ .Secret }}</code>
+		<form method="post">{{ .TwoFactorForm.Render }}<button>Enable</button></form>
+	{{ end }}`,
+	VerifyEmailTemplateName: `{{ if .Sent }}<p class="sent">{{ .Email }}</p>{{ else }}
+		<form method="post">{{ .VerifyEmailForm.Render }}<button>Send</button></form>
+	{{ end }}`,
+	"verify_email.txt": `{{ .URL }}`,
+}
+
+func setupTestApp() {
+	setupOnce.Do(func() {
+		a := app.New()
+		a.Logger = nil
+		a.Config().Secret = stringutil.Random(32)
+		a.Config().EncryptionKey = stringutil.Random(32)
+		a.Config().Database = config.MustParseURL("memory://users")
+		a.Config().Cache = config.MustParseURL("memory://")
+		a.SetUserFunc(Func)
+		files := make(map[string]*vfs.File)
+		for k, v := range testTemplates {
+			files[k] = &vfs.File{Data: []byte(v)}
+		}
+		fs, err := vfs.Map(files)
+		if err != nil {
+			panic(err)
+		}
+		a.SetTemplatesFS(fs)
+		// Signs in the user with the given id, like
+		// the sign in handlers do after checking the
+		// user credentials.
+		a.HandleNamed("^/sign-in/$", func(ctx *app.Context) {
+			var id int64
+			ctx.MustParseFormValue("u", &id)
+			user, userVal := newEmptyUser()
+			if !ctx.Orm().MustOne(ById(id), userVal) {
+				ctx.NotFound("")
+				return
+			}
+			writeSignedIn(ctx, user, "")
+		}, app.SignInHandlerName)
+		a.HandleOptions("^/verify-email/$", VerifyEmailHandler.Handler, VerifyEmailHandler.Options)
+		a.HandleOptions("^/two-factor/$", TwoFactorHandler.Handler, TwoFactorHandler.Options)
+		a.HandleOptions("^/two-factor/setup/$", TwoFactorSetupHandler.Handler, TwoFactorSetupHandler.Options)
+		// Registered by RegisterOIDCProvider in the users App,
+		// which is not included in the test App.
+		a.HandleNamed("^/sign-in/oidc/testidp/$", app.Anonymous(oidcSignInHandler(testOIDCProvider)), testOIDCProvider.HandlerName())
+		if err := a.Prepare(); err != nil {
+			panic(err)
+		}
+		testApp = a
+	})
+}
+
+// newTester returns a Tester for the App used by newTestContext, with
+// the handlers for signing in (including the test OpenID Connect
+// provider), verifying emails and two-factor authentication.
+func newTester(t *testing.T) *tester.Tester {
+	setupTestApp()
+	return tester.New(t, testApp)
+}
+
+// newTestContext returns a context for an App with a memory
+// ORM and cache, for a request originating from remoteAddr.
+func newTestContext(t *testing.T, remoteAddr string) *app.Context {
+	setupTestApp()
+	req, err := http.NewRequest("POST", "/sign-in/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.RemoteAddr = remoteAddr
+	ctx := testApp.NewContext(nil)
+	ctx.R = req
+	return ctx
+}
+
+// newTestUser inserts a new user with the given username prefix. A
+// suffix is added to make it unique, since the ORM is shared by all tests.
+func newTestUser(t *testing.T, ctx *app.Context, prefix string, pw string, verified bool) *testUser {
+	testUsers++
+	username := fmt.Sprintf("%s%d", prefix, testUsers)
+	u := &testUser{User{
+		Username:      username,
+		Email:         username + "@example.com",
+		Password:      password.New(pw),
+		EmailVerified: verified,
+	}}
+	u.Save()
+	if _, err := ctx.Orm().Insert(u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
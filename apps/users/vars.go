@@ -1,6 +1,8 @@
 package users
 
 import (
+	"gnd.la/app"
+	"gnd.la/net/oauth2"
 	"gnd.la/social/facebook"
 	"gnd.la/social/github"
 	"gnd.la/social/google"
@@ -24,5 +26,11 @@ var (
 	// and social accounts will be able to log in.
 	AllowRegistration = true
 
+	// TokenStore, if non-nil, is used to persist the oAuth 2 tokens obtained
+	// when users sign in with Facebook, Google or Github, so the app can keep
+	// accessing those services on behalf of the user (see SocialToken). Use
+	// ORMTokenStore to store them in the app ORM.
+	TokenStore func(ctx *app.Context) oauth2.TokenStore
+
 	SocialOrder = []string{SocialTypeFacebook, SocialTypeTwitter, SocialTypeGoogle, SocialTypeGithub}
 )
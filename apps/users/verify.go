@@ -0,0 +1,123 @@
+package users
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/form"
+	"gnd.la/i18n"
+	"gnd.la/net/mail"
+)
+
+const (
+	VerifyEmailHandlerName = "users-verify-email"
+)
+
+var (
+	// VerifyEmails enables sending an email with a verification link
+	// to the users after they sign up.
+	VerifyEmails = false
+	// RequireVerifiedEmail prevents users from signing in with their
+	// password until they've verified their email. Setting it to true
+	// also enables VerifyEmails.
+	RequireVerifiedEmail = false
+	// EmailVerificationExpiry is the maximum time between sending the
+	// verification email and the user clicking the link in it.
+	EmailVerificationExpiry = 7 * 24 * time.Hour
+	// VerifyEmailTemplateName is the template used for verifying emails
+	// and requesting new verification emails.
+	VerifyEmailTemplateName = "verify-email.html"
+
+	// ErrEmailNotVerified is returned when signing in with an unverified
+	// email and RequireVerifiedEmail is true.
+	ErrEmailNotVerified = i18n.NewError("please, verify your email address before signing in")
+
+	VerifyEmailHandler = app.NamedHandler(VerifyEmailHandlerName, verifyEmailHandler)
+)
+
+func verifyEmails() bool {
+	return VerifyEmails || RequireVerifiedEmail
+}
+
+// verificationSentURL returns the URL of the page which tells
+// the user that a verification email has been sent.
+func verificationSentURL(ctx *app.Context) string {
+	return ctx.MustReverse(VerifyEmailHandlerName) + "?sent=1"
+}
+
+// SendVerificationEmail sends an email with a link for verifying the
+// email address of the given user, which must be a pointer to the type
+// set with SetType. The link is only valid while the user's email
+// remains unchanged, so apps which allow users to change their email
+// should call this function after every change.
+func SendVerificationEmail(ctx *app.Context, user interface{}) error {
+	val := reflect.ValueOf(user)
+	email := getUserValue(val, "Email").(string)
+	if email == "" {
+		return fmt.Errorf("user %d does not have an email", asGondolaUser(val).Id())
+	}
+	extra := url.Values{"e": []string{Normalize(email)}}
+	p, err := encodeUserPayload(ctx, asGondolaUser(val).Id(), payloadActionVerify, extra)
+	if err != nil {
+		return err
+	}
+	data := map[string]interface{}{
+		"URL": absoluteURL(ctx, VerifyEmailHandlerName, p),
+	}
+	msg := &mail.Message{
+		To:      email,
+		From:    mailFrom(ctx),
+		Subject: fmt.Sprintf(ctx.T("Verify your %s email address"), SiteName),
+	}
+	return ctx.SendMail("verify_email.txt", data, msg)
+}
+
+func verifyEmailHandler(ctx *app.Context) {
+	payload := ctx.FormValue("p")
+	data := map[string]interface{}{}
+	if payload != "" {
+		user, qs, err := decodeUserPayload(ctx, payload, payloadActionVerify, EmailVerificationExpiry)
+		valid := err == nil && user.IsValid() && qs.Get("e") == getUserValue(user, "NormalizedEmail")
+		if valid {
+			if !getUserValue(user, "EmailVerified").(bool) {
+				setUserValue(user, "EmailVerified", true)
+				ctx.Orm().MustSave(user.Interface())
+				audit(ctx, asGondolaUser(user).Id(), AuditEmailVerified, "")
+			}
+			data["Done"] = true
+		} else {
+			data["Expired"] = err == errPayloadExpired
+			data["Invalid"] = !valid
+		}
+		ctx.MustExecute(VerifyEmailTemplateName, data)
+		return
+	}
+	// Form for requesting a new verification email. The response
+	// is the same whether or not the address belongs to a user with
+	// an unverified email, so it can't be used for finding out which
+	// addresses are registered.
+	var fields struct {
+		Email string `form:",singleline,label=Email"`
+	}
+	if u := ctx.User(); u != nil {
+		fields.Email = getUserValue(reflect.ValueOf(u), "Email").(string)
+	}
+	f := form.New(ctx, &fields)
+	if f.Submitted() && f.IsValid() {
+		user, userVal := newEmptyUser()
+		if ctx.Orm().MustOne(ByEmail(fields.Email), userVal) && !getUserValue(user, "EmailVerified").(bool) {
+			if err := SendVerificationEmail(ctx, userVal); err != nil {
+				panic(err)
+			}
+		}
+		data["Sent"] = true
+		data["Email"] = fields.Email
+	} else if ctx.FormValue("sent") != "" {
+		data["Sent"] = true
+	}
+	data["VerifyEmailForm"] = f
+	ctx.MustExecute(VerifyEmailTemplateName, data)
+}
@@ -0,0 +1,128 @@
+package users
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"gnd.la/app"
+	"gnd.la/net/mail"
+)
+
+// encodeTestPayload encodes the given values like encodeUserPayload,
+// but allows tests to omit or alter any of them.
+func encodeTestPayload(t *testing.T, ctx *app.Context, values url.Values) string {
+	se, err := ctx.App().EncryptSigner(Salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := se.EncryptSign([]byte(values.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDecodeUserPayload(t *testing.T) {
+	ctx := newTestContext(t, "192.0.2.10:1234")
+	defer testApp.CloseContext(ctx)
+	user := newTestUser(t, ctx, "payload", "secret", false)
+	id := strconv.FormatInt(user.Id(), 36)
+	now := strconv.FormatInt(time.Now().Unix(), 36)
+	old := strconv.FormatInt(time.Now().Add(-2*time.Hour).Unix(), 36)
+	reset, err := encodeUserPayload(ctx, user.Id(), payloadActionReset, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify, err := encodeUserPayload(ctx, user.Id(), payloadActionVerify, url.Values{"e": {"payload@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := encodeTestPayload(t, ctx, url.Values{"u": {id}, "t": {now}})
+	expired := encodeTestPayload(t, ctx, url.Values{"u": {id}, "t": {old}, "a": {payloadActionVerify}})
+	missing := encodeTestPayload(t, ctx, url.Values{"u": {"zzzz"}, "t": {now}, "a": {payloadActionReset}})
+	cases := []struct {
+		name    string
+		payload string
+		action  string
+		err     error
+	}{
+		{"reset", reset, payloadActionReset, nil},
+		{"verify", verify, payloadActionVerify, nil},
+		// Payloads without an action are only valid for resets
+		{"legacy reset", legacy, payloadActionReset, nil},
+		{"legacy verify", legacy, payloadActionVerify, errInvalidPayload},
+		{"reset at verify", reset, payloadActionVerify, errInvalidPayload},
+		{"verify at reset", verify, payloadActionReset, errInvalidPayload},
+		{"expired", expired, payloadActionVerify, errPayloadExpired},
+		{"no such user", missing, payloadActionReset, errNoSuchUser},
+	}
+	for _, v := range cases {
+		u, qs, err := decodeUserPayload(ctx, v.payload, v.action, time.Hour)
+		if err != v.err {
+			t.Errorf("%s: expecting error %v, got %v", v.name, v.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if uid := asGondolaUser(u).Id(); uid != user.Id() {
+			t.Errorf("%s: expecting user %d, got %d", v.name, user.Id(), uid)
+		}
+		if v.action == payloadActionVerify && qs.Get("e") != "payload@example.com" {
+			t.Errorf("%s: expecting e = payload@example.com, got %q", v.name, qs.Get("e"))
+		}
+	}
+	if _, _, err := decodeUserPayload(ctx, reset[:len(reset)-2], payloadActionReset, time.Hour); err == nil {
+		t.Error("expecting an error when decoding a tampered payload")
+	}
+}
+
+func TestSignInRequireVerifiedEmail(t *testing.T) {
+	defer func(require bool) { RequireVerifiedEmail = require }(RequireVerifiedEmail)
+	ctx := newTestContext(t, "192.0.2.11:1234")
+	defer testApp.CloseContext(ctx)
+	ctx.Cache().Flush()
+	unverified := newTestUser(t, ctx, "unverified", "secret", false)
+	verified := newTestUser(t, ctx, "verified", "secret", true)
+	cases := []struct {
+		require  bool
+		user     *testUser
+		password string
+		err      error
+	}{
+		{false, unverified, "secret", nil},
+		{true, unverified, "secret", ErrEmailNotVerified},
+		// Wrong passwords are reported first
+		{true, unverified, "wrong", ErrInvalidPassword},
+		{true, verified, "secret", nil},
+		{true, verified, "wrong", ErrInvalidPassword},
+	}
+	for ii, v := range cases {
+		RequireVerifiedEmail = v.require
+		s := &SignIn{Username: v.user.Username, Password: v.password}
+		if err := s.ValidateUsername(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.ValidatePassword(ctx); err != v.err {
+			t.Errorf("%d: expecting error %v, got %v", ii, v.err, err)
+		}
+	}
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	defer func(server string) { mail.Config.MailServer = server }(mail.Config.MailServer)
+	mail.Config.MailServer = "echo"
+	tt := newTester(t)
+	ctx := newTestContext(t, "192.0.2.12:1234")
+	defer testApp.CloseContext(ctx)
+	unverified := newTestUser(t, ctx, "resend", "secret", false)
+	verified := newTestUser(t, ctx, "resendverified", "secret", true)
+	// The response must not reveal if the address is registered
+	// nor if it has been already verified
+	for _, v := range []string{unverified.Email, verified.Email, "nobody@example.com"} {
+		tt.SubmitForm("/verify-email/", map[string]interface{}{"email": v}).Expect(200).
+			ExpectHTMLText(".sent", v)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"gnd.la/app"
 	"gnd.la/log"
+	"gnd.la/orm/fixtures"
 
 	"gopkgs.com/vfs.v1"
 )
@@ -108,6 +109,68 @@ func renderTemplate(ctx *app.Context) {
 	}
 }
 
+func dumpFixtures(ctx *app.Context) {
+	var f string
+	ctx.ParseParamValue("format", &f)
+	format, err := fixtures.ParseFormat(f)
+	if err != nil {
+		UsageError(err)
+	}
+	var names []string
+	for ii := 0; ii < ctx.Count(); ii++ {
+		names = append(names, ctx.IndexValue(ii))
+	}
+	o := ctx.Orm().Orm
+	tables, err := fixtures.Tables(o, names...)
+	if err != nil {
+		Error(err)
+	}
+	var output string
+	ctx.ParseParamValue("o", &output)
+	if output == "" || output == "-" {
+		if err := fixtures.Dump(o, os.Stdout, format, tables...); err != nil {
+			Error(err)
+		}
+		return
+	}
+	files, err := fixtures.DumpDir(o, output, format, tables...)
+	if err != nil {
+		Error(err)
+	}
+	for _, v := range files {
+		fmt.Println(v)
+	}
+}
+
+func loadFixtures(ctx *app.Context) {
+	var files []string
+	for ii := 0; ii < ctx.Count(); ii++ {
+		files = append(files, ctx.IndexValue(ii))
+	}
+	if len(files) == 0 {
+		UsageError("no fixture files provided")
+	}
+	o := ctx.Orm().Orm
+	var truncate bool
+	ctx.ParseParamValue("truncate", &truncate)
+	if truncate {
+		tables, err := fixtures.Tables(o)
+		if err != nil {
+			Error(err)
+		}
+		if err := fixtures.Truncate(o, tables...); err != nil {
+			Error(err)
+		}
+	}
+	tables, err := fixtures.LoadFiles(o, files...)
+	if err != nil {
+		Error(err)
+	}
+	for _, v := range tables {
+		fmt.Println(v.TableName())
+	}
+}
+
 func init() {
 	Register(catFile, &Options{
 		Help:  "Prints a file from the blobstore to the stdout",
@@ -116,6 +179,19 @@ func init() {
 	Register(makeAssets, &Options{
 		Help: "Pre-compile and bundle all app assets",
 	})
+	Register(dumpFixtures, &Options{
+		Help:  "Dump the objects in the ORM tables, or only the given ones, as fixtures",
+		Usage: "[table]...",
+		Flags: Flags(
+			StringFlag("format", "json", "Fixtures format, either json or yaml"),
+			StringFlag("o", "", "Output directory, writing one file per table. If empty or -, outputs to stdout"),
+		),
+	})
+	Register(loadFixtures, &Options{
+		Help:  "Load fixtures from the given files or directories into the ORM, in reference order",
+		Usage: "<file-or-dir>...",
+		Flags: Flags(BoolFlag("truncate", false, "Remove all the objects from all the tables before loading")),
+	})
 	Register(printResources, &Options{Name: "_print-resources"})
 	Register(renderTemplate, &Options{
 		Name:  "_render-template",
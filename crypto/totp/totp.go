@@ -0,0 +1,145 @@
+// Package totp implements time-based one-time passwords (TOTP), as
+// described in RFC 6238, which are compatible with Google Authenticator
+// and most other authenticator apps.
+//
+// Secrets are represented as base32 encoded strings without padding,
+// which is the format expected by the authenticator apps. To enroll
+// a user, generate a new secret with NewSecret and display the URI
+// returned by ProvisioningURI as a QR code (or the secret itself, for
+// manual input). Then, use Validate to check the codes provided by
+// the user.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the number of digits in the generated codes.
+	Digits = 6
+	// Period is the amount of time each code is valid for.
+	Period = 30 * time.Second
+	// SecretSize is the size in bytes of the secrets generated
+	// by NewSecret, as recommended by RFC 4226.
+	SecretSize = 20
+)
+
+var (
+	// ErrInvalidSecret is returned when the secret can't
+	// be decoded.
+	ErrInvalidSecret = errors.New("invalid TOTP secret")
+	// ErrInvalidCode is returned by Validate when the code
+	// does not match.
+	ErrInvalidCode = errors.New("invalid TOTP code")
+)
+
+// NewSecret returns a new random secret, encoded
+// in base32 without padding.
+func NewSecret() (string, error) {
+	b := make([]byte, SecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(b), "="), nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	if rem := len(s) % 8; rem != 0 {
+		s += strings.Repeat("=", 8-rem)
+	}
+	key, err := base32.StdEncoding.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// HOTP returns the HMAC-based one-time password for the given
+// key and counter, as described in RFC 4226.
+func HOTP(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	mod := uint32(1)
+	for ii := 0; ii < Digits; ii++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+// Counter returns the TOTP counter for the given time.
+func Counter(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code for the given secret at the given time.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return HOTP(key, Counter(t)), nil
+}
+
+// Validate checks the given code against the secret at the given
+// time, also accepting the codes for up to skew periods before and
+// after it, to account for clock drift. If the code is valid, its
+// counter is returned. Callers should store it and reject any codes
+// with a counter lower or equal than the stored one, to prevent the
+// same code from being used twice. If the code is not valid,
+// ErrInvalidCode is returned.
+func Validate(secret string, code string, t time.Time, skew int) (int64, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return 0, err
+	}
+	code = strings.Replace(code, " ", "", -1)
+	if len(code) != Digits {
+		return 0, ErrInvalidCode
+	}
+	counter := Counter(t)
+	for ii := -skew; ii <= skew; ii++ {
+		c := counter + int64(ii)
+		if subtle.ConstantTimeCompare([]byte(HOTP(key, c)), []byte(code)) == 1 {
+			return c, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+// ProvisioningURI returns an otpauth:// URI for the given secret,
+// which can be encoded as a QR code and scanned by an authenticator
+// app. Issuer is usually the site name while account is the user
+// name or email, and both are displayed by the app.
+func ProvisioningURI(secret string, issuer string, account string) string {
+	label := url.QueryEscape(account)
+	if issuer != "" {
+		label = url.QueryEscape(issuer) + ":" + label
+	}
+	values := make(url.Values)
+	values.Set("secret", secret)
+	if issuer != "" {
+		values.Set("issuer", issuer)
+	}
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprintf("%d", Digits))
+	values.Set("period", fmt.Sprintf("%d", int(Period/time.Second)))
+	// Some apps don't decode + as space in the label
+	label = strings.Replace(label, "+", "%20", -1)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
@@ -0,0 +1,84 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Secret from RFC 6238, appendix B, base32 encoded
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 test vectors for SHA1, truncated to 6 digits
+	cases := []struct {
+		ts   int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range cases {
+		code, err := Code(rfcSecret, time.Unix(v.ts, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("expecting code %s at %d, got %s", v.code, v.ts, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	code, err := Code(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter, err := Validate(secret, code, now, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counter != Counter(now) {
+		t.Errorf("expecting counter %d, got %d", Counter(now), counter)
+	}
+	later := now.Add(Period)
+	if _, err := Validate(secret, code, later, 0); err != ErrInvalidCode {
+		t.Errorf("expecting ErrInvalidCode without skew, got %v", err)
+	}
+	if c, err := Validate(secret, code, later, 1); err != nil || c != counter {
+		t.Errorf("expecting counter %d with skew, got %d (%v)", counter, c, err)
+	}
+	if _, err := Validate(secret, "12345", now, 1); err != ErrInvalidCode {
+		t.Errorf("expecting ErrInvalidCode for short code, got %v", err)
+	}
+	if _, err := Validate("not base32!", code, now, 1); err != ErrInvalidSecret {
+		t.Errorf("expecting ErrInvalidSecret, got %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI(rfcSecret, "Gondola Site", "alice@example.com")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("invalid URI %s", uri)
+	}
+	if u.Path != "/Gondola Site:alice@example.com" {
+		t.Errorf("invalid label %q", u.Path)
+	}
+	q := u.Query()
+	if q.Get("secret") != rfcSecret || q.Get("issuer") != "Gondola Site" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("invalid parameters %v", q)
+	}
+}
@@ -0,0 +1,262 @@
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gnd.la/app"
+	"gnd.la/i18n"
+)
+
+// Validator is the interface implemented by types which need to
+// perform validations which involve several fields (e.g. checking
+// that the end date is after the start date or that both passwords
+// match). Validate is called after all the fields have been parsed
+// and validated, and only if none of them had errors. To assign
+// the error to a given field, return a *FieldError.
+type Validator interface {
+	Validate(ctx *app.Context) error
+}
+
+// FieldError is an error which is associated with a field. Validators
+// might return a *FieldError to make the error appear next to the
+// given field rather than as a form level error.
+type FieldError struct {
+	// Field is the name of the field in the struct (e.g. Password
+	// or Shipping.Street).
+	Field string
+	// Err is the error itself. Use an i18n.Error to make it
+	// translatable.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// NewFieldError is a shorthand for returning a *FieldError
+// with the given field and error.
+func NewFieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+// Errors maps the names of the inputs with errors (e.g. email or
+// items.0.name) to their translated error messages. Form level
+// errors (returned from a Validator without using a *FieldError)
+// use an empty key. Errors is intended to be sent to API clients
+// using Context.WriteJSON.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var buf bytes.Buffer
+	for ii, k := range keys {
+		if ii > 0 {
+			buf.WriteString(", ")
+		}
+		if k != "" {
+			buf.WriteString(k)
+			buf.WriteString(": ")
+		}
+		buf.WriteString(e[k])
+	}
+	return buf.String()
+}
+
+// Err returns the form level error returned by the Validator
+// implemented by any of the form values, if any. Note that
+// errors assigned to fields are not returned by this function.
+func (f *Form) Err() error {
+	return f.err
+}
+
+// Errors returns the errors in the form, or nil if there are
+// no errors. See Errors for more information.
+func (f *Form) Errors() Errors {
+	if !f.IsValid() && !f.rowsChanged {
+		errs := make(Errors)
+		for _, v := range f.allFields() {
+			if v.err != nil {
+				errs[v.HTMLName] = v.err.Error()
+			}
+		}
+		if f.err != nil {
+			errs[""] = f.err.Error()
+		}
+		return errs
+	}
+	return nil
+}
+
+func (f *Form) formValue(name string) string {
+	if f.input != nil {
+		return strings.TrimSpace(f.input.Get(name))
+	}
+	return f.ctx.FormValue(name)
+}
+
+// inputNames returns the names of all the input
+// values received by the form.
+func (f *Form) inputNames() []string {
+	values := f.input
+	if values == nil {
+		// Make sure the form is parsed
+		f.ctx.FormValue("")
+		values = f.ctx.R.Form
+	}
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	return names
+}
+
+func (f *Form) runValidators() {
+	for _, v := range f.values {
+		validator, ok := v.Addr().Interface().(Validator)
+		if !ok {
+			continue
+		}
+		err := validator.Validate(f.ctx)
+		if err == nil {
+			continue
+		}
+		if ferr, ok := err.(*FieldError); ok {
+			if field := f.fieldByName(ferr.Field); field != nil {
+				field.err = i18n.TranslatedError(ferr.Err, f.ctx)
+				continue
+			}
+		}
+		f.err = i18n.TranslatedError(err, f.ctx)
+	}
+}
+
+func (f *Form) fieldByName(name string) *Field {
+	for _, v := range f.allFields() {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Bind decodes the request into the given values, which must be
+// pointers to structs, using the same rules as the forms (the same
+// tags, field validation functions and Validator implementations).
+// If the request has a JSON body (either application/json or any
+// other type with the +json suffix), it's decoded using the input
+// names used by forms as keys (e.g. FirstName is read from first_name).
+// Nested structs are read from nested objects, while repeated fields
+// are read from arrays of objects. Since JSON bodies can't include
+// files, required file fields are always reported as errors and
+// optional ones are left empty. Otherwise, the values are read
+// from the request form values.
+//
+// If the input is not valid, the returned error will be of type Errors,
+// which might be sent directly to the client using Context.WriteJSON.
+// Note that Bind does not perform any CSRF checks, so it's intended for
+// API endpoints which perform their own authentication. Use Form for
+// handling forms submitted by browsers.
+func Bind(ctx *app.Context, values ...interface{}) error {
+	var input url.Values
+	isJSON := isJSONRequest(ctx)
+	if isJSON {
+		input = make(url.Values)
+		if ctx.R.Body != nil {
+			dec := json.NewDecoder(ctx.R.Body)
+			dec.UseNumber()
+			var data interface{}
+			if err := dec.Decode(&data); err != nil {
+				return Errors{"": i18n.Errorfc("form", "invalid JSON body: %s", err).TranslatedError(ctx)}
+			}
+			if _, ok := data.(map[string]interface{}); !ok && data != nil {
+				return Errors{"": i18n.Errorfc("form", "JSON body must be an object").TranslatedError(ctx)}
+			}
+			flattenJSON(input, "", data)
+		}
+	} else {
+		if err := ctx.R.ParseMultipartForm(32 << 20); err != nil && err != http.ErrNotMultipart {
+			return Errors{"": i18n.Errorfc("form", "invalid form data: %s", err).TranslatedError(ctx)}
+		}
+		input = ctx.R.Form
+		if input == nil {
+			input = make(url.Values)
+		}
+	}
+	f := newForm(ctx, nil, input, values...)
+	f.DisableCSRF = true
+	f.jsonInput = isJSON
+	if errs := f.Errors(); errs != nil {
+		return errs
+	}
+	return nil
+}
+
+func isJSONRequest(ctx *app.Context) bool {
+	ct := ctx.R.Header.Get("Content-Type")
+	if ct == "" {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json"))
+}
+
+// flattenJSON stores the given decoded JSON value into values,
+// using the same names used by the HTML inputs.
+func flattenJSON(values url.Values, prefix string, data interface{}) {
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+	switch x := data.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			flattenJSON(values, join(k), v)
+		}
+	case []interface{}:
+		if len(x) > 0 {
+			if _, ok := x[0].(map[string]interface{}); ok {
+				// Rows of a repeated field
+				values.Set(prefix+".count", strconv.Itoa(len(x)))
+				for ii, v := range x {
+					flattenJSON(values, join(strconv.Itoa(ii)), v)
+				}
+				break
+			}
+		} else {
+			values.Set(prefix+".count", "0")
+		}
+		for _, v := range x {
+			values.Add(prefix, jsonString(v))
+		}
+	default:
+		values.Add(prefix, jsonString(x))
+	}
+}
+
+func jsonString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,175 @@
+package form
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/i18n"
+)
+
+type bindAddress struct {
+	Street string
+	City   string `form:",optional"`
+}
+
+type bindAccount struct {
+	FirstName string
+	Age       int `form:",optional"`
+	Password  string
+	Confirm   string
+	Address   bindAddress
+}
+
+func (a *bindAccount) Validate(ctx *app.Context) error {
+	if a.Password != a.Confirm {
+		return NewFieldError("Confirm", i18n.Errorf("passwords don't match"))
+	}
+	if a.FirstName == a.Password {
+		return i18n.Errorf("password can't be your name")
+	}
+	return nil
+}
+
+func newBindTester(t *testing.T) *tester.Tester {
+	a := app.New()
+	a.Handle("^/bind/$", func(ctx *app.Context) {
+		var account bindAccount
+		if err := Bind(ctx, &account); err != nil {
+			ctx.WriteHeader(400)
+			ctx.WriteJSON(err)
+			return
+		}
+		ctx.WriteJSON(&account)
+	})
+	return tester.New(t, a)
+}
+
+func TestBind(t *testing.T) {
+	tt := newBindTester(t)
+	const expect = `{"FirstName":"Alberto","Age":30,"Password":"secret","Confirm":"secret","Address":{"Street":"Gran Vía","City":"Madrid"}}`
+	// JSON and urlencoded bodies decode into the same struct
+	tt.Post("/bind/", `{"first_name": "Alberto", "age": 30, "password": "secret", "confirm": "secret",
+		"address": {"street": "Gran Vía", "city": "Madrid"}}`).
+		AddHeader("Content-Type", "application/json; charset=utf-8").Expect(200).Expect(expect)
+	tt.Form("/bind/", map[string]interface{}{
+		"first_name":     "Alberto",
+		"age":            30,
+		"password":       "secret",
+		"confirm":        "secret",
+		"address.street": "Gran Vía",
+		"address.city":   "Madrid",
+	}).Expect(200).Expect(expect)
+	// +json suffix
+	tt.Post("/bind/", `{"first_name": "Alberto", "password": "secret", "confirm": "secret", "address": {"street": "Gran Vía"}}`).
+		AddHeader("Content-Type", "application/vnd.api+json").Expect(200).
+		Expect(`{"FirstName":"Alberto","Age":0,"Password":"secret","Confirm":"secret","Address":{"Street":"Gran Vía","City":""}}`)
+}
+
+func TestBindErrors(t *testing.T) {
+	tt := newBindTester(t)
+	post := func(body string) *tester.Request {
+		return tt.Post("/bind/", body).AddHeader("Content-Type", "application/json")
+	}
+	// Missing required fields, including nested ones
+	post(`{"first_name": "Alberto", "password": "secret", "confirm": "secret"}`).Expect(400).
+		Expect(`{"address.street":"Street is required"}`)
+	post(`{"password": "secret", "confirm": "secret"}`).Expect(400).
+		Expect(`{"address.street":"Street is required","first_name":"First Name is required"}`)
+	post(`{"first_name": "Alberto", "age": "thirty", "password": "secret", "confirm": "secret", "address": {"street": "Gran Vía"}}`).
+		Expect(400).Expect(`{"age":"could not parse \"thirty\": invalid syntax"}`)
+	// Validate assigning the error to a field
+	post(`{"first_name": "Alberto", "password": "secret", "confirm": "other", "address": {"street": "Gran Vía"}}`).
+		Expect(400).Expect(`{"confirm":"passwords don't match"}`)
+	// Form level error returned from Validate
+	post(`{"first_name": "secret", "password": "secret", "confirm": "secret", "address": {"street": "Gran Vía"}}`).
+		Expect(400).Expect(`{"":"password can't be your name"}`)
+	// Invalid bodies
+	post(`{"first_name": `).Expect(400).Contains(`{"":"invalid JSON body: `)
+	post(`["first_name"]`).Expect(400).Expect(`{"":"JSON body must be an object"}`)
+}
+
+func TestBindFiles(t *testing.T) {
+	a := app.New()
+	a.Handle("^/bind/$", func(ctx *app.Context) {
+		var upload struct {
+			Name   string
+			Avatar File `form:",optional"`
+			Resume File
+		}
+		var optional struct {
+			Name   string
+			Avatar File `form:",optional"`
+		}
+		out := &upload
+		if ctx.FormValue("optional") != "" {
+			ctx.WriteJSON(Bind(ctx, &optional))
+			return
+		}
+		ctx.WriteJSON(Bind(ctx, out))
+	})
+	tt := tester.New(t, a)
+	// JSON bodies can't include files, so required file
+	// fields are errors and optional ones are skipped
+	tt.Post("/bind/", `{"name": "Alberto", "resume": "data"}`).AddHeader("Content-Type", "application/json").
+		Expect(200).Expect(`{"resume":"files must be uploaded using multipart/form-data"}`)
+	tt.Post("/bind/?optional=1", `{"name": "Alberto", "avatar": "data"}`).AddHeader("Content-Type", "application/json").
+		Expect(200).Expect(`null`)
+}
+
+func TestErrorsString(t *testing.T) {
+	errs := Errors{"": "form error", "confirm": "no match", "address.street": "required"}
+	if s, expect := errs.Error(), "form error, address.street: required, confirm: no match"; s != expect {
+		t.Errorf("expecting %q, got %q", expect, s)
+	}
+	err := NewFieldError("Confirm", i18n.Errorf("no match"))
+	if s := err.Error(); s != "Confirm: no match" {
+		t.Errorf("expecting %q, got %q", "Confirm: no match", s)
+	}
+}
+
+func TestFlattenJSON(t *testing.T) {
+	cases := []struct {
+		data   string
+		expect url.Values
+	}{
+		{`{"name": "foo", "count": 3, "price": 1.5, "active": true, "extra": null}`, url.Values{
+			"name":   {"foo"},
+			"count":  {"3"},
+			"price":  {"1.5"},
+			"active": {"true"},
+			"extra":  {""},
+		}},
+		{`{"address": {"street": "Gran Vía", "geo": {"lat": 40}}}`, url.Values{
+			"address.street":  {"Gran Vía"},
+			"address.geo.lat": {"40"},
+		}},
+		{`{"tags": ["a", "b"], "none": []}`, url.Values{
+			"tags":       {"a", "b"},
+			"none.count": {"0"},
+		}},
+		{`{"items": [{"name": "one", "qty": 1}, {"name": "two"}]}`, url.Values{
+			"items.count":  {"2"},
+			"items.0.name": {"one"},
+			"items.0.qty":  {"1"},
+			"items.1.name": {"two"},
+		}},
+	}
+	for _, v := range cases {
+		dec := json.NewDecoder(strings.NewReader(v.data))
+		dec.UseNumber()
+		var data interface{}
+		if err := dec.Decode(&data); err != nil {
+			t.Fatal(err)
+		}
+		values := make(url.Values)
+		flattenJSON(values, "", data)
+		if !reflect.DeepEqual(values, v.expect) {
+			t.Errorf("flattening %s: expecting %v, got %v", v.data, v.expect, values)
+		}
+	}
+}
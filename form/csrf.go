@@ -169,8 +169,8 @@ func newCSRF(f *Form) (*csrf, error) {
 	if !f.Submitted() {
 		return c.generate(f.ctx)
 	}
-	c.GondolaCSRFA = f.ctx.FormValue(f.toHTMLName("GondolaCSRFA"))
-	c.GondolaCSRFB = f.ctx.FormValue(f.toHTMLName("GondolaCSRFB"))
-	c.GondolaCSRFC = f.ctx.FormValue(f.toHTMLName("GondolaCSRFC"))
+	c.GondolaCSRFA = f.formValue(f.toHTMLName("GondolaCSRFA"))
+	c.GondolaCSRFB = f.formValue(f.toHTMLName("GondolaCSRFB"))
+	c.GondolaCSRFC = f.formValue(f.toHTMLName("GondolaCSRFC"))
 	return c, nil
 }
@@ -23,6 +23,13 @@ type Field struct {
 	sval   reflect.Value
 	pos    int
 	err    error
+	// inputName is the name used for reading the field
+	// input, which might be different from HTMLName for
+	// fields in repeated rows. Empty for rows which were
+	// not submitted.
+	inputName string
+	// rows are only used for REPEATED fields
+	rows []*Row
 }
 
 func (f *Field) String() string {
@@ -52,6 +59,15 @@ func (f *Field) HasAddOns() bool {
 	return len(f.addons) > 0
 }
 
+// Err returns the error in the field, if any. For
+// REPEATED fields, it only returns errors in the field
+// itself, use Rows to check the errors in each row.
 func (f *Field) Err() error {
 	return f.err
 }
+
+// Rows returns the rows in a REPEATED field. For
+// other types of fields, it returns nil.
+func (f *Field) Rows() []*Row {
+	return f.rows
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"gnd.la/app"
 	"gnd.la/crypto/password"
@@ -40,6 +42,17 @@ type Form struct {
 	// form has been rendered or validated has no effect.
 	DisableCSRF bool
 	hasCSRF     bool
+	// rowsChanged is true when a row has been added to
+	// or removed from a REPEATED field in this request.
+	rowsChanged bool
+	// input is used instead of the request form values
+	// when it's non-nil. See Bind.
+	input url.Values
+	// jsonInput is true when input was decoded from a
+	// JSON body, which can't include files.
+	jsonInput bool
+	// err is the form level error returned by a Validator
+	err error
 }
 
 func (f *Form) validate() {
@@ -47,51 +60,97 @@ func (f *Form) validate() {
 		panic(err)
 	}
 	for _, v := range f.fields {
-		inp := f.ctx.FormValue(v.HTMLName)
-		label := v.Label.TranslatedString(f.ctx)
-		if f.NamelessErrors {
-			label = ""
-		}
-		if v.Type.HasChoices() {
-			if inp == NotChosen {
-				v.err = i18n.Errorfc("form", "You must choose a value").Err(f.ctx)
-				continue
-			}
-			// Verify that the input mathces one of the available choices
-			choices := f.fieldChoices(v)
-			found := false
-			for _, c := range choices {
-				if inp == toHTMLValue(c.Value) {
-					found = true
-					break
+		if v.Type == REPEATED {
+			for _, row := range v.rows {
+				for _, rf := range row.Fields {
+					f.validateField(rf)
 				}
 			}
-			if !found {
-				v.err = i18n.Errorfc("form", "%v is not a valid choice", inp).Err(f.ctx)
-				continue
-			}
+			continue
 		}
-		if v.Type == FILE {
-			file, header, err := f.ctx.R.FormFile(v.HTMLName)
-			if err != nil && !v.Tag().Optional() {
-				v.err = input.RequiredInputError(label)
-				continue
-			}
-			if file != nil && header != nil {
-				value := File([]interface{}{file, header})
-				v.value.Set(reflect.ValueOf(value))
+		f.validateField(v)
+	}
+	if f.valid() {
+		f.runValidators()
+	}
+	if f.rowsChanged {
+		// Rows were added or removed, so the user
+		// hasn't finished filling the form. Don't
+		// show any errors.
+		for _, v := range f.allFields() {
+			v.err = nil
+		}
+	}
+}
+
+func (f *Form) validateField(v *Field) {
+	if v.inputName == "" {
+		// Row added in this request
+		return
+	}
+	inp := f.formValue(v.inputName)
+	label := v.Label.TranslatedString(f.ctx)
+	if f.NamelessErrors {
+		label = ""
+	}
+	if v.Type.HasChoices() {
+		if inp == NotChosen {
+			v.err = i18n.Errorfc("form", "You must choose a value").Err(f.ctx)
+			return
+		}
+		// Verify that the input mathces one of the available choices
+		choices := f.fieldChoices(v)
+		found := false
+		for _, c := range choices {
+			if inp == toHTMLValue(c.Value) {
+				found = true
+				break
 			}
-		} else {
-			if err := input.InputNamed(label, inp, v.SettableValue(), v.Tag(), true); err != nil {
-				v.err = i18n.TranslatedError(err, f.ctx)
-				continue
+		}
+		if !found {
+			v.err = i18n.Errorfc("form", "%v is not a valid choice", inp).Err(f.ctx)
+			return
+		}
+	}
+	if v.Type == FILE {
+		if f.jsonInput {
+			// JSON bodies can't include files
+			if !v.Tag().Optional() {
+				v.err = i18n.Errorfc("form", "files must be uploaded using multipart/form-data").Err(f.ctx)
 			}
+			return
 		}
-		if err := structs.Validate(v.sval.Addr().Interface(), v.Name, f.ctx); err != nil {
+		file, header, err := f.ctx.R.FormFile(v.inputName)
+		if err != nil && !v.Tag().Optional() {
+			v.err = input.RequiredInputError(label)
+			return
+		}
+		if file != nil && header != nil {
+			value := File([]interface{}{file, header})
+			v.value.Set(reflect.ValueOf(value))
+		}
+	} else {
+		if err := input.InputNamed(label, inp, v.SettableValue(), v.Tag(), true); err != nil {
 			v.err = i18n.TranslatedError(err, f.ctx)
-			continue
+			return
 		}
 	}
+	if err := structs.Validate(v.sval.Addr().Interface(), v.Name, f.ctx); err != nil {
+		v.err = i18n.TranslatedError(err, f.ctx)
+	}
+}
+
+// allFields returns all the fields in the form, including
+// the ones in the rows of REPEATED fields.
+func (f *Form) allFields() []*Field {
+	var fields []*Field
+	for _, v := range f.fields {
+		fields = append(fields, v)
+		for _, row := range v.rows {
+			fields = append(fields, row.Fields...)
+		}
+	}
+	return fields
 }
 
 func (f *Form) makeField(name string) (*Field, error) {
@@ -119,10 +178,28 @@ func (f *Form) makeField(name string) (*Field, error) {
 	if idx < 0 {
 		return nil, fmt.Errorf("can't map form field %q", name)
 	}
+	field, err := f.newField(name, f.toHTMLName(name), s, sval, idx, fieldValue)
+	if err != nil || field == nil {
+		return nil, err
+	}
+	if field.Type == REPEATED {
+		if err := f.makeRows(field); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+// newField returns a new field for the idx-th field in s, with the
+// given name and HTML name. sval is the struct which contains the
+// field (which is used for validation and choices) and fieldValue
+// the field value. If the field should not be included in the form,
+// it returns nil.
+func (f *Form) newField(name string, htmlName string, s *structs.Struct, sval reflect.Value, idx int, fieldValue reflect.Value) (*Field, error) {
 	tag := s.Tags[idx]
 	label := tag.Value("label")
 	if label == "" {
-		label = stringutil.CamelCaseToWords(name, " ")
+		label = stringutil.CamelCaseToWords(name[strings.LastIndex(name, ".")+1:], " ")
 	}
 	var typ Type
 	if tag.Has("hidden") {
@@ -132,12 +209,15 @@ func (f *Form) makeField(name string) (*Field, error) {
 	} else if tag.Has("select") {
 		typ = SELECT
 	} else {
-		switch s.Types[idx].Kind() {
+		ftype := s.Types[idx]
+		switch ftype.Kind() {
 		case reflect.Func:
 			return nil, nil
 		case reflect.String:
-			if s.Types[idx] == reflect.TypeOf(password.Password("")) || tag.Has("password") {
+			if ftype == reflect.TypeOf(password.Password("")) || tag.Has("password") {
 				typ = PASSWORD
+			} else if t, ok := html5Types[input.InputType(ftype, tag)]; ok {
+				typ = t
 			} else {
 				if ml, ok := tag.MaxLength(); ok && ml > 0 {
 					typ = TEXT
@@ -154,13 +234,25 @@ func (f *Form) makeField(name string) (*Field, error) {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 			reflect.Float32, reflect.Float64:
-			typ = TEXT
+			if t, ok := html5Types[input.InputType(ftype, tag)]; ok {
+				typ = t
+			} else {
+				typ = TEXT
+			}
 		default:
-			if s.Types[idx] == fileType {
+			if ftype == fileType {
 				typ = FILE
 				break
 			}
-			return nil, fmt.Errorf("field %q has invalid type %v", name, s.Types[idx])
+			if t, ok := html5Types[input.InputType(ftype, tag)]; ok {
+				typ = t
+				break
+			}
+			if isRepeated(ftype) {
+				typ = REPEATED
+				break
+			}
+			return nil, fmt.Errorf("field %q has invalid type %v", name, ftype)
 		}
 	}
 	// Check if the struct implements the ChoicesProvider interface
@@ -170,7 +262,6 @@ func (f *Form) makeField(name string) (*Field, error) {
 			return nil, fmt.Errorf("field %q requires choices, but %T does not implement ChoicesProvider", name, container)
 		}
 	}
-	htmlName := f.toHTMLName(name)
 	field := &Field{
 		Type:        typ,
 		Name:        name,
@@ -178,11 +269,12 @@ func (f *Form) makeField(name string) (*Field, error) {
 		Label:       i18n.String(label),
 		Placeholder: i18n.String(tag.Value("placeholder")),
 		Help:        i18n.String(tag.Value("help")),
-		id:          htmlName,
+		id:          strings.Replace(htmlName, ".", "_", -1),
 		value:       fieldValue,
 		s:           s,
 		sval:        sval,
 		pos:         idx,
+		inputName:   htmlName,
 	}
 	return field, nil
 }
@@ -224,7 +316,10 @@ func (f *Form) appendVal(val interface{}) error {
 }
 
 func (f *Form) valid() bool {
-	for _, f := range f.fields {
+	if f.rowsChanged || f.err != nil {
+		return false
+	}
+	for _, f := range f.allFields() {
 		if f.err != nil {
 			return false
 		}
@@ -237,7 +332,7 @@ func (f *Form) HasErrors() bool {
 }
 
 func (f *Form) Submitted() bool {
-	return f.ctx.R.Method == "POST" || f.ctx.FormValue("submitted") != ""
+	return f.input != nil || f.ctx.R.Method == "POST" || f.ctx.FormValue("submitted") != ""
 }
 
 func (f *Form) IsValid() bool {
@@ -345,7 +440,7 @@ func (f *Form) endInput(buf *bytes.Buffer, field *Field, pos int) error {
 
 func (f *Form) writeField(buf *bytes.Buffer, field *Field) error {
 	var closed bool
-	if field.Type != HIDDEN {
+	if field.Type != HIDDEN && field.Type != REPEATED {
 		closed = field.Type != CHECKBOX
 		label := field.Label.TranslatedString(f.ctx)
 		if err := f.writeLabel(buf, field, field.Id(), label, closed, -1); err != nil {
@@ -354,14 +449,10 @@ func (f *Form) writeField(buf *bytes.Buffer, field *Field) error {
 	}
 	var err error
 	switch field.Type {
-	case TEXT:
-		err = f.writeInput(buf, "text", field)
-	case PASSWORD:
-		err = f.writeInput(buf, "password", field)
-	case HIDDEN:
-		err = f.writeInput(buf, "hidden", field)
-	case FILE:
-		err = f.writeInput(buf, "file", field)
+	case TEXT, PASSWORD, HIDDEN, FILE, EMAIL, NUMBER, DATE, DATETIME_LOCAL, URL, TEL, RANGE, COLOR:
+		err = f.writeInput(buf, inputTypes[field.Type], field)
+	case REPEATED:
+		err = f.writeRepeated(buf, field)
 	case TEXTAREA:
 		attrs := html.Attrs{
 			"id":   field.Id(),
@@ -502,14 +593,20 @@ func (f *Form) writeInput(buf *bytes.Buffer, itype string, field *Field) error {
 		if t, ok := types.IsTrue(field.value.Interface()); t && ok {
 			attrs["checked"] = "checked"
 		}
-	case TEXT, PASSWORD, HIDDEN:
-		attrs["value"] = html.Escape(types.ToString(field.Value()))
+	case TEXT, PASSWORD, HIDDEN, EMAIL, URL, TEL, NUMBER:
+		attrs["value"] = html.Escape(inputValue(field))
 		if field.Placeholder != "" {
 			attrs["placeholder"] = html.Escape(field.Placeholder.TranslatedString(f.ctx))
 		}
 		if ml, ok := field.Tag().MaxLength(); ok {
 			attrs["maxlength"] = strconv.Itoa(ml)
 		}
+		if field.Type == NUMBER {
+			setRangeAttributes(field, attrs)
+		}
+	case RANGE, DATE, DATETIME_LOCAL, COLOR:
+		attrs["value"] = html.Escape(inputValue(field))
+		setRangeAttributes(field, attrs)
 	case FILE:
 	default:
 		panic("unreachable")
@@ -565,7 +662,16 @@ func (f *Form) renderField(buf *bytes.Buffer, field *Field) (err error) {
 }
 
 func (f *Form) toHTMLName(name string) string {
-	return stringutil.CamelCaseToLower(name, "_")
+	// Nested fields (e.g. Address.Street) are separated
+	// by dots (e.g. address.street). Each part is converted
+	// on its own, since converting the whole name would
+	// produce address._street, which can't be matched with
+	// the nested objects accepted by Bind.
+	parts := strings.Split(name, ".")
+	for ii, v := range parts {
+		parts[ii] = stringutil.CamelCaseToLower(v, "_")
+	}
+	return strings.Join(parts, ".")
 }
 
 func (f *Form) render(fields []*Field) (template.HTML, error) {
@@ -663,7 +769,7 @@ func (f *Form) Id() string {
 func (f *Form) SetId(id string) {
 	f.id = id
 	p := id + "_"
-	for _, v := range f.fields {
+	for _, v := range f.allFields() {
 		v.prefix = p
 	}
 }
@@ -688,6 +794,10 @@ func New(ctx *app.Context, values ...interface{}) *Form {
 //
 // Consult the package documentation for the the tags parsed by the form library.
 func NewOpts(ctx *app.Context, opts *Options, values ...interface{}) *Form {
+	return newForm(ctx, opts, nil, values...)
+}
+
+func newForm(ctx *app.Context, opts *Options, input url.Values, values ...interface{}) *Form {
 	var r Renderer = nil
 	if opts != nil {
 		r = opts.Renderer
@@ -699,6 +809,7 @@ func NewOpts(ctx *app.Context, opts *Options, values ...interface{}) *Form {
 		ctx:      ctx,
 		renderer: r,
 		options:  opts,
+		input:    input,
 	}
 	for _, v := range values {
 		err := form.appendVal(v)
@@ -725,6 +836,11 @@ func NewOpts(ctx *app.Context, opts *Options, values ...interface{}) *Form {
 func fieldByIndex(v reflect.Value, indexes []int) reflect.Value {
 	for _, idx := range indexes {
 		if v.Kind() == reflect.Ptr {
+			if v.IsNil() && v.CanSet() {
+				// Nested struct pointer, allocate it
+				// so its fields can be set.
+				v.Set(reflect.New(v.Type().Elem()))
+			}
 			v = v.Elem()
 		}
 		if v.IsValid() {
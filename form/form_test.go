@@ -0,0 +1,238 @@
+package form
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"gnd.la/app"
+	"gnd.la/app/tester"
+	"gnd.la/html"
+)
+
+type orderAddress struct {
+	Street string `form:",singleline"`
+	City   string `form:",singleline,optional"`
+}
+
+type orderItem struct {
+	Name     string `form:",singleline"`
+	Quantity int    `form:",optional"`
+}
+
+type order struct {
+	Customer string `form:",singleline"`
+	Address  orderAddress
+	Items    []orderItem `form:",min_rows=1"`
+}
+
+// orderResult is written as JSON by the test handler
+type orderResult struct {
+	Valid       bool
+	RowsChanged bool
+	Errors      Errors
+	RowErrors   []bool
+	Order       order
+	HTML        string
+}
+
+func newOrderTester(t *testing.T) *tester.Tester {
+	a := app.New()
+	a.Handle("^/order/$", func(ctx *app.Context) {
+		var o order
+		f := New(ctx, &o)
+		f.DisableCSRF = true
+		var res orderResult
+		if f.Submitted() {
+			res.Valid = f.IsValid()
+			res.RowsChanged = f.RowsChanged()
+			res.Errors = f.Errors()
+		}
+		items, err := f.FieldByName("Items")
+		if err != nil {
+			panic(err)
+		}
+		for _, v := range items.Rows() {
+			res.RowErrors = append(res.RowErrors, v.HasErrors())
+		}
+		rendered, err := f.Render()
+		if err != nil {
+			panic(err)
+		}
+		res.Order = o
+		res.HTML = string(rendered)
+		ctx.WriteJSON(&res)
+	})
+	return tester.New(t, a)
+}
+
+func postOrder(t *testing.T, r *tester.Request) (*orderResult, map[string]string) {
+	var res orderResult
+	if err := json.Unmarshal(r.Expect(200).ResponseBody(), &res); err != nil {
+		t.Fatal(err)
+	}
+	// Collect the names and values of the inputs and buttons
+	// in the rendered form.
+	root, err := html.ParseString(res.HTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inputs := make(map[string]string)
+	root.Walk(func(n *html.Node) bool {
+		if n.Tag == "input" || n.Tag == "button" {
+			inputs[n.Attr("name")] = n.Attr("value")
+		}
+		return true
+	})
+	return &res, inputs
+}
+
+func expectInputs(t *testing.T, inputs map[string]string, expect map[string]string) {
+	for k, v := range expect {
+		if val, ok := inputs[k]; !ok {
+			t.Errorf("expecting an input named %q, got %v", k, inputs)
+		} else if val != v {
+			t.Errorf("expecting input %q = %q, got %q", k, v, val)
+		}
+	}
+}
+
+func expectRowErrors(t *testing.T, res *orderResult, expect ...bool) {
+	if len(res.RowErrors) != len(expect) {
+		t.Errorf("expecting %d rows, got %d", len(expect), len(res.RowErrors))
+		return
+	}
+	for ii, v := range expect {
+		if res.RowErrors[ii] != v {
+			t.Errorf("expecting row %d HasErrors() = %v, got %v", ii, v, res.RowErrors[ii])
+		}
+	}
+}
+
+func TestNestedAndRepeated(t *testing.T) {
+	tt := newOrderTester(t)
+	// min_rows adds an empty row
+	_, inputs := postOrder(t, tt.Get("/order/", nil))
+	expectInputs(t, inputs, map[string]string{
+		"customer":         "",
+		"address.street":   "",
+		"address.city":     "",
+		"items.count":      "1",
+		"items.0.name":     "",
+		"items.0.quantity": "0",
+		"items.remove":     "0",
+		"items.add":        "1",
+	})
+	params := map[string]interface{}{
+		"customer":         "Alberto",
+		"address.street":   "Gran Vía",
+		"address.city":     "Madrid",
+		"items.count":      2,
+		"items.0.name":     "Apples",
+		"items.0.quantity": 3,
+		"items.1.name":     "",
+	}
+	// The error is assigned to the field in the row
+	res, inputs := postOrder(t, tt.Form("/order/", params))
+	if res.Valid {
+		t.Error("expecting an invalid form")
+	}
+	if len(res.Errors) != 1 || res.Errors["items.1.name"] != "Name is required" {
+		t.Errorf("expecting an error in items.1.name, got %v", res.Errors)
+	}
+	expectRowErrors(t, res, false, true)
+	// And the submitted values are rendered again
+	expectInputs(t, inputs, map[string]string{
+		"customer":         "Alberto",
+		"address.street":   "Gran Vía",
+		"address.city":     "Madrid",
+		"items.count":      "2",
+		"items.0.name":     "Apples",
+		"items.0.quantity": "3",
+		"items.1.name":     "",
+	})
+	params["items.1.name"] = "Pears"
+	res, _ = postOrder(t, tt.Form("/order/", params))
+	if !res.Valid || res.Errors != nil {
+		t.Errorf("expecting a valid form, got errors %v", res.Errors)
+	}
+	expect := order{
+		Customer: "Alberto",
+		Address:  orderAddress{Street: "Gran Vía", City: "Madrid"},
+		Items:    []orderItem{{Name: "Apples", Quantity: 3}, {Name: "Pears"}},
+	}
+	if res.Order.Customer != expect.Customer || res.Order.Address != expect.Address ||
+		len(res.Order.Items) != 2 || res.Order.Items[0] != expect.Items[0] || res.Order.Items[1] != expect.Items[1] {
+		t.Errorf("expecting order %+v, got %+v", expect, res.Order)
+	}
+}
+
+func TestRepeatedAddRemove(t *testing.T) {
+	tt := newOrderTester(t)
+	params := map[string]interface{}{
+		"customer":       "Alberto",
+		"address.street": "Gran Vía",
+		"items.count":    2,
+		"items.0.name":   "Apples",
+		"items.1.name":   "",
+		"items.add":      1,
+	}
+	// Adding a row doesn't show the error in the incomplete row
+	res, inputs := postOrder(t, tt.Form("/order/", params))
+	if res.Valid || !res.RowsChanged || res.Errors != nil {
+		t.Errorf("expecting changed rows without errors, got valid = %v, changed = %v, errors = %v", res.Valid, res.RowsChanged, res.Errors)
+	}
+	expectRowErrors(t, res, false, false, false)
+	expectInputs(t, inputs, map[string]string{
+		"items.count":  "3",
+		"items.0.name": "Apples",
+		"items.2.name": "",
+	})
+	// Removing the first row renumbers the other ones
+	delete(params, "items.add")
+	params["items.1.name"] = "Pears"
+	params["items.remove"] = 0
+	res, inputs = postOrder(t, tt.Form("/order/", params))
+	if res.Valid || !res.RowsChanged || res.Errors != nil {
+		t.Errorf("expecting changed rows without errors, got valid = %v, changed = %v, errors = %v", res.Valid, res.RowsChanged, res.Errors)
+	}
+	expectRowErrors(t, res, false)
+	expectInputs(t, inputs, map[string]string{
+		"items.count":  "1",
+		"items.0.name": "Pears",
+	})
+	if _, ok := inputs["items.1.name"]; ok {
+		t.Error("expecting no inputs for removed rows")
+	}
+	// max_rows is enforced too
+	params = map[string]interface{}{"items.count": strconv.Itoa(maxRows + 1)}
+	res, _ = postOrder(t, tt.Form("/order/", params))
+	if len(res.RowErrors) != maxRows {
+		t.Errorf("expecting %d rows, got %d", maxRows, len(res.RowErrors))
+	}
+}
+
+func TestNumberInputs(t *testing.T) {
+	var values struct {
+		Count  int
+		Amount float64 `form:",number"`
+		Level  int     `form:",range,min=1,max=5"`
+		Limit  int     `form:",optional,max=10"`
+	}
+	a := app.New()
+	a.Handle("^/$", func(ctx *app.Context) {
+		f := New(ctx, &values)
+		f.DisableCSRF = true
+		types := make(map[string]string)
+		for _, v := range f.Fields() {
+			types[v.Name] = inputTypes[v.Type]
+		}
+		ctx.WriteJSON(map[string]interface{}{"Types": types, "Errors": f.Errors()})
+	})
+	tt := tester.New(t, a)
+	// Numbers are only rendered as number inputs when requested,
+	// but min and max are always validated.
+	tt.Form("/", map[string]interface{}{"count": 1, "amount": 1.5, "level": 3, "limit": 11}).Expect(200).
+		ExpectJSON("Types", map[string]interface{}{"Count": "text", "Amount": "number", "Level": "range", "Limit": "text"}).
+		ExpectJSON("Errors", map[string]interface{}{"limit": "Limit must be at most 10"})
+}
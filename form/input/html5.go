@@ -0,0 +1,230 @@
+package input
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gnd.la/i18n"
+	"gnd.la/util/structs"
+)
+
+// Email is a string type which is validated as an email
+// address and rendered by gnd.la/form as <input type="email">.
+// String fields tagged with "email" behave the same way.
+type Email string
+
+// URL is a string type which is validated as an absolute URL
+// and rendered by gnd.la/form as <input type="url">. String
+// fields tagged with "url" behave the same way.
+type URL string
+
+// Tel is a string type which is validated as a telephone number
+// and rendered by gnd.la/form as <input type="tel">. String fields
+// tagged with "tel" behave the same way.
+type Tel string
+
+// Color is a string type which is validated as a color in the #rrggbb
+// format and rendered by gnd.la/form as <input type="color">. String
+// fields tagged with "color" behave the same way.
+type Color string
+
+const (
+	// DateFormat is the format used by <input type="date">
+	DateFormat = "2006-01-02"
+	// DateTimeLocalFormat is the format used by <input type="datetime-local">
+	DateTimeLocalFormat = "2006-01-02T15:04"
+)
+
+var (
+	emailType = reflect.TypeOf(Email(""))
+	urlType   = reflect.TypeOf(URL(""))
+	telType   = reflect.TypeOf(Tel(""))
+	colorType = reflect.TypeOf(Color(""))
+	timeType  = reflect.TypeOf(time.Time{})
+
+	telRe   = regexp.MustCompile(`^\+?[0-9 ().\-]*[0-9][0-9 ().\-]*$`)
+	colorRe = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+	// Formats accepted when parsing a time.Time, in order.
+	timeFormats = []string{
+		DateTimeLocalFormat,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04:05.999999999",
+		DateFormat,
+		time.RFC3339Nano,
+	}
+)
+
+// InputType returns the HTML5 input type for the given type and tag,
+// which is one of "email", "url", "tel", "color", "number", "range",
+// "date", "datetime-local" or an empty string if there's no specific
+// input type for it. Types are inferred from the Go type and might be
+// overridden by the tag:
+//
+//   - Email, URL, Tel and Color return their respective types. The same
+//     happens for string fields with the "email", "url", "tel" or "color" tags.
+//   - Integer and floating point types return "number" when they're tagged
+//     with "number" and "range" when they're tagged with "range". Otherwise,
+//     they're rendered as text inputs, so browsers don't alter their values
+//     (e.g. by rounding them to the input step). Note that the "min" and
+//     "max" tags are validated regardless of the input type.
+//   - time.Time returns "datetime-local" or "date" if it's tagged with "date".
+//   - Strings tagged with "date" return "date".
+func InputType(typ reflect.Type, tag *structs.Tag) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	has := func(opt string) bool { return tag != nil && tag.Has(opt) }
+	switch typ.Kind() {
+	case reflect.String:
+		switch {
+		case typ == emailType || has("email"):
+			return "email"
+		case typ == urlType || has("url"):
+			return "url"
+		case typ == telType || has("tel"):
+			return "tel"
+		case typ == colorType || has("color"):
+			return "color"
+		case has("date"):
+			return "date"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if has("range") {
+			return "range"
+		}
+		if has("number") {
+			return "number"
+		}
+	case reflect.Struct:
+		if typ == timeType {
+			if has("date") {
+				return "date"
+			}
+			return "datetime-local"
+		}
+	}
+	return ""
+}
+
+// parseTime parses a time.Time from any of the formats used by
+// the HTML5 date inputs. Times without a timezone are interpreted
+// as UTC.
+func parseTime(val string) (time.Time, error) {
+	for _, v := range timeFormats {
+		if t, err := time.Parse(v, val); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, i18n.Errorfc("form", "invalid date %q", val)
+}
+
+func validateInputType(name string, input string, v reflect.Value, tag *structs.Tag) error {
+	if input == "" {
+		return nil
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	inputType := InputType(v.Type(), tag)
+	if inputType == "" && isNumber(v.Kind()) {
+		inputType = "number"
+	}
+	switch inputType {
+	case "email":
+		addr, err := mail.ParseAddress(input)
+		if err != nil || addr.Address != input || !strings.Contains(addr.Address, "@") {
+			if name != "" {
+				return i18n.Errorfc("form", "%s is not a valid email address", name)
+			}
+			return i18n.Errorfc("form", "not a valid email address")
+		}
+	case "url":
+		u, err := url.Parse(input)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			if name != "" {
+				return i18n.Errorfc("form", "%s is not a valid URL", name)
+			}
+			return i18n.Errorfc("form", "not a valid URL")
+		}
+	case "tel":
+		if !telRe.MatchString(input) {
+			if name != "" {
+				return i18n.Errorfc("form", "%s is not a valid telephone number", name)
+			}
+			return i18n.Errorfc("form", "not a valid telephone number")
+		}
+	case "color":
+		if !colorRe.MatchString(input) {
+			if name != "" {
+				return i18n.Errorfc("form", "%s is not a valid color", name)
+			}
+			return i18n.Errorfc("form", "not a valid color")
+		}
+		// Colors are always stored in lowercase
+		v.SetString(strings.ToLower(input))
+	case "date":
+		if v.Kind() == reflect.String {
+			if _, err := time.Parse(DateFormat, input); err != nil {
+				if name != "" {
+					return i18n.Errorfc("form", "%s is not a valid date", name)
+				}
+				return i18n.Errorfc("form", "not a valid date")
+			}
+		}
+	case "number", "range":
+		var f float64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(v.Uint())
+		default:
+			f = v.Float()
+		}
+		if min, ok := floatTag(tag, "min"); ok && f < min {
+			if name != "" {
+				return i18n.Errorfc("form", "%s must be at least %v", name, min)
+			}
+			return i18n.Errorfc("form", "must be at least %v", min)
+		}
+		if max, ok := floatTag(tag, "max"); ok && f > max {
+			if name != "" {
+				return i18n.Errorfc("form", "%s must be at most %v", name, max)
+			}
+			return i18n.Errorfc("form", "must be at most %v", max)
+		}
+	}
+	return nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func floatTag(tag *structs.Tag, key string) (float64, bool) {
+	if tag == nil {
+		return 0, false
+	}
+	if s := tag.Value(key); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -35,6 +35,9 @@ func RequiredInputError(name string) error {
 //  - max_length: Sets the maximum length for the input.
 //  - min_length: Sets the minimum length for the input.
 //  - alphanumeric: Requires the input to be only letters and numbers
+//  - email, url, tel, color: Requires the input to be a valid value for the
+//    corresponding HTML5 input type (see InputType).
+//  - min, max: Set the minimum and maximum values for numeric inputs.
 //
 // Finally, the required parameter indicates if the value should be considered required
 // or optional in absence of the "required" and "optional" tag fields.
@@ -69,7 +72,7 @@ func InputNamed(name string, input string, out interface{}, tag *structs.Tag, re
 			return i18n.Errorfc("form", "must be alphanumeric")
 		}
 	}
-	return nil
+	return validateInputType(name, input, v, tag)
 }
 
 // Input is a shorthand for InputNamed("", ...).
@@ -27,7 +27,8 @@ type Parser interface {
 //     Parse("27.5", &f)
 //     var width uint
 //     Parse("57", &width)
-// Supported types are: string, bool, u?int(8|16|32|64)? and float(32|64). If
+// Supported types are: string, bool, u?int(8|16|32|64)?, float(32|64) and
+// time.Time (using any of the formats sent by the HTML5 date inputs). If
 // the parsed value would overflow the given type, the maximum value
 // (or minimum, if it's negative) for the type will be set.
 // If arg implements the Parser interface, its Parse method will
@@ -65,6 +66,14 @@ func parse(val string, v reflect.Value) error {
 		v.Set(reflect.Zero(v.Type()))
 		return nil
 	}
+	if v.Type() == timeType {
+		t, err := parseTime(val)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
 	switch v.Type().Kind() {
 	case reflect.Bool:
 		res := false
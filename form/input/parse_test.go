@@ -3,6 +3,9 @@ package input
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"gnd.la/util/structs"
 )
 
 type ParseCase struct {
@@ -24,6 +27,9 @@ func TestParse(t *testing.T) {
 		{"2000", reflect.TypeOf(int8(0)), int8(127)},
 		{"56.950000", reflect.TypeOf(float64(0)), 56.95},
 		{"foo", reflect.TypeOf("bar"), "foo"},
+		{"2014-03-15", reflect.TypeOf(time.Time{}), time.Date(2014, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"2014-03-15T10:30", reflect.TypeOf(time.Time{}), time.Date(2014, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"2014-03-15T10:30:45", reflect.TypeOf(time.Time{}), time.Date(2014, 3, 15, 10, 30, 45, 0, time.UTC)},
 	}
 	for _, v := range cases {
 		val := reflect.New(v.Type)
@@ -40,3 +46,79 @@ func TestParse(t *testing.T) {
 		t.Logf("Parsed %q as %v", v.Value, result)
 	}
 }
+
+type InputCase struct {
+	Value string
+	Out   interface{}
+	Tag   string
+	Valid bool
+}
+
+func TestInputTypes(t *testing.T) {
+	var email Email
+	var url URL
+	var tel Tel
+	var color Color
+	var s string
+	var n int
+	var f float64
+	var tm time.Time
+	cases := []InputCase{
+		{"alice@example.com", &email, "", true},
+		{"alice", &email, "", false},
+		{"Alice <alice@example.com>", &email, "", false},
+		{"alice@example.com", &s, ",email", true},
+		{"example.com", &s, ",email", false},
+		{"https://example.com/path", &url, "", true},
+		{"/path", &url, "", false},
+		{"example.com", &s, ",url", false},
+		{"+34 (91) 555-12.34", &tel, "", true},
+		{"call me", &tel, "", false},
+		{"#00FFaa", &color, "", true},
+		{"red", &color, "", false},
+		{"#00ff", &s, ",color", false},
+		{"2014-03-15", &s, ",date", true},
+		{"15/03/2014", &s, ",date", false},
+		{"5", &n, ",min=1,max=10", true},
+		{"0", &n, ",min=1,max=10", false},
+		{"11", &n, ",min=1,max=10", false},
+		{"0.5", &f, ",min=0.1", true},
+		{"0.05", &f, ",min=0.1", false},
+		{"2014-03-15T10:30", &tm, "", true},
+		{"tomorrow", &tm, "", false},
+		{"", &email, ",optional", true},
+		{"", &email, "", false},
+	}
+	for _, v := range cases {
+		tag := structs.MustParseTag(v.Tag)
+		err := Input(v.Value, v.Out, tag, true)
+		if v.Valid && err != nil {
+			t.Errorf("error parsing %q into %T (tag %q): %s", v.Value, v.Out, v.Tag, err)
+		} else if !v.Valid && err == nil {
+			t.Errorf("expecting an error parsing %q into %T (tag %q)", v.Value, v.Out, v.Tag)
+		}
+	}
+	if err := Input("#00FFaa", &color, nil, true); err != nil || color != "#00ffaa" {
+		t.Errorf("expecting color #00ffaa, got %s", color)
+	}
+	typeCases := []struct {
+		Out    interface{}
+		Tag    string
+		Expect string
+	}{
+		{email, "", "email"},
+		{s, "", ""},
+		{s, ",tel", "tel"},
+		{n, "", ""},
+		{n, ",number", "number"},
+		{f, ",range", "range"},
+		{tm, "", "datetime-local"},
+		{tm, ",date", "date"},
+		{&tm, "", "datetime-local"},
+	}
+	for _, v := range typeCases {
+		if it := InputType(reflect.TypeOf(v.Out), structs.MustParseTag(v.Tag)); it != v.Expect {
+			t.Errorf("expecting input type %q for %T (tag %q), got %q", v.Expect, v.Out, v.Tag, it)
+		}
+	}
+}
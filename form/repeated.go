@@ -0,0 +1,260 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gnd.la/form/input"
+	"gnd.la/html"
+	"gnd.la/i18n"
+	"gnd.la/util/structs"
+	"gnd.la/util/types"
+)
+
+const (
+	// maxRows is the maximum number of rows accepted in
+	// a REPEATED field when the field has no max_rows tag.
+	maxRows = 1000
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// Row represents a row in a REPEATED field. Each row
+// corresponds to an element in the slice the field is
+// bound to.
+type Row struct {
+	// Index is the index of the row in the field.
+	Index int
+	// Fields are the fields in this row, one for
+	// each field of the struct in the slice.
+	Fields []*Field
+}
+
+// HasErrors returns true iff any of the fields
+// in the row has an error.
+func (r *Row) HasErrors() bool {
+	for _, v := range r.Fields {
+		if v.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isRepeated returns wheter a field of the given type
+// should be rendered as a REPEATED field. This happens
+// for slices of structs or pointers to structs.
+func isRepeated(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && elem != timeType && elem != fileType
+}
+
+// RowsChanged returns true if the user added or removed any rows
+// of a REPEATED field when submitting the form. In that case, the
+// form is never considered valid nor has any errors, since the user
+// has not finished filling it.
+func (f *Form) RowsChanged() bool {
+	return f.rowsChanged
+}
+
+func (f *Form) submitted() bool {
+	return f.input != nil || (f.ctx != nil && f.ctx.R != nil && f.Submitted())
+}
+
+// submittedRows returns the number of rows submitted for
+// the given REPEATED field.
+func (f *Form) submittedRows(field *Field) int {
+	if count, err := strconv.Atoi(f.formValue(field.HTMLName + ".count")); err == nil {
+		return count
+	}
+	// No count, look for the highest index in the inputs
+	count := 0
+	prefix := field.HTMLName + "."
+	for _, k := range f.inputNames() {
+		if strings.HasPrefix(k, prefix) {
+			rest := k[len(prefix):]
+			if dot := strings.IndexByte(rest, '.'); dot >= 0 {
+				if idx, err := strconv.Atoi(rest[:dot]); err == nil && idx >= count {
+					count = idx + 1
+				}
+			}
+		}
+	}
+	return count
+}
+
+// makeRows resizes the slice bound to the given REPEATED field to
+// the number of submitted rows (if the form was submitted) and
+// creates the fields for each row.
+func (f *Form) makeRows(field *Field) error {
+	slice := field.value
+	elemType := slice.Type().Elem()
+	s, err := structs.NewStruct(elemType, formTags)
+	if err != nil {
+		return err
+	}
+	limit := maxRows
+	if mr, ok := field.Tag().IntValue("max_rows"); ok {
+		limit = mr
+	}
+	// inputs holds the input index for each row, -1 for
+	// rows which were not submitted
+	var inputs []int
+	if f.submitted() {
+		count := f.submittedRows(field)
+		if count > limit {
+			count = limit
+		}
+		for ii := 0; ii < count; ii++ {
+			inputs = append(inputs, ii)
+		}
+		// Rows can't be added nor removed when using Bind
+		if f.input == nil {
+			if rm, err := strconv.Atoi(f.formValue(field.HTMLName + ".remove")); err == nil && rm >= 0 && rm < len(inputs) {
+				inputs = append(inputs[:rm], inputs[rm+1:]...)
+				f.rowsChanged = true
+			}
+			if f.formValue(field.HTMLName+".add") != "" && len(inputs) < limit {
+				inputs = append(inputs, -1)
+				f.rowsChanged = true
+			}
+		}
+	} else {
+		for ii := 0; ii < slice.Len(); ii++ {
+			inputs = append(inputs, ii)
+		}
+		if mr, ok := field.Tag().IntValue("min_rows"); ok {
+			for len(inputs) < mr {
+				inputs = append(inputs, -1)
+			}
+		}
+	}
+	rows := reflect.MakeSlice(slice.Type(), len(inputs), len(inputs))
+	for ii, v := range inputs {
+		elem := rows.Index(ii)
+		if v >= 0 && v < slice.Len() {
+			elem.Set(slice.Index(v))
+		}
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			elem.Set(reflect.New(elemType.Elem()))
+		}
+	}
+	slice.Set(rows)
+	for ii, v := range inputs {
+		elem := rows.Index(ii)
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		row := &Row{Index: ii}
+		for jj, qname := range s.QNames {
+			if _, err := structs.ValidationFunction(elem.Addr().Interface(), qname); err != nil {
+				return err
+			}
+			htmlName := field.HTMLName + "." + strconv.Itoa(ii) + "." + f.toHTMLName(qname)
+			rf, err := f.newField(field.Name+"."+strconv.Itoa(ii)+"."+qname, htmlName, s, elem, jj, fieldByIndex(elem, s.Indexes[jj]))
+			if err != nil {
+				return err
+			}
+			if rf == nil {
+				continue
+			}
+			if rf.Type == REPEATED {
+				return fmt.Errorf("field %q can't contain repeated field %q", field.Name, qname)
+			}
+			rf.inputName = ""
+			if v >= 0 {
+				rf.inputName = field.HTMLName + "." + strconv.Itoa(v) + "." + f.toHTMLName(qname)
+			}
+			row.Fields = append(row.Fields, rf)
+		}
+		field.rows = append(field.rows, row)
+	}
+	return nil
+}
+
+func (f *Form) writeRepeated(buf *bytes.Buffer, field *Field) error {
+	attrs := html.Attrs{
+		"id":    field.Id(),
+		"class": "repeated",
+	}
+	if err := f.prepareFieldAttributes(field, attrs, -1); err != nil {
+		return err
+	}
+	f.openTag(buf, "fieldset", attrs)
+	f.openTag(buf, "legend", nil)
+	buf.WriteString(html.Escape(field.Label.TranslatedString(f.ctx)))
+	f.closeTag(buf, "legend")
+	f.openTag(buf, "input", html.Attrs{
+		"type":  "hidden",
+		"name":  field.HTMLName + ".count",
+		"value": strconv.Itoa(len(field.rows)),
+	})
+	remove := i18n.String("form|Remove").TranslatedString(f.ctx)
+	for _, row := range field.rows {
+		f.openTag(buf, "div", html.Attrs{
+			"class":    "repeated-row",
+			"data-row": strconv.Itoa(row.Index),
+		})
+		for _, v := range row.Fields {
+			if err := f.renderField(buf, v); err != nil {
+				return err
+			}
+		}
+		f.openTag(buf, "button", html.Attrs{
+			"type":           "submit",
+			"name":           field.HTMLName + ".remove",
+			"value":          strconv.Itoa(row.Index),
+			"formnovalidate": "formnovalidate",
+		})
+		buf.WriteString(html.Escape(remove))
+		f.closeTag(buf, "button")
+		f.closeTag(buf, "div")
+	}
+	f.openTag(buf, "button", html.Attrs{
+		"type":           "submit",
+		"name":           field.HTMLName + ".add",
+		"value":          "1",
+		"formnovalidate": "formnovalidate",
+	})
+	buf.WriteString(html.Escape(i18n.String("form|Add").TranslatedString(f.ctx)))
+	f.closeTag(buf, "button")
+	f.closeTag(buf, "fieldset")
+	return nil
+}
+
+// inputValue returns the value for the value attribute of
+// the <input> for the given field.
+func inputValue(field *Field) string {
+	if t, ok := field.Value().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		if field.Type == DATE {
+			return t.Format(input.DateFormat)
+		}
+		return t.Format(input.DateTimeLocalFormat)
+	}
+	return types.ToString(field.Value())
+}
+
+// setRangeAttributes sets the min, max and step attributes
+// from the field tag.
+func setRangeAttributes(field *Field, attrs html.Attrs) {
+	for _, v := range []string{"min", "max", "step"} {
+		if val := field.Tag().Value(v); val != "" {
+			attrs[v] = val
+		}
+	}
+}
@@ -19,6 +19,54 @@ const (
 	SELECT
 	// <input type="file">
 	FILE
+	// <input type="email">
+	EMAIL
+	// <input type="number">
+	NUMBER
+	// <input type="date">
+	DATE
+	// <input type="datetime-local">
+	DATETIME_LOCAL
+	// <input type="url">
+	URL
+	// <input type="tel">
+	TEL
+	// <input type="range">
+	RANGE
+	// <input type="color">
+	COLOR
+	// A group of fields, repeated once per element in a slice
+	// of structs. See Field.Rows.
+	REPEATED
+)
+
+var (
+	inputTypes = map[Type]string{
+		TEXT:           "text",
+		PASSWORD:       "password",
+		HIDDEN:         "hidden",
+		CHECKBOX:       "checkbox",
+		FILE:           "file",
+		EMAIL:          "email",
+		NUMBER:         "number",
+		DATE:           "date",
+		DATETIME_LOCAL: "datetime-local",
+		URL:            "url",
+		TEL:            "tel",
+		RANGE:          "range",
+		COLOR:          "color",
+	}
+	// Maps the types returned by input.InputType
+	html5Types = map[string]Type{
+		"email":          EMAIL,
+		"number":         NUMBER,
+		"date":           DATE,
+		"datetime-local": DATETIME_LOCAL,
+		"url":            URL,
+		"tel":            TEL,
+		"range":          RANGE,
+		"color":          COLOR,
+	}
 )
 
 // HasChoices returns wheter the type has multiple
@@ -27,3 +75,10 @@ const (
 func (t Type) HasChoices() bool {
 	return t == RADIO || t == SELECT
 }
+
+// IsInput returns true iff the type is rendered
+// as an <input> element.
+func (t Type) IsInput() bool {
+	_, ok := inputTypes[t]
+	return ok
+}
@@ -1,6 +1,7 @@
 package html
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -27,3 +28,105 @@ func TestTree(t *testing.T) {
 func TestAttr(t *testing.T) {
 	testHTML(t, Div().AddClass("error").SetAttr("id", "foo"), t2)
 }
+
+func TestParse(t *testing.T) {
+	n, err := ParseString(`<title>Test</title><div class="error" id="foo">Fish &amp; <b>chips</b><br></div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txt := n.Text(); txt != "TestFish & chips" {
+		t.Errorf("expecting text %q, got %q", "TestFish & chips", txt)
+	}
+	var tags []string
+	var div *Node
+	n.Walk(func(c *Node) bool {
+		if c.Type == TAG_NODE {
+			tags = append(tags, c.Tag)
+			if c.Tag == "div" {
+				div = c
+			}
+		}
+		return c.Tag != "head"
+	})
+	if s := strings.Join(tags, " "); s != "html head body div b br" {
+		t.Errorf("expecting walked tags %q, got %q", "html head body div b br", s)
+	}
+	// Attributes are stored in a map, so check them via the node
+	// and remove all but one before comparing the rendered HTML.
+	if div == nil {
+		t.Fatal("div not found")
+	}
+	if len(div.Attrs) != 2 || div.Attr("class") != "error" || div.Attr("id") != "foo" {
+		t.Errorf("expecting div attributes class=error and id=foo, got %v", div.Attrs)
+	}
+	div.DelAttr("id")
+	testHTML(t, n, `<html><head><title>Test</title></head><body><div class="error">Fish &amp; <b>chips</b><br></div></body></html>`)
+}
+
+func TestSelector(t *testing.T) {
+	root, err := ParseString(`<div id="main" class="content wide">
+	<h1 lang="en-US">Title</h1>
+	<ul>
+		<li class="item first">1</li>
+		<li class="item"><a href="/two" data-x="foo-bar">2</a></li>
+		<li class="item last"><span></span>3</li>
+	</ul>
+	<p>a</p><p class="note">b</p>
+</div>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		selector string
+		expect   string
+	}{
+		{"li", "1 2 3"},
+		{"#main h1", "Title"},
+		{".content > h1", "Title"},
+		{"div > li", ""},
+		{"ul li.first", "1"},
+		{"li.item.last", "3"},
+		{"li:first-child, li:last-child", "1 3"},
+		{"li:nth-child(2)", "2"},
+		{"li:nth-child(odd)", "1 3"},
+		{"li:nth-child(-n+2)", "1 2"},
+		{"li:not(.first)", "2 3"},
+		{"span:empty", ""},
+		{"a[href]", "2"},
+		{"a[href='/two']", "2"},
+		{"a[href^=\"/t\"]", "2"},
+		{"a[href$=o]", "2"},
+		{"a[href*=w]", "2"},
+		{"a[data-x|=foo]", "2"},
+		{"div[class~=wide] h1", "Title"},
+		{"h1[lang|=en]", "Title"},
+		{"h1 + ul > li:only-child", ""},
+		{"h1 ~ p", "a b"},
+		{"p + p", "b"},
+		{"p + .note", "b"},
+		{"html body div ul", "123"},
+		{"* > .note", "b"},
+	}
+	for _, v := range cases {
+		nodes, err := root.Select(v.selector)
+		if err != nil {
+			t.Errorf("error compiling %q: %s", v.selector, err)
+			continue
+		}
+		var texts []string
+		for _, n := range nodes {
+			texts = append(texts, strings.TrimSpace(strings.Join(strings.Fields(n.Text()), "")))
+		}
+		if s := strings.Join(texts, " "); s != v.expect {
+			t.Errorf("expecting %q to select %q, got %q", v.selector, v.expect, s)
+		}
+	}
+	if n := MustCompile("span:empty").First(root); n == nil || n.Tag != "span" {
+		t.Errorf("expecting span:empty to match a span, got %v", n)
+	}
+	for _, v := range []string{"", "li >", "a[href", "a[href^]", ":unknown", "li:nth-child(x)", "li,", "#"} {
+		if _, err := Compile(v); err == nil {
+			t.Errorf("expecting an error compiling %q", v)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package html
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"code.google.com/p/go.net/html"
+)
+
+// Elements which can't have any children, rendered
+// without a closing tag.
+var voidElements = map[string]bool{
+	"area":   true,
+	"base":   true,
+	"br":     true,
+	"col":    true,
+	"embed":  true,
+	"hr":     true,
+	"img":    true,
+	"input":  true,
+	"link":   true,
+	"meta":   true,
+	"param":  true,
+	"source": true,
+	"track":  true,
+	"wbr":    true,
+}
+
+// Parse parses the HTML document read from r and returns its root
+// element (usually, the <html> element). Parse follows the HTML5
+// parsing rules, so it accepts any input and adds any missing
+// <html>, <head> or <body> elements. Comments and doctypes are
+// omitted from the returned tree.
+func Parse(r io.Reader) (*Node, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return convertNode(c), nil
+		}
+	}
+	return &Node{Type: TAG_NODE, Tag: "html"}, nil
+}
+
+// ParseString is a shorthand for Parse(strings.NewReader(s)).
+func ParseString(s string) (*Node, error) {
+	return Parse(strings.NewReader(s))
+}
+
+func convertNode(n *html.Node) *Node {
+	var node *Node
+	switch n.Type {
+	case html.ElementNode:
+		node = &Node{Type: TAG_NODE, Tag: n.Data, Open: voidElements[n.Data]}
+		if len(n.Attr) > 0 {
+			node.Attrs = make(Attrs, len(n.Attr))
+			for _, v := range n.Attr {
+				node.Attrs[v.Key] = v.Val
+			}
+		}
+		var last *Node
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			child := convertNode(c)
+			if child == nil {
+				continue
+			}
+			if child.Type == TEXT_NODE && (n.Data == "script" || n.Data == "style") {
+				// Raw text, not escaped
+				child.Content = c.Data
+			}
+			if last == nil {
+				node.Children = child
+			} else {
+				last.Next = child
+			}
+			last = child
+		}
+	case html.TextNode:
+		node = &Node{Type: TEXT_NODE, Content: Escape(n.Data)}
+	}
+	return node
+}
+
+// Walk calls f for n and then for each one of its descendants, in
+// document order. If f returns false, the descendants of the node
+// passed to f are not visited.
+func (n *Node) Walk(f func(*Node) bool) {
+	if f(n) {
+		for c := n.Children; c != nil; c = c.Next {
+			c.Walk(f)
+		}
+	}
+}
+
+// Text returns the unescaped text contained in the node
+// and all its descendants, without any markup.
+func (n *Node) Text() string {
+	var buf bytes.Buffer
+	n.Walk(func(c *Node) bool {
+		if c.Type == TEXT_NODE {
+			buf.WriteString(c.Content)
+		}
+		return true
+	})
+	return Unescape(buf.String())
+}
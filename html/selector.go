@@ -0,0 +1,509 @@
+package html
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selector represents a compiled CSS selector, which can be used
+// to find elements in a tree of *Node. Use Compile or MustCompile
+// to create a Selector.
+//
+// The following syntax is supported:
+//
+//  *                    any element
+//  E                    an element of type E
+//  #id                  an element with the given id
+//  .class               an element with the given class
+//  [attr]               an element with the attribute
+//  [attr=val]           attribute equal to val
+//  [attr~=val]          attribute containing the word val
+//  [attr|=val]          attribute equal to val or starting with val-
+//  [attr^=val]          attribute starting with val
+//  [attr$=val]          attribute ending with val
+//  [attr*=val]          attribute containing val
+//  :first-child         first element child of its parent
+//  :last-child          last element child of its parent
+//  :only-child          only element child of its parent
+//  :nth-child(an+b)     also accepts odd and even
+//  :empty               an element without children
+//  :not(S)              an element not matching the simple selector S
+//  E F                  F descendant of E
+//  E > F                F child of E
+//  E + F                F immediately preceded by E
+//  E ~ F                F preceded by E
+//  S1, S2               elements matching either S1 or S2
+type Selector struct {
+	selector string
+	groups   [][]*selectorPart
+}
+
+type selectorPart struct {
+	// combinator with the previous part, one of ' ', '>', '+' or '~'.
+	// Zero for the first part.
+	comb     byte
+	compound *compound
+}
+
+type compound struct {
+	tag     string
+	filters []selectorFilter
+}
+
+type selectorFilter func(n *Node, siblings []*Node, pos int) bool
+
+// Compile parses the given CSS selector and returns
+// a Selector. See Selector for the supported syntax.
+func Compile(selector string) (*Selector, error) {
+	p := &selectorParser{s: selector}
+	groups, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %s", selector, err)
+	}
+	return &Selector{selector: selector, groups: groups}, nil
+}
+
+// MustCompile works like Compile, but panics if
+// there's an error.
+func MustCompile(selector string) *Selector {
+	s, err := Compile(selector)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the source of the selector.
+func (s *Selector) String() string {
+	return s.selector
+}
+
+// Select returns all the elements matching the selector in the
+// tree rooted at n (including n itself), in document order.
+func (s *Selector) Select(n *Node) []*Node {
+	var nodes []*Node
+	s.walk(n, nil, []*Node{n}, 0, &nodes)
+	return nodes
+}
+
+// First returns the first element matching the selector in the
+// tree rooted at n, or nil if there are no matches.
+func (s *Selector) First(n *Node) *Node {
+	if nodes := s.Select(n); len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+func (s *Selector) walk(n *Node, ancestors []*Node, siblings []*Node, pos int, nodes *[]*Node) {
+	if n.Type != TAG_NODE {
+		return
+	}
+	for _, v := range s.groups {
+		if matchParts(v, len(v)-1, n, ancestors, siblings, pos) {
+			*nodes = append(*nodes, n)
+			break
+		}
+	}
+	children := elementChildren(n)
+	ancestors = append(ancestors, n)
+	for ii, v := range children {
+		s.walk(v, ancestors, children, ii, nodes)
+	}
+}
+
+func elementChildren(n *Node) []*Node {
+	var children []*Node
+	for c := n.Children; c != nil; c = c.Next {
+		if c.Type == TAG_NODE {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+// matchParts returns wheter parts[:idx+1] match n, using
+// its ancestors (parent last) and siblings. pos is the index
+// of n in siblings.
+func matchParts(parts []*selectorPart, idx int, n *Node, ancestors []*Node, siblings []*Node, pos int) bool {
+	part := parts[idx]
+	if !part.compound.match(n, siblings, pos) {
+		return false
+	}
+	if idx == 0 {
+		return true
+	}
+	switch part.comb {
+	case ' ', '>':
+		for ii := len(ancestors) - 1; ii >= 0; ii-- {
+			var psiblings []*Node
+			ppos := 0
+			if ii > 0 {
+				psiblings = elementChildren(ancestors[ii-1])
+				for jj, v := range psiblings {
+					if v == ancestors[ii] {
+						ppos = jj
+						break
+					}
+				}
+			} else {
+				psiblings = []*Node{ancestors[ii]}
+			}
+			if matchParts(parts, idx-1, ancestors[ii], ancestors[:ii], psiblings, ppos) {
+				return true
+			}
+			if part.comb == '>' {
+				break
+			}
+		}
+	case '+':
+		if pos > 0 {
+			return matchParts(parts, idx-1, siblings[pos-1], ancestors, siblings, pos-1)
+		}
+	case '~':
+		for ii := pos - 1; ii >= 0; ii-- {
+			if matchParts(parts, idx-1, siblings[ii], ancestors, siblings, ii) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *compound) match(n *Node, siblings []*Node, pos int) bool {
+	if n.Type != TAG_NODE {
+		return false
+	}
+	if c.tag != "" && c.tag != "*" && !strings.EqualFold(c.tag, n.Tag) {
+		return false
+	}
+	for _, v := range c.filters {
+		if !v(n, siblings, pos) {
+			return false
+		}
+	}
+	return true
+}
+
+// Select is a shorthand for compiling the given selector and
+// calling its Select method with n.
+func (n *Node) Select(selector string) ([]*Node, error) {
+	s, err := Compile(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.Select(n), nil
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *selectorParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.s) && isSelectorSpace(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func isSelectorSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func (p *selectorParser) ident() (string, error) {
+	start := p.pos
+	for p.pos < len(p.s) && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expecting identifier")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *selectorParser) parse() ([][]*selectorPart, error) {
+	var groups [][]*selectorPart
+	for {
+		p.skipSpace()
+		parts, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, parts)
+		p.skipSpace()
+		switch p.peek() {
+		case 0:
+			return groups, nil
+		case ',':
+			p.pos++
+		default:
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+	}
+}
+
+func (p *selectorParser) parseComplex() ([]*selectorPart, error) {
+	var parts []*selectorPart
+	var comb byte
+	for {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, &selectorPart{comb: comb, compound: c})
+		space := p.skipSpace()
+		switch ch := p.peek(); ch {
+		case 0, ',':
+			return parts, nil
+		case '>', '+', '~':
+			p.pos++
+			p.skipSpace()
+			comb = ch
+		default:
+			if !space {
+				return nil, p.errorf("unexpected %q", ch)
+			}
+			comb = ' '
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (*compound, error) {
+	c := &compound{}
+	if ch := p.peek(); ch == '*' {
+		p.pos++
+		c.tag = "*"
+	} else if isIdentChar(ch) {
+		c.tag, _ = p.ident()
+	}
+	for {
+		var f selectorFilter
+		var err error
+		switch p.peek() {
+		case '#':
+			p.pos++
+			var id string
+			if id, err = p.ident(); err == nil {
+				f = func(n *Node, _ []*Node, _ int) bool { return n.Attr("id") == id }
+			}
+		case '.':
+			p.pos++
+			var class string
+			if class, err = p.ident(); err == nil {
+				f = func(n *Node, _ []*Node, _ int) bool { return hasWord(n.Attr("class"), class) }
+			}
+		case '[':
+			f, err = p.parseAttr()
+		case ':':
+			f, err = p.parsePseudo()
+		default:
+			if c.tag == "" && len(c.filters) == 0 {
+				return nil, p.errorf("expecting selector")
+			}
+			return c, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.filters = append(c.filters, f)
+	}
+}
+
+func (p *selectorParser) parseAttr() (selectorFilter, error) {
+	// Skip [
+	p.pos++
+	p.skipSpace()
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	var op string
+	switch ch := p.peek(); ch {
+	case ']':
+		p.pos++
+		return func(n *Node, _ []*Node, _ int) bool {
+			_, ok := n.Attrs[name]
+			return ok
+		}, nil
+	case '=':
+		op = "="
+		p.pos++
+	case '~', '|', '^', '$', '*':
+		if !strings.HasPrefix(p.s[p.pos+1:], "=") {
+			return nil, p.errorf("expecting =")
+		}
+		op = string(ch) + "="
+		p.pos += 2
+	default:
+		return nil, p.errorf("unexpected %q in attribute selector", ch)
+	}
+	p.skipSpace()
+	var value string
+	if q := p.peek(); q == '"' || q == '\'' {
+		end := strings.IndexByte(p.s[p.pos+1:], q)
+		if end < 0 {
+			return nil, p.errorf("unterminated string")
+		}
+		value = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		if value, err = p.ident(); err != nil {
+			return nil, err
+		}
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.errorf("expecting ]")
+	}
+	p.pos++
+	var match func(string) bool
+	switch op {
+	case "=":
+		match = func(s string) bool { return s == value }
+	case "~=":
+		match = func(s string) bool { return hasWord(s, value) }
+	case "|=":
+		match = func(s string) bool { return s == value || strings.HasPrefix(s, value+"-") }
+	case "^=":
+		match = func(s string) bool { return value != "" && strings.HasPrefix(s, value) }
+	case "$=":
+		match = func(s string) bool { return value != "" && strings.HasSuffix(s, value) }
+	case "*=":
+		match = func(s string) bool { return value != "" && strings.Contains(s, value) }
+	}
+	return func(n *Node, _ []*Node, _ int) bool {
+		v, ok := n.Attrs[name]
+		return ok && match(v)
+	}, nil
+}
+
+func (p *selectorParser) parsePseudo() (selectorFilter, error) {
+	// Skip :
+	p.pos++
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(name) {
+	case "first-child":
+		return func(_ *Node, _ []*Node, pos int) bool { return pos == 0 }, nil
+	case "last-child":
+		return func(_ *Node, siblings []*Node, pos int) bool { return pos == len(siblings)-1 }, nil
+	case "only-child":
+		return func(_ *Node, siblings []*Node, _ int) bool { return len(siblings) == 1 }, nil
+	case "empty":
+		return func(n *Node, _ []*Node, _ int) bool {
+			for c := n.Children; c != nil; c = c.Next {
+				if c.Type == TAG_NODE || c.Content != "" {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case "nth-child":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		a, b, err := parseNth(arg)
+		if err != nil {
+			return nil, p.errorf("invalid :nth-child argument %q", arg)
+		}
+		return func(_ *Node, _ []*Node, pos int) bool {
+			idx := pos + 1
+			if a == 0 {
+				return idx == b
+			}
+			return (idx-b)%a == 0 && (idx-b)/a >= 0
+		}, nil
+	case "not":
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		sub := &selectorParser{s: arg}
+		c, err := sub.parseCompound()
+		if err != nil || sub.pos != len(arg) {
+			return nil, p.errorf("invalid :not argument %q", arg)
+		}
+		return func(n *Node, siblings []*Node, pos int) bool { return !c.match(n, siblings, pos) }, nil
+	}
+	return nil, p.errorf("unsupported pseudo-class :%s", name)
+}
+
+func (p *selectorParser) parseArg() (string, error) {
+	if p.peek() != '(' {
+		return "", p.errorf("expecting (")
+	}
+	end := strings.IndexByte(p.s[p.pos:], ')')
+	if end < 0 {
+		return "", p.errorf("expecting )")
+	}
+	arg := strings.TrimSpace(p.s[p.pos+1 : p.pos+end])
+	p.pos += end + 1
+	return arg, nil
+}
+
+// parseNth parses an an+b expression, as used
+// by :nth-child.
+func parseNth(s string) (int, int, error) {
+	s = strings.ToLower(strings.Replace(s, " ", "", -1))
+	switch s {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	n := strings.IndexByte(s, 'n')
+	if n < 0 {
+		b, err := strconv.Atoi(s)
+		return 0, b, err
+	}
+	var a, b int
+	switch as := s[:n]; as {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		var err error
+		if a, err = strconv.Atoi(as); err != nil {
+			return 0, 0, err
+		}
+	}
+	if bs := s[n+1:]; bs != "" {
+		var err error
+		if b, err = strconv.Atoi(strings.TrimPrefix(bs, "+")); err != nil {
+			return 0, 0, err
+		}
+	}
+	return a, b, nil
+}
+
+func hasWord(s string, word string) bool {
+	for _, v := range strings.Fields(s) {
+		if v == word {
+			return true
+		}
+	}
+	return false
+}
@@ -134,7 +134,7 @@ func readString(r io.Reader) (string, error) {
 		return "", err
 	}
 	b := make([]byte, int(s))
-	if _, err := r.Read(b); err != nil {
+	if _, err := io.ReadFull(r, b); err != nil {
 		return "", err
 	}
 	return string(b), nil
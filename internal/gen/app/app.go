@@ -174,7 +174,7 @@ func (app *App) Gen(release bool) error {
 				name := fmt.Sprintf("tmpl_%s", suffix)
 				fmt.Fprintf(&buf, "%s := template.New(templatesFS, manager)\n", name)
 				fmt.Fprintf(&buf, "%s.Funcs(map[string]interface{}{\n", name)
-				funcNames := []string{"t", "tn", "tc", "tnc", "reverse"}
+				funcNames := []string{"t", "tn", "tc", "tnc", "can", "reverse"}
 				for _, v := range funcNames {
 					fmt.Fprintf(&buf, "\"%s\": func(_ ...interface{}) interface{} { return nil },\n", v)
 				}
@@ -61,6 +61,9 @@ func AppEngineAppHost() string {
 }
 
 func init() {
-	inTest = strings.Contains(os.Args[0], string(filepath.Separator)+"_test"+string(filepath.Separator))
+	// Older go versions build the test binary into a _test directory,
+	// while newer ones just name it pkg.test
+	inTest = strings.Contains(os.Args[0], string(filepath.Separator)+"_test"+string(filepath.Separator)) ||
+		strings.HasSuffix(strings.TrimSuffix(os.Args[0], ".exe"), ".test")
 	goRun = strings.Contains(os.Args[0], "_obj"+string(filepath.Separator)+"exe")
 }
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultAsyncQueueSize is the queue size used by NewAsyncWriter
+// when a non-positive size is provided.
+const DefaultAsyncQueueSize = 1024
+
+var errAsyncClosed = errors.New("async log writer is closed")
+
+type asyncMessage struct {
+	level LLevel
+	flags int
+	data  []byte
+	entry *Entry
+}
+
+// AsyncWriter wraps another Writer, queueing the messages and writing
+// them from a separate goroutine, so logging never blocks on slow
+// writers (e.g. network or disk). The queue is bounded: when it's full,
+// messages are dropped rather than blocking the caller. The number of
+// dropped messages is available from Dropped and reported to the wrapped
+// writer as a warning once the queue has room again.
+//
+// AsyncWriter implements EntryWriter. If the wrapped writer doesn't, entries
+// are formatted as text before passing them to it.
+type AsyncWriter struct {
+	w        Writer
+	queue    chan *asyncMessage
+	done     chan struct{}
+	mu       sync.RWMutex
+	closed   bool
+	dropped  uint64
+	reported uint64
+}
+
+// NewAsyncWriter returns an AsyncWriter which writes to w, queueing
+// up to size messages. If size is not positive, DefaultAsyncQueueSize
+// is used.
+func NewAsyncWriter(w Writer, size int) *AsyncWriter {
+	if size <= 0 {
+		size = DefaultAsyncQueueSize
+	}
+	a := &AsyncWriter{
+		w:     w,
+		queue: make(chan *asyncMessage, size),
+		done:  make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Level returns the level of the wrapped writer.
+func (a *AsyncWriter) Level() LLevel {
+	return a.w.Level()
+}
+
+// Write queues the message for writing it to the wrapped writer.
+// Since the message is written later, errors from the wrapped writer
+// are not returned. Write only returns an error after Close.
+func (a *AsyncWriter) Write(level LLevel, flags int, b []byte) (int, error) {
+	// b is reused by the Logger, so it must be copied
+	data := make([]byte, len(b))
+	copy(data, b)
+	if err := a.enqueue(&asyncMessage{level: level, flags: flags, data: data}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteEntry queues the entry for writing it to the wrapped writer.
+// Like Write, it only returns an error after Close.
+func (a *AsyncWriter) WriteEntry(e *Entry) error {
+	return a.enqueue(&asyncMessage{level: e.Level, flags: e.Flags, entry: e})
+}
+
+func (a *AsyncWriter) enqueue(m *asyncMessage) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return errAsyncClosed
+	}
+	select {
+	case a.queue <- m:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped returns the number of messages dropped so
+// far because the queue was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Queued returns the number of messages waiting
+// to be written.
+func (a *AsyncWriter) Queued() int {
+	return len(a.queue)
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for m := range a.queue {
+		if dropped := atomic.LoadUint64(&a.dropped); dropped > a.reported {
+			a.write(&asyncMessage{
+				level: LWarning,
+				entry: &Entry{
+					Time:    time.Now(),
+					Level:   LWarning,
+					Message: fmt.Sprintf("log queue full, dropped %d messages", dropped-a.reported),
+					Flags:   m.flags,
+				},
+			})
+			a.reported = dropped
+		}
+		a.write(m)
+	}
+}
+
+func (a *AsyncWriter) write(m *asyncMessage) {
+	if m.level < a.w.Level() {
+		return
+	}
+	if m.entry == nil {
+		a.w.Write(m.level, m.flags, m.data)
+		return
+	}
+	if ew, ok := a.w.(EntryWriter); ok {
+		ew.WriteEntry(m.entry)
+		return
+	}
+	a.w.Write(m.level, m.flags, formatEntry(m.entry))
+}
+
+// Close stops accepting new messages and waits until all the
+// queued ones have been written. If the wrapped writer has a
+// Close method, it's called afterwards.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	<-a.done
+	if c, ok := a.w.(closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type closer interface {
+	Close() error
+}
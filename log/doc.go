@@ -3,4 +3,14 @@
 // Users should not use this package directly. Instead, the
 // gnd.la/app.Context.Logger method should be used to obtain
 // an Interface to log messages.
+//
+// Loggers might carry structured fields (see Logger.With), which
+// are appended as key=value pairs by text writers like IOWriter
+// and encoded as JSON object keys by JSONWriter.
+//
+// Besides IOWriter and JSONWriter, messages might be written to rotating
+// files (FileWriter), sent to a syslog server (SyslogWriter) or sent by
+// email, optionally batched into digests (SmtpWriter). Any Writer can be
+// wrapped with an AsyncWriter, so slow writers don't block the goroutine
+// logging the message.
 package log
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field is a key/value pair attached to log messages. Fields
+// are added to a Logger using Logger.With or Logger.WithFields.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Entry represents a log message with its fields, as received
+// by an EntryWriter.
+type Entry struct {
+	// Time is the time when the message was logged.
+	Time time.Time
+	// Level is the message level.
+	Level LLevel
+	// Message is the logged message, without any header
+	// nor trailing newline.
+	Message string
+	// File and Line indicate the caller location. They're
+	// only set when the Logger has the Lshortfile or
+	// Llongfile flags.
+	File string
+	Line int
+	// Fields contains the fields attached to the Logger,
+	// in the order they were added.
+	Fields []Field
+	// Flags are the flags of the Logger which logged
+	// the message.
+	Flags int
+}
+
+// EntryWriter is implemented by writers which handle structured
+// log messages. When a Writer implements EntryWriter, the Logger
+// calls WriteEntry rather than Write.
+type EntryWriter interface {
+	Writer
+	WriteEntry(e *Entry) error
+}
+
+// appendFields appends the given fields to buf, using the
+// key=value format. Values containing spaces, quotes or
+// equal signs are quoted.
+func appendFields(buf []byte, fields []Field) []byte {
+	for _, v := range fields {
+		buf = append(buf, ' ')
+		buf = append(buf, v.Key...)
+		buf = append(buf, '=')
+		s := fmt.Sprint(v.Value)
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			buf = strconv.AppendQuote(buf, s)
+		} else {
+			buf = append(buf, s...)
+		}
+	}
+	return buf
+}
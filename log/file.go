@@ -0,0 +1,295 @@
+// +build !appengine
+
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const rotatedTimeFormat = "20060102-150405"
+
+var errFileClosed = errors.New("log file is closed")
+
+// RotateOptions specify when a FileWriter rotates its
+// file and what happens to the rotated files.
+type RotateOptions struct {
+	// MaxSize is the maximum size of the file in bytes. When a message
+	// would make the file grow beyond MaxSize, the file is rotated
+	// before writing it. Zero disables size based rotation.
+	MaxSize int64
+	// Interval is the maximum time a file is written to before it's
+	// rotated (e.g. 24 * time.Hour for daily files). Zero disables
+	// time based rotation.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to keep. The
+	// oldest ones are removed after rotating. Zero keeps all the
+	// rotated files.
+	MaxBackups int
+	// Compress indicates if rotated files should be compressed
+	// using gzip. Compression is done in the background.
+	Compress bool
+}
+
+// FileWriter writes log messages to a file, rotating it as specified
+// by its RotateOptions. Rotated files are renamed by appending the
+// time of the rotation to the file name (e.g. app.log.20140102-150405)
+// and, optionally, compressed (adding the .gz extension).
+type FileWriter struct {
+	mutex    sync.Mutex
+	filename string
+	level    LLevel
+	opts     RotateOptions
+	file     *os.File
+	size     int64
+	opened   time.Time
+	wg       sync.WaitGroup
+	// serializes compression and pruning of rotated files
+	rotated sync.Mutex
+}
+
+// NewFileWriter returns a FileWriter which writes the messages with at
+// least the given level to the given file, appending to it if it already
+// exists. If opts is nil, the file is never rotated.
+func NewFileWriter(filename string, level LLevel, opts *RotateOptions) (*FileWriter, error) {
+	w := &FileWriter{filename: filename, level: level}
+	if opts != nil {
+		w.opts = *opts
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = st.Size()
+	w.opened = time.Now()
+	return nil
+}
+
+func (w *FileWriter) Level() LLevel {
+	return w.level
+}
+
+// Filename returns the name of the file currently being written.
+func (w *FileWriter) Filename() string {
+	return w.filename
+}
+
+func (w *FileWriter) Write(level LLevel, flags int, b []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return 0, errFileClosed
+	}
+	size := int64(len(b))
+	newline := len(b) == 0 || b[len(b)-1] != '\n'
+	if newline {
+		size++
+	}
+	if w.shouldRotate(size) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(b)
+	if err == nil && newline {
+		var n1 int
+		n1, err = w.file.Write([]byte{'\n'})
+		n += n1
+	}
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *FileWriter) shouldRotate(size int64) bool {
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+size > w.opts.MaxSize {
+		return true
+	}
+	return w.opts.Interval > 0 && time.Since(w.opened) >= w.opts.Interval
+}
+
+// Rotate rotates the file immediately, regardless of the
+// RotateOptions (e.g. when receiving a signal).
+func (w *FileWriter) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return errFileClosed
+	}
+	return w.rotate()
+}
+
+func (w *FileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	base := w.filename + "." + time.Now().Format(rotatedTimeFormat)
+	name := base
+	for ii := 1; fileExists(name) || fileExists(name+".gz"); ii++ {
+		name = fmt.Sprintf("%s-%d", base, ii)
+	}
+	if err := os.Rename(w.filename, name); err != nil {
+		// Keep writing to the same file
+		if oerr := w.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	if w.opts.Compress || w.opts.MaxBackups > 0 {
+		w.wg.Add(1)
+		go w.afterRotate(name)
+	}
+	return nil
+}
+
+// afterRotate compresses the rotated file, if requested,
+// and removes the oldest backups.
+func (w *FileWriter) afterRotate(name string) {
+	defer w.wg.Done()
+	w.rotated.Lock()
+	defer w.rotated.Unlock()
+	if w.opts.Compress {
+		if err := compressFile(name); err != nil {
+			fmt.Fprintf(os.Stderr, "error compressing log file %s: %s\n", name, err)
+		}
+	}
+	if w.opts.MaxBackups > 0 {
+		backups, err := w.Backups()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listing rotated log files: %s\n", err)
+			return
+		}
+		for len(backups) > w.opts.MaxBackups {
+			if err := os.Remove(backups[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "error removing rotated log file %s: %s\n", backups[0], err)
+			}
+			backups = backups[1:]
+		}
+	}
+}
+
+// Backups returns the rotated files, sorted from
+// the oldest to the newest.
+func (w *FileWriter) Backups() ([]string, error) {
+	matches, err := filepath.Glob(w.filename + ".*")
+	if err != nil {
+		return nil, err
+	}
+	prefix := w.filename + "."
+	var backups rotatedFiles
+	for _, v := range matches {
+		suffix := strings.TrimSuffix(v[len(prefix):], ".gz")
+		if len(suffix) < len(rotatedTimeFormat) {
+			continue
+		}
+		t, err := time.Parse(rotatedTimeFormat, suffix[:len(rotatedTimeFormat)])
+		if err != nil {
+			continue
+		}
+		var n int
+		if rem := suffix[len(rotatedTimeFormat):]; rem != "" {
+			if n, err = strconv.Atoi(strings.TrimPrefix(rem, "-")); err != nil || rem[0] != '-' {
+				continue
+			}
+		}
+		backups = append(backups, &rotatedFile{name: v, t: t, n: n})
+	}
+	sort.Sort(backups)
+	names := make([]string, len(backups))
+	for ii, v := range backups {
+		names[ii] = v.name
+	}
+	return names, nil
+}
+
+// Close closes the file and waits until any pending
+// compression of rotated files finishes.
+func (w *FileWriter) Close() error {
+	w.mutex.Lock()
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mutex.Unlock()
+	w.wg.Wait()
+	return err
+}
+
+type rotatedFile struct {
+	name string
+	t    time.Time
+	// n is the suffix added when several files
+	// are rotated within the same second.
+	n int
+}
+
+type rotatedFiles []*rotatedFile
+
+func (r rotatedFiles) Len() int      { return len(r) }
+func (r rotatedFiles) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r rotatedFiles) Less(i, j int) bool {
+	if r[i].t.Equal(r[j].t) {
+		return r[i].n < r[j].n
+	}
+	return r[i].t.Before(r[j].t)
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	return os.Remove(name)
+}
@@ -29,4 +29,9 @@ type Interface interface {
 	// Errorf formats its arguments like fmt.Printf and records a
 	// log message at the error level.
 	Errorf(format string, args ...interface{})
+
+	// With returns a new Interface which attaches the given
+	// key/value pair to every message it logs, in addition
+	// to any fields attached to the original one.
+	With(key string, value interface{}) Interface
 }
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONWriter writes each log message as a JSON object in its
+// own line, suitable for log aggregation systems. Each object
+// contains the keys time (formatted using RFC 3339), level,
+// message and, when the Logger includes the caller location,
+// file and line. The fields attached to the Logger are added
+// as additional keys, unless they collide with the previous ones.
+type JSONWriter struct {
+	mutex sync.Mutex
+	out   io.Writer
+	level LLevel
+}
+
+// NewJSONWriter returns a new JSONWriter which writes
+// the messages with at least the given level to out.
+func NewJSONWriter(out io.Writer, level LLevel) *JSONWriter {
+	return &JSONWriter{out: out, level: level}
+}
+
+func (w *JSONWriter) Level() LLevel {
+	return w.level
+}
+
+// Write implements the Writer interface by writing the already
+// formatted message b as the message key. It's only called
+// when the JSONWriter is used with a type other than Logger.
+func (w *JSONWriter) Write(level LLevel, flags int, b []byte) (int, error) {
+	err := w.WriteEntry(&Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: strings.TrimSuffix(string(b), "\n"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteEntry implements the EntryWriter interface.
+func (w *JSONWriter) WriteEntry(e *Entry) error {
+	obj := make(map[string]interface{}, len(e.Fields)+5)
+	for _, v := range e.Fields {
+		obj[v.Key] = jsonFieldValue(v.Value)
+	}
+	obj["time"] = e.Time.Format(time.RFC3339Nano)
+	obj["level"] = strings.ToLower(e.Level.String())
+	obj["message"] = e.Message
+	if e.File != "" {
+		obj["file"] = e.File
+		obj["line"] = e.Line
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	_, err = w.out.Write(data)
+	return err
+}
+
+// jsonFieldValue returns the value to be encoded for a field. Errors
+// are encoded as their messages, while values which can't be encoded
+// as JSON are formatted with fmt.Sprint.
+func jsonFieldValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case error:
+		return v.Error()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	}
+	if _, err := json.Marshal(val); err != nil {
+		return fmt.Sprint(val)
+	}
+	return val
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 )
 
@@ -38,6 +39,7 @@ type Logger struct {
 	flags   int // properties
 	level   LLevel
 	writers []Writer // destination for output
+	fields  []Field  // attached to every message
 }
 
 // New creates a new Logger.   The out variable sets the
@@ -121,19 +123,22 @@ func (l *Logger) formatHeader(level LLevel, buf *[]byte, t time.Time, file strin
 	}
 }
 
-func (l *Logger) FormatMessage(level LLevel, calldepth int, s string) []byte {
-	now := time.Now() // get this early.
-	var file string
-	var line int
+func (l *Logger) caller(calldepth int) (string, int) {
 	if l.flags&(Lshortfile|Llongfile) != 0 {
 		// release lock while getting caller info - it's expensive.
-		var ok bool
-		_, file, line, ok = runtime.Caller(calldepth)
-		if !ok {
-			file = "???"
-			line = 0
+		if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+			return file, line
 		}
+		return "???", 0
 	}
+	return "", 0
+}
+
+// FormatMessage returns the text line for the given message, including
+// the header determined by the Logger flags and the Logger fields, if any.
+func (l *Logger) FormatMessage(level LLevel, calldepth int, s string) []byte {
+	now := time.Now() // get this early.
+	file, line := l.caller(calldepth)
 	var buf []byte
 	select {
 	case buf = <-pool:
@@ -142,10 +147,45 @@ func (l *Logger) FormatMessage(level LLevel, calldepth int, s string) []byte {
 		buf = make([]byte, 0, maxPoolCap)
 	}
 	l.formatHeader(level, &buf, now, file, line)
-	buf = append(buf, s...)
+	if len(l.fields) > 0 {
+		buf = append(buf, strings.TrimSuffix(s, "\n")...)
+		buf = appendFields(buf, l.fields)
+	} else {
+		buf = append(buf, s...)
+	}
 	return buf
 }
 
+func (l *Logger) newEntry(level LLevel, calldepth int, s string) *Entry {
+	now := time.Now()
+	file, line := l.caller(calldepth)
+	if l.flags&Lshortfile != 0 {
+		if idx := strings.LastIndex(file, "/"); idx >= 0 {
+			file = file[idx+1:]
+		}
+	}
+	return &Entry{
+		Time:    now,
+		Level:   level,
+		Message: strings.TrimSuffix(s, "\n"),
+		File:    file,
+		Line:    line,
+		Fields:  l.fields,
+		Flags:   l.flags,
+	}
+}
+
+// formatEntry returns the text line for the given Entry, formatted
+// like Logger.FormatMessage would do. It's used by writers which
+// wrap other writers and receive entries, like AsyncWriter.
+func formatEntry(e *Entry) []byte {
+	l := &Logger{flags: e.Flags, fields: e.Fields}
+	buf := make([]byte, 0, len(e.Message)+64)
+	l.formatHeader(e.Level, &buf, e.Time, e.File, e.Line)
+	buf = append(buf, e.Message...)
+	return appendFields(buf, e.Fields)
+}
+
 func (l *Logger) AddWriter(w Writer) {
 	l.writers = append(l.writers, w)
 }
@@ -154,6 +194,33 @@ func (l *Logger) RemoveWriters() {
 	l.writers = nil
 }
 
+// With returns a new Logger which attaches the given key/value pair to
+// every message, in addition to the fields already attached to l. See
+// WithFields for more details.
+func (l *Logger) With(key string, value interface{}) Interface {
+	return l.WithFields(Field{Key: key, Value: value})
+}
+
+// WithFields returns a new Logger which attaches the given fields to
+// every message, in addition to the fields already attached to l. The
+// new Logger has the same writers, flags and level that l had at the
+// time WithFields was called. Text writers receive the fields appended
+// to the message as key=value pairs, while writers implementing
+// EntryWriter (like JSONWriter) receive them as an Entry.
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	cpy := *l
+	cpy.fields = make([]Field, 0, len(l.fields)+len(fields))
+	cpy.fields = append(cpy.fields, l.fields...)
+	cpy.fields = append(cpy.fields, fields...)
+	return &cpy
+}
+
+// Fields returns the fields attached to every message
+// logged by l.
+func (l *Logger) Fields() []Field {
+	return l.fields
+}
+
 // Write is a generic low-level interface to a Logger. By using the calldepth
 // parameters, wrappers can define their own functions which correctly obtain
 // the PC for the callers (otherwise, all the calls to the logging would appear
@@ -174,13 +241,25 @@ func (l *Logger) Writeln(level LLevel, calldepth int, v ...interface{}) {
 func (l *Logger) write(level LLevel, calldepth int, v ...interface{}) {
 	if level >= l.level {
 		s := fmt.Sprint(v...)
-		msg := l.FormatMessage(level, calldepth, s)
+		var msg []byte
+		var entry *Entry
 		for _, w := range l.writers {
-			if level >= w.Level() {
-				w.Write(level, l.flags, msg)
+			if level < w.Level() {
+				continue
+			}
+			if ew, ok := w.(EntryWriter); ok {
+				if entry == nil {
+					entry = l.newEntry(level, calldepth, s)
+				}
+				ew.WriteEntry(entry)
+				continue
 			}
+			if msg == nil {
+				msg = l.FormatMessage(level, calldepth, s)
+			}
+			w.Write(level, l.flags, msg)
 		}
-		if cap(msg) <= maxPoolCap {
+		if msg != nil && cap(msg) <= maxPoolCap {
 			select {
 			case pool <- msg:
 			default:
@@ -348,6 +427,12 @@ func (l *Logger) IsDebug() bool {
 	return l.level <= LDebug
 }
 
+// With returns a new Logger derived from the standard logger which
+// attaches the given key/value pair to every message. See Logger.With.
+func With(key string, value interface{}) Interface {
+	return Std.With(key, value)
+}
+
 // AddWriter adds a writer to the standard logger for the standard logger.
 func SetOutput(out Writer) {
 	Std.AddWriter(out)
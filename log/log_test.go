@@ -0,0 +1,48 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewIOWriter(&buf, LDebug), Lshortlevel, LDebug)
+	logger.Info("no fields")
+	child := logger.With("user", 42).With("path", "/foo bar")
+	child.Infof("hello %s", "world")
+	// Fields must not leak to the parent
+	logger.Errorln("parent")
+	expect := "[I] no fields\n[I] hello world user=42 path=\"/foo bar\"\n[E] parent\n"
+	if s := buf.String(); s != expect {
+		t.Errorf("expecting %q, got %q", expect, s)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(NewJSONWriter(&buf, LInfo), Lshortfile, LDebug)
+	logger.Debug("not written")
+	logger.WithFields(Field{"task", "cleanup"}, Field{"err", errors.New("boom")}, Field{"level", "ignored"}).Warningln("failed")
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("error decoding %q: %s", buf.String(), err)
+	}
+	expect := map[string]interface{}{
+		"message": "failed",
+		"level":   "warning",
+		"task":    "cleanup",
+		"err":     "boom",
+		"file":    "log_test.go",
+	}
+	for k, v := range expect {
+		if obj[k] != v {
+			t.Errorf("expecting %s = %v, got %v", k, v, obj[k])
+		}
+	}
+	if _, ok := obj["time"].(string); !ok {
+		t.Errorf("expecting time to be a string, got %T", obj["time"])
+	}
+}
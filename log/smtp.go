@@ -1,17 +1,53 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"gnd.la/net/mail"
 )
 
+// DefaultSmtpMaxMessages is the default maximum number of
+// messages included in an email sent by a SmtpWriter.
+const DefaultSmtpMaxMessages = 100
+
+// SmtpWriter sends log messages by email. By default, each message is
+// sent synchronously from Write. Setting Interval enables batching the
+// messages into digests, sending at most one email every Interval. The
+// first message after a quiet period is sent immediately. Note that
+// batched messages are sent from a timer, so Flush or Close must be
+// called before the process exits to avoid losing them. Messages with
+// the LFatal level always flush the pending ones synchronously, since
+// the process is about to exit.
 type SmtpWriter struct {
-	level  LLevel
-	server string
-	from   string
-	to     []string
+	// Interval is the minimum time between two emails.
+	// If zero, messages are not batched.
+	Interval time.Duration
+	// MaxMessages is the maximum number of messages included in
+	// each email. Additional messages are counted, but not included.
+	// If zero, DefaultSmtpMaxMessages is used.
+	MaxMessages int
+	level       LLevel
+	server      string
+	from        string
+	to          []string
+	mutex       sync.Mutex
+	pending     []*smtpMessage
+	omitted     int
+	timer       *time.Timer
+	lastSent    time.Time
+	// send is used to send the emails. It's a
+	// variable so it can be replaced in tests.
+	send func(*mail.Message) error
+}
+
+type smtpMessage struct {
+	level LLevel
+	time  time.Time
+	data  []byte
 }
 
 func (w *SmtpWriter) Level() LLevel {
@@ -22,21 +58,106 @@ func (w *SmtpWriter) Write(level LLevel, flags int, b []byte) (int, error) {
 	if w.server == "" || len(w.to) == 0 {
 		return 0, nil
 	}
+	w.mutex.Lock()
+	maxMessages := w.MaxMessages
+	if maxMessages <= 0 {
+		maxMessages = DefaultSmtpMaxMessages
+	}
+	if len(w.pending) < maxMessages {
+		// b is reused by the Logger, so it must be copied
+		data := make([]byte, len(b))
+		copy(data, b)
+		w.pending = append(w.pending, &smtpMessage{level: level, time: time.Now(), data: data})
+	} else {
+		w.omitted++
+	}
+	if level >= LFatal || w.Interval <= 0 {
+		w.mutex.Unlock()
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.timer == nil {
+		delay := w.Interval - time.Since(w.lastSent)
+		if delay < 0 {
+			delay = 0
+		}
+		w.timer = time.AfterFunc(delay, w.flush)
+	}
+	w.mutex.Unlock()
+	return len(b), nil
+}
+
+func (w *SmtpWriter) flush() {
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "error sending log email: %s\n", err)
+	}
+}
+
+// Flush sends any pending messages immediately.
+func (w *SmtpWriter) Flush() error {
+	w.mutex.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	pending := w.pending
+	omitted := w.omitted
+	w.pending = nil
+	w.omitted = 0
+	if len(pending) > 0 {
+		w.lastSent = time.Now()
+	}
+	w.mutex.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	return w.send(w.digest(pending, omitted))
+}
+
+// Close sends any pending messages.
+func (w *SmtpWriter) Close() error {
+	return w.Flush()
+}
 
+func (w *SmtpWriter) digest(pending []*smtpMessage, omitted int) *mail.Message {
 	hostname, _ := os.Hostname()
-	subject := fmt.Sprintf("%s message on %s", level.String(), hostname)
-	err := mail.Send(&mail.Message{
+	level := pending[0].level
+	for _, v := range pending[1:] {
+		if v.level > level {
+			level = v.level
+		}
+	}
+	var subject string
+	if count := len(pending) + omitted; count == 1 {
+		subject = fmt.Sprintf("%s message on %s", level.String(), hostname)
+	} else {
+		subject = fmt.Sprintf("%d messages (up to %s) on %s", count, level.String(), hostname)
+	}
+	var buf bytes.Buffer
+	for ii, v := range pending {
+		if len(pending) > 1 {
+			if ii > 0 {
+				buf.WriteString("\n\n")
+			}
+			fmt.Fprintf(&buf, "--- %s at %s\n", v.level.String(), v.time.Format(time.RFC3339))
+		}
+		buf.Write(v.data)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&buf, "\n\n%d more messages were omitted\n", omitted)
+	}
+	return &mail.Message{
+		Server:   w.server,
+		From:     w.from,
 		To:       w.to,
 		Subject:  subject,
-		TextBody: string(b),
-	})
-	if err != nil {
-		return 0, err
+		TextBody: buf.String(),
 	}
-	return len(b), nil
 }
 
 func NewSmtpWriter(level LLevel, server, from, to string) *SmtpWriter {
 	addrs := mail.MustParseAddressList(to)
-	return &SmtpWriter{level, server, from, addrs}
+	return &SmtpWriter{level: level, server: server, from: from, to: addrs, send: mail.Send}
 }
@@ -0,0 +1,300 @@
+// +build !appengine
+
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Syslog facilities, as defined by RFC 5424.
+const (
+	FacilityKern     = 0
+	FacilityUser     = 1
+	FacilityMail     = 2
+	FacilityDaemon   = 3
+	FacilityAuth     = 4
+	FacilitySyslog   = 5
+	FacilityLocal0   = 16
+	FacilityLocal1   = 17
+	FacilityLocal2   = 18
+	FacilityLocal3   = 19
+	FacilityLocal4   = 20
+	FacilityLocal5   = 21
+	FacilityLocal6   = 22
+	FacilityLocal7   = 23
+	syslogNilValue   = "-"
+	syslogSDID       = "fields@32473"
+	syslogMaxNameLen = 32
+)
+
+var (
+	syslogLocalSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+	errNoLocalSyslog   = errors.New("can't find a local syslog socket")
+)
+
+// SyslogOptions specify the values used in the header
+// of the messages sent by a SyslogWriter.
+type SyslogOptions struct {
+	// Facility is the syslog facility. Note that FacilityKern
+	// can't be used, since zero is interpreted as FacilityUser.
+	Facility int
+	// Hostname is the host name sent with each message. If
+	// empty, the value returned by os.Hostname is used.
+	Hostname string
+	// AppName is the application name sent with each
+	// message. If empty, the executable name is used.
+	AppName string
+}
+
+// SyslogWriter sends log messages to a syslog server, using the
+// format defined by RFC 5424. Fields attached to the Logger are
+// sent as structured data, with the SD-ID fields@32473.
+type SyslogWriter struct {
+	mutex    sync.Mutex
+	network  string
+	addr     string
+	level    LLevel
+	facility int
+	hostname string
+	appName  string
+	conn     net.Conn
+	// octetCounting is true when messages are framed by
+	// their length, as required by TCP syslog servers.
+	octetCounting bool
+	// newline is true when messages are terminated by a
+	// newline, as expected by local syslog daemons listening
+	// on unix stream sockets.
+	newline bool
+}
+
+// NewSyslogWriter returns a SyslogWriter which sends the messages with at
+// least the given level to the syslog server at the given address. Supported
+// networks are udp, tcp, unixgram and unix. If both network and addr are
+// empty, the local syslog daemon is used. Messages sent over TCP are framed
+// using octet counting, as defined by RFC 6587, while the ones sent over
+// unix stream sockets are terminated by a newline.
+func NewSyslogWriter(network, addr string, level LLevel, opts *SyslogOptions) (*SyslogWriter, error) {
+	w := &SyslogWriter{
+		network:  network,
+		addr:     addr,
+		level:    level,
+		facility: FacilityUser,
+	}
+	if opts != nil {
+		if opts.Facility != 0 {
+			w.facility = opts.Facility
+		}
+		w.hostname = opts.Hostname
+		w.appName = opts.AppName
+	}
+	if w.hostname == "" {
+		w.hostname, _ = os.Hostname()
+	}
+	if w.appName == "" {
+		w.appName = filepath.Base(os.Args[0])
+	}
+	w.hostname = syslogHeaderValue(w.hostname, 255)
+	w.appName = syslogHeaderValue(w.appName, 48)
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	if w.network == "" && w.addr == "" {
+		for _, v := range syslogLocalSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				if conn, err := net.Dial(network, v); err == nil {
+					w.conn = conn
+					w.octetCounting = false
+					w.newline = network == "unix"
+					return nil
+				}
+			}
+		}
+		return errNoLocalSyslog
+	}
+	conn, err := net.Dial(w.network, w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	w.octetCounting = strings.HasPrefix(w.network, "tcp")
+	w.newline = w.network == "unix"
+	return nil
+}
+
+func (w *SyslogWriter) Level() LLevel {
+	return w.level
+}
+
+// Write implements the Writer interface. It's only called
+// when the SyslogWriter is used with a type other than Logger.
+func (w *SyslogWriter) Write(level LLevel, flags int, b []byte) (int, error) {
+	err := w.WriteEntry(&Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: strings.TrimSuffix(string(b), "\n"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// WriteEntry implements the EntryWriter interface.
+func (w *SyslogWriter) WriteEntry(e *Entry) error {
+	msg := w.format(e)
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return err
+		}
+	}
+	if err := w.send(msg); err != nil {
+		// Try to reconnect once (e.g. the syslog
+		// daemon was restarted)
+		if err := w.connect(); err != nil {
+			return err
+		}
+		return w.send(msg)
+	}
+	return nil
+}
+
+func (w *SyslogWriter) send(msg []byte) error {
+	var err error
+	switch {
+	case w.octetCounting:
+		_, err = fmt.Fprintf(w.conn, "%d %s", len(msg), msg)
+	case w.newline:
+		_, err = w.conn.Write(append(msg, '\n'))
+	default:
+		_, err = w.conn.Write(msg)
+	}
+	return err
+}
+
+// format returns the RFC 5424 message for the given entry.
+func (w *SyslogWriter) format(e *Entry) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(w.facility*8 + syslogSeverity(e.Level)))
+	buf.WriteString(">1 ")
+	buf.WriteString(e.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteByte(' ')
+	buf.WriteString(w.hostname)
+	buf.WriteByte(' ')
+	buf.WriteString(w.appName)
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.Itoa(os.Getpid()))
+	buf.WriteString(" - ")
+	if len(e.Fields) > 0 || e.File != "" {
+		buf.WriteString("[" + syslogSDID)
+		if e.File != "" {
+			writeSyslogParam(&buf, "file", e.File)
+			writeSyslogParam(&buf, "line", strconv.Itoa(e.Line))
+		}
+		for _, v := range e.Fields {
+			writeSyslogParam(&buf, v.Key, fmt.Sprint(v.Value))
+		}
+		buf.WriteByte(']')
+	} else {
+		buf.WriteString(syslogNilValue)
+	}
+	if e.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(e.Message)
+	}
+	return buf.Bytes()
+}
+
+// Close closes the connection to the syslog server.
+func (w *SyslogWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.conn != nil {
+		err := w.conn.Close()
+		w.conn = nil
+		return err
+	}
+	return nil
+}
+
+func syslogSeverity(level LLevel) int {
+	switch level {
+	case LDebug:
+		return 7
+	case LInfo:
+		return 6
+	case LWarning:
+		return 4
+	case LError:
+		return 3
+	case LPanic:
+		return 2
+	case LFatal:
+		return 1
+	}
+	return 5
+}
+
+// writeSyslogParam writes a SD-PARAM, removing the characters
+// not allowed in its name and escaping its value.
+func writeSyslogParam(buf *bytes.Buffer, name string, value string) {
+	name = strings.Map(func(r rune) rune {
+		if r <= 32 || r >= 127 || r == '=' || r == ']' || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		return
+	}
+	if len(name) > syslogMaxNameLen {
+		name = name[:syslogMaxNameLen]
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	for _, c := range []byte(value) {
+		if c == '"' || c == '\\' || c == ']' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteByte(c)
+	}
+	buf.WriteByte('"')
+}
+
+// syslogHeaderValue returns the given value suitable for using it
+// in a syslog header field, which only allows printable US-ASCII.
+func syslogHeaderValue(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= 32 || r >= 127 {
+			return -1
+		}
+		return r
+	}, value)
+	if value == "" {
+		return syslogNilValue
+	}
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
@@ -0,0 +1,269 @@
+// +build !appengine
+
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gnd.la/net/mail"
+)
+
+type blockingWriter struct {
+	sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Level() LLevel {
+	return LDebug
+}
+
+func (w *blockingWriter) Write(level LLevel, flags int, b []byte) (int, error) {
+	<-w.release
+	w.Lock()
+	defer w.Unlock()
+	return w.buf.Write(b)
+}
+
+func TestAsyncWriter(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{})}
+	w := NewAsyncWriter(bw, 1)
+	logger := New(w, Lshortlevel, LDebug)
+	logger.Info("first")
+	// Wait until the first message is being written
+	for w.Queued() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	logger.Info("second")
+	logger.Info("third")
+	if d := w.Dropped(); d != 1 {
+		t.Errorf("expecting 1 dropped message, got %d", d)
+	}
+	close(bw.release)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expect := "[I] first[W] log queue full, dropped 1 messages[I] second"
+	if s := bw.buf.String(); s != expect {
+		t.Errorf("expecting %q, got %q", expect, s)
+	}
+	// Writing after closing is an error, not a dropped message
+	if _, err := w.Write(LInfo, 0, []byte("closed")); err == nil {
+		t.Error("expecting an error when writing after Close")
+	}
+	if err := w.WriteEntry(&Entry{Level: LInfo, Message: "closed"}); err == nil {
+		t.Error("expecting an error when writing an entry after Close")
+	}
+	if d := w.Dropped(); d != 1 {
+		t.Errorf("expecting 1 dropped message after Close, got %d", d)
+	}
+}
+
+func TestFileWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+	w, err := NewFileWriter(filename, LDebug, &RotateOptions{MaxSize: 20, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(w, 0, LDebug)
+	for _, v := range []string{"message 1", "message 2", "message 3", "message 4", "message 5", "message 6"} {
+		logger.Info(v)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); s != "message 5\nmessage 6\n" {
+		t.Errorf("expecting last 2 messages in %s, got %q", filename, s)
+	}
+	backups, err := w.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expecting 2 backups, got %v", backups)
+	}
+	for _, v := range backups {
+		if !strings.HasSuffix(v, ".gz") {
+			t.Errorf("expecting compressed backup, got %s", v)
+		}
+	}
+	if _, err := w.Write(LInfo, 0, []byte("closed")); err != errFileClosed {
+		t.Errorf("expecting error %v after closing, got %v", errFileClosed, err)
+	}
+}
+
+func TestSyslogWriter(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := NewSyslogWriter("udp", conn.LocalAddr().String(), LInfo, &SyslogOptions{Hostname: "example", AppName: "my app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	logger := New(w, 0, LDebug)
+	logger.With("user", `"42"`).Error("failed")
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<11>1 ") {
+		t.Errorf("invalid PRI/VERSION in %q", msg)
+	}
+	suffix := ` example myapp ` + strconv.Itoa(os.Getpid()) + ` - [fields@32473 user="\"42\""] failed`
+	if !strings.HasSuffix(msg, suffix) {
+		t.Errorf("expecting %q to end with %q", msg, suffix)
+	}
+}
+
+// testSyslogStream returns the data received by a stream
+// syslog server listening on the given network after
+// logging "first" and "second".
+func testSyslogStream(t *testing.T, network string, addr string) string {
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+	w, err := NewSyslogWriter(network, ln.Addr().String(), LInfo, &SyslogOptions{Hostname: "example", AppName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := New(w, 0, LDebug)
+	logger.Info("first")
+	logger.Info("second")
+	w.Close()
+	select {
+	case data := <-received:
+		return data
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog server didn't receive the messages")
+	}
+	return ""
+}
+
+func TestSyslogFraming(t *testing.T) {
+	// TCP uses octet counting
+	data := testSyslogStream(t, "tcp", "127.0.0.1:0")
+	for _, v := range []string{"first", "second"} {
+		idx := strings.Index(data, " ")
+		n, err := strconv.Atoi(data[:idx])
+		if err != nil {
+			t.Fatalf("invalid octet count in %q", data)
+		}
+		msg := data[idx+1 : idx+1+n]
+		if !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, " "+v) {
+			t.Errorf("unexpected message %q", msg)
+		}
+		data = data[idx+1+n:]
+	}
+	if data != "" {
+		t.Errorf("unexpected trailing data %q", data)
+	}
+	// Unix stream sockets use newlines
+	dir, err := ioutil.TempDir("", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data = testSyslogStream(t, "unix", filepath.Join(dir, "log"))
+	lines := strings.Split(data, "\n")
+	if len(lines) != 3 || lines[2] != "" {
+		t.Fatalf("expecting 2 newline terminated messages, got %q", data)
+	}
+	for ii, v := range []string{"first", "second"} {
+		if msg := lines[ii]; !strings.HasPrefix(msg, "<14>1 ") || !strings.HasSuffix(msg, " "+v) {
+			t.Errorf("unexpected message %q", msg)
+		}
+	}
+}
+
+func TestSmtpSynchronous(t *testing.T) {
+	var sent []*mail.Message
+	w := NewSmtpWriter(LError, "localhost:25", "from@example.com", "to@example.com")
+	w.send = func(m *mail.Message) error {
+		sent = append(sent, m)
+		return nil
+	}
+	logger := New(w, 0, LDebug)
+	logger.Error("error 1")
+	logger.Error("error 2")
+	if len(sent) != 2 {
+		t.Fatalf("expecting 2 emails sent synchronously, got %d", len(sent))
+	}
+	if body := sent[1].TextBody; body != "error 2" {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestSmtpDigest(t *testing.T) {
+	var sent []*mail.Message
+	w := NewSmtpWriter(LError, "localhost:25", "from@example.com", "to@example.com")
+	w.MaxMessages = 2
+	w.Interval = time.Hour
+	w.send = func(m *mail.Message) error {
+		sent = append(sent, m)
+		return nil
+	}
+	// Pretend an email was just sent, so messages are batched
+	w.lastSent = time.Now()
+	logger := New(w, 0, LDebug)
+	logger.Info("ignored")
+	logger.Error("error 1")
+	logger.Error("error 2")
+	logger.Error("error 3")
+	if len(sent) != 0 {
+		t.Fatalf("expecting no emails before flushing, got %d", len(sent))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expecting 1 email, got %d", len(sent))
+	}
+	m := sent[0]
+	if !strings.HasPrefix(m.Subject, "3 messages (up to Error) on ") {
+		t.Errorf("unexpected subject %q", m.Subject)
+	}
+	body := m.TextBody
+	if !strings.Contains(body, "error 1") || !strings.Contains(body, "error 2") || strings.Contains(body, "error 3") {
+		t.Errorf("unexpected body %q", body)
+	}
+	if !strings.Contains(body, "1 more messages were omitted") {
+		t.Errorf("expecting omitted count in body %q", body)
+	}
+}
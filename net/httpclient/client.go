@@ -54,6 +54,7 @@ func New(ctx Context) *Client {
 	if log, ok := ctx.(logger); ok {
 		client.logger = log.Logger()
 	}
+	tr.logger = client.logger
 	return client
 }
 
@@ -74,6 +75,7 @@ func (c *Client) Clone(ctx Context) *Client {
 	} else {
 		cp.logger = nil
 	}
+	tr.logger = cp.logger
 	return cp
 }
 
@@ -105,6 +107,22 @@ func (c *Client) SetTimeout(timeout time.Duration) *Client {
 	return c
 }
 
+// RetryPolicy returns the RetryPolicy for this client, or
+// nil if requests are not retried.
+func (c *Client) RetryPolicy() *RetryPolicy {
+	return c.transport.retry
+}
+
+// SetRetryPolicy sets the policy used for retrying failed requests
+// and for breaking the circuit to failing hosts. Setting it to nil
+// disables retries. Since the policy is applied by the Transport,
+// it affects all the requests sent by the Client, including Trip
+// and the requests made by an Iter. See RetryPolicy for more details.
+func (c *Client) SetRetryPolicy(p *RetryPolicy) *Client {
+	c.transport.retry = p
+	return c
+}
+
 // HTTPClient returns the *http.Client used by this Client.
 func (c *Client) HTTPClient() *http.Client {
 	return c.c
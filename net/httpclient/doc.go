@@ -6,4 +6,7 @@
 //
 // Also, requests made with an httpclient instance are properly measured
 // when profiling an app.
+//
+// To test code which uses this package without network access, see
+// gnd.la/net/httpclient/recorder.
 package httpclient
@@ -0,0 +1,181 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+var (
+	// Request headers which are never saved to the fixtures.
+	redactedHeaders = []string{"Authorization", "Cookie"}
+	// Response headers which are never saved to the fixtures.
+	redactedResponseHeaders = []string{"Set-Cookie"}
+)
+
+type fixture struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  *Request  `json:"request"`
+	Response *Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+func (r *Response) httpResponse(req *http.Request) *http.Response {
+	header := cloneHeader(r.Header)
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func cloneHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+func (r *Recorder) isIgnored(name string) bool {
+	for _, v := range r.IgnoredParams {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requestKey returns the key used for matching requests, formed
+// by the method, the URL with its query parameters sorted and the
+// normalized body. Ignored parameters are removed from both the
+// query and the body.
+func (r *Recorder) requestKey(method string, rawurl string, contentType string, body []byte) string {
+	u, err := url.Parse(rawurl)
+	if err == nil {
+		u.RawQuery = r.filterValues(u.Query(), nil).Encode()
+		rawurl = u.String()
+	}
+	return strings.ToUpper(method) + " " + rawurl + "\n" + string(r.normalizeBody(contentType, body, nil))
+}
+
+// filterValues removes the ignored parameters from values, or sets
+// them to repl if it's non-nil.
+func (r *Recorder) filterValues(values url.Values, repl *string) url.Values {
+	for k := range values {
+		if r.isIgnored(k) {
+			if repl != nil {
+				values[k] = []string{*repl}
+			} else {
+				delete(values, k)
+			}
+		}
+	}
+	return values
+}
+
+// normalizeBody returns the body in a canonical form when its
+// type is known: form encoded bodies have their keys sorted and
+// JSON bodies are reencoded without whitespace and with sorted keys.
+// Ignored parameters are removed or replaced by repl. Other bodies
+// are returned unchanged.
+func (r *Recorder) normalizeBody(contentType string, body []byte, repl *string) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			return []byte(r.filterValues(values, repl).Encode())
+		}
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		var v interface{}
+		if err := dec.Decode(&v); err == nil {
+			if m, ok := v.(map[string]interface{}); ok {
+				for k := range m {
+					if r.isIgnored(k) {
+						if repl != nil {
+							m[k] = *repl
+						} else {
+							delete(m, k)
+						}
+					}
+				}
+			}
+			if data, err := json.Marshal(v); err == nil {
+				return data
+			}
+		}
+	}
+	return body
+}
+
+func (r *Recorder) redactURL(u *url.URL) string {
+	c := *u
+	if c.RawQuery != "" {
+		repl := redacted
+		query := c.Query()
+		for k := range query {
+			if r.isIgnored(k) {
+				c.RawQuery = r.filterValues(query, &repl).Encode()
+				break
+			}
+		}
+	}
+	return c.String()
+}
+
+func (r *Recorder) redactBody(contentType string, body []byte) []byte {
+	repl := redacted
+	return r.normalizeBody(contentType, body, &repl)
+}
+
+func redactHeader(h http.Header, names []string) http.Header {
+	h = cloneHeader(h)
+	for _, v := range names {
+		if _, ok := h[v]; ok {
+			h[v] = []string{redacted}
+		}
+	}
+	return h
+}
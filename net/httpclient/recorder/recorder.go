@@ -0,0 +1,326 @@
+// Package recorder implements an http.RoundTripper which records
+// HTTP interactions to fixture files and replays them later, allowing
+// code which talks to remote APIs to be tested without network access.
+//
+// A Recorder is usually attached to an *httpclient.Client in a test:
+//
+//	func TestSomething(t *testing.T) {
+//	    c := httpclient.New(nil)
+//	    rec, err := recorder.Attach(c, "testdata/something.json")
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//	    defer rec.Close()
+//	    // Use c as usual
+//	}
+//
+// By default, tests run in replay mode, which serves the responses from
+// the fixture files and fails on any request which was not recorded. To
+// (re)generate the fixtures, run the tests with -httpclient.mode=record.
+// Use -httpclient.mode=live to hit the network without touching the
+// fixtures.
+//
+// The values of the Authorization and Cookie request headers and the
+// Set-Cookie response headers are never saved to the fixture files.
+// See Recorder.IgnoredParams for redacting parameters.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gnd.la/net/httpclient"
+)
+
+// Mode indicates how a Recorder handles requests.
+type Mode int
+
+const (
+	// Replay serves the responses from the fixture file and
+	// returns an error for requests which were not recorded.
+	Replay Mode = iota
+	// Record sends the requests using the underlying
+	// http.RoundTripper and records the interactions, saving
+	// them to the fixture file when the Recorder is closed.
+	Record
+	// Live sends the requests using the underlying http.RoundTripper,
+	// without reading nor writing the fixture file.
+	Live
+)
+
+var modeNames = map[Mode]string{
+	Replay: "replay",
+	Record: "record",
+	Live:   "live",
+}
+
+func (m Mode) String() string {
+	if s, ok := modeNames[m]; ok {
+		return s
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// Set implements flag.Value.
+func (m *Mode) Set(s string) error {
+	for k, v := range modeNames {
+		if v == s {
+			*m = k
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid mode %q, must be replay, record or live", s)
+}
+
+var (
+	// DefaultMode is the Mode used by Attach. It's set from the
+	// -httpclient.mode command line flag, which defaults to replay.
+	DefaultMode = Replay
+
+	// DefaultIgnoredParams are the parameters used by default as the
+	// Recorder IgnoredParams. They include the most common parameters
+	// used for authentication and request signing.
+	DefaultIgnoredParams = []string{
+		"access_token",
+		"appsecret_proof",
+		"client_secret",
+		"oauth_nonce",
+		"oauth_signature",
+		"oauth_timestamp",
+		"refresh_token",
+	}
+)
+
+func init() {
+	flag.Var(&DefaultMode, "httpclient.mode", "Mode for gnd.la/net/httpclient/recorder: replay, record or live")
+}
+
+// UnexpectedRequestError is returned (wrapped in a *url.Error by
+// http.Client) when a request can't be matched against any recorded
+// interaction in Replay mode.
+type UnexpectedRequestError struct {
+	Method   string
+	URL      string
+	Filename string
+}
+
+func (e *UnexpectedRequestError) Error() string {
+	return fmt.Sprintf("unexpected request %s %s, not recorded in %s (run the tests with -httpclient.mode=record to update it)", e.Method, e.URL, e.Filename)
+}
+
+// Recorder is an http.RoundTripper which records and replays HTTP
+// interactions. See the package documentation for an example.
+type Recorder struct {
+	// Mode indicates the Recorder mode. It must not be
+	// changed after the Recorder is used for the first time.
+	Mode Mode
+	// Filename is the fixture file where the interactions
+	// are read from or saved to.
+	Filename string
+	// Underlying is the http.RoundTripper used to send the
+	// requests in Record and Live modes.
+	Underlying http.RoundTripper
+	// IgnoredParams are the names of the query or form parameters
+	// (as well as JSON top level keys) which are ignored when matching
+	// requests. Their values are also replaced by a placeholder in the
+	// fixture files, so credentials are not saved to them.
+	IgnoredParams []string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+	client       *httpclient.Client
+	prev         http.RoundTripper
+}
+
+// New returns a new Recorder with the given mode and fixture
+// file. In Replay mode the fixture file is loaded immediately,
+// returning an error if it can't be read. underlying might be
+// nil, in which case http.DefaultTransport is used.
+func New(filename string, mode Mode, underlying http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		Mode:          mode,
+		Filename:      filename,
+		Underlying:    underlying,
+		IgnoredParams: DefaultIgnoredParams,
+	}
+	if mode == Replay {
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Attach creates a new Recorder using DefaultMode and the
+// given fixture file and sets it as the underlying http.RoundTripper
+// of the given client's Transport. Calling Close on the returned
+// Recorder restores the previous http.RoundTripper.
+func Attach(c *httpclient.Client, filename string) (*Recorder, error) {
+	tr := c.Transport()
+	r, err := New(filename, DefaultMode, tr.Underlying())
+	if err != nil {
+		return nil, err
+	}
+	r.client = c
+	r.prev = tr.Underlying()
+	tr.SetUnderlying(r)
+	return r, nil
+}
+
+// Interactions returns the interactions recorded so far in
+// Record mode, or the ones loaded from the fixture file in
+// Replay mode.
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.interactions...)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch r.Mode {
+	case Replay:
+		return r.replay(req)
+	case Record:
+		return r.record(req)
+	}
+	return r.underlying().RoundTrip(req)
+}
+
+// Close saves the recorded interactions to the fixture file in
+// Record mode. If the Recorder was created with Attach, the previous
+// http.RoundTripper is restored.
+func (r *Recorder) Close() error {
+	if r.client != nil {
+		r.client.Transport().SetUnderlying(r.prev)
+		r.client = nil
+	}
+	if r.Mode == Record {
+		return r.save()
+	}
+	return nil
+}
+
+func (r *Recorder) underlying() http.RoundTripper {
+	if r.Underlying != nil {
+		return r.Underlying
+	}
+	return http.DefaultTransport
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := r.requestKey(req.Method, req.URL.String(), req.Header.Get("Content-Type"), body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	match := -1
+	for ii, v := range r.interactions {
+		if r.requestKey(v.Request.Method, v.Request.URL, v.Request.Header.Get("Content-Type"), []byte(v.Request.Body)) != key {
+			continue
+		}
+		// Prefer interactions which have not been used yet, so
+		// the same request might return different responses,
+		// but reuse the last one if all of them were used.
+		match = ii
+		if !r.used[ii] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, &UnexpectedRequestError{Method: req.Method, URL: req.URL.String(), Filename: r.Filename}
+	}
+	r.used[match] = true
+	return r.interactions[match].Response.httpResponse(req), nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.underlying().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	in := &Interaction{
+		Request: &Request{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL),
+			Header: redactHeader(req.Header, redactedHeaders),
+			Body:   string(r.redactBody(req.Header.Get("Content-Type"), body)),
+		},
+		Response: &Response{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header, redactedResponseHeaders),
+			Body:       string(respBody),
+		},
+	}
+	r.mu.Lock()
+	r.interactions = append(r.interactions, in)
+	r.used = append(r.used, true)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) load() error {
+	data, err := ioutil.ReadFile(r.Filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("fixture %s does not exist, run the tests with -httpclient.mode=record to create it", r.Filename)
+		}
+		return err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("error decoding fixture %s: %s", r.Filename, err)
+	}
+	r.interactions = f.Interactions
+	r.used = make([]bool, len(r.interactions))
+	return nil
+}
+
+func (r *Recorder) save() error {
+	r.mu.Lock()
+	f := fixture{Interactions: r.interactions}
+	r.mu.Unlock()
+	data, err := json.MarshalIndent(&f, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Filename), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Filename, append(data, '\n'), 0644)
+}
+
+// readBody reads the request body and replaces it with
+// a new reader, so it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,141 @@
+package recorder
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gnd.la/net/httpclient"
+)
+
+func testServer(calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		r.ParseForm()
+		w.Header().Set("Content-Type", "text/plain")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		fmt.Fprintf(w, "%s %s %s %d", r.Method, r.URL.Path, r.Form.Get("q"), *calls)
+	}))
+}
+
+func responseReader(t *testing.T) func(*httpclient.Response, error) string {
+	return func(resp *httpclient.Response, err error) string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "testdata", "fixture.json")
+	calls := 0
+	srv := testServer(&calls)
+	defer srv.Close()
+
+	readResponse := responseReader(t)
+	c := httpclient.New(nil)
+	prev := c.Transport().Underlying()
+	defer func(m Mode) { DefaultMode = m }(DefaultMode)
+	DefaultMode = Record
+	rec, err := Attach(c, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded []string
+	recorded = append(recorded, readResponse(c.Get(srv.URL+"/a?q=1&access_token=secret")))
+	recorded = append(recorded, readResponse(c.PostForm(srv.URL+"/b", url.Values{"q": {"2"}, "z": {"3"}})))
+	recorded = append(recorded, readResponse(c.Post(srv.URL+"/c", "application/json", strings.NewReader(`{"b": 1, "a": [1, 2]}`))))
+	recorded = append(recorded, readResponse(c.Get(srv.URL+"/a?q=1")))
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Transport().Underlying() != prev {
+		t.Error("Close() did not restore the underlying transport")
+	}
+	if calls != len(recorded) {
+		t.Fatalf("expecting %d calls, got %d", len(recorded), calls)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("fixture contains ignored parameter or cookie value:\n%s", string(data))
+	}
+
+	DefaultMode = Replay
+	rec, err = Attach(c, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Close()
+	var replayed []string
+	// Different parameter order and different value for an ignored parameter
+	replayed = append(replayed, readResponse(c.Get(srv.URL+"/a?access_token=other&q=1")))
+	replayed = append(replayed, readResponse(c.Post(srv.URL+"/b", "application/x-www-form-urlencoded", strings.NewReader("z=3&q=2"))))
+	replayed = append(replayed, readResponse(c.Post(srv.URL+"/c", "application/json", strings.NewReader(`{"a":[1,2],"b":1}`))))
+	replayed = append(replayed, readResponse(c.Get(srv.URL+"/a?q=1")))
+	if calls != len(recorded) {
+		t.Errorf("replaying made %d calls to the server", calls-len(recorded))
+	}
+	for ii := range recorded {
+		if recorded[ii] != replayed[ii] {
+			t.Errorf("interaction %d: recorded %q, replayed %q", ii, recorded[ii], replayed[ii])
+		}
+	}
+	// All the matching interactions have been used, the last one is reused
+	if s := readResponse(c.Get(srv.URL + "/a?q=1")); s != recorded[3] {
+		t.Errorf("expecting %q when reusing interaction, got %q", recorded[3], s)
+	}
+	_, err = c.Get(srv.URL + "/a?q=3")
+	if err == nil {
+		t.Fatal("expecting an error for an unexpected request")
+	}
+	if uerr, ok := err.(*url.Error); !ok || !isUnexpected(uerr.Err) {
+		t.Errorf("expecting *UnexpectedRequestError, got %T: %v", err, err)
+	}
+}
+
+func isUnexpected(err error) bool {
+	_, ok := err.(*UnexpectedRequestError)
+	return ok
+}
+
+func TestReplayMissingFixture(t *testing.T) {
+	if _, err := New(filepath.Join(os.TempDir(), "does-not-exist.json"), Replay, nil); err == nil {
+		t.Error("expecting an error for a missing fixture")
+	}
+}
+
+func TestMode(t *testing.T) {
+	for _, v := range []Mode{Replay, Record, Live} {
+		var m Mode
+		if err := m.Set(v.String()); err != nil {
+			t.Fatal(err)
+		}
+		if m != v {
+			t.Errorf("expecting mode %s, got %s", v, m)
+		}
+	}
+	var m Mode
+	if err := m.Set("foo"); err == nil {
+		t.Error("expecting an error for an invalid mode")
+	}
+}
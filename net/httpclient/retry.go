@@ -0,0 +1,337 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gnd.la/app/profile"
+)
+
+const (
+	retryProfileName   = "http-retry"
+	breakerProfileName = "http-breaker"
+)
+
+var (
+	// sleep is used for waiting between retries, so
+	// tests can replace it.
+	sleep = sleepDone
+	// now is used by the circuit breakers, so tests
+	// can replace it.
+	now = time.Now
+)
+
+// BreakerOpenError is returned by the Transport when a request
+// is not sent because the circuit breaker for its host is open.
+type BreakerOpenError struct {
+	// Host is the host with the open circuit.
+	Host string
+	// Until is the time when the breaker will allow
+	// a new request to the host.
+	Until time.Time
+}
+
+func (e *BreakerOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open until %s", e.Host, e.Until.Format(time.RFC3339))
+}
+
+// RetryPolicy indicates how a Client retries failed requests
+// and when it stops sending requests to a failing host. Use
+// NewRetryPolicy to obtain a policy initialized with the default
+// values and Client.SetRetryPolicy to enable it.
+//
+// Only requests with idempotent methods (GET, HEAD, OPTIONS, TRACE,
+// PUT and DELETE) are retried. A request is retried when the transport
+// returns an error (e.g. the connection was reset) or when the response
+// status code is one of RetryStatus. The time between retries grows
+// exponentially from MinBackoff up to MaxBackoff, with some random
+// jitter. If the response includes a Retry-After header, its value is
+// used instead, as long as it's not greater than MaxRetryAfter.
+//
+// A RetryPolicy also includes a per host circuit breaker. After
+// BreakerThreshold consecutive failed requests for a given host, all
+// the requests to it fail immediately with a *BreakerOpenError during
+// BreakerTimeout. Once BreakerTimeout has elapsed, one request is
+// allowed and its result determines if the breaker is closed again.
+// A request which still fails after being retried counts as a single
+// failure. Retries stop when the request's context is canceled.
+//
+// A RetryPolicy might be shared by several clients, which will
+// also share the circuit breaker state. Note that clients created
+// with Client.Clone share the policy with their parent.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is
+	// retried.
+	MaxRetries int
+	// MinBackoff is the time to wait before the first retry.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum time to wait between retries.
+	MaxBackoff time.Duration
+	// Jitter is the fraction of the backoff (between 0 and 1)
+	// which is randomized, to avoid several clients retrying at
+	// the same time.
+	Jitter float64
+	// MaxRetryAfter is the maximum time the client will wait
+	// when the server responds with a Retry-After header. If
+	// the server asks to wait more, the response is returned
+	// without retrying.
+	MaxRetryAfter time.Duration
+	// RetryStatus are the response status codes which
+	// cause the request to be retried.
+	RetryStatus []int
+	// BreakerThreshold is the number of consecutive failures
+	// required to open the circuit breaker for a host. Zero
+	// disables the circuit breaker.
+	BreakerThreshold int
+	// BreakerTimeout is the time the breaker is kept open
+	// before letting a request go through.
+	BreakerTimeout time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewRetryPolicy returns a new RetryPolicy with the default values:
+// 3 retries, backoff between 100ms and 10s with 50% jitter, Retry-After
+// headers up to 1 minute, retries on 429, 502, 503 and 504 and a circuit
+// breaker which opens for 30 seconds after 5 consecutive failures.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:       3,
+		MinBackoff:       100 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		Jitter:           0.5,
+		MaxRetryAfter:    time.Minute,
+		RetryStatus:      []int{429, 502, 503, 504},
+		BreakerThreshold: 5,
+		BreakerTimeout:   30 * time.Second,
+	}
+}
+
+func (p *RetryPolicy) shouldRetryStatus(code int) bool {
+	for _, v := range p.RetryStatus {
+		if v == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the time to wait before the given retry,
+// starting at 0.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	d := p.MinBackoff
+	for ii := 0; ii < retry && (p.MaxBackoff <= 0 || d < p.MaxBackoff); ii++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 && d > 0 {
+		d -= time.Duration(rand.Float64() * p.Jitter * float64(d))
+	}
+	return d
+}
+
+func (p *RetryPolicy) breaker(host string) *breaker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.breakers == nil {
+		p.breakers = make(map[string]*breaker)
+	}
+	b := p.breakers[host]
+	if b == nil {
+		b = &breaker{}
+		p.breakers[host] = b
+	}
+	return b
+}
+
+// breaker is a circuit breaker for a single host.
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	until    time.Time
+	trial    bool
+}
+
+// allow returns true if a request might be sent. Otherwise,
+// it returns the time when the breaker will allow requests
+// again.
+func (b *breaker) allow() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.until.IsZero() {
+		return time.Time{}, true
+	}
+	if now().Before(b.until) || b.trial {
+		return b.until, false
+	}
+	// Half open, let one request through
+	b.trial = true
+	return time.Time{}, true
+}
+
+// done records the result of a request and returns
+// true if the breaker was opened by this result.
+func (b *breaker) done(p *RetryPolicy, ok bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasTrial := b.trial
+	b.trial = false
+	if ok {
+		b.failures = 0
+		b.until = time.Time{}
+		return false
+	}
+	b.failures++
+	if wasTrial || b.failures >= p.BreakerThreshold {
+		b.until = now().Add(p.BreakerTimeout)
+		return true
+	}
+	return false
+}
+
+// sleepDone waits for the given duration, returning false
+// if done is closed before it elapses.
+func sleepDone(done <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "", "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header in the response, which
+// might be either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	val := resp.Header.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		d := t.Sub(now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// roundTripRetry sends the request using the given policy,
+// retrying it and updating the circuit breaker as needed. The
+// breaker only sees the final result of the request, regardless
+// of how many times it was retried.
+func (t *transport) roundTripRetry(p *RetryPolicy, req *http.Request) (resp *http.Response, err error) {
+	host := req.URL.Host
+	if p.BreakerThreshold > 0 {
+		b := p.breaker(host)
+		if until, ok := b.allow(); !ok {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			if profile.On && profile.Profiling() {
+				profile.Start(breakerProfileName).Note("REJECTED", host).End()
+			}
+			return nil, &BreakerOpenError{Host: host, Until: until}
+		}
+		defer func() {
+			failed := err != nil || p.shouldRetryStatus(resp.StatusCode)
+			if b.done(p, !failed) {
+				t.warningf("circuit breaker for %s opened for %s", host, p.BreakerTimeout)
+				if profile.On && profile.Profiling() {
+					profile.Start(breakerProfileName).Note("OPEN", host).End()
+				}
+			}
+		}()
+	}
+	retries := p.MaxRetries
+	if !isIdempotent(req.Method) {
+		retries = 0
+	}
+	var body []byte
+	if retries > 0 && req.Body != nil {
+		// Buffer the body, so it can be sent again
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	for retry := 0; ; retry++ {
+		r := req
+		if body != nil {
+			// Don't modify the caller's request, as
+			// required by http.RoundTripper
+			r = new(http.Request)
+			*r = *req
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		resp, err = t.transport.RoundTrip(r)
+		failed := err != nil || p.shouldRetryStatus(resp.StatusCode)
+		if !failed || retry >= retries {
+			return resp, err
+		}
+		wait := p.backoff(retry)
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			if ra, ok := retryAfter(resp); ok {
+				if p.MaxRetryAfter > 0 && ra > p.MaxRetryAfter {
+					return resp, nil
+				}
+				wait = ra
+			}
+			// Discard the body, so the connection can be reused
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		t.infof("retrying %s %s in %s (%d/%d): %s", req.Method, req.URL, wait, retry+1, retries, reason)
+		var ev *profile.Timed
+		if profile.On && profile.Profiling() {
+			ev = profile.Start(retryProfileName).Note(req.Method, req.URL.String()).Note("reason", reason)
+		}
+		done := sleep(req.Context().Done(), wait)
+		if ev != nil {
+			ev.End()
+		}
+		if !done {
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+func (t *transport) infof(format string, args ...interface{}) {
+	if t.logger != nil {
+		t.logger.Infof("[httpclient] "+format, args...)
+	}
+}
+
+func (t *transport) warningf(format string, args ...interface{}) {
+	if t.logger != nil {
+		t.logger.Warningf("[httpclient] "+format, args...)
+	}
+}
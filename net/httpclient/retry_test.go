@@ -0,0 +1,264 @@
+package httpclient
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type retryServer struct {
+	mu       sync.Mutex
+	calls    int
+	failures int
+	status   int
+	header   http.Header
+	bodies   []string
+}
+
+func (s *retryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	data, _ := ioutil.ReadAll(r.Body)
+	s.bodies = append(s.bodies, string(data))
+	if s.calls <= s.failures {
+		for k, v := range s.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(s.status)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+func withFakeTime(f func(waits *[]time.Duration, advance func(time.Duration))) {
+	var waits []time.Duration
+	cur := time.Now()
+	prevSleep, prevNow := sleep, now
+	sleep = func(_ <-chan struct{}, d time.Duration) bool {
+		waits = append(waits, d)
+		return true
+	}
+	now = func() time.Time { return cur }
+	defer func() { sleep, now = prevSleep, prevNow }()
+	f(&waits, func(d time.Duration) { cur = cur.Add(d) })
+}
+
+func TestRetry(t *testing.T) {
+	withFakeTime(func(waits *[]time.Duration, _ func(time.Duration)) {
+		s := &retryServer{failures: 2, status: http.StatusServiceUnavailable}
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		p := NewRetryPolicy()
+		p.Jitter = 0
+		c := New(nil).SetRetryPolicy(p)
+		req, _ := http.NewRequest("PUT", srv.URL, strings.NewReader("data"))
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expecting status 200, got %d", resp.StatusCode)
+		}
+		if s.calls != 3 {
+			t.Errorf("expecting 3 calls, got %d", s.calls)
+		}
+		for ii, v := range s.bodies {
+			if v != "data" {
+				t.Errorf("call %d: expecting body %q, got %q", ii, "data", v)
+			}
+		}
+		exp := []time.Duration{p.MinBackoff, 2 * p.MinBackoff}
+		if len(*waits) != len(exp) || (*waits)[0] != exp[0] || (*waits)[1] != exp[1] {
+			t.Errorf("expecting waits %v, got %v", exp, *waits)
+		}
+		// POST is not idempotent, must not be retried
+		s.calls = 0
+		resp, err = c.Post(srv.URL, "text/plain", strings.NewReader("data"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if resp.StatusCode != http.StatusServiceUnavailable || s.calls != 1 {
+			t.Errorf("expecting 1 call with status 503, got %d calls with status %d", s.calls, resp.StatusCode)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	withFakeTime(func(waits *[]time.Duration, _ func(time.Duration)) {
+		s := &retryServer{failures: 1, status: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"3"}}}
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		p := NewRetryPolicy()
+		c := New(nil).SetRetryPolicy(p)
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if resp.StatusCode != http.StatusOK || len(*waits) != 1 || (*waits)[0] != 3*time.Second {
+			t.Errorf("expecting a wait of 3s and status 200, got %v and %d", *waits, resp.StatusCode)
+		}
+		// Retry-After too long, must return the response
+		s.calls = 0
+		s.header.Set("Retry-After", "3600")
+		resp, err = c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || s.calls != 1 {
+			t.Errorf("expecting 1 call with status 429, got %d calls with status %d", s.calls, resp.StatusCode)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	p := NewRetryPolicy()
+	p.Jitter = 0
+	p.MinBackoff = time.Second
+	p.MaxBackoff = 5 * time.Second
+	exp := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for ii, v := range exp {
+		if b := p.backoff(ii); b != v {
+			t.Errorf("backoff(%d) = %s, want %s", ii, b, v)
+		}
+	}
+	p.Jitter = 0.5
+	for ii := 0; ii < 100; ii++ {
+		if b := p.backoff(1); b > 2*time.Second || b < time.Second {
+			t.Errorf("backoff with jitter out of range: %s", b)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	withFakeTime(func(waits *[]time.Duration, advance func(time.Duration)) {
+		s := &retryServer{failures: 1000, status: http.StatusBadGateway}
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		p := NewRetryPolicy()
+		p.MaxRetries = 0
+		p.BreakerThreshold = 3
+		c := New(nil).SetRetryPolicy(p)
+		get := func() error {
+			resp, err := c.Get(srv.URL)
+			if err == nil {
+				resp.Close()
+			}
+			return err
+		}
+		for ii := 0; ii < p.BreakerThreshold; ii++ {
+			if err := get(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := get()
+		if err == nil || !strings.Contains(err.Error(), "circuit breaker") {
+			t.Fatalf("expecting breaker open error, got %v", err)
+		}
+		if s.calls != p.BreakerThreshold {
+			t.Errorf("expecting %d calls, got %d", p.BreakerThreshold, s.calls)
+		}
+		// Clones share the breakers
+		resp, err := c.Clone(nil).Get(srv.URL)
+		if err == nil {
+			resp.Close()
+			t.Error("expecting breaker open error in cloned client")
+		}
+		// Half open, failing trial opens it again
+		advance(p.BreakerTimeout)
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+		if err := get(); err == nil {
+			t.Fatal("expecting breaker open error after failed trial")
+		}
+		// Successful trial closes it
+		advance(p.BreakerTimeout)
+		s.failures = 0
+		for ii := 0; ii < p.BreakerThreshold+1; ii++ {
+			if err := get(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestRetryCanceled(t *testing.T) {
+	s := &retryServer{failures: 1000, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	p := NewRetryPolicy()
+	p.MinBackoff = time.Hour
+	c := New(nil).SetRetryPolicy(p)
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req = req.WithContext(ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.Do(req)
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("expecting %v, got %v", context.Canceled, err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("canceling the request didn't stop the backoff, took %s", elapsed)
+	}
+	if s.calls != 1 {
+		t.Errorf("expecting 1 call, got %d", s.calls)
+	}
+}
+
+func TestRetryRequestUnchanged(t *testing.T) {
+	withFakeTime(func(_ *[]time.Duration, _ func(time.Duration)) {
+		s := &retryServer{failures: 1, status: http.StatusServiceUnavailable}
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		c := New(nil).SetRetryPolicy(NewRetryPolicy())
+		req, _ := http.NewRequest("PUT", srv.URL, strings.NewReader("data"))
+		body := req.Body
+		resp, err := c.Trip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Close()
+		if s.calls != 2 {
+			t.Errorf("expecting 2 calls, got %d", s.calls)
+		}
+		if req.Body != body {
+			t.Error("retrying modified the request body")
+		}
+	})
+}
+
+func TestBreakerRetries(t *testing.T) {
+	withFakeTime(func(_ *[]time.Duration, _ func(time.Duration)) {
+		s := &retryServer{failures: 1000, status: http.StatusBadGateway}
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		p := NewRetryPolicy()
+		p.BreakerThreshold = 2
+		c := New(nil).SetRetryPolicy(p)
+		// Retries count as a single failure
+		for ii := 0; ii < p.BreakerThreshold; ii++ {
+			resp, err := c.Get(srv.URL)
+			if err != nil {
+				t.Fatalf("request %d: %s", ii, err)
+			}
+			resp.Close()
+		}
+		if exp := p.BreakerThreshold * (p.MaxRetries + 1); s.calls != exp {
+			t.Errorf("expecting %d calls, got %d", exp, s.calls)
+		}
+		if _, err := c.Get(srv.URL); err == nil || !strings.Contains(err.Error(), "circuit breaker") {
+			t.Errorf("expecting breaker open error, got %v", err)
+		}
+	})
+}
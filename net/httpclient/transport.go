@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"gnd.la/log"
 )
 
 // Transport is the interface used as a transport by *Client.
@@ -61,6 +63,8 @@ type transport struct {
 	userAgent string
 	timeout   time.Duration
 	transport http.RoundTripper
+	retry     *RetryPolicy
+	logger    log.Interface
 }
 
 func (t *transport) clone(ctx Context) *transport {
@@ -91,5 +95,8 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 			req.Header.Add("User-Agent", t.userAgent)
 		}
 	}
+	if t.retry != nil {
+		return t.roundTripRetry(t.retry, req)
+	}
 	return t.transport.RoundTrip(req)
 }
@@ -7,10 +7,15 @@ import (
 // Config specifies the mail configuration. It's not recommended
 // to change this fields manually. Instead, use their respective
 // config keys or flags. See DefaultServer, DefaultFrom and AdminEmail.
+// To sign all outgoing messages using DKIM, set DKIMDomain, DKIMSelector
+// and DKIMKey. See DKIM for more information.
 var Config struct {
-	MailServer  string `default:"localhost:25" help:"Default mail server used by gnd.la/net/mail"`
-	DefaultFrom string `help:"Default From address when sending emails"`
-	AdminEmail  string `help:"When running in non-debug mode, any error messages will be emailed to this adddress"`
+	MailServer   string `default:"localhost:25" help:"Default mail server used by gnd.la/net/mail"`
+	DefaultFrom  string `help:"Default From address when sending emails"`
+	AdminEmail   string `help:"When running in non-debug mode, any error messages will be emailed to this adddress"`
+	DKIMDomain   string `help:"Domain used for signing outgoing emails with DKIM"`
+	DKIMSelector string `help:"DKIM selector for the public key, published at <selector>._domainkey.<domain>"`
+	DKIMKey      string `help:"Path to the PEM encoded RSA or Ed25519 private key used for DKIM signatures"`
 }
 
 func init() {
@@ -0,0 +1,255 @@
+package mail
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// DKIMHeaders are the headers signed by default, when present
+	// in the message. See DKIM.Headers.
+	DKIMHeaders = []string{
+		"From",
+		"Reply-To",
+		"Subject",
+		"Date",
+		"To",
+		"Cc",
+		"Message-ID",
+		"MIME-Version",
+		"Content-Type",
+		"Content-Transfer-Encoding",
+		"List-Unsubscribe",
+		"List-Unsubscribe-Post",
+	}
+
+	errNoDKIMDomain   = errors.New("DKIM domain can't be empty")
+	errNoDKIMSelector = errors.New("DKIM selector can't be empty")
+	errNoPEMBlock     = errors.New("no PEM block found in DKIM private key")
+
+	wspRe = regexp.MustCompile("[ \t]+")
+
+	configDKIM struct {
+		sync.Mutex
+		dkim *DKIM
+		err  error
+		// Configuration used to load dkim
+		domain   string
+		selector string
+		key      string
+	}
+)
+
+// DKIM signs outgoing messages using DomainKeys Identified Mail
+// (RFC 6376). Both rsa-sha256 and ed25519-sha256 (RFC 8463) signatures
+// are supported, and headers and body are always canonicalized using the
+// relaxed algorithm. Use NewDKIM to create a DKIM or set the dkim-domain,
+// dkim-selector and dkim-key configuration keys to sign all messages sent
+// with Send.
+type DKIM struct {
+	// Domain is the signing domain (the d= tag).
+	Domain string
+	// Selector is the selector used to look up the public key
+	// in DNS, at <selector>._domainkey.<domain> (the s= tag).
+	Selector string
+	// Signer is the private key, either an *rsa.PrivateKey or
+	// an ed25519.PrivateKey.
+	Signer crypto.Signer
+	// Headers is the list of headers to sign. Headers not present in
+	// the message are ignored. If empty, DKIMHeaders is used.
+	Headers []string
+}
+
+// NewDKIM returns a new DKIM signer for the given domain and selector, using
+// the given PEM encoded private key. The key might be either an RSA key
+// (encoded as PKCS#1 or PKCS#8) or an Ed25519 key (encoded as PKCS#8).
+func NewDKIM(domain string, selector string, key []byte) (*DKIM, error) {
+	if domain == "" {
+		return nil, errNoDKIMDomain
+	}
+	if selector == "" {
+		return nil, errNoDKIMSelector
+	}
+	signer, err := parseDKIMKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &DKIM{Domain: domain, Selector: selector, Signer: signer}, nil
+}
+
+func parseDKIMKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errNoPEMBlock
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing DKIM private key: %s", err)
+	}
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	}
+	return nil, fmt.Errorf("unsupported DKIM private key type %T", key)
+}
+
+func (d *DKIM) algorithm() (string, error) {
+	switch d.Signer.(type) {
+	case *rsa.PrivateKey:
+		return "rsa-sha256", nil
+	case ed25519.PrivateKey:
+		return "ed25519-sha256", nil
+	}
+	return "", fmt.Errorf("unsupported DKIM signer type %T", d.Signer)
+}
+
+// Sign signs the given RFC 5322 message, which must use CRLF line
+// endings, and returns it with a DKIM-Signature header prepended.
+func (d *DKIM) Sign(msg []byte) ([]byte, error) {
+	if d.Domain == "" {
+		return nil, errNoDKIMDomain
+	}
+	if d.Selector == "" {
+		return nil, errNoDKIMSelector
+	}
+	algo, err := d.algorithm()
+	if err != nil {
+		return nil, err
+	}
+	headers, body := splitMessage(msg)
+	names := d.Headers
+	if len(names) == 0 {
+		names = DKIMHeaders
+	}
+	var signed []string
+	var canonical bytes.Buffer
+	for _, v := range names {
+		if h := findHeader(headers, v); h != "" {
+			signed = append(signed, strings.ToLower(v))
+			canonical.WriteString(relaxedHeader(h))
+			canonical.WriteString("\r\n")
+		}
+	}
+	bodyHash := sha256.Sum256(relaxedBody(body))
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s;\r\n\tt=%d; h=%s;\r\n\tbh=%s;\r\n\tb=",
+		algo, d.Domain, d.Selector, now().Unix(), strings.Join(signed, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	header := "DKIM-Signature: " + value
+	canonical.WriteString(relaxedHeader(header))
+	hashed := sha256.Sum256(canonical.Bytes())
+	var sig []byte
+	switch k := d.Signer.(type) {
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hashed[:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, hashed[:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteString(base64.StdEncoding.EncodeToString(sig))
+	buf.WriteString("\r\n")
+	buf.Write(msg)
+	return buf.Bytes(), nil
+}
+
+// splitMessage returns the unfolded headers (without their trailing
+// CRLF) and the body of the message.
+func splitMessage(msg []byte) ([]string, []byte) {
+	var headers []string
+	var body []byte
+	end := bytes.Index(msg, []byte("\r\n\r\n"))
+	if end >= 0 {
+		body = msg[end+4:]
+	} else {
+		end = len(msg)
+	}
+	for _, v := range strings.Split(string(msg[:end]), "\r\n") {
+		if len(headers) > 0 && (strings.HasPrefix(v, " ") || strings.HasPrefix(v, "\t")) {
+			headers[len(headers)-1] += "\r\n" + v
+			continue
+		}
+		headers = append(headers, v)
+	}
+	return headers, body
+}
+
+// findHeader returns the last instance of the given header, as
+// mandated by RFC 6376 section 5.4.2.
+func findHeader(headers []string, name string) string {
+	prefix := strings.ToLower(name) + ":"
+	for ii := len(headers) - 1; ii >= 0; ii-- {
+		if strings.HasPrefix(strings.ToLower(headers[ii]), prefix) {
+			return headers[ii]
+		}
+	}
+	return ""
+}
+
+func relaxedHeader(h string) string {
+	sep := strings.IndexByte(h, ':')
+	name := strings.ToLower(strings.TrimRight(h[:sep], " \t"))
+	value := strings.Replace(h[sep+1:], "\r\n", "", -1)
+	value = strings.TrimSpace(wspRe.ReplaceAllString(value, " "))
+	return name + ":" + value
+}
+
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for ii, v := range lines {
+		lines[ii] = strings.TrimRight(wspRe.ReplaceAllString(v, " "), " ")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// defaultDKIM returns the DKIM signer specified in the configuration,
+// or nil if DKIM has not been configured.
+func defaultDKIM() (*DKIM, error) {
+	if Config.DKIMDomain == "" && Config.DKIMSelector == "" && Config.DKIMKey == "" {
+		return nil, nil
+	}
+	configDKIM.Lock()
+	defer configDKIM.Unlock()
+	if configDKIM.domain != Config.DKIMDomain || configDKIM.selector != Config.DKIMSelector ||
+		configDKIM.key != Config.DKIMKey || (configDKIM.dkim == nil && configDKIM.err == nil) {
+		configDKIM.domain = Config.DKIMDomain
+		configDKIM.selector = Config.DKIMSelector
+		configDKIM.key = Config.DKIMKey
+		configDKIM.dkim, configDKIM.err = loadDKIM(Config.DKIMDomain, Config.DKIMSelector, Config.DKIMKey)
+	}
+	return configDKIM.dkim, configDKIM.err
+}
+
+func loadDKIM(domain, selector, filename string) (*DKIM, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading DKIM key: %s", err)
+	}
+	return NewDKIM(domain, selector, data)
+}
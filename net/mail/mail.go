@@ -10,8 +10,11 @@ import (
 	"mime"
 	"net/mail"
 	"path"
+	"strings"
+	"time"
 
 	"gnd.la/util/generic"
+	"gnd.la/util/stringutil"
 )
 
 const (
@@ -27,6 +30,8 @@ var (
 	errNoFrom          = errors.New("missing From: address")
 	// Changed for tests
 	printer = fmt.Printf
+	now     = time.Now
+	random  = stringutil.Random
 )
 
 // ParseAddressList splits a comma separated list of addresses
@@ -114,7 +119,8 @@ type Message struct {
 	// set using the Headers field.
 	Subject string
 	// TextBody is the message body to be sent as text/plain. Either this
-	// or HTMLBody must be non-empty
+	// or HTMLBody must be non-empty. If TextBody is empty, a plain text
+	// version of HTMLBody is generated using HTMLToText.
 	TextBody string
 	// HTMLBody is the message body to be sent as text/html. Either this
 	// or TextBody must be non-empty
@@ -123,6 +129,20 @@ type Message struct {
 	Headers Headers
 	// Attachments to add to the email. See Attachment and NewAttachment.
 	Attachments []*Attachment
+	// ListUnsubscribe contains the URLs (either mailto: or http(s):)
+	// which might be used to unsubscribe from the mailing list this
+	// message belongs to. They're sent in the List-Unsubscribe header.
+	ListUnsubscribe []string
+	// OneClickUnsubscribe indicates that the http(s) URLs in ListUnsubscribe
+	// support one click unsubscription as described in RFC 8058 (a POST
+	// request with the List-Unsubscribe=One-Click form value) and adds
+	// the List-Unsubscribe-Post header.
+	OneClickUnsubscribe bool
+	// DKIM is used to sign the message. If nil, the DKIM signer specified
+	// in the configuration is used (if any). See Config and DKIM.
+	// Note that App Engine signs outgoing emails by itself, so this
+	// field is ignored there.
+	DKIM *DKIM
 	// Context is used interally by Gondola and should not be altered by users.
 	Context interface{}
 }
@@ -195,6 +215,27 @@ func AdminEmail() string {
 	return Config.AdminEmail
 }
 
+func textBody(msg *Message) string {
+	if msg.TextBody == "" && msg.HTMLBody != "" {
+		return HTMLToText(msg.HTMLBody)
+	}
+	return msg.TextBody
+}
+
+func listUnsubscribeHeaders(msg *Message, headers Headers) {
+	if len(msg.ListUnsubscribe) == 0 {
+		return
+	}
+	urls := make([]string, len(msg.ListUnsubscribe))
+	for ii, v := range msg.ListUnsubscribe {
+		urls[ii] = "<" + v + ">"
+	}
+	headers["List-Unsubscribe"] = strings.Join(urls, ", ")
+	if msg.OneClickUnsubscribe {
+		headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
+	}
+}
+
 func parseDestinataries(value interface{}, name string) ([]string, error) {
 	var addrs []string
 	var err error
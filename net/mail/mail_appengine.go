@@ -49,7 +49,7 @@ func sendMail(to []string, cc []string, bcc []string, msg *Message) error {
 		Cc:       prepareAddrs(cc, &toAdmins),
 		Bcc:      prepareAddrs(bcc, &toAdmins),
 		Subject:  msg.Subject,
-		Body:     msg.TextBody,
+		Body:     textBody(msg),
 		HTMLBody: msg.HTMLBody,
 		Headers:  make(nmail.Header),
 	}
@@ -60,8 +60,13 @@ func sendMail(to []string, cc []string, bcc []string, msg *Message) error {
 			ContentID: v.ContentID,
 		})
 	}
+	headers := make(Headers)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	listUnsubscribeHeaders(msg, headers)
 	for _, v := range allowedHeaders {
-		if value := msg.Headers[v]; value != "" {
+		if value := headers[v]; value != "" {
 			gaeMsg.Headers[v] = append(gaeMsg.Headers[v], value)
 		}
 	}
@@ -8,12 +8,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
+	"mime/quotedprintable"
+	nmail "net/mail"
 	"net/smtp"
 	"net/textproto"
+	"os"
+	"sort"
 	"strings"
+	"time"
+)
 
-	"gnd.la/util/stringutil"
+const (
+	// RFC 2045 limits lines to 76 characters
+	base64LineLength = 76
 )
 
 var (
@@ -21,28 +30,30 @@ var (
 	crlf            = []byte("\r\n")
 )
 
-func joinAddrs(addrs []string) (string, error) {
+func makeBoundary() string {
+	return "Gondola-Boundary-" + random(32)
+}
+
+// expandAddrs replaces the Admin pseudo-address with the addresses
+// returned by AdminEmail().
+func expandAddrs(addrs []string) ([]string, error) {
 	var values []string
 	for _, v := range addrs {
 		if v == Admin {
 			addr := AdminEmail()
 			if addr == "" {
-				return "", errNoAdminEmail
+				return nil, errNoAdminEmail
 			}
 			addrs, err := ParseAddressList(addr)
 			if err != nil {
-				return "", fmt.Errorf("invalid admin address %q: %s", addr, err)
+				return nil, fmt.Errorf("invalid admin address %q: %s", addr, err)
 			}
 			values = append(values, addrs...)
 			continue
 		}
 		values = append(values, v)
 	}
-	return strings.Join(values, ", "), nil
-}
-
-func makeBoundary() string {
-	return "Gondola-Boundary-" + stringutil.Random(32)
+	return values, nil
 }
 
 func sendMail(to []string, cc []string, bcc []string, msg *Message) error {
@@ -66,156 +77,225 @@ func sendMail(to []string, cc []string, bcc []string, msg *Message) error {
 			auth = smtp.PlainAuth("", username, password, server)
 		}
 	}
-	var buf bytes.Buffer
-	headers := msg.Headers
-	if headers == nil {
-		headers = make(Headers)
-	}
-	if msg.Subject != "" {
-		headers["Subject"] = msg.Subject
-	}
-	headers["From"] = from
-	if msg.ReplyTo != "" {
-		headers["Reply-To"] = msg.ReplyTo
+	var rcpt []string
+	headers := make(Headers)
+	for k, v := range msg.Headers {
+		headers[k] = v
 	}
-	var err error
-	if len(to) > 0 {
-		headers["To"], err = joinAddrs(to)
+	for _, v := range []struct {
+		name  string
+		addrs []string
+	}{{"To", to}, {"Cc", cc}, {"Bcc", bcc}} {
+		addrs, err := expandAddrs(v.addrs)
 		if err != nil {
 			return err
 		}
-	}
-	if len(cc) > 0 {
-		headers["Cc"], err = joinAddrs(cc)
-		if err != nil {
-			return err
+		for _, addr := range addrs {
+			a, err := nmail.ParseAddress(addr)
+			if err != nil {
+				return fmt.Errorf("invalid %s address %q: %s", v.name, addr, err)
+			}
+			rcpt = append(rcpt, a.Address)
 		}
-	}
-	if len(bcc) > 0 {
-		headers["Bcc"], err = joinAddrs(bcc)
-		if err != nil {
-			return err
+		// Bcc destinataries must not appear in the headers
+		if len(addrs) > 0 && v.name != "Bcc" {
+			headers[v.name] = strings.Join(addrs, ", ")
 		}
 	}
-	for k, v := range headers {
-		buf.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
+	delete(headers, "Bcc")
+	data, err := makeMessage(from, headers, msg)
+	if err != nil {
+		return err
 	}
-	buf.WriteString("MIME-Version: 1.0\r\n")
-	mw := multipart.NewWriter(&buf)
-	mw.SetBoundary(makeBoundary())
-	// Create a multipart mixed first
-	fmt.Fprintf(&buf, "Content-Type: multipart/mixed;\r\n\tboundary=%q\r\n\r\n", mw.Boundary())
-	var bodyWriter *multipart.Writer
-	if msg.TextBody != "" && msg.HTMLBody != "" {
-		boundary := makeBoundary()
-		outerHeader := make(textproto.MIMEHeader)
-		// First part is a multipart/alternative, which contains the text and html bodies
-		outerHeader.Set("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
-		iw, err := mw.CreatePart(outerHeader)
-		if err != nil {
+	dkim := msg.DKIM
+	if dkim == nil {
+		if dkim, err = defaultDKIM(); err != nil {
 			return err
 		}
-		bodyWriter = multipart.NewWriter(iw)
-		bodyWriter.SetBoundary(boundary)
-	} else {
-		bodyWriter = mw
-	}
-	if msg.TextBody != "" {
-		textHeader := make(textproto.MIMEHeader)
-		textHeader.Set("Content-Type", "text/plain; charset=UTF-8")
-		tpw, err := bodyWriter.CreatePart(textHeader)
-		if err != nil {
-			return err
-		}
-		if _, err := io.WriteString(tpw, msg.TextBody); err != nil {
+	}
+	if dkim != nil {
+		if data, err = dkim.Sign(data); err != nil {
 			return err
 		}
-		tpw.Write(crlf)
-		tpw.Write(crlf)
+	}
+	if server == "echo" {
+		printer("%s", data)
+		return nil
+	}
+	return smtp.SendMail(server, auth, from, rcpt, data)
+}
+
+// makeMessage returns the full message, including its headers, encoded
+// as described in RFC 5322 and RFC 2045. The resulting message is structured
+// as follows, omitting any multipart which would contain only one part:
+//
+//  multipart/mixed
+//    multipart/alternative
+//      text/plain
+//      multipart/related
+//        text/html
+//        inline attachments referenced by their Content-ID
+//    attachments
+func makeMessage(from string, headers Headers, msg *Message) ([]byte, error) {
+	if msg.Subject != "" {
+		headers["Subject"] = mime.QEncoding.Encode("UTF-8", msg.Subject)
+	}
+	headers["From"] = from
+	if msg.ReplyTo != "" {
+		headers["Reply-To"] = msg.ReplyTo
+	}
+	if headers["Date"] == "" {
+		headers["Date"] = now().Format(time.RFC1123Z)
+	}
+	if headers["Message-ID"] == "" {
+		headers["Message-ID"] = fmt.Sprintf("<%s@%s>", random(32), addressDomain(from))
+	}
+	listUnsubscribeHeaders(msg, headers)
+	var alternatives []*mimePart
+	if text := textBody(msg); text != "" {
+		alternatives = append(alternatives, newTextPart("text/plain", text))
 	}
 	attached := make(map[*Attachment]bool)
 	if msg.HTMLBody != "" {
-		var htmlAttachments []*Attachment
+		related := []*mimePart{newTextPart("text/html", msg.HTMLBody)}
 		for _, v := range msg.Attachments {
-			if v.ContentID != "" && strings.Contains(msg.HTMLBody, fmt.Sprintf("cid:%s", v.ContentID)) {
-				htmlAttachments = append(htmlAttachments, v)
+			if v.ContentID != "" && strings.Contains(msg.HTMLBody, "cid:"+v.ContentID) {
+				p := newAttachmentPart(v, "inline")
+				p.header.Set("Content-ID", fmt.Sprintf("<%s>", v.ContentID))
+				related = append(related, p)
 				attached[v] = true
 			}
 		}
-		var htmlWriter *multipart.Writer
-		if len(htmlAttachments) > 0 {
-			relatedHeader := make(textproto.MIMEHeader)
-			relatedBoundary := makeBoundary()
-			relatedHeader.Set("Content-Type", fmt.Sprintf("multipart/related; boundary=%q; type=\"text/html\"", relatedBoundary))
-			rw, err := bodyWriter.CreatePart(relatedHeader)
-			if err != nil {
-				return err
-			}
-			htmlWriter = multipart.NewWriter(rw)
-			htmlWriter.SetBoundary(relatedBoundary)
-		} else {
-			htmlWriter = bodyWriter
-		}
-		htmlHeader := make(textproto.MIMEHeader)
-		htmlHeader.Set("Content-Type", "text/html; charset=UTF-8")
-		thw, err := htmlWriter.CreatePart(htmlHeader)
-		if err != nil {
-			return err
-		}
-		if _, err := io.WriteString(thw, msg.HTMLBody); err != nil {
-			return err
-		}
-		thw.Write(crlf)
-		thw.Write(crlf)
-		for _, v := range htmlAttachments {
-			attachmentHeader := make(textproto.MIMEHeader)
-			attachmentHeader.Set("Content-Type", v.ContentType)
-			attachmentHeader.Set("Content-Disposition", "inline")
-			attachmentHeader.Set("Content-ID", fmt.Sprintf("<%s>", v.ContentID))
-			attachmentHeader.Set("Content-Transfer-Encoding", "base64")
-			aw, err := htmlWriter.CreatePart(attachmentHeader)
-			if err != nil {
-				return err
-			}
-			b := make([]byte, base64.StdEncoding.EncodedLen(len(v.Data)))
-			base64.StdEncoding.Encode(b, v.Data)
-			aw.Write(b)
-		}
-		if htmlWriter != bodyWriter {
-			if err := htmlWriter.Close(); err != nil {
-				return err
-			}
-		}
+		alternatives = append(alternatives, newMultipart("related", related))
 	}
-	if bodyWriter != mw {
-		if err := bodyWriter.Close(); err != nil {
-			return err
+	mixed := []*mimePart{newMultipart("alternative", alternatives)}
+	for _, v := range msg.Attachments {
+		if !attached[v] {
+			mixed = append(mixed, newAttachmentPart(v, fmt.Sprintf("attachment; filename=%q", v.Name)))
 		}
 	}
-	for _, v := range msg.Attachments {
-		if attached[v] {
-			continue
+	root := newMultipart("mixed", mixed)
+	var buf bytes.Buffer
+	writeHeaders(&buf, headers)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	writeHeaders(&buf, root.mimeHeaders())
+	buf.Write(crlf)
+	if err := root.writeBody(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeHeaders(buf *bytes.Buffer, headers Headers) {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, headers[k])
+	}
+}
+
+func addressDomain(addr string) string {
+	if a, err := nmail.ParseAddress(addr); err == nil {
+		if idx := strings.LastIndex(a.Address, "@"); idx >= 0 {
+			return a.Address[idx+1:]
 		}
-		attachmentHeader := make(textproto.MIMEHeader)
-		attachmentHeader.Set("Content-Type", v.ContentType)
-		attachmentHeader.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", v.Name))
-		attachmentHeader.Set("Content-Transfer-Encoding", "base64")
-		aw, err := mw.CreatePart(attachmentHeader)
-		if err != nil {
-			return err
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "localhost"
+}
+
+// mimePart represents a MIME entity. If parts is non-empty,
+// the part is a multipart, otherwise its contents are stored
+// already encoded in body.
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+	parts  []*mimePart
+}
+
+func newTextPart(contentType string, text string) *mimePart {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	// Normalize line endings, quotedprintable will
+	// output them as CRLF.
+	w.Write([]byte(strings.Replace(text, "\r\n", "\n", -1)))
+	w.Close()
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+func newAttachmentPart(a *Attachment, disposition string) *mimePart {
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	var buf bytes.Buffer
+	for len(encoded) > 0 {
+		n := base64LineLength
+		if n > len(encoded) {
+			n = len(encoded)
 		}
-		b := make([]byte, base64.StdEncoding.EncodedLen(len(v.Data)))
-		base64.StdEncoding.Encode(b, v.Data)
-		aw.Write(b)
+		buf.WriteString(encoded[:n])
+		buf.Write(crlf)
+		encoded = encoded[n:]
 	}
-	if err := mw.Close(); err != nil {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", a.ContentType)
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Transfer-Encoding", "base64")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+// newMultipart returns a multipart with the given subtype. If
+// parts contains only one part, it's returned unchanged.
+func newMultipart(subtype string, parts []*mimePart) *mimePart {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	params := map[string]string{"boundary": makeBoundary()}
+	if subtype == "related" {
+		params["type"] = "text/html"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, params))
+	return &mimePart{header: header, parts: parts}
+}
+
+func (p *mimePart) mimeHeaders() Headers {
+	headers := make(Headers, len(p.header))
+	for k, v := range p.header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	return headers
+}
+
+func (p *mimePart) writeBody(w io.Writer) error {
+	if len(p.parts) == 0 {
+		_, err := w.Write(p.body)
 		return err
 	}
-	if server == "echo" {
-		printer(buf.String())
-		return nil
+	_, params, err := mime.ParseMediaType(p.header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(params["boundary"]); err != nil {
+		return err
+	}
+	for _, v := range p.parts {
+		pw, err := mw.CreatePart(v.header)
+		if err != nil {
+			return err
+		}
+		if err := v.writeBody(pw); err != nil {
+			return err
+		}
 	}
-	return smtp.SendMail(server, auth, from, to, buf.Bytes())
+	return mw.Close()
 }
 
 func parseServer(server string) (bool, string, string, string) {
@@ -5,14 +5,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"testing"
 	"text/template"
+	"time"
 )
 
 var (
-	boundaryRe = regexp.MustCompile("Gondola\\-Boundary\\-\\w+")
-	tmpl       = template.Must(template.New("tmpl").Parse("{{ .foo }}"))
+	tmpl = template.Must(template.New("tmpl").Parse("{{ .foo }}"))
 )
 
 func testCredentials(t *testing.T, addr, server, username, password string, cram bool) {
@@ -73,6 +72,18 @@ type EmailTest struct {
 	Expect  string
 }
 
+func loadTestDKIM(file string) *DKIM {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		panic(err)
+	}
+	dkim, err := NewDKIM("example.com", "gondola", data)
+	if err != nil {
+		panic(err)
+	}
+	return dkim
+}
+
 func makeAttachments(file string, contentId string) []*Attachment {
 	f, err := os.Open(filepath.Join("testdata", file))
 	if err != nil {
@@ -105,18 +116,50 @@ var (
 			HTMLBody:    "<html><body>LENNA <br><img src=\"cid:LENNA\" alt=\"This is Lenna\"><br><b>EMBEDDED</b></body></html>",
 			Attachments: makeAttachments("lenna.jpg", "LENNA"),
 		},
+		&Message{
+			Subject:  "Texto generado automáticamente",
+			HTMLBody: "<html><head><title>Ignored</title></head><body><h1>Welcome</h1><p>Click <a href=\"http://www.example.com/confirm\">here</a> to confirm your account.</p></body></html>",
+		},
+		&Message{
+			HTMLBody:    "<p>LENNA<img src=\"cid:LENNA\" alt=\"This is Lenna\"></p>",
+			Attachments: append(makeAttachments("lenna.jpg", "LENNA"), makeAttachments("lenna.jpg", "")...),
+		},
+		&Message{
+			Subject:             "Newsletter",
+			TextBody:            "Our newsletter",
+			ListUnsubscribe:     []string{"mailto:unsubscribe@example.com", "https://www.example.com/unsubscribe/AAAA"},
+			OneClickUnsubscribe: true,
+		},
+		&Message{
+			Subject:  "Signed",
+			TextBody: "This message is signed with DKIM",
+			Cc:       "Somebody <cc@example.com>",
+			Bcc:      "bcc@example.com",
+			DKIM:     loadTestDKIM("dkim-ed25519.pem"),
+		},
 	}
 )
 
-func replaceBoundary(s string) string {
-	return boundaryRe.ReplaceAllString(s, "Gondola-Boundary-A")
+// deterministicRandom returns a function which can be used
+// as a replacement for stringutil.Random in tests.
+func deterministicRandom() func(int) string {
+	count := 0
+	return func(n int) string {
+		count++
+		return fmt.Sprintf("%0*d", n, count)
+	}
+}
+
+func testNow() time.Time {
+	return time.Date(2014, 12, 10, 12, 0, 0, 0, time.UTC)
 }
 
 func TestSendEmail(t *testing.T) {
-	p := printer
+	p, r, n := printer, random, now
 	defer func() {
-		printer = p
+		printer, random, now = p, r, n
 	}()
+	now = testNow
 	var res string
 	count := 0
 	printer = func(format string, args ...interface{}) (int, error) {
@@ -127,6 +170,7 @@ func TestSendEmail(t *testing.T) {
 		return len(res), nil
 	}
 	for ii, v := range sendTests {
+		random = deterministicRandom()
 		if v.To == nil {
 			v.To = []string{"receiver@example.com"}
 		}
@@ -138,8 +182,6 @@ func TestSendEmail(t *testing.T) {
 			t.Error(err)
 			continue
 		}
-		// boundary is random, so we need to change it
-		res = replaceBoundary(res)
 		path := filepath.Join("testdata", fmt.Sprintf("expect.%d.eml", ii))
 		data, err := ioutil.ReadFile(path)
 		if err != nil {
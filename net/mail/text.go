@@ -0,0 +1,254 @@
+package mail
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	spaceRe = regexp.MustCompile(`\s+`)
+	// Elements which are separated by an empty line
+	textBlockElements = map[string]bool{
+		"address":    true,
+		"article":    true,
+		"blockquote": true,
+		"div":        true,
+		"dl":         true,
+		"footer":     true,
+		"h1":         true,
+		"h2":         true,
+		"h3":         true,
+		"h4":         true,
+		"h5":         true,
+		"h6":         true,
+		"header":     true,
+		"hr":         true,
+		"ol":         true,
+		"p":          true,
+		"pre":        true,
+		"section":    true,
+		"table":      true,
+		"ul":         true,
+	}
+	// Elements whose contents are never included in the text
+	textSkipElements = map[string]bool{
+		"head":   true,
+		"script": true,
+		"style":  true,
+		"title":  true,
+	}
+)
+
+// HTMLToText returns a plain text representation of the given
+// HTML, suitable for using it as the text/plain alternative of
+// an HTML email. Block elements are separated by empty lines,
+// list items are prefixed by "* " and the destination of links
+// is included between parenthesis after their text. Whitespace
+// inside <pre> elements is preserved.
+//
+// Send uses this function to generate the TextBody when only
+// the HTMLBody is provided.
+func HTMLToText(s string) string {
+	t := &textWriter{}
+	var skip string
+	var hrefs []string
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			lt = len(s)
+		}
+		if skip == "" {
+			t.text(s[:lt])
+		}
+		s = s[lt:]
+		if s == "" {
+			break
+		}
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				break
+			}
+			s = s[end+3:]
+			continue
+		}
+		gt := strings.IndexByte(s, '>')
+		if gt < 0 {
+			break
+		}
+		tag := s[1:gt]
+		s = s[gt+1:]
+		closing := strings.HasPrefix(tag, "/")
+		name, attrs := tagName(strings.TrimPrefix(tag, "/"))
+		if skip != "" {
+			if closing && name == skip {
+				skip = ""
+			}
+			continue
+		}
+		switch {
+		case textSkipElements[name]:
+			if !closing && !strings.HasSuffix(tag, "/") {
+				skip = name
+			}
+		case name == "br":
+			t.newline()
+		case name == "tr":
+			t.endLine()
+		case textBlockElements[name]:
+			t.paragraph()
+			t.pre = name == "pre" && !closing
+			if t.pre {
+				// A newline right after <pre> is ignored
+				if strings.HasPrefix(s, "\r\n") {
+					s = s[2:]
+				} else if strings.HasPrefix(s, "\n") {
+					s = s[1:]
+				}
+			}
+		case name == "li":
+			if !closing {
+				t.endLine()
+				t.text("* ")
+			}
+		case name == "td" || name == "th":
+			if !closing {
+				t.text(" ")
+			}
+		case name == "a":
+			if closing {
+				if n := len(hrefs); n > 0 {
+					if href := hrefs[n-1]; href != "" {
+						t.text(" (" + href + ")")
+					}
+					hrefs = hrefs[:n-1]
+				}
+			} else {
+				href := attrValue(attrs, "href")
+				if strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+					href = ""
+				}
+				hrefs = append(hrefs, href)
+			}
+		case name == "img":
+			if alt := attrValue(attrs, "alt"); alt != "" {
+				t.text(" " + alt + " ")
+			}
+		}
+	}
+	return t.String()
+}
+
+func tagName(tag string) (string, string) {
+	tag = strings.TrimSuffix(tag, "/")
+	end := strings.IndexAny(tag, " \t\r\n")
+	if end < 0 {
+		return strings.ToLower(tag), ""
+	}
+	return strings.ToLower(tag[:end]), tag[end+1:]
+}
+
+func attrValue(attrs string, name string) string {
+	for attrs != "" {
+		attrs = strings.TrimLeft(attrs, " \t\r\n")
+		eq := strings.IndexByte(attrs, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(attrs[:eq]))
+		attrs = strings.TrimLeft(attrs[eq+1:], " \t\r\n")
+		var value string
+		if attrs != "" && (attrs[0] == '"' || attrs[0] == '\'') {
+			end := strings.IndexByte(attrs[1:], attrs[0])
+			if end < 0 {
+				end = len(attrs) - 1
+			}
+			value, attrs = attrs[1:end+1], attrs[end+1:]
+			if attrs != "" {
+				attrs = attrs[1:]
+			}
+		} else {
+			end := strings.IndexAny(attrs, " \t\r\n")
+			if end < 0 {
+				end = len(attrs)
+			}
+			value, attrs = attrs[:end], attrs[end:]
+		}
+		if key == name {
+			return html.UnescapeString(value)
+		}
+	}
+	return ""
+}
+
+type textWriter struct {
+	lines []string
+	cur   string
+	pre   bool
+	// Number of empty lines requested before
+	// the next text is written.
+	breaks int
+}
+
+func (t *textWriter) text(s string) {
+	s = html.UnescapeString(s)
+	if t.pre {
+		s = strings.Replace(s, "\r\n", "\n", -1)
+		for ii, v := range strings.Split(s, "\n") {
+			if ii > 0 {
+				t.newline()
+			}
+			t.write(v)
+		}
+		return
+	}
+	s = spaceRe.ReplaceAllString(s, " ")
+	if t.cur == "" || strings.HasSuffix(t.cur, " ") {
+		s = strings.TrimLeft(s, " ")
+	}
+	t.write(s)
+}
+
+func (t *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	if t.cur == "" {
+		for ; t.breaks > 0; t.breaks-- {
+			t.lines = append(t.lines, "")
+		}
+		t.breaks = 0
+	}
+	t.cur += s
+}
+
+func (t *textWriter) newline() {
+	line := t.cur
+	if !t.pre {
+		line = strings.TrimRight(line, " ")
+	}
+	t.lines = append(t.lines, line)
+	t.cur = ""
+}
+
+func (t *textWriter) endLine() {
+	if t.cur != "" {
+		t.newline()
+	}
+}
+
+func (t *textWriter) paragraph() {
+	t.endLine()
+	t.breaks = 1
+}
+
+func (t *textWriter) String() string {
+	lines := t.lines
+	if t.cur != "" {
+		lines = append(lines, strings.TrimRight(t.cur, " "))
+	}
+	// Don't use strings.TrimSpace, since it would remove
+	// the indentation of a leading <pre>.
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"testing"
+)
+
+var htmlToTextTests = []struct {
+	html string
+	text string
+}{
+	{"plain text", "plain text"},
+	{"<b>Hello</b> <i>world</i>", "Hello world"},
+	{"<p>First\n   paragraph</p><p>Second paragraph</p>", "First paragraph\n\nSecond paragraph"},
+	{"Line<br>Another line<br/>Last line", "Line\nAnother line\nLast line"},
+	{"<ul><li>One</li><li>Two</li></ul>", "* One\n* Two"},
+	{"Visit <a href=\"http://www.example.com\">our site</a>.", "Visit our site (http://www.example.com)."},
+	{"<a href='#top' class=up>Top</a>", "Top"},
+	{"<html><head><title>T</title><style>p { color: red; }</style></head><body><p>Body</p><script>alert(1)</script></body></html>", "Body"},
+	{"<p>Fish &amp; chips &lt;3</p><!-- a <p>comment</p> -->", "Fish & chips <3"},
+	{"<img src=\"cid:logo\" alt=\"Our logo\">", "Our logo"},
+	{"<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td>d</td></tr></table>", "a b\nc d"},
+	{"<pre>  indented\n    code</pre>", "  indented\n    code"},
+	{"<p>Code:</p><pre>\nfunc main() {\n\tfmt.Println(\"a  b\")  \n}\n</pre><p>Done</p>", "Code:\n\nfunc main() {\n\tfmt.Println(\"a  b\")  \n}\n\nDone"},
+	{"<pre><b>bold</b>   and\r\n  <i>italic</i></pre>", "bold   and\n  italic"},
+}
+
+func TestHTMLToText(t *testing.T) {
+	for _, v := range htmlToTextTests {
+		if text := HTMLToText(v.html); text != v.text {
+			t.Errorf("expecting text %q from %q, got %q instead", v.text, v.html, text)
+		}
+	}
+}
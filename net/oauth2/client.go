@@ -11,6 +11,12 @@ import (
 	"gnd.la/util/stringutil"
 )
 
+var (
+	// ErrNoRefreshToken is returned from Client.Refresh when
+	// the *Token does not have a refresh token.
+	ErrNoRefreshToken = errors.New("token has no refresh token")
+)
+
 // Client represents an oAuth 2 client. Use New
 // to initialize a *Client.
 type Client struct {
@@ -50,6 +56,10 @@ type Client struct {
 	// empty, it defaults to ",". Note that some provides use ","
 	// (e.g. Facebook), while others use an space " " (e.g. Google).
 	ScopeSeparator string
+	// PKCE indicates if Handler should use PKCE (Proof Key for Code
+	// Exchange, RFC 7636) with the S256 method when requesting
+	// authorization. Note that the provider must support it.
+	PKCE bool
 }
 
 // New returns a new oAuth 2 Client. The authorization parameter
@@ -119,6 +129,15 @@ func (c *Client) Clone(ctx httpclient.Context) *Client {
 // Authorization returns the URL for requesting authorization from the user. Note that most
 // providers require redirectURI to be registered with them.
 func (c *Client) Authorization(redirectURI string, scopes []string, state string) string {
+	return c.AuthorizationPKCE(redirectURI, scopes, state, "")
+}
+
+// AuthorizationPKCE works like Authorization, but also sends the PKCE
+// challenge for the given verifier, using the S256 method. The same
+// verifier must be later passed to ExchangePKCE. Use NewVerifier to
+// generate a verifier. If verifier is empty, this function is equivalent
+// to Authorization.
+func (c *Client) AuthorizationPKCE(redirectURI string, scopes []string, state string, verifier string) string {
 	data := make(url.Values)
 	data.Set("client_id", c.Id)
 	data.Set("redirect_uri", redirectURI)
@@ -133,20 +152,65 @@ func (c *Client) Authorization(redirectURI string, scopes []string, state string
 		data.Set("scope", strings.Join(scopes, sep))
 	}
 	data.Set("state", state)
+	if verifier != "" {
+		data.Set("code_challenge", Challenge(verifier))
+		data.Set("code_challenge_method", "S256")
+	}
 	return urlutil.AppendQuery(c.AuthorizationURL, data)
 }
 
 // Exchange exchanges the given code for a *Token. Note that redirectURI must
 // match the value used in Authorization().
 func (c *Client) Exchange(redirectURI string, code string) (*Token, error) {
+	return c.ExchangePKCE(redirectURI, code, "")
+}
+
+// ExchangePKCE works like Exchange, but also sends the given PKCE verifier,
+// which must be the same one passed to AuthorizationPKCE. If verifier is
+// empty, this function is equivalent to Exchange.
+func (c *Client) ExchangePKCE(redirectURI string, code string, verifier string) (*Token, error) {
 	data := make(url.Values)
 	data.Set("client_id", c.Id)
 	data.Set("client_secret", c.Secret)
 	data.Set("redirect_uri", redirectURI)
 	data.Set("code", code)
+	if verifier != "" {
+		data.Set("code_verifier", verifier)
+	}
 	for k, v := range c.ExchangeParameters {
 		data.Set(k, v)
 	}
+	return c.requestToken(data)
+}
+
+// Refresh uses the refresh token in the given *Token to obtain a new
+// access token from the provider, sending the request to ExchangeURL.
+// If the provider does not return a new refresh token, the returned
+// *Token keeps the previous one. If token has no refresh token,
+// ErrNoRefreshToken is returned.
+func (c *Client) Refresh(token *Token) (*Token, error) {
+	if token == nil || token.Refresh == "" {
+		return nil, ErrNoRefreshToken
+	}
+	data := make(url.Values)
+	data.Set("client_id", c.Id)
+	data.Set("client_secret", c.Secret)
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", token.Refresh)
+	refreshed, err := c.requestToken(data)
+	if err != nil {
+		return nil, err
+	}
+	if refreshed.Refresh == "" {
+		refreshed.Refresh = token.Refresh
+	}
+	if len(refreshed.Scopes) == 0 {
+		refreshed.Scopes = token.Scopes
+	}
+	return refreshed, nil
+}
+
+func (c *Client) requestToken(data url.Values) (*Token, error) {
 	resp, err := c.client().PostForm(c.ExchangeURL, data)
 	if err != nil {
 		return nil, err
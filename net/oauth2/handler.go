@@ -8,8 +8,9 @@ import (
 )
 
 const (
-	stateCookieName = "state"
-	redirCookieName = "redir"
+	stateCookieName    = "state"
+	redirCookieName    = "redir"
+	verifierCookieName = "verifier"
 )
 
 // OAuth2TokenHandler is a handler type which receives a *Client and a
@@ -33,11 +34,18 @@ func Handler(handler OAuth2TokenHandler, client *Client, scopes []string) app.Ha
 			// First request, redirect to authorization
 			state := stringutil.Random(32)
 			redir := ctx.URL().String()
-			auth := client.Clone(ctx).Authorization(redir, scopes, state)
+			var verifier string
+			if client.PKCE {
+				verifier = NewVerifier()
+			}
+			auth := client.Clone(ctx).AuthorizationPKCE(redir, scopes, state, verifier)
 			// Save parameters
 			cookies := ctx.Cookies()
 			cookies.Set(cookieName(client, stateCookieName), state)
 			cookies.Set(cookieName(client, redirCookieName), redir)
+			if verifier != "" {
+				cookies.Set(cookieName(client, verifierCookieName), verifier)
+			}
 			ctx.Redirect(auth, false)
 			return
 		}
@@ -51,11 +59,17 @@ func Handler(handler OAuth2TokenHandler, client *Client, scopes []string) app.Ha
 		redirCookie := cookieName(client, redirCookieName)
 		cookies.Get(redirCookie, &redir)
 		cookies.Delete(redirCookie)
+		var verifier string
+		if client.PKCE {
+			verifierCookie := cookieName(client, verifierCookieName)
+			cookies.Get(verifierCookie, &verifier)
+			cookies.Delete(verifierCookie)
+		}
 		if state != savedState {
 			ctx.Forbidden("invalid state")
 			return
 		}
-		token, err := client.Clone(ctx).Exchange(redir, code)
+		token, err := client.Clone(ctx).ExchangePKCE(redir, code, verifier)
 		if err != nil {
 			panic(err)
 		}
@@ -0,0 +1,119 @@
+package oauth2
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestChallenge(t *testing.T) {
+	// Example from RFC 7636, appendix B
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if c := Challenge(verifier); c != challenge {
+		t.Errorf("expecting challenge %q, got %q", challenge, c)
+	}
+	if v := NewVerifier(); len(v) < 43 || len(v) > 128 {
+		t.Errorf("invalid verifier length %d", len(v))
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	var gotVerifier string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVerifier = r.FormValue("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"a","token_type":"bearer"}`)
+	}))
+	defer srv.Close()
+	c := New("http://example.com/auth", srv.URL)
+	verifier := NewVerifier()
+	auth, err := url.Parse(c.AuthorizationPKCE("http://example.com/redir", nil, "state", verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := auth.Query()
+	if q.Get("code_challenge") != Challenge(verifier) || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("invalid PKCE parameters in %s", auth)
+	}
+	if _, err := c.ExchangePKCE("http://example.com/redir", "code", verifier); err != nil {
+		t.Fatal(err)
+	}
+	if gotVerifier != verifier {
+		t.Errorf("expecting verifier %q, got %q", verifier, gotVerifier)
+	}
+}
+
+func TestRefreshTransport(t *testing.T) {
+	refreshes := 0
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "r" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"new%d","token_type":"bearer","expires_in":3600}`, refreshes)
+	}))
+	defer tokenSrv.Close()
+	var revoked string
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if auth == "" || auth == "Bearer "+revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, auth)
+	}))
+	defer apiSrv.Close()
+
+	c := New("http://example.com/auth", tokenSrv.URL)
+	if _, err := c.Refresh(&Token{Key: "a"}); err != ErrNoRefreshToken {
+		t.Errorf("expecting ErrNoRefreshToken, got %v", err)
+	}
+	expired, err := c.Refresh(&Token{Key: "a", Refresh: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired.Key != "new1" || expired.Refresh != "r" || expired.Expired() {
+		t.Fatalf("unexpected refreshed token %+v", expired)
+	}
+	// Make it expired
+	expired.Expires = expired.Expires.Add(-2 * time.Hour)
+	if !expired.Expired() {
+		t.Fatal("token should be expired")
+	}
+	tr := c.NewTransport(nil, expired)
+	var saved []*Token
+	tr.Refreshed = func(tok *Token) error {
+		saved = append(saved, tok)
+		return nil
+	}
+	get := func() string {
+		resp, err := tr.HTTPClient().Get(apiSrv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status %d", resp.StatusCode)
+		}
+		var body string
+		fmt.Fscan(resp.Body, &body, &body)
+		return body
+	}
+	if auth := get(); auth != "new2" {
+		t.Errorf("expecting refreshed token new2, got %q", auth)
+	}
+	// Revoke the current token, should refresh on 401
+	revoked = tr.Token().Key
+	if auth := get(); auth != "new3" {
+		t.Errorf("expecting refreshed token new3, got %q", auth)
+	}
+	if len(saved) != 2 || saved[1].Key != "new3" {
+		t.Errorf("expecting 2 refreshed tokens, got %v", saved)
+	}
+}
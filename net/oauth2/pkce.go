@@ -0,0 +1,25 @@
+package oauth2
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"gnd.la/util/stringutil"
+)
+
+const verifierLength = 64
+
+// NewVerifier returns a new random PKCE code verifier, to be used
+// with Client.AuthorizationPKCE and Client.ExchangePKCE.
+func NewVerifier() string {
+	return stringutil.Random(verifierLength)
+}
+
+// Challenge returns the PKCE code challenge for the given verifier
+// using the S256 method, which is the base64 URL encoding (without
+// padding) of the SHA256 of the verifier.
+func Challenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(h[:]), "=")
+}
@@ -0,0 +1,132 @@
+package oauth2
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"gnd.la/orm"
+	"gnd.la/orm/query"
+)
+
+var (
+	storedTokenType = reflect.TypeOf(StoredToken{})
+
+	errStoredTokenNotRegistered = errors.New("oauth2.StoredToken is not registered with the orm - add oauth2.RegisterTokenModel() to an init function in your app")
+)
+
+// TokenStore is the interface implemented by types which persist
+// tokens, keyed by the user id and the provider name (e.g. "Google"
+// or "Github").
+type TokenStore interface {
+	// Token returns the token for the given user and provider,
+	// or nil if there's no stored token.
+	Token(userId int64, provider string) (*Token, error)
+	// SaveToken stores the given token, replacing any
+	// previously stored one for the same user and provider.
+	SaveToken(userId int64, provider string, token *Token) error
+	// DeleteToken removes the stored token for the given user
+	// and provider. If there's no such token, it's a no-op.
+	DeleteToken(userId int64, provider string) error
+}
+
+// StoredToken is the model used by ORMTokenStore for
+// storing the tokens. It must be registered with the ORM
+// using RegisterTokenModel.
+type StoredToken struct {
+	UserId   int64
+	Provider string
+	Key      string
+	Refresh  string `orm:",omitempty,nullempty"`
+	Scopes   string `orm:",omitempty,nullempty"`
+	Expires  time.Time
+	Updated  time.Time
+}
+
+// Token returns the StoredToken as a *Token.
+func (s *StoredToken) Token() *Token {
+	var scopes []string
+	if s.Scopes != "" {
+		scopes = strings.Split(s.Scopes, " ")
+	}
+	return &Token{
+		Key:     s.Key,
+		Scopes:  scopes,
+		Refresh: s.Refresh,
+		Type:    TokenTypeBearer,
+		Expires: s.Expires,
+	}
+}
+
+// RegisterTokenModel registers StoredToken with the ORM, so
+// ORMTokenStore can be used. It should be called from an init
+// function.
+func RegisterTokenModel() {
+	orm.Register(&StoredToken{}, &orm.Options{
+		Table:      "oauth2_token",
+		PrimaryKey: []string{"UserId", "Provider"},
+	})
+}
+
+// ORMTokenStore is a TokenStore which stores the tokens using
+// the ORM. Note that StoredToken must be registered with the ORM
+// by calling RegisterTokenModel.
+type ORMTokenStore struct {
+	o *orm.Orm
+}
+
+// NewORMTokenStore returns a new *ORMTokenStore which uses
+// the given ORM (e.g. the one returned by app.Context.Orm).
+func NewORMTokenStore(o *orm.Orm) *ORMTokenStore {
+	return &ORMTokenStore{o: o}
+}
+
+func (s *ORMTokenStore) query(userId int64, provider string) (*orm.Table, query.Q, error) {
+	tbl := s.o.TypeTable(storedTokenType)
+	if tbl == nil {
+		return nil, nil, errStoredTokenNotRegistered
+	}
+	return tbl, orm.And(orm.Eq("UserId", userId), orm.Eq("Provider", provider)), nil
+}
+
+// Token implements TokenStore.
+func (s *ORMTokenStore) Token(userId int64, provider string) (*Token, error) {
+	tbl, q, err := s.query(userId, provider)
+	if err != nil {
+		return nil, err
+	}
+	var stored StoredToken
+	ok, err := s.o.Table(tbl).Filter(q).One(&stored)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return stored.Token(), nil
+}
+
+// SaveToken implements TokenStore.
+func (s *ORMTokenStore) SaveToken(userId int64, provider string, token *Token) error {
+	if _, _, err := s.query(userId, provider); err != nil {
+		return err
+	}
+	_, err := s.o.Save(&StoredToken{
+		UserId:   userId,
+		Provider: provider,
+		Key:      token.Key,
+		Refresh:  token.Refresh,
+		Scopes:   strings.Join(token.Scopes, " "),
+		Expires:  token.Expires.UTC(),
+		Updated:  time.Now().UTC(),
+	})
+	return err
+}
+
+// DeleteToken implements TokenStore.
+func (s *ORMTokenStore) DeleteToken(userId int64, provider string) error {
+	tbl, q, err := s.query(userId, provider)
+	if err != nil {
+		return err
+	}
+	_, err = s.o.DeleteFrom(tbl, q)
+	return err
+}
@@ -22,6 +22,11 @@ const (
 	TokenTypeBearer TokenType = iota
 )
 
+// ExpiryDelta is the time before its expiration when a
+// token is considered expired, to account for clock skew
+// and network latency.
+var ExpiryDelta = 10 * time.Second
+
 // Token represents an oAuth 2 token. Note that
 // not all oAuth 2 providers use all the fields.
 type Token struct {
@@ -30,8 +35,8 @@ type Token struct {
 	// Scopes contains the scopes granted by the user. Note that
 	// not all providers return this information.
 	Scopes []string
-	// Refresh is used by Google tokens to obtain a new fresh
-	// token from an expired one.
+	// Refresh is used to obtain a new fresh token from an
+	// expired one. See Client.Refresh.
 	Refresh string
 	// Type is the token type. Currently, this is always
 	// TokenTypeBearer.
@@ -143,3 +148,9 @@ func NewToken(r *httpclient.Response) (*Token, error) {
 	}
 	return ParseToken(r.Body)
 }
+
+// Expired returns true iff the token has an expiration
+// time and it's less than ExpiryDelta away.
+func (t *Token) Expired() bool {
+	return !t.Expires.IsZero() && !time.Now().Add(ExpiryDelta).Before(t.Expires)
+}
@@ -0,0 +1,103 @@
+package oauth2
+
+import (
+	"net/http"
+	"sync"
+
+	"gnd.la/net/httpclient"
+)
+
+// Transport is an httpclient.Transport which authenticates the
+// requests using a *Token, sent as a bearer token in the Authorization
+// header. When the token expires, it's automatically refreshed using
+// Client.Refresh. If the server responds with a 401 Unauthorized
+// status to a request without a body, the token is refreshed and the
+// request is sent again. Use Client.NewTransport to create a Transport.
+type Transport struct {
+	httpclient.Transport
+	// Refreshed, if non-nil, is called every time the token is
+	// refreshed. It's usually used to persist the new token
+	// (e.g. using a TokenStore). If it returns an error, the
+	// request fails with it.
+	Refreshed func(token *Token) error
+	client    *Client
+	mu        sync.Mutex
+	token     *Token
+}
+
+// NewTransport returns a new *Transport which authenticates the requests
+// with the given token, using this client for refreshing it. The ctx
+// parameter is used for creating the underlying httpclient.Transport.
+func (c *Client) NewTransport(ctx httpclient.Context, token *Token) *Transport {
+	return &Transport{
+		Transport: httpclient.NewTransport(ctx),
+		client:    c.Clone(ctx),
+		token:     token,
+	}
+}
+
+// Token returns the current token, which might be different
+// from the initial one if it has been refreshed.
+func (t *Transport) Token() *Token {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+// HTTPClient returns an *http.Client which uses this Transport.
+func (t *Transport) HTTPClient() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *Transport) currentToken(force bool, prev *Token) (*Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// If force is true, refresh only if no other request
+	// has refreshed the token in the meantime.
+	if (force && t.token == prev) || (!force && t.token.Expired() && t.token.Refresh != "") {
+		token, err := t.client.Refresh(t.token)
+		if err != nil {
+			return nil, err
+		}
+		if t.Refreshed != nil {
+			if err := t.Refreshed(token); err != nil {
+				return nil, err
+			}
+		}
+		t.token = token
+	}
+	return t.token, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.currentToken(false, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.Transport.RoundTrip(authorizedRequest(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || token.Refresh == "" || req.Body != nil {
+		return resp, err
+	}
+	// Token might have been revoked or expired earlier
+	// than expected, refresh it and try again.
+	resp.Body.Close()
+	if token, err = t.currentToken(true, token); err != nil {
+		return nil, err
+	}
+	return t.Transport.RoundTrip(authorizedRequest(req, token))
+}
+
+// authorizedRequest returns a copy of the request with
+// the Authorization header set to the given token. Note
+// that RoundTrippers must not modify the request.
+func authorizedRequest(req *http.Request, token *Token) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set("Authorization", "Bearer "+token.Key)
+	return r
+}
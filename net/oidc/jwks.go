@@ -0,0 +1,164 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"gnd.la/net/httpclient"
+)
+
+var (
+	// KeysMaxAge is the maximum time the keys fetched from
+	// a provider are cached.
+	KeysMaxAge = time.Hour
+	// KeysMinRefresh is the minimum time between two fetches
+	// of the provider keys when a token signed with an unknown
+	// key is received. This prevents an attacker from making
+	// the app hammer the provider by sending bogus tokens.
+	KeysMinRefresh = time.Minute
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := decodeSegment(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// publicKey returns the *rsa.PublicKey or the *ecdsa.PublicKey
+// represented by the JWK.
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+type publicKey struct {
+	id  string
+	key interface{}
+}
+
+// keySet fetches and caches the keys published by a
+// provider at its jwks_uri.
+type keySet struct {
+	mu      sync.Mutex
+	url     string
+	keys    []*publicKey
+	fetched time.Time
+}
+
+func (s *keySet) fetch(c *httpclient.Client) error {
+	resp, err := c.Get(s.url)
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+	if !resp.IsOK() {
+		return fmt.Errorf("error fetching keys from %s: %s", s.url, resp.Status)
+	}
+	var set jsonWebKeySet
+	if err := resp.DecodeJSON(&set); err != nil {
+		return fmt.Errorf("error decoding keys from %s: %s", s.url, err)
+	}
+	var keys []*publicKey
+	for _, v := range set.Keys {
+		if v.Use != "" && v.Use != "sig" {
+			continue
+		}
+		key, err := v.publicKey()
+		if err != nil {
+			// Ignore keys we don't understand
+			continue
+		}
+		keys = append(keys, &publicKey{id: v.Kid, key: key})
+	}
+	s.keys = keys
+	s.fetched = now()
+	return nil
+}
+
+// candidates returns the keys which might be used to verify
+// a token signed with the given key id, fetching them if
+// required.
+func (s *keySet) candidates(c *httpclient.Client, kid string) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := now()
+	if s.fetched.IsZero() || t.Sub(s.fetched) > KeysMaxAge {
+		if err := s.fetch(c); err != nil {
+			return nil, err
+		}
+	}
+	keys := s.matching(kid)
+	if len(keys) == 0 && t.Sub(s.fetched) > KeysMinRefresh {
+		// Provider might have rotated its keys
+		if err := s.fetch(c); err != nil {
+			return nil, err
+		}
+		keys = s.matching(kid)
+	}
+	return keys, nil
+}
+
+func (s *keySet) matching(kid string) []interface{} {
+	var keys []interface{}
+	for _, v := range s.keys {
+		if kid == "" || v.id == "" || v.id == kid {
+			keys = append(keys, v.key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,143 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrInvalidSignature is returned when the ID token
+	// signature can't be verified with any of the provider
+	// keys.
+	ErrInvalidSignature = errors.New("invalid ID token signature")
+
+	// esCurves maps each ECDSA algorithm to the name
+	// of the curve it must be used with.
+	esCurves = map[string]string{
+		"ES256": "P-256",
+		"ES384": "P-384",
+		"ES512": "P-521",
+	}
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// jwt is a parsed, but not yet verified, JSON Web Token.
+type jwt struct {
+	header    jwtHeader
+	claims    map[string]interface{}
+	signed    []byte
+	signature []byte
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.URLEncoding.DecodeString(s + strings.Repeat("=", (4-len(s)%4)%4))
+}
+
+func parseJWT(raw string) (*jwt, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT, it has %d parts instead of 3", len(parts))
+	}
+	var t jwt
+	data, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %s", err)
+	}
+	if err := json.Unmarshal(data, &t.header); err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %s", err)
+	}
+	if data, err = decodeSegment(parts[1]); err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %s", err)
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&t.claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %s", err)
+	}
+	if t.signature, err = decodeSegment(parts[2]); err != nil {
+		return nil, fmt.Errorf("invalid JWT signature: %s", err)
+	}
+	t.signed = []byte(parts[0] + "." + parts[1])
+	return &t, nil
+}
+
+func algHash(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// verify checks the token signature using the given key, which must
+// be an *rsa.PublicKey, an *ecdsa.PublicKey or a []byte for HMAC.
+func (t *jwt) verify(key interface{}) error {
+	hash, ok := algHash(t.header.Alg)
+	if !ok {
+		return fmt.Errorf("unsupported JWT algorithm %q", t.header.Alg)
+	}
+	h := hash.New()
+	h.Write(t.signed)
+	sum := h.Sum(nil)
+	switch t.header.Alg[:2] {
+	case "RS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		if rsa.VerifyPKCS1v15(k, hash, sum, t.signature) != nil {
+			return ErrInvalidSignature
+		}
+	case "ES":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || k.Curve.Params().Name != esCurves[t.header.Alg] {
+			// Each ES algorithm must be used with its own
+			// curve (RFC 7518, section 3.4).
+			return ErrInvalidSignature
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) != 2*size {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(k, sum, r, s) {
+			return ErrInvalidSignature
+		}
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(t.signed)
+		if !hmac.Equal(mac.Sum(nil), t.signature) {
+			return ErrInvalidSignature
+		}
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", t.header.Alg)
+	}
+	return nil
+}
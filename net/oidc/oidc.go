@@ -0,0 +1,480 @@
+// Package oidc implements an OpenID Connect relying party (client),
+// built on top of gnd.la/net/oauth2.
+//
+// A Provider is created from the issuer URL and the client credentials.
+// Its configuration is obtained from the discovery document published by
+// the issuer, while the keys used for verifying the ID tokens are fetched
+// from the provider and cached. A sign in flow looks like:
+//
+//	p := oidc.New("https://accounts.example.com", clientId, clientSecret)
+//	// Redirect the user to authorize the app
+//	state, nonce, verifier := ... // random values, saved e.g. in cookies
+//	auth, err := p.Authorization(redirectURI, state, nonce, verifier)
+//	...
+//	// In the redirectURI handler, after checking the state
+//	token, idToken, err := p.Exchange(redirectURI, code, nonce, verifier)
+//
+// See gnd.la/apps/users for a ready to use sign in implementation.
+package oidc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gnd.la/net/httpclient"
+	"gnd.la/net/oauth2"
+)
+
+const discoveryPath = "/.well-known/openid-configuration"
+
+var (
+	// ClockSkew is the tolerance used when checking the
+	// times in the ID tokens.
+	ClockSkew = 2 * time.Minute
+
+	// ErrNoIDToken is returned from Provider.Exchange when
+	// the response does not include an ID token.
+	ErrNoIDToken = errors.New("token response does not include an ID token")
+	// ErrInvalidNonce is returned when the nonce in an ID
+	// token does not match the expected one.
+	ErrInvalidNonce = errors.New("invalid ID token nonce")
+
+	// now is used when validating tokens, so tests
+	// can replace it.
+	now = time.Now
+)
+
+// Config is the provider configuration, obtained from
+// its discovery document. Only the fields used by this
+// package are included.
+type Config struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserInfoEndpoint      string   `json:"userinfo_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	ScopesSupported       []string `json:"scopes_supported"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// IDToken is a verified ID token.
+type IDToken struct {
+	// Issuer is the iss claim, which always matches the
+	// provider issuer.
+	Issuer string
+	// Subject is the sub claim, the user identifier at the
+	// provider.
+	Subject string
+	// Audience is the aud claim, which always includes the
+	// client id.
+	Audience []string
+	// Expires is the exp claim.
+	Expires time.Time
+	// IssuedAt is the iat claim.
+	IssuedAt time.Time
+	// Nonce is the nonce claim.
+	Nonce string
+	// Claims contains all the claims in the token, including
+	// the ones above. Numbers are represented as json.Number.
+	Claims map[string]interface{}
+	// Raw is the token as received from the provider.
+	Raw string
+}
+
+// String returns the value of the given claim as a string. If the
+// claim does not exist, an empty string is returned. Nested claims
+// might be accessed using dots (e.g. address.country).
+func (t *IDToken) String(name string) string {
+	switch x := t.claim(name).(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		if x {
+			return "true"
+		}
+		return "false"
+	}
+	return ""
+}
+
+// Bool returns the value of the given claim as a boolean. Note
+// that some providers return booleans encoded as strings, those
+// are also supported. See String for the claim name syntax.
+func (t *IDToken) Bool(name string) bool {
+	switch x := t.claim(name).(type) {
+	case bool:
+		return x
+	case string:
+		return x == "true"
+	}
+	return false
+}
+
+func (t *IDToken) claim(name string) interface{} {
+	var cur interface{} = t.Claims
+	for _, v := range strings.Split(name, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[v]
+	}
+	return cur
+}
+
+// shared is the state shared between a Provider
+// and its clones.
+type shared struct {
+	mu     sync.Mutex
+	config *Config
+	keys   *keySet
+}
+
+// Provider represents an OpenID Connect provider. Use New to
+// initialize a *Provider.
+type Provider struct {
+	// Issuer is the issuer URL, which is used for fetching the
+	// discovery document and must match the iss claim in the
+	// ID tokens.
+	Issuer string
+	// ClientId is the client id, obtained from the provider.
+	ClientId string
+	// ClientSecret is the client secret, obtained from the
+	// provider.
+	ClientSecret string
+	// Scopes are the scopes requested in the authorization. The
+	// openid scope is always included, even if it's not in Scopes.
+	Scopes []string
+	// AuthorizationParameters lists additional parameters
+	// to be sent in the authorization request (e.g. prompt or
+	// hd).
+	AuthorizationParameters map[string]string
+	// HTTPClient is the HTTP client used for sending requests
+	// to the provider. It's initialized by New.
+	HTTPClient *httpclient.Client
+	shared     *shared
+}
+
+// New returns a new *Provider with the given issuer and client
+// credentials, requesting the openid, email and profile scopes.
+func New(issuer string, clientId string, clientSecret string) *Provider {
+	return &Provider{
+		Issuer:       issuer,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"openid", "email", "profile"},
+		HTTPClient:   httpclient.New(nil),
+		shared:       &shared{},
+	}
+}
+
+// Clone returns a copy of the Provider which uses the given context.
+// The discovery document and keys cache is shared with the original
+// Provider.
+func (p *Provider) Clone(ctx httpclient.Context) *Provider {
+	pc := *p
+	pc.HTTPClient = pc.HTTPClient.Clone(ctx)
+	if pc.shared == nil {
+		p.shared = &shared{}
+		pc.shared = p.shared
+	}
+	return &pc
+}
+
+func (p *Provider) client() *httpclient.Client {
+	if p.HTTPClient == nil {
+		p.HTTPClient = httpclient.New(nil)
+	}
+	return p.HTTPClient
+}
+
+func (p *Provider) state() *shared {
+	if p.shared == nil {
+		p.shared = &shared{}
+	}
+	return p.shared
+}
+
+// Config returns the provider configuration, fetching its
+// discovery document the first time it's called.
+func (p *Provider) Config() (*Config, error) {
+	s := p.state()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config != nil {
+		return s.config, nil
+	}
+	u := strings.TrimSuffix(p.Issuer, "/") + discoveryPath
+	resp, err := p.client().Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	if !resp.IsOK() {
+		return nil, fmt.Errorf("error fetching discovery document from %s: %s", u, resp.Status)
+	}
+	var config Config
+	if err := resp.DecodeJSON(&config); err != nil {
+		return nil, fmt.Errorf("error decoding discovery document from %s: %s", u, err)
+	}
+	if strings.TrimSuffix(config.Issuer, "/") != strings.TrimSuffix(p.Issuer, "/") {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", config.Issuer, p.Issuer)
+	}
+	if config.AuthorizationEndpoint == "" || config.TokenEndpoint == "" || config.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document from %s is missing required endpoints", u)
+	}
+	s.config = &config
+	s.keys = &keySet{url: config.JWKSURI}
+	return s.config, nil
+}
+
+func (p *Provider) oauth2Client(config *Config) *oauth2.Client {
+	return &oauth2.Client{
+		Id:                      p.ClientId,
+		Secret:                  p.ClientSecret,
+		AuthorizationURL:        config.AuthorizationEndpoint,
+		AuthorizationParameters: p.AuthorizationParameters,
+		ExchangeURL:             config.TokenEndpoint,
+		HTTPClient:              p.client(),
+		ScopeSeparator:          " ",
+	}
+}
+
+func (p *Provider) scopes() []string {
+	scopes := []string{"openid"}
+	for _, v := range p.Scopes {
+		if v != "openid" {
+			scopes = append(scopes, v)
+		}
+	}
+	return scopes
+}
+
+// Authorization returns the URL for requesting authorization from the
+// user. The state must be verified when the user is redirected back to
+// redirectURI, while nonce and verifier (which might be empty, to disable
+// PKCE) must be passed to Exchange. Use oauth2.NewVerifier to generate
+// a PKCE verifier.
+func (p *Provider) Authorization(redirectURI string, state string, nonce string, verifier string) (string, error) {
+	config, err := p.Config()
+	if err != nil {
+		return "", err
+	}
+	c := p.oauth2Client(config)
+	params := map[string]string{"response_type": "code"}
+	for k, v := range p.AuthorizationParameters {
+		params[k] = v
+	}
+	if nonce != "" {
+		params["nonce"] = nonce
+	}
+	c.AuthorizationParameters = params
+	return c.AuthorizationPKCE(redirectURI, p.scopes(), state, verifier), nil
+}
+
+// Exchange exchanges the given code for an access token and an ID token.
+// The ID token is verified (see Verify) and its nonce must match the one
+// passed to Authorization.
+func (p *Provider) Exchange(redirectURI string, code string, nonce string, verifier string) (*oauth2.Token, *IDToken, error) {
+	config, err := p.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	data := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {p.ClientId},
+		"client_secret": {p.ClientSecret},
+		"redirect_uri":  {redirectURI},
+		"code":          {code},
+	}
+	if verifier != "" {
+		data.Set("code_verifier", verifier)
+	}
+	resp, err := p.client().PostForm(config.TokenEndpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !resp.IsOK() {
+		return nil, nil, fmt.Errorf("error exchanging code: %s: %s", resp.Status, string(body))
+	}
+	token, err := oauth2.ParseJSONToken(bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	var raw struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, err
+	}
+	if raw.IDToken == "" {
+		return nil, nil, ErrNoIDToken
+	}
+	idToken, err := p.Verify(raw.IDToken, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, idToken, nil
+}
+
+// Verify parses the given raw ID token and verifies its signature, using
+// the keys published by the provider (or the client secret, for tokens
+// signed with an HMAC algorithm advertised by the provider), as well as
+// its iss, aud, azp, exp, iat and nonce claims. If nonce is empty, the
+// nonce claim is not checked.
+func (p *Provider) Verify(raw string, nonce string) (*IDToken, error) {
+	if _, err := p.Config(); err != nil {
+		return nil, err
+	}
+	t, err := parseJWT(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verifySignature(t); err != nil {
+		return nil, err
+	}
+	return p.validateClaims(t, raw, nonce)
+}
+
+func (p *Provider) verifySignature(t *jwt) error {
+	alg := t.header.Alg
+	hmac := strings.HasPrefix(alg, "HS")
+	// Check the algorithm first, so a token signed with the client
+	// secret is only accepted when the provider advertises it.
+	algs := p.state().config.SigningAlgs
+	if len(algs) > 0 || hmac {
+		supported := false
+		for _, v := range algs {
+			if v == alg {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("ID token algorithm %q is not supported by the provider", alg)
+		}
+	}
+	if hmac {
+		if p.ClientSecret == "" {
+			return ErrInvalidSignature
+		}
+		return t.verify([]byte(p.ClientSecret))
+	}
+	keys, err := p.state().keys.candidates(p.client(), t.header.Kid)
+	if err != nil {
+		return err
+	}
+	for _, v := range keys {
+		if err := t.verify(v); err == nil {
+			return nil
+		} else if err != ErrInvalidSignature {
+			return err
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func numericDate(v interface{}) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0).UTC(), true
+}
+
+func (p *Provider) validateClaims(t *jwt, raw string, nonce string) (*IDToken, error) {
+	tok := &IDToken{Claims: t.claims, Raw: raw}
+	tok.Issuer, _ = t.claims["iss"].(string)
+	tok.Subject, _ = t.claims["sub"].(string)
+	tok.Nonce, _ = t.claims["nonce"].(string)
+	switch x := t.claims["aud"].(type) {
+	case string:
+		tok.Audience = []string{x}
+	case []interface{}:
+		for _, v := range x {
+			if s, ok := v.(string); ok {
+				tok.Audience = append(tok.Audience, s)
+			}
+		}
+	}
+	var ok bool
+	if tok.Expires, ok = numericDate(t.claims["exp"]); !ok {
+		return nil, errors.New("ID token has no expiration")
+	}
+	tok.IssuedAt, _ = numericDate(t.claims["iat"])
+	if strings.TrimSuffix(tok.Issuer, "/") != strings.TrimSuffix(p.Issuer, "/") {
+		return nil, fmt.Errorf("ID token issuer %q does not match %q", tok.Issuer, p.Issuer)
+	}
+	if tok.Subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+	found := false
+	for _, v := range tok.Audience {
+		if v == p.ClientId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("ID token audience %v does not include %q", tok.Audience, p.ClientId)
+	}
+	if azp, _ := t.claims["azp"].(string); azp != "" && azp != p.ClientId {
+		return nil, fmt.Errorf("ID token authorized party %q does not match %q", azp, p.ClientId)
+	}
+	cur := now()
+	if cur.Add(-ClockSkew).After(tok.Expires) {
+		return nil, fmt.Errorf("ID token expired at %s", tok.Expires)
+	}
+	if !tok.IssuedAt.IsZero() && cur.Add(ClockSkew).Before(tok.IssuedAt) {
+		return nil, fmt.Errorf("ID token issued in the future (%s)", tok.IssuedAt)
+	}
+	if nonce != "" && tok.Nonce != nonce {
+		return nil, ErrInvalidNonce
+	}
+	return tok, nil
+}
+
+// UserInfo requests the claims about the user from the provider
+// userinfo endpoint, using the given access token.
+func (p *Provider) UserInfo(token *oauth2.Token) (map[string]interface{}, error) {
+	config, err := p.Config()
+	if err != nil {
+		return nil, err
+	}
+	if config.UserInfoEndpoint == "" {
+		return nil, errors.New("provider does not have an userinfo endpoint")
+	}
+	resp, err := p.oauth2Client(config).Get(config.UserInfoEndpoint, nil, token.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+	if !resp.IsOK() {
+		return nil, fmt.Errorf("error fetching user info: %s", resp.Status)
+	}
+	var claims map[string]interface{}
+	if err := resp.DecodeJSON(&claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
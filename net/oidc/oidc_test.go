@@ -0,0 +1,362 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testClientId     = "client"
+	testClientSecret = "secret"
+)
+
+// testIdP is a minimal in-process OpenID Connect provider.
+type testIdP struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	kid        string
+	algs       []string
+	claims     map[string]interface{}
+	keyFetches int
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &testIdP{key: key, kid: "k1", algs: []string{"RS256"}}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"issuer":                                idp.URL,
+			"authorization_endpoint":                idp.URL + "/authorize",
+			"token_endpoint":                        idp.URL + "/token",
+			"userinfo_endpoint":                     idp.URL + "/userinfo",
+			"jwks_uri":                              idp.URL + "/keys",
+			"id_token_signing_alg_values_supported": idp.algs,
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		idp.keyFetches++
+		writeJSON(w, map[string]interface{}{
+			"keys": []map[string]interface{}{{
+				"kty": "RSA",
+				"kid": idp.kid,
+				"use": "sig",
+				"n":   encodeSegment(idp.key.N.Bytes()),
+				"e":   encodeSegment(big.NewInt(int64(idp.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || r.FormValue("client_id") != testClientId {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "bearer",
+			"expires_in":   3600,
+			"id_token":     idp.sign(idp.claims),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("access_token") != "access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"sub": "user1", "email": "user@example.com"})
+	})
+	idp.Server = httptest.NewServer(mux)
+	idp.claims = idp.validClaims("nonce")
+	return idp
+}
+
+func (idp *testIdP) validClaims(nonce string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":            idp.URL,
+		"sub":            "user1",
+		"aud":            testClientId,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          nonce,
+		"email":          "user@example.com",
+		"email_verified": true,
+		"address":        map[string]interface{}{"country": "ES"},
+	}
+}
+
+func (idp *testIdP) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": idp.kid})
+	payload, _ := json.Marshal(claims)
+	signed := encodeSegment(header) + "." + encodeSegment(payload)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, sum[:])
+	if err != nil {
+		panic(err)
+	}
+	return signed + "." + encodeSegment(sig)
+}
+
+func encodeSegment(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestAuthorization(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	p := New(idp.URL, testClientId, testClientSecret)
+	p.Scopes = []string{"email"}
+	auth, err := p.Authorization("http://example.com/redir", "state", "nonce", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(auth, idp.URL+"/authorize?") {
+		t.Errorf("unexpected authorization URL %s", auth)
+	}
+	q := u.Query()
+	expect := map[string]string{
+		"response_type": "code",
+		"client_id":     testClientId,
+		"scope":         "openid email",
+		"state":         "state",
+		"nonce":         "nonce",
+	}
+	for k, v := range expect {
+		if q.Get(k) != v {
+			t.Errorf("expecting %s = %q, got %q", k, v, q.Get(k))
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	p := New(idp.URL, testClientId, testClientSecret)
+	token, idToken, err := p.Exchange("http://example.com/redir", "code", "nonce", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Key != "access" {
+		t.Errorf("expecting access token %q, got %q", "access", token.Key)
+	}
+	if idToken.Subject != "user1" || idToken.String("email") != "user@example.com" {
+		t.Errorf("unexpected ID token %+v", idToken)
+	}
+	if !idToken.Bool("email_verified") {
+		t.Error("email should be verified")
+	}
+	if c := idToken.String("address.country"); c != "ES" {
+		t.Errorf("expecting country ES, got %q", c)
+	}
+	info, err := p.UserInfo(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info["email"] != "user@example.com" {
+		t.Errorf("unexpected user info %v", info)
+	}
+	// Keys must be cached
+	if _, err := p.Verify(idp.sign(idp.claims), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if idp.keyFetches != 1 {
+		t.Errorf("expecting 1 key fetch, got %d", idp.keyFetches)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	p := New(idp.URL, testClientId, testClientSecret)
+	other, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := &testIdP{Server: idp.Server, key: other, kid: idp.kid}
+	modified := func(k string, v interface{}) string {
+		claims := idp.validClaims("nonce")
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+		return idp.sign(claims)
+	}
+	bad := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"nonce", idp.sign(idp.validClaims("nonce")), "other"},
+		{"issuer", modified("iss", "http://evil.example.com"), "nonce"},
+		{"audience", modified("aud", "other"), "nonce"},
+		{"azp", modified("azp", "other"), "nonce"},
+		{"expired", modified("exp", time.Now().Add(-time.Hour).Unix()), "nonce"},
+		{"no exp", modified("exp", nil), "nonce"},
+		{"future", modified("iat", time.Now().Add(time.Hour).Unix()), "nonce"},
+		{"no subject", modified("sub", nil), "nonce"},
+		{"signature", forged.sign(idp.validClaims("nonce")), "nonce"},
+		{"malformed", "foo.bar", "nonce"},
+	}
+	for _, v := range bad {
+		if _, err := p.Verify(v.token, v.nonce); err == nil {
+			t.Errorf("%s: expecting an error", v.name)
+		}
+	}
+	// Audience as a list
+	if _, err := p.Verify(modified("aud", []string{"other", testClientId}), "nonce"); err != nil {
+		t.Error(err)
+	}
+	// Tokens signed with an unknown key should not cause a refetch
+	// before KeysMinRefresh
+	fetches := idp.keyFetches
+	forged.kid = "k2"
+	p.Verify(forged.sign(idp.validClaims("nonce")), "nonce")
+	if idp.keyFetches != fetches {
+		t.Errorf("keys were refetched before KeysMinRefresh")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	p := New(idp.URL, testClientId, testClientSecret)
+	if _, err := p.Verify(idp.sign(idp.claims), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp.key = key
+	idp.kid = "k2"
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Now().Add(2 * KeysMinRefresh) }
+	if _, err := p.Verify(idp.sign(idp.claims), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if idp.keyFetches != 2 {
+		t.Errorf("expecting 2 key fetches, got %d", idp.keyFetches)
+	}
+}
+
+func signHMAC(claims map[string]interface{}, secret string) string {
+	header := encodeSegment([]byte(`{"alg":"HS256"}`))
+	payload, _ := json.Marshal(claims)
+	signed := header + "." + encodeSegment(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + encodeSegment(mac.Sum(nil))
+}
+
+func TestHMAC(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	idp.algs = []string{"RS256", "HS256"}
+	p := New(idp.URL, testClientId, testClientSecret)
+	if _, err := p.Verify(signHMAC(idp.claims, testClientSecret), "nonce"); err != nil {
+		t.Error(err)
+	}
+	if _, err := p.Verify(signHMAC(idp.claims, "other"), "nonce"); err != ErrInvalidSignature {
+		t.Errorf("expecting ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestHMACNotAdvertised(t *testing.T) {
+	idp := newTestIdP(t)
+	defer idp.Close()
+	// The IdP doesn't advertise HS256, so tokens signed
+	// with the client secret must be rejected.
+	for _, algs := range [][]string{{"RS256"}, nil} {
+		idp.algs = algs
+		p := New(idp.URL, testClientId, testClientSecret)
+		_, err := p.Verify(signHMAC(idp.claims, testClientSecret), "nonce")
+		if err == nil || !strings.Contains(err.Error(), "not supported") {
+			t.Errorf("expecting unsupported algorithm error with algs %v, got %v", algs, err)
+		}
+	}
+	// RS256 tokens are still accepted
+	p := New(idp.URL, testClientId, testClientSecret)
+	if _, err := p.Verify(idp.sign(idp.claims), "nonce"); err != nil {
+		t.Error(err)
+	}
+}
+
+func signECDSA(alg string, key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+	header := encodeSegment([]byte(`{"alg":"` + alg + `"}`))
+	payload, _ := json.Marshal(claims)
+	signed := header + "." + encodeSegment(payload)
+	hash, _ := algHash(alg)
+	h := hash.New()
+	h.Write([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	sig := make([]byte, 2*size)
+	rb, sb := r.Bytes(), s.Bytes()
+	copy(sig[size-len(rb):size], rb)
+	copy(sig[2*size-len(sb):], sb)
+	return signed + "." + encodeSegment(sig)
+}
+
+func TestECDSACurves(t *testing.T) {
+	curves := map[string]elliptic.Curve{
+		"ES256": elliptic.P256(),
+		"ES384": elliptic.P384(),
+		"ES512": elliptic.P521(),
+	}
+	keys := make(map[string]*ecdsa.PrivateKey)
+	for alg, curve := range curves {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[alg] = key
+	}
+	claims := map[string]interface{}{"sub": "user1"}
+	for alg := range curves {
+		for keyAlg, key := range keys {
+			token, err := parseJWT(signECDSA(alg, key, claims))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = token.verify(&key.PublicKey)
+			if keyAlg == alg && err != nil {
+				t.Errorf("error verifying %s token: %s", alg, err)
+			}
+			// Keys on the wrong curve must be rejected, even
+			// if the signature is valid
+			if keyAlg != alg && err != ErrInvalidSignature {
+				t.Errorf("expecting ErrInvalidSignature for %s token signed with a %s key, got %v",
+					alg, key.Curve.Params().Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gnd.la/app/profile"
+	"gnd.la/orm/driver"
+	"gnd.la/orm/query"
+)
+
+// DefaultBatchSize is the number of objects inserted in each
+// round trip by InsertMany when no batch size is specified, as
+// well as the number of objects loaded by each query performed
+// by Query.Each.
+const DefaultBatchSize = 500
+
+var (
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type bulkResult struct {
+	lastId   int64
+	affected int64
+}
+
+func (r *bulkResult) LastInsertId() (int64, error) {
+	return r.lastId, nil
+}
+
+func (r *bulkResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+func (r *bulkResult) add(res Result) {
+	if id, err := res.LastInsertId(); err == nil && id != 0 {
+		r.lastId = id
+	}
+	if aff, err := res.RowsAffected(); err == nil {
+		r.affected += aff
+	}
+}
+
+// objectsValue returns the objects in the slice val as pointers,
+// so their primary keys can be set.
+func objectsValue(fname string, objs interface{}) ([]interface{}, error) {
+	val := reflect.ValueOf(objs)
+	if val.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%s requires a slice of objects, not %T", fname, objs)
+	}
+	count := val.Len()
+	values := make([]interface{}, count)
+	for ii := 0; ii < count; ii++ {
+		v := val.Index(ii)
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Struct && v.CanAddr() {
+			v = v.Addr()
+		}
+		values[ii] = v.Interface()
+	}
+	return values, nil
+}
+
+// InsertMany inserts all the objects in objs, which must be a slice of
+// structs or pointers to structs, all of them of the same model. Objects
+// are inserted in batches of batchSize (if batchSize <= 0, DefaultBatchSize
+// is used). Drivers which support it (like the SQL ones) insert each batch
+// in a single round trip using multi-row INSERT statements, while the rest
+// insert the objects one by one.
+//
+// Like Insert, InsertMany populates auto_increment primary keys, sets
+// default values, calls the insert hooks and emits the inserted signal for
+// every object. If an error occurs, the objects inserted before it are not
+// removed from the database. Call InsertMany from a transaction to insert
+// either all the objects or none of them.
+func (o *Orm) InsertMany(objs interface{}, batchSize int) (Result, error) {
+	values, err := objectsValue("InsertMany", objs)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	var m *model
+	res := &bulkResult{}
+	for start := 0; start < len(values); start += batchSize {
+		end := start + batchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		batch := make([]interface{}, end-start)
+		pks := make([]reflect.Value, end-start)
+		for ii, v := range values[start:end] {
+			vm, err := o.model(v)
+			if err != nil {
+				return nil, err
+			}
+			if m == nil {
+				m = vm
+			} else if vm != m {
+				return nil, fmt.Errorf("InsertMany requires all the objects to be of the same model, found %s and %s", m.name, vm.name)
+			}
+			if err := m.fields.Methods.Save(v); err != nil {
+				return nil, err
+			}
+			if batch[ii], pks[ii], err = o.prepareInsert(m, v); err != nil {
+				return nil, err
+			}
+		}
+		if err := o.insertBatch(m, batch, pks, res); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// MustInsertMany works like InsertMany, but panics if there's an error.
+func (o *Orm) MustInsertMany(objs interface{}, batchSize int) Result {
+	res, err := o.InsertMany(objs, batchSize)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (o *Orm) insertBatch(m *model, batch []interface{}, pks []reflect.Value, res *bulkResult) error {
+	if profile.On && profile.Profiling() {
+		defer profile.Start(orm).Note("insert many", m.name).End()
+	}
+	o.wrote()
+	if bulk, ok := o.conn.(driver.BulkInserter); ok {
+		r, ids, err := bulk.InsertMany(m, batch)
+		if err != nil {
+			return err
+		}
+		for ii, v := range pks {
+			if v.IsValid() && v.Int() == 0 && ii < len(ids) && ids[ii] != 0 {
+				o.setPrimaryKey(m, v, ids[ii])
+			}
+		}
+		res.add(r)
+	} else {
+		for ii, v := range batch {
+			r, err := o.conn.Insert(m, v)
+			if err != nil {
+				return err
+			}
+			if pk := pks[ii]; pk.IsValid() && pk.Int() == 0 {
+				if id, err := r.LastInsertId(); err == nil && id != 0 {
+					o.setPrimaryKey(m, pk, id)
+				}
+			}
+			res.add(r)
+		}
+	}
+	for _, v := range batch {
+		if err := o.inserted(m, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateMany updates all the objects in objs, which must be a slice of
+// structs or pointers to structs with a primary key (either simple or
+// composite), using the primary key of each object to select the row
+// it updates. Objects might belong to different models. Objects which
+// don't exist in the database are ignored.
+//
+// Like Update, UpdateMany calls the update hooks, emits the updated
+// signal and checks the versions of the objects with a version field.
+// When called outside of a transaction, UpdateMany runs inside one if
+// the driver supports Begin, so either all the objects are updated
+// or none of them is.
+func (o *Orm) UpdateMany(objs interface{}) (Result, error) {
+	values, err := objectsValue("UpdateMany", objs)
+	if err != nil {
+		return nil, err
+	}
+	if o.conn == driver.Conn(o.driver) && o.driver.Capabilities()&driver.CAP_BEGIN != 0 {
+		var res Result
+		err := o.Transaction(func(tx *Orm) error {
+			var err error
+			res, err = tx.updateMany(values)
+			return err
+		})
+		return res, err
+	}
+	return o.updateMany(values)
+}
+
+// MustUpdateMany works like UpdateMany, but panics if there's an error.
+func (o *Orm) MustUpdateMany(objs interface{}) Result {
+	res, err := o.UpdateMany(objs)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (o *Orm) updateMany(values []interface{}) (Result, error) {
+	res := &bulkResult{}
+	for _, v := range values {
+		m, err := o.model(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.fields.Methods.Save(v); err != nil {
+			return nil, err
+		}
+		q, err := o.primaryKeyQuery(m, v)
+		if err != nil {
+			return nil, err
+		}
+		r, err := o.update(m, q, v)
+		if err != nil {
+			return nil, err
+		}
+		res.add(r)
+	}
+	return res, nil
+}
+
+// Each calls f for every object matching the query, loading them
+// in pages of DefaultBatchSize objects. f must be a function which
+// receives a single argument, either a struct of a registered model or a
+// pointer to it, and returns an error. If f returns an error, Each stops
+// and returns it. e.g.
+//
+//  err := o.Query(orm.Eq("Published", true)).Each(func(a *Article) error {
+//	return export(a)
+//  })
+//
+// Rather than using OFFSET, which gets slower as the offset increases,
+// Each uses keyset pagination, sorting the objects by their primary key
+// and starting each page after the last key in the previous one, so it's
+// suitable for iterating over huge tables. For this reason, the model
+// must have a non-composite primary key and the query can't have
+// an offset nor any sorting. If the query has a limit, at most that
+// number of objects are loaded. Fields requested with Preload are loaded
+// for each page.
+//
+// Since every page is fully loaded before calling f, f might use the same
+// Orm or Tx to perform other queries or updates.
+func (q *Query) Each(f interface{}) error {
+	fn := reflect.ValueOf(f)
+	ft := fn.Type()
+	if fn.Kind() != reflect.Func || ft.NumIn() != 1 || ft.NumOut() != 1 || ft.Out(0) != errorType {
+		return fmt.Errorf("argument to Each must be a func(*T) error, not %T", f)
+	}
+	in := ft.In(0)
+	base := in
+	if base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	m := q.orm.typeRegistry[base]
+	if m == nil {
+		return fmt.Errorf("no model registered for type %s", base)
+	}
+	if q.model != nil && q.model.model != m {
+		return fmt.Errorf("query for table %s can't load objects of type %s", q.model.name, base)
+	}
+	pk := m.fields.PrimaryKey
+	if pk < 0 {
+		return fmt.Errorf("Each requires a model with a non-composite primary key, %s doesn't have one", m.name)
+	}
+	if len(q.sort) > 0 || q.offset > 0 {
+		return fmt.Errorf("Each can't be used with queries with sorting nor offset")
+	}
+	pkName := m.fields.QNames[pk]
+	remaining := q.limit
+	var last interface{}
+	sliceType := reflect.SliceOf(reflect.PtrTo(base))
+	for remaining != 0 {
+		limit := DefaultBatchSize
+		if remaining > 0 && remaining < limit {
+			limit = remaining
+		}
+		page := q.Clone()
+		page.model = tableWithModel(m).model
+		page.sort = []driver.Sort{&querySort{field: pkName, dir: driver.SortDirection(ASC)}}
+		page.limit = limit
+		if last != nil {
+			var conds []query.Q
+			if q.q != nil {
+				conds = append(conds, q.q)
+			}
+			page.q = And(append(conds, Gt(pkName, last))...)
+		}
+		objs := reflect.New(sliceType)
+		if err := page.All(objs.Interface()); err != nil {
+			return err
+		}
+		items := objs.Elem()
+		count := items.Len()
+		for ii := 0; ii < count; ii++ {
+			item := items.Index(ii)
+			if in.Kind() != reflect.Ptr {
+				item = item.Elem()
+			}
+			if out := fn.Call([]reflect.Value{item}); !out[0].IsNil() {
+				return out[0].Interface().(error)
+			}
+		}
+		if count < limit {
+			break
+		}
+		last = q.orm.fieldByIndex(items.Index(count-1), m.fields.Indexes[pk]).Interface()
+		if remaining > 0 {
+			remaining -= count
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gnd.la/orm/driver"
+)
+
+type Bulk struct {
+	Id    int64 `orm:",primary_key,auto_increment"`
+	Value string
+	Num   int
+}
+
+func testBulk(t *testing.T, o *Orm) {
+	table := o.mustRegister((*Bulk)(nil), &Options{
+		Table: "test_bulk",
+	})
+	o.mustInitialize()
+	const count = 1234
+	objs := make([]Bulk, count)
+	for ii := range objs {
+		objs[ii].Value = fmt.Sprintf("value-%d", ii)
+		objs[ii].Num = ii
+	}
+	res := o.MustInsertMany(objs, 100)
+	if aff, err := res.RowsAffected(); err != nil || aff != count {
+		t.Errorf("expecting %d affected rows, got %d (%v)", count, aff, err)
+	}
+	if c := o.Table(table).MustCount(); c != count {
+		t.Fatalf("expecting %d objects, got %d", count, c)
+	}
+	seen := make(map[int64]bool)
+	for _, v := range objs {
+		if v.Id == 0 || seen[v.Id] {
+			t.Fatalf("invalid or duplicate id %d", v.Id)
+		}
+		seen[v.Id] = true
+	}
+	for _, v := range []Bulk{objs[0], objs[count/2], objs[count-1]} {
+		var b Bulk
+		if !o.MustOne(Eq("Id", v.Id), &b) || b.Value != v.Value {
+			t.Errorf("expecting value %q with id %d, got %q", v.Value, v.Id, b.Value)
+		}
+	}
+	// Each
+	var last int64
+	n := 0
+	err := o.Table(table).Each(func(b *Bulk) error {
+		if b.Id <= last {
+			return fmt.Errorf("id %d after %d", b.Id, last)
+		}
+		last = b.Id
+		n++
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if n != count {
+		t.Errorf("expecting %d objects in Each, got %d", count, n)
+	}
+	n = 0
+	if err := o.Query(Lt("Num", 600)).Limit(550).Each(func(b Bulk) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Error(err)
+	}
+	if n != 550 {
+		t.Errorf("expecting 550 objects in Each with limit, got %d", n)
+	}
+	stop := errors.New("stop")
+	if err := o.Query(nil).Each(func(b *Bulk) error { return stop }); err != stop {
+		t.Errorf("expecting error %v from Each, got %v", stop, err)
+	}
+	if err := o.Query(nil).Sort("Num", DESC).Each(func(b *Bulk) error { return nil }); err == nil {
+		t.Error("expecting an error when calling Each on a sorted query")
+	}
+	// UpdateMany
+	updated := []*Bulk{&objs[1], &objs[2], &objs[3]}
+	for _, v := range updated {
+		v.Value = "updated"
+	}
+	res = o.MustUpdateMany(updated)
+	if aff, err := res.RowsAffected(); err != nil || aff != int64(len(updated)) {
+		t.Errorf("expecting %d affected rows, got %d (%v)", len(updated), aff, err)
+	}
+	if c := o.Table(table).Filter(Eq("Value", "updated")).MustCount(); c != uint64(len(updated)) {
+		t.Errorf("expecting %d updated objects, got %d", len(updated), c)
+	}
+	if o.Driver().Capabilities()&driver.CAP_BEGIN == 0 {
+		return
+	}
+	tx := o.MustBegin()
+	more := []*Bulk{{Value: "tx1"}, {Value: "tx2"}}
+	tx.MustInsertMany(more, 0)
+	if more[0].Id == 0 || more[1].Id == 0 {
+		t.Error("ids not set when inserting inside a transaction")
+	}
+	n = 0
+	if err := tx.Query(Eq("Value", "tx1")).Each(func(b *Bulk) error {
+		n++
+		b.Value = "tx1-updated"
+		_, err := tx.UpdateMany([]*Bulk{b})
+		return err
+	}); err != nil {
+		t.Error(err)
+	}
+	if n != 1 {
+		t.Errorf("expecting 1 object in Each inside transaction, got %d", n)
+	}
+	if c := tx.Table(table).Filter(Eq("Value", "tx1-updated")).MustCount(); c != 1 {
+		t.Errorf("expecting 1 updated object inside transaction, got %d", c)
+	}
+	tx.MustRollback()
+	if c := o.Table(table).MustCount(); c != count {
+		t.Errorf("expecting %d objects after rollback, got %d", count, c)
+	}
+}
@@ -16,3 +16,12 @@ type Conn interface {
 	Delete(m Model, q query.Q) (Result, error)
 	Connection() interface{}
 }
+
+// BulkInserter is implemented by connections which can insert
+// multiple objects of the same model more efficiently than
+// inserting them one by one. If the model has an auto_increment
+// primary key, the returned ids must contain the id assigned
+// to each object, in the same order.
+type BulkInserter interface {
+	InsertMany(m Model, data []interface{}) (Result, []int64, error)
+}
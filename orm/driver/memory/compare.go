@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// compare compares two non-nil stored values, returning -1, 0
+// or 1 if a is respectively lower, equal or greater than b.
+// Numeric values can be compared regardless of their kind and
+// strings can be compared with []byte.
+func compare(a, b interface{}) (int, error) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return compareInt64(x, y), nil
+		case uint64:
+			if x < 0 {
+				return -1, nil
+			}
+			return compareUint64(uint64(x), y), nil
+		case float64:
+			return compareFloat64(float64(x), y), nil
+		}
+	case uint64:
+		switch y := b.(type) {
+		case int64:
+			if y < 0 {
+				return 1, nil
+			}
+			return compareUint64(x, uint64(y)), nil
+		case uint64:
+			return compareUint64(x, y), nil
+		case float64:
+			return compareFloat64(float64(x), y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return compareFloat64(x, float64(y)), nil
+		case uint64:
+			return compareFloat64(x, float64(y)), nil
+		case float64:
+			return compareFloat64(x, y), nil
+		}
+	case string:
+		switch y := b.(type) {
+		case string:
+			return strings.Compare(x, y), nil
+		case []byte:
+			return strings.Compare(x, string(y)), nil
+		}
+	case []byte:
+		switch y := b.(type) {
+		case string:
+			return strings.Compare(string(x), y), nil
+		case []byte:
+			return bytes.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case !x:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1, nil
+			case x.After(y):
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	return 0, fmt.Errorf("can't compare %T with %T", a, b)
+}
+
+// equal returns true iff a and b are comparable and equal.
+func equal(a, b interface{}) bool {
+	c, err := compare(a, b)
+	return err == nil && c == 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+// Package memory implements an ORM driver which keeps all the
+// data in memory, intended for fast unit tests which don't need
+// a real database. To enable the driver, import its package:
+//
+//  import (
+//      _ "gnd.la/orm/driver/memory"
+//  )
+//
+// The URL format for this package is:
+//
+//  memory://[name]
+//
+// When a name is provided, all the ORMs opened with the same name
+// share the same data, so it can be e.g. populated by a test and then
+// queried from the App being tested. Otherwise, each ORM gets its own
+// empty database. No driver specific options are supported.
+//
+// This driver supports JOINs, transactions, primary keys (including
+// composite ones), unique fields and indexes, auto_increment and foreign
+// keys, but some caveats your need to be aware of:
+//
+//  - Transactions are not isolated from each other, their changes are
+//      visible to other connections before they're committed. Rolling back
+//      a transaction restores the tables it modified to the state they were
+//      at when the transaction first modified them.
+//  - Contains is case sensitive.
+//  - Subqueries (gnd.la/orm/query.Subquery) are not supported.
+//  - Upserts are not supported.
+package memory
@@ -0,0 +1,540 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gnd.la/config"
+	"gnd.la/orm/driver"
+	"gnd.la/orm/operation"
+	"gnd.la/orm/query"
+)
+
+var (
+	errUpsertNotSupported = errors.New("memory driver does not support upserts")
+	errJoinNotSupported   = errors.New("memory driver does not support JOIN when modifying rows")
+
+	databases struct {
+		sync.Mutex
+		named map[string]*database
+	}
+)
+
+type transaction struct {
+	// tables as they were before being first
+	// modified by the transaction
+	saved    map[string]*table
+	finished bool
+}
+
+type Driver struct {
+	db *database
+	tx *transaction
+}
+
+func (d *Driver) Check() error {
+	return nil
+}
+
+func (d *Driver) Initialize(ms []driver.Model) error {
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	for _, v := range ms {
+		if v.Fields() == nil {
+			continue
+		}
+		if d.tx != nil {
+			d.save(v.Table())
+		}
+		if err := d.db.initialize(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) Query(m driver.Model, q query.Q, sort []driver.Sort, limit int, offset int) driver.Iter {
+	sel, tuples, err := d.selectTuples(m, q, sort, limit, offset)
+	return &Iter{sel: sel, tuples: tuples, err: err}
+}
+
+func (d *Driver) Count(m driver.Model, q query.Q, limit int, offset int) (uint64, error) {
+	_, tuples, err := d.selectTuples(m, q, nil, limit, offset)
+	return uint64(len(tuples)), err
+}
+
+func (d *Driver) Exists(m driver.Model, q query.Q) (bool, error) {
+	_, tuples, err := d.selectTuples(m, q, nil, 1, -1)
+	return len(tuples) > 0, err
+}
+
+func (d *Driver) selectTuples(m driver.Model, q query.Q, sort []driver.Sort, limit int, offset int) (*selection, []tuple, error) {
+	if err := d.checkFinished(); err != nil {
+		return nil, nil, err
+	}
+	d.db.mu.RLock()
+	defer d.db.mu.RUnlock()
+	sel, err := newSelection(d.db, m)
+	if err != nil {
+		return nil, nil, err
+	}
+	tuples, err := sel.tuples(q, sort, limit, offset)
+	return sel, tuples, err
+}
+
+func (d *Driver) Insert(m driver.Model, data interface{}) (driver.Result, error) {
+	if err := d.checkFinished(); err != nil {
+		return nil, err
+	}
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	t, err := d.db.table(m.Table())
+	if err != nil {
+		return nil, err
+	}
+	id, err := d.insert(t, m, data)
+	if err != nil {
+		return nil, err
+	}
+	return &result{id: id, count: 1}, nil
+}
+
+// InsertMany inserts all the objects in data while holding
+// the database lock, so other connections can't observe a
+// partial insert unless an error occurs.
+func (d *Driver) InsertMany(m driver.Model, data []interface{}) (driver.Result, []int64, error) {
+	if err := d.checkFinished(); err != nil {
+		return nil, nil, err
+	}
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	t, err := d.db.table(m.Table())
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]int64, len(data))
+	res := &result{}
+	for ii, v := range data {
+		id, err := d.insert(t, m, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[ii] = id
+		res.id = id
+		res.count++
+	}
+	return res, ids, nil
+}
+
+func (d *Driver) insert(t *table, m driver.Model, data interface{}) (int64, error) {
+	cols, values, err := saveValues(t, m, data)
+	if err != nil {
+		return 0, err
+	}
+	r := make(row, len(t.columns))
+	provided := make([]bool, len(t.columns))
+	for ii, v := range cols {
+		r[v] = values[ii]
+		provided[v] = true
+	}
+	for ii, c := range t.columns {
+		if !provided[ii] {
+			if r[ii], err = c.defaultValue(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	var id int64
+	lastId := t.lastId
+	if ai := t.autoIncrement; ai >= 0 {
+		id = toInt64(r[ai])
+		if id == 0 {
+			lastId++
+			id = lastId
+			if t.columns[ai].kind == kindUint {
+				r[ai] = uint64(id)
+			} else {
+				r[ai] = id
+			}
+		} else if id > lastId {
+			lastId = id
+		}
+	}
+	if err := d.db.checkRow(t, t.rows, r, -1); err != nil {
+		return 0, err
+	}
+	d.save(t.name)
+	t.rows = append(t.rows, r)
+	t.lastId = lastId
+	return id, nil
+}
+
+func (d *Driver) Operate(m driver.Model, q query.Q, ops []*operation.Operation) (driver.Result, error) {
+	return d.update(m, q, func(sel *selection, t *table, r row) (row, error) {
+		nr := make(row, len(r))
+		copy(nr, r)
+		for _, op := range ops {
+			_, col, err := sel.field(op.Field)
+			if err != nil {
+				return nil, err
+			}
+			c := t.columns[col]
+			var val interface{}
+			switch op.Operator {
+			case operation.OpAdd, operation.OpSub:
+				delta, err := normalize(reflect.ValueOf(op.Value))
+				if err != nil {
+					return nil, err
+				}
+				if val, err = add(r[col], delta, op.Operator == operation.OpSub); err != nil {
+					return nil, err
+				}
+			case operation.OpSet:
+				if f, ok := op.Value.(operation.Field); ok {
+					_, fcol, err := sel.field(string(f))
+					if err != nil {
+						return nil, err
+					}
+					val = r[fcol]
+				} else if val, err = encode(c, reflect.ValueOf(op.Value)); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, fmt.Errorf("operator %d is not supported", op.Operator)
+			}
+			nr[col] = val
+		}
+		return nr, nil
+	})
+}
+
+func (d *Driver) Update(m driver.Model, q query.Q, data interface{}) (driver.Result, error) {
+	var cols []int
+	var values []interface{}
+	return d.update(m, q, func(sel *selection, t *table, r row) (row, error) {
+		if cols == nil {
+			var err error
+			if cols, values, err = saveValues(t, m, data); err != nil {
+				return nil, err
+			}
+		}
+		nr := make(row, len(r))
+		copy(nr, r)
+		for ii, v := range cols {
+			nr[v] = values[ii]
+		}
+		return nr, nil
+	})
+}
+
+// update replaces each row matched by q with the row returned by f.
+func (d *Driver) update(m driver.Model, q query.Q, f func(*selection, *table, row) (row, error)) (driver.Result, error) {
+	if err := d.checkFinished(); err != nil {
+		return nil, err
+	}
+	if m.Join() != nil {
+		return nil, errJoinNotSupported
+	}
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	sel, err := newSelection(d.db, m)
+	if err != nil {
+		return nil, err
+	}
+	t := sel.tables[0]
+	rows := make([]row, len(t.rows))
+	copy(rows, t.rows)
+	var updated []int
+	for ii, r := range t.rows {
+		ok, err := sel.match(tuple{r}, q)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if rows[ii], err = f(sel, t, r); err != nil {
+				return nil, err
+			}
+			updated = append(updated, ii)
+		}
+	}
+	for _, v := range updated {
+		if err := d.db.checkRow(t, rows, rows[v], v); err != nil {
+			return nil, err
+		}
+	}
+	if len(updated) > 0 {
+		d.save(t.name)
+		t.rows = rows
+	}
+	return &result{count: len(updated)}, nil
+}
+
+func (d *Driver) Upsert(m driver.Model, q query.Q, data interface{}) (driver.Result, error) {
+	return nil, errUpsertNotSupported
+}
+
+func (d *Driver) Delete(m driver.Model, q query.Q) (driver.Result, error) {
+	if err := d.checkFinished(); err != nil {
+		return nil, err
+	}
+	if m.Join() != nil {
+		return nil, errJoinNotSupported
+	}
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	sel, err := newSelection(d.db, m)
+	if err != nil {
+		return nil, err
+	}
+	t := sel.tables[0]
+	var deleted, remaining []row
+	for _, r := range t.rows {
+		ok, err := sel.match(tuple{r}, q)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			deleted = append(deleted, r)
+		} else {
+			remaining = append(remaining, r)
+		}
+	}
+	if len(deleted) > 0 {
+		if err := d.db.checkDelete(t, deleted, remaining); err != nil {
+			return nil, err
+		}
+		d.save(t.name)
+		t.rows = remaining
+	}
+	return &result{count: len(deleted)}, nil
+}
+
+func (d *Driver) Close() error {
+	return nil
+}
+
+func (d *Driver) Upserts() bool {
+	return false
+}
+
+func (d *Driver) Tags() []string {
+	return []string{"memory"}
+}
+
+func (d *Driver) Begin() (driver.Tx, error) {
+	if d.tx != nil {
+		return nil, driver.ErrInTransaction
+	}
+	return &Driver{
+		db: d.db,
+		tx: &transaction{saved: make(map[string]*table)},
+	}, nil
+}
+
+func (d *Driver) Commit() error {
+	if d.tx == nil {
+		return driver.ErrNotInTransaction
+	}
+	if d.tx.finished {
+		return driver.ErrFinished
+	}
+	d.tx.finished = true
+	d.tx.saved = nil
+	return nil
+}
+
+func (d *Driver) Rollback() error {
+	if d.tx == nil {
+		return driver.ErrNotInTransaction
+	}
+	if d.tx.finished {
+		return driver.ErrFinished
+	}
+	d.db.mu.Lock()
+	defer d.db.mu.Unlock()
+	for k, v := range d.tx.saved {
+		if v != nil {
+			d.db.tables[k] = v
+		} else {
+			delete(d.db.tables, k)
+		}
+	}
+	d.tx.finished = true
+	d.tx.saved = nil
+	return nil
+}
+
+func (d *Driver) Transaction(f func(driver.Driver) error) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	drv := tx.(*Driver)
+	if err := f(drv); err != nil {
+		drv.Rollback()
+		return err
+	}
+	return drv.Commit()
+}
+
+// save stores a copy of the given table, so it can be restored
+// if the transaction is rolled back. It must be called with the
+// database lock held and before modifying the table.
+func (d *Driver) save(name string) {
+	if d.tx == nil {
+		return
+	}
+	if _, ok := d.tx.saved[name]; ok {
+		return
+	}
+	var saved *table
+	if t := d.db.tables[name]; t != nil {
+		saved = t.clone()
+	}
+	d.tx.saved[name] = saved
+}
+
+func (d *Driver) checkFinished() error {
+	if d.tx != nil && d.tx.finished {
+		return driver.ErrFinished
+	}
+	return nil
+}
+
+func (d *Driver) Capabilities() driver.Capability {
+	return driver.CAP_JOIN | driver.CAP_OR | driver.CAP_TRANSACTION | driver.CAP_BEGIN |
+		driver.CAP_AUTO_ID | driver.CAP_AUTO_INCREMENT | driver.CAP_PK | driver.CAP_COMPOSITE_PK |
+		driver.CAP_UNIQUE
+}
+
+func (d *Driver) HasFunc(fname string, retType reflect.Type) bool {
+	return false
+}
+
+func (d *Driver) Connection() interface{} {
+	return d.db
+}
+
+// saveValues returns the column indexes and the values to be stored
+// for the given data, which must be of type m.Type(). Fields inside
+// nil pointers and empty fields tagged with omitempty are not included.
+func saveValues(t *table, m driver.Model, data interface{}) ([]int, []interface{}, error) {
+	val := driver.Direct(reflect.ValueOf(data))
+	fields := m.Fields()
+	cols := make([]int, 0, len(fields.MNames))
+	values := make([]interface{}, 0, len(fields.MNames))
+	for ii, v := range fields.Indexes {
+		f := fieldByIndex(val, v, false)
+		if !f.IsValid() {
+			continue
+		}
+		if fields.OmitEmpty[ii] && driver.IsZero(f) {
+			continue
+		}
+		idx, c, err := t.column(fields.MNames[ii])
+		if err != nil {
+			return nil, nil, err
+		}
+		var fval interface{}
+		if !fields.NullEmpty[ii] || !driver.IsZero(f) {
+			if fval, err = encode(c, f); err != nil {
+				return nil, nil, fmt.Errorf("error encoding field %s: %s", fields.QNames[ii], err)
+			}
+		}
+		cols = append(cols, idx)
+		values = append(values, fval)
+	}
+	return cols, values, nil
+}
+
+func toInt64(val interface{}) int64 {
+	switch x := val.(type) {
+	case int64:
+		return x
+	case uint64:
+		return int64(x)
+	}
+	return 0
+}
+
+// add returns val + delta or val - delta if sub is true. If
+// val is nil, the result is also nil.
+func add(val interface{}, delta interface{}, sub bool) (interface{}, error) {
+	if val == nil {
+		return nil, nil
+	}
+	switch x := val.(type) {
+	case int64:
+		var d int64
+		switch y := delta.(type) {
+		case int64:
+			d = y
+		case uint64:
+			d = int64(y)
+		case float64:
+			d = int64(y)
+		default:
+			return nil, fmt.Errorf("can't add %T to %T", delta, val)
+		}
+		if sub {
+			return x - d, nil
+		}
+		return x + d, nil
+	case uint64:
+		var d int64
+		switch y := delta.(type) {
+		case int64:
+			d = y
+		case uint64:
+			d = int64(y)
+		case float64:
+			d = int64(y)
+		default:
+			return nil, fmt.Errorf("can't add %T to %T", delta, val)
+		}
+		if sub {
+			d = -d
+		}
+		return uint64(int64(x) + d), nil
+	case float64:
+		var d float64
+		switch y := delta.(type) {
+		case int64:
+			d = float64(y)
+		case uint64:
+			d = float64(y)
+		case float64:
+			d = y
+		default:
+			return nil, fmt.Errorf("can't add %T to %T", delta, val)
+		}
+		if sub {
+			return x - d, nil
+		}
+		return x + d, nil
+	}
+	return nil, fmt.Errorf("can't add to non-numeric value of type %T", val)
+}
+
+func memoryOpener(url *config.URL) (driver.Driver, error) {
+	if url.Value == "" {
+		return &Driver{db: newDatabase()}, nil
+	}
+	databases.Lock()
+	defer databases.Unlock()
+	db := databases.named[url.Value]
+	if db == nil {
+		if databases.named == nil {
+			databases.named = make(map[string]*database)
+		}
+		db = newDatabase()
+		databases.named[url.Value] = db
+	}
+	return &Driver{db: db}, nil
+}
+
+func init() {
+	driver.Register("memory", memoryOpener)
+}
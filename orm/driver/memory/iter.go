@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type Iter struct {
+	sel    *selection
+	tuples []tuple
+	pos    int
+	err    error
+}
+
+func (i *Iter) Next(out ...interface{}) bool {
+	if i.err != nil || i.pos >= len(i.tuples) {
+		return false
+	}
+	t := i.tuples[i.pos]
+	i.pos++
+	pos := i.nextModel(0)
+	for _, v := range out {
+		if isNil(v) || pos >= len(i.sel.models) {
+			continue
+		}
+		if err := i.setOut(pos, t[pos], v); err != nil {
+			i.err = err
+			return false
+		}
+		pos = i.nextModel(pos + 1)
+	}
+	return true
+}
+
+// nextModel returns the position of the first non-skipped
+// model in the selection, starting at pos.
+func (i *Iter) nextModel(pos int) int {
+	for pos < len(i.sel.models) && i.sel.models[pos].Skip() {
+		pos++
+	}
+	return pos
+}
+
+func (i *Iter) setOut(pos int, r row, out interface{}) error {
+	val := reflect.ValueOf(out)
+	vt := val.Type()
+	if vt.Kind() != reflect.Ptr {
+		return fmt.Errorf("can't set object of type %T. Please, pass a %v rather than a %v", out, reflect.PtrTo(vt), vt)
+	}
+	if r == nil {
+		// No row for this model (outer join)
+		el := val.Elem()
+		el.Set(reflect.Zero(el.Type()))
+		return nil
+	}
+	if vt.Elem().Kind() == reflect.Ptr && vt.Elem().Elem().Kind() == reflect.Struct {
+		// Received a pointer to pointer. Always create a new object,
+		// to avoid overwriting the previous result.
+		val = val.Elem()
+		val.Set(reflect.New(val.Type().Elem()))
+	}
+	for val.Kind() == reflect.Ptr {
+		el := val.Elem()
+		if !el.IsValid() {
+			if !val.CanSet() {
+				// Typed nil pointer
+				return nil
+			}
+			el = reflect.New(val.Type().Elem())
+			val.Set(el)
+		}
+		val = el
+	}
+	fields := i.sel.models[pos].Fields()
+	t := i.sel.tables[pos]
+	cols := make([]int, len(fields.MNames))
+	for ii, v := range fields.MNames {
+		idx, _, err := t.column(v)
+		if err != nil {
+			return err
+		}
+		cols[ii] = idx
+	}
+	for _, p := range fields.Pointers {
+		isNil := true
+		for ii, v := range fields.Indexes {
+			if fields.IsSubfield(v, p) && r[cols[ii]] != nil {
+				isNil = false
+				break
+			}
+		}
+		if isNil {
+			if fval := fieldByIndex(val, p, false); fval.IsValid() {
+				fval.Set(reflect.Zero(fval.Type()))
+			}
+		}
+	}
+	for ii, v := range fields.Indexes {
+		stored := r[cols[ii]]
+		field := fieldByIndex(val, v, stored != nil)
+		if !field.IsValid() {
+			continue
+		}
+		if err := decode(t.columns[cols[ii]], field, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Iter) Err() error {
+	return i.err
+}
+
+func (i *Iter) Close() error {
+	i.tuples = nil
+	return nil
+}
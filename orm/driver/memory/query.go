@@ -0,0 +1,357 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gnd.la/orm/driver"
+	"gnd.la/orm/query"
+)
+
+var (
+	errSubquery = errors.New("memory driver does not support subqueries")
+)
+
+// tuple contains a row for each model in a selection. Rows
+// for models which didn't match an outer join are nil.
+type tuple []row
+
+// selection represents the tables involved in a query, in
+// the same order as the models in the join chain.
+type selection struct {
+	models []driver.Model
+	tables []*table
+}
+
+func newSelection(db *database, m driver.Model) (*selection, error) {
+	s := &selection{}
+	for cur := m; ; {
+		t, err := db.table(cur.Table())
+		if err != nil {
+			return nil, err
+		}
+		s.models = append(s.models, cur)
+		s.tables = append(s.tables, t)
+		join := cur.Join()
+		if join == nil {
+			break
+		}
+		cur = join.Model()
+	}
+	return s, nil
+}
+
+// field returns the position in the selection and the column index
+// for the given field name (as passed to the ORM, e.g. Foo or Bar|Foo).
+func (s *selection) field(name string) (int, int, error) {
+	quoted, _, err := s.models[0].Map(name)
+	if err != nil {
+		return -1, -1, err
+	}
+	// quoted is "table"."field"
+	sep := strings.Index(quoted, "\".\"")
+	if sep < 0 {
+		return -1, -1, fmt.Errorf("invalid field name %q", quoted)
+	}
+	tableName := quoted[1:sep]
+	fieldName := quoted[sep+3 : len(quoted)-1]
+	for ii, t := range s.tables {
+		if t.name == tableName {
+			if idx, ok := t.colMap[fieldName]; ok {
+				return ii, idx, nil
+			}
+		}
+	}
+	return -1, -1, fmt.Errorf("can't find field %q in selection", quoted)
+}
+
+// tuples returns the tuples from the selection which match q,
+// sorted by the given sort and with limit and offset applied.
+func (s *selection) tuples(q query.Q, sortBy []driver.Sort, limit int, offset int) ([]tuple, error) {
+	var tuples []tuple
+	for _, r := range s.tables[0].rows {
+		t := make(tuple, len(s.models))
+		t[0] = r
+		tuples = append(tuples, t)
+	}
+	for ii, m := range s.models[:len(s.models)-1] {
+		var err error
+		if tuples, err = s.join(tuples, ii+1, m.Join()); err != nil {
+			return nil, err
+		}
+	}
+	var matched []tuple
+	for _, v := range tuples {
+		ok, err := s.match(v, q)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, v)
+		}
+	}
+	if len(sortBy) > 0 && len(matched) > 1 {
+		ts := &tupleSorter{tuples: matched}
+		for _, v := range sortBy {
+			pos, col, err := s.field(v.Field())
+			if err != nil {
+				return nil, err
+			}
+			ts.pos = append(ts.pos, pos)
+			ts.cols = append(ts.cols, col)
+			ts.dirs = append(ts.dirs, int(v.Direction()))
+		}
+		sort.Stable(ts)
+		if ts.err != nil {
+			return nil, ts.err
+		}
+	}
+	if offset > 0 {
+		if offset >= len(matched) {
+			return nil, nil
+		}
+		matched = matched[offset:]
+	}
+	if limit >= 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// join joins the given tuples with the rows in the table at position pos.
+func (s *selection) join(tuples []tuple, pos int, j driver.Join) ([]tuple, error) {
+	var result []tuple
+	rows := s.tables[pos].rows
+	joined := make([]bool, len(rows))
+	jt := j.Type()
+	for _, t := range tuples {
+		found := false
+		for ii, r := range rows {
+			nt := make(tuple, len(t))
+			copy(nt, t)
+			nt[pos] = r
+			ok, err := s.match(nt, j.Query())
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, nt)
+				found = true
+				joined[ii] = true
+			}
+		}
+		if !found && (jt == driver.LeftJoin || jt == driver.OuterJoin) {
+			result = append(result, t)
+		}
+	}
+	if jt == driver.RightJoin || jt == driver.OuterJoin {
+		for ii, r := range rows {
+			if !joined[ii] {
+				t := make(tuple, len(s.models))
+				t[pos] = r
+				result = append(result, t)
+			}
+		}
+	}
+	return result, nil
+}
+
+// value returns the stored value for the given field in the tuple.
+func (s *selection) value(t tuple, name string) (interface{}, *column, error) {
+	pos, col, err := s.field(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := s.tables[pos].columns[col]
+	if r := t[pos]; r != nil {
+		return r[col], c, nil
+	}
+	return nil, c, nil
+}
+
+// operand returns the value to compare the field with, which might
+// be another field in the tuple when value is a query.F.
+func (s *selection) operand(t tuple, c *column, value interface{}) (interface{}, error) {
+	switch x := value.(type) {
+	case query.F:
+		val, _, err := s.value(t, string(x))
+		return val, err
+	case query.Subquery:
+		return nil, errSubquery
+	}
+	return encode(c, reflect.ValueOf(value))
+}
+
+func (s *selection) match(t tuple, q query.Q) (bool, error) {
+	switch x := q.(type) {
+	case nil:
+		return true, nil
+	case *query.And:
+		for _, v := range x.Conditions {
+			ok, err := s.match(t, v)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case *query.Or:
+		for _, v := range x.Conditions {
+			ok, err := s.match(t, v)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case *query.Eq:
+		if isNil(x.Value) {
+			val, _, err := s.value(t, x.Field.Field)
+			return val == nil, err
+		}
+		return s.compareField(t, &x.Field, func(c int) bool { return c == 0 })
+	case *query.Neq:
+		if isNil(x.Value) {
+			val, _, err := s.value(t, x.Field.Field)
+			return val != nil, err
+		}
+		return s.compareField(t, &x.Field, func(c int) bool { return c != 0 })
+	case *query.Lt:
+		return s.compareField(t, &x.Field, func(c int) bool { return c < 0 })
+	case *query.Lte:
+		return s.compareField(t, &x.Field, func(c int) bool { return c <= 0 })
+	case *query.Gt:
+		return s.compareField(t, &x.Field, func(c int) bool { return c > 0 })
+	case *query.Gte:
+		return s.compareField(t, &x.Field, func(c int) bool { return c >= 0 })
+	case *query.Contains:
+		val, c, err := s.value(t, x.Field.Field)
+		if err != nil {
+			return false, err
+		}
+		op, err := s.operand(t, c, x.Value)
+		if err != nil || val == nil || op == nil {
+			return false, err
+		}
+		vs, ok1 := stringValue(val)
+		ops, ok2 := stringValue(op)
+		if !ok1 || !ok2 {
+			return false, fmt.Errorf("can't use CONTAINS with %T and %T", val, op)
+		}
+		return strings.Contains(vs, ops), nil
+	case *query.In:
+		val, c, err := s.value(t, x.Field.Field)
+		if err != nil {
+			return false, err
+		}
+		if _, ok := x.Value.(query.Subquery); ok {
+			return false, errSubquery
+		}
+		items := reflect.ValueOf(x.Value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return false, fmt.Errorf("argument for IN must be a slice or an array, not %T", x.Value)
+		}
+		if items.Len() == 0 {
+			return false, fmt.Errorf("empty IN (%s)", x.Field.Field)
+		}
+		if val == nil {
+			return false, nil
+		}
+		for ii := 0; ii < items.Len(); ii++ {
+			op, err := encode(c, items.Index(ii))
+			if err != nil {
+				return false, err
+			}
+			if op != nil && equal(val, op) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("memory driver does not support query type %T", q)
+}
+
+// compareField compares the field value with the field in the tuple using
+// the function f. As in SQL, comparisons involving NULL values are always
+// false.
+func (s *selection) compareField(t tuple, field *query.Field, f func(int) bool) (bool, error) {
+	val, c, err := s.value(t, field.Field)
+	if err != nil {
+		return false, err
+	}
+	op, err := s.operand(t, c, field.Value)
+	if err != nil || val == nil || op == nil {
+		return false, err
+	}
+	res, err := compare(val, op)
+	if err != nil {
+		return false, err
+	}
+	return f(res), nil
+}
+
+func stringValue(val interface{}) (string, bool) {
+	switch x := val.(type) {
+	case string:
+		return x, true
+	case []byte:
+		return string(x), true
+	}
+	return "", false
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	val := reflect.ValueOf(v)
+	return val.Kind() == reflect.Ptr && val.IsNil()
+}
+
+type tupleSorter struct {
+	tuples []tuple
+	pos    []int
+	cols   []int
+	dirs   []int
+	err    error
+}
+
+func (t *tupleSorter) Len() int {
+	return len(t.tuples)
+}
+
+func (t *tupleSorter) Less(i, j int) bool {
+	ti, tj := t.tuples[i], t.tuples[j]
+	for ii, pos := range t.pos {
+		var vi, vj interface{}
+		if r := ti[pos]; r != nil {
+			vi = r[t.cols[ii]]
+		}
+		if r := tj[pos]; r != nil {
+			vj = r[t.cols[ii]]
+		}
+		var c int
+		switch {
+		case vi == nil && vj == nil:
+		case vi == nil:
+			// NULLs go first
+			c = -1
+		case vj == nil:
+			c = 1
+		default:
+			var err error
+			if c, err = compare(vi, vj); err != nil && t.err == nil {
+				t.err = err
+			}
+		}
+		if c != 0 {
+			return c*t.dirs[ii] < 0
+		}
+	}
+	return false
+}
+
+func (t *tupleSorter) Swap(i, j int) {
+	t.tuples[i], t.tuples[j] = t.tuples[j], t.tuples[i]
+}
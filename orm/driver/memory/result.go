@@ -0,0 +1,14 @@
+package memory
+
+type result struct {
+	id    int64
+	count int
+}
+
+func (r *result) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r *result) RowsAffected() (int64, error) {
+	return int64(r.count), nil
+}
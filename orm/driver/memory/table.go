@@ -0,0 +1,543 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gnd.la/encoding/codec"
+	"gnd.la/encoding/pipe"
+	"gnd.la/orm/driver"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// kind represents how a value is stored. All the
+// values in a table are stored as one of int64, uint64,
+// float64, string, bool, []byte or time.Time.
+type kind int
+
+const (
+	kindInt kind = iota + 1
+	kindUint
+	kindFloat
+	kindString
+	kindBool
+	kindBytes
+	kindTime
+)
+
+func (k kind) String() string {
+	switch k {
+	case kindInt:
+		return "int"
+	case kindUint:
+		return "uint"
+	case kindFloat:
+		return "float"
+	case kindString:
+		return "string"
+	case kindBool:
+		return "bool"
+	case kindBytes:
+		return "bytes"
+	case kindTime:
+		return "time"
+	}
+	return "invalid"
+}
+
+type row []interface{}
+
+type column struct {
+	name    string
+	kind    kind
+	codec   *codec.Codec
+	pipe    *pipe.Pipe
+	notNull bool
+	// default value, as stored in driver.Fields.Defaults
+	def reflect.Value
+}
+
+// defaultValue returns the value stored in the column
+// when no value is provided for it.
+func (c *column) defaultValue() (interface{}, error) {
+	if !c.def.IsValid() {
+		return nil, nil
+	}
+	val := c.def
+	if val.Kind() == reflect.Func {
+		val = val.Call(nil)[0]
+	}
+	return encode(c, val)
+}
+
+type reference struct {
+	column int
+	table  string
+	field  string
+}
+
+type table struct {
+	name    string
+	columns []*column
+	colMap  map[string]int
+	// index of the auto_increment column or -1
+	autoIncrement int
+	lastId        int64
+	// sets of columns which must be unique, including the pk
+	unique     [][]int
+	references []*reference
+	rows       []row
+}
+
+// clone returns a copy of the table which can be modified without
+// altering t. Rows are never modified in place, so they're shared.
+func (t *table) clone() *table {
+	c := *t
+	c.rows = append([]row(nil), t.rows...)
+	return &c
+}
+
+func (t *table) column(name string) (int, *column, error) {
+	if idx, ok := t.colMap[name]; ok {
+		return idx, t.columns[idx], nil
+	}
+	return -1, nil, fmt.Errorf("table %q has no field named %q", t.name, name)
+}
+
+// hasUnique returns true if the table already has an unique
+// constraint on the given columns.
+func (t *table) hasUnique(cols []int) bool {
+	for _, v := range t.unique {
+		if equalInts(v, cols) {
+			return true
+		}
+	}
+	return false
+}
+
+type database struct {
+	mu     sync.RWMutex
+	tables map[string]*table
+}
+
+func newDatabase() *database {
+	return &database{tables: make(map[string]*table)}
+}
+
+func (db *database) table(name string) (*table, error) {
+	if t := db.tables[name]; t != nil {
+		return t, nil
+	}
+	return nil, fmt.Errorf("table %q does not exist", name)
+}
+
+// checkRow checks that r, which is going to be stored at position pos
+// in t.rows (or appended, if pos is negative), does not violate any
+// of the table constraints.
+func (db *database) checkRow(t *table, rows []row, r row, pos int) error {
+	for ii, c := range t.columns {
+		if c.notNull && r[ii] == nil {
+			return fmt.Errorf("field %q in table %q can't be NULL", c.name, t.name)
+		}
+	}
+	for _, u := range t.unique {
+		if hasNil(r, u) {
+			continue
+		}
+		for ii, v := range rows {
+			if ii != pos && equalColumns(r, v, u) {
+				names := make([]string, len(u))
+				for jj, c := range u {
+					names[jj] = t.columns[c].name
+				}
+				return fmt.Errorf("duplicate value for unique field(s) %s in table %q", strings.Join(names, ", "), t.name)
+			}
+		}
+	}
+	for _, ref := range t.references {
+		val := r[ref.column]
+		if val == nil {
+			continue
+		}
+		found := false
+		if rt := db.tables[ref.table]; rt != nil {
+			if idx, ok := rt.colMap[ref.field]; ok {
+				if ref.table == t.name {
+					// Self reference, must use the new rows
+					found = containsValue(rows, idx, val) || (r[idx] != nil && equal(r[idx], val))
+				} else {
+					found = containsValue(rt.rows, idx, val)
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("foreign key violation: field %q in table %q references %s.%s = %v, which does not exist",
+				t.columns[ref.column].name, t.name, ref.table, ref.field, val)
+		}
+	}
+	return nil
+}
+
+// checkDelete checks that none of the given rows from t are
+// referenced from other rows.
+func (db *database) checkDelete(t *table, deleted []row, remaining []row) error {
+	for _, ot := range db.tables {
+		rows := ot.rows
+		if ot == t {
+			rows = remaining
+		}
+		for _, ref := range ot.references {
+			if ref.table != t.name {
+				continue
+			}
+			idx, ok := t.colMap[ref.field]
+			if !ok {
+				continue
+			}
+			for _, d := range deleted {
+				if val := d[idx]; val != nil && containsValue(rows, ref.column, val) {
+					return fmt.Errorf("foreign key violation: can't delete row with %s.%s = %v, it's referenced from table %q",
+						t.name, ref.field, val, ot.name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// initialize creates or updates the table for the given model.
+func (db *database) initialize(m driver.Model) error {
+	fields := m.Fields()
+	name := m.Table()
+	prev := db.tables[name]
+	t := &table{name: name, autoIncrement: -1, colMap: make(map[string]int)}
+	if prev != nil {
+		t.columns = append(t.columns, prev.columns...)
+		for k, v := range prev.colMap {
+			t.colMap[k] = v
+		}
+		t.autoIncrement = prev.autoIncrement
+		t.lastId = prev.lastId
+		t.unique = append(t.unique, prev.unique...)
+		t.references = append(t.references, prev.references...)
+	}
+	var added []int
+	for ii, mname := range fields.MNames {
+		tag := fields.Tags[ii]
+		c := &column{
+			name:    mname,
+			codec:   codec.FromTag(tag),
+			notNull: tag.Has("notnull"),
+			def:     fields.Defaults[ii],
+		}
+		if c.codec != nil {
+			c.pipe = pipe.FromTag(tag)
+			c.kind = kindBytes
+		} else {
+			k, err := typeKind(fields.Types[ii])
+			if err != nil {
+				return fmt.Errorf("can't store field %s in %s: %s", fields.QNames[ii], fields.Type, err)
+			}
+			c.kind = k
+		}
+		if idx, ok := t.colMap[mname]; ok {
+			if pc := t.columns[idx]; pc.kind != c.kind {
+				return fmt.Errorf("can't change type of field %q in table %q from %s to %s", mname, name, pc.kind, c.kind)
+			}
+			continue
+		}
+		if prev != nil {
+			if c.notNull && !fields.HasDefault(ii) {
+				return fmt.Errorf("can't add NOT NULL field %q without a default value to table %q", mname, name)
+			}
+			if isPrimaryKey(fields, ii) {
+				return fmt.Errorf("can't add primary key field %q to table %q", mname, name)
+			}
+		}
+		t.colMap[mname] = len(t.columns)
+		t.columns = append(t.columns, c)
+		added = append(added, ii)
+	}
+	if prev == nil {
+		if fields.PrimaryKey >= 0 {
+			idx := t.colMap[fields.MNames[fields.PrimaryKey]]
+			t.unique = append(t.unique, []int{idx})
+			if fields.AutoincrementPk {
+				t.autoIncrement = idx
+			}
+		} else if len(fields.CompositePrimaryKey) > 0 {
+			var pk []int
+			for _, v := range fields.CompositePrimaryKey {
+				pk = append(pk, t.colMap[fields.MNames[v]])
+			}
+			t.unique = append(t.unique, pk)
+		}
+	}
+	for ii, mname := range fields.MNames {
+		if fields.Tags[ii].Has("unique") {
+			if cols := []int{t.colMap[mname]}; !t.hasUnique(cols) {
+				t.unique = append(t.unique, cols)
+			}
+		}
+	}
+	for _, idx := range m.Indexes() {
+		if !idx.Unique {
+			continue
+		}
+		var cols []int
+		for _, v := range idx.Fields {
+			mname, _, err := fields.Map(v)
+			if err != nil {
+				return err
+			}
+			cols = append(cols, t.colMap[mname])
+		}
+		if !t.hasUnique(cols) {
+			t.unique = append(t.unique, cols)
+		}
+	}
+	for k, v := range fields.References {
+		pos, ok := fields.QNameMap[k]
+		if !ok {
+			return fmt.Errorf("can't map referencing field %q in %s", k, fields.Type)
+		}
+		refField, _, err := v.Model.Fields().Map(v.Field)
+		if err != nil {
+			return err
+		}
+		ref := &reference{column: t.colMap[fields.MNames[pos]], table: v.Model.Table(), field: refField}
+		exists := false
+		for _, r := range t.references {
+			if *r == *ref {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			t.references = append(t.references, ref)
+		}
+	}
+	if prev != nil {
+		// Add the new columns to the existing rows
+		defaults := make([]interface{}, len(added))
+		for ii, v := range added {
+			val, err := t.columns[t.colMap[fields.MNames[v]]].defaultValue()
+			if err != nil {
+				return err
+			}
+			defaults[ii] = val
+		}
+		t.rows = make([]row, len(prev.rows))
+		for ii, v := range prev.rows {
+			r := make(row, len(t.columns))
+			copy(r, v)
+			for jj, idx := range added {
+				r[t.colMap[fields.MNames[idx]]] = defaults[jj]
+			}
+			t.rows[ii] = r
+		}
+	}
+	db.tables[name] = t
+	return nil
+}
+
+func isPrimaryKey(fields *driver.Fields, idx int) bool {
+	if fields.PrimaryKey == idx {
+		return true
+	}
+	for _, v := range fields.CompositePrimaryKey {
+		if v == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func typeKind(typ reflect.Type) (kind, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return kindInt, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindUint, nil
+	case reflect.Float32, reflect.Float64:
+		return kindFloat, nil
+	case reflect.String:
+		return kindString, nil
+	case reflect.Bool:
+		return kindBool, nil
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return kindBytes, nil
+		}
+	case reflect.Struct:
+		if typ.ConvertibleTo(timeType) {
+			return kindTime, nil
+		}
+	}
+	return 0, fmt.Errorf("type %s is not supported", typ)
+}
+
+// normalize returns the value stored for val, which must be
+// an int64, uint64, float64, string, bool, []byte or time.Time.
+// Nil pointers are stored as nil.
+func normalize(val reflect.Value) (interface{}, error) {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil, nil
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return nil, nil
+	}
+	k, err := typeKind(val.Type())
+	if err != nil {
+		return nil, err
+	}
+	switch k {
+	case kindInt:
+		return val.Int(), nil
+	case kindUint:
+		return val.Uint(), nil
+	case kindFloat:
+		return val.Float(), nil
+	case kindString:
+		return val.String(), nil
+	case kindBool:
+		return val.Bool(), nil
+	case kindBytes:
+		b := val.Bytes()
+		if b == nil {
+			return nil, nil
+		}
+		return append([]byte{}, b...), nil
+	case kindTime:
+		return val.Convert(timeType).Interface(), nil
+	}
+	panic("unreachable")
+}
+
+// encode returns the value stored in the given column for val.
+func encode(c *column, val reflect.Value) (interface{}, error) {
+	if c.codec != nil {
+		if !val.IsValid() {
+			return nil, nil
+		}
+		if b, ok := val.Interface().([]byte); ok {
+			// Already encoded (e.g. a query value)
+			return b, nil
+		}
+		data, err := c.codec.Encode(val.Interface())
+		if err != nil {
+			return nil, err
+		}
+		if c.pipe != nil {
+			if data, err = c.pipe.Encode(data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+	return normalize(val)
+}
+
+// decode sets the field to the value stored in the given column.
+// Nil values set the field to its zero value.
+func decode(c *column, field reflect.Value, val interface{}) error {
+	if val == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if c.codec != nil {
+		data := val.([]byte)
+		if len(data) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		if c.pipe != nil {
+			var err error
+			if data, err = c.pipe.Decode(data); err != nil {
+				return err
+			}
+		}
+		return c.codec.Decode(data, field.Addr().Interface())
+	}
+	for field.Kind() == reflect.Ptr {
+		el := reflect.New(field.Type().Elem())
+		field.Set(el)
+		field = el.Elem()
+	}
+	if b, ok := val.([]byte); ok {
+		val = append([]byte{}, b...)
+	}
+	v := reflect.ValueOf(val)
+	if !v.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("can't assign value of type %T to field of type %s", val, field.Type())
+	}
+	field.Set(v.Convert(field.Type()))
+	return nil
+}
+
+func fieldByIndex(val reflect.Value, indexes []int, alloc bool) reflect.Value {
+	for _, v := range indexes {
+		if val.Type().Kind() == reflect.Ptr {
+			if val.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				val.Set(reflect.New(val.Type().Elem()))
+			}
+			val = val.Elem()
+		}
+		val = val.Field(v)
+	}
+	return val
+}
+
+func hasNil(r row, cols []int) bool {
+	for _, v := range cols {
+		if r[v] == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func equalColumns(r1, r2 row, cols []int) bool {
+	for _, v := range cols {
+		if r1[v] == nil || r2[v] == nil || !equal(r1[v], r2[v]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(rows []row, col int, val interface{}) bool {
+	for _, v := range rows {
+		if v[col] != nil && equal(v[col], val) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for ii, v := range a {
+		if b[ii] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -90,6 +90,18 @@ func (b *Backend) AlterField(db *sql.DB, m driver.Model, table *sql.Table, oldFi
 	return err
 }
 
+// InsertMany uses the LastInsertId returned by MySQL, which is
+// the id of the first inserted row. Note that ids are only
+// guaranteed to be consecutive when innodb_autoinc_lock_mode
+// is 0 or 1 (the default in MySQL < 8.0).
+func (b *Backend) InsertMany(db *sql.DB, m driver.Model, query string, count int, args ...interface{}) (driver.Result, []int64, error) {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, sql.ConsecutiveIds(m, res, count, true), nil
+}
+
 func (b *Backend) HasIndex(db *sql.DB, m driver.Model, idx *index.Index, name string) (bool, error) {
 	rows, err := db.Query("SHOW INDEX FROM ? WHERE Key_name = ?", m.Table(), name)
 	if err != nil {
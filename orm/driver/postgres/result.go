@@ -9,3 +9,16 @@ func (i insertResult) LastInsertId() (int64, error) {
 func (i insertResult) RowsAffected() (int64, error) {
 	return 1, nil
 }
+
+type bulkInsertResult []int64
+
+func (b bulkInsertResult) LastInsertId() (int64, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	return b[len(b)-1], nil
+}
+
+func (b bulkInsertResult) RowsAffected() (int64, error) {
+	return int64(len(b)), nil
+}
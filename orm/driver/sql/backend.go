@@ -56,6 +56,11 @@ type Backend interface {
 	// Insert performs an insert on the given database for the given model fields.
 	// Most drivers should just return db.Exec(query, args...).
 	Insert(*DB, driver.Model, string, ...interface{}) (driver.Result, error)
+	// InsertMany performs a multi-row insert of the given number of rows, returning
+	// the ids assigned to each row when the model has an auto_increment primary key.
+	// Most drivers should use the SqlBackend implementation, which assumes the
+	// rows are assigned consecutive ids and LastInsertId returns the last one.
+	InsertMany(db *DB, m driver.Model, query string, count int, args ...interface{}) (driver.Result, []int64, error)
 	// Returns the db type of the given field (e.g. INTEGER)
 	FieldType(reflect.Type, *structs.Tag) (string, error)
 	// Types that need to be transformed (e.g. sqlite transforms time.Time and bool to integer)
@@ -298,6 +303,37 @@ func (b *SqlBackend) Insert(db *DB, m driver.Model, query string, args ...interf
 	return db.Exec(query, args...)
 }
 
+func (b *SqlBackend) InsertMany(db *DB, m driver.Model, query string, count int, args ...interface{}) (driver.Result, []int64, error) {
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, ConsecutiveIds(m, res, count, false), nil
+}
+
+// ConsecutiveIds returns the ids assigned to count rows inserted in a
+// single statement, assuming they're consecutive. If first is true, the
+// LastInsertId from res is the id of the first row, otherwise it's the
+// id of the last one. If the model has no auto_increment primary key or
+// the last insert id is not available, it returns nil.
+func ConsecutiveIds(m driver.Model, res driver.Result, count int, first bool) []int64 {
+	if !m.Fields().AutoincrementPk {
+		return nil
+	}
+	id, err := res.LastInsertId()
+	if err != nil || id == 0 {
+		return nil
+	}
+	if !first {
+		id -= int64(count - 1)
+	}
+	ids := make([]int64, count)
+	for ii := range ids {
+		ids[ii] = id + int64(ii)
+	}
+	return ids
+}
+
 func (b *SqlBackend) Transforms() []reflect.Type {
 	return nil
 }
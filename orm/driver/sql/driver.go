@@ -220,6 +220,110 @@ func (d *Driver) Insert(m driver.Model, data interface{}) (driver.Result, error)
 	return res, err
 }
 
+// maxInsertParameters is the maximum number of parameters used in a
+// single multi-row INSERT. It's the lowest limit among the supported
+// backends (SQLite's SQLITE_MAX_VARIABLE_NUMBER).
+const maxInsertParameters = 999
+
+// InsertMany inserts all the objects in data, which must be of
+// the model m, using multi-row INSERT statements. Consecutive
+// objects which provide the same fields are inserted in the same
+// statement, as long as the number of parameters doesn't exceed
+// maxInsertParameters.
+func (d *Driver) InsertMany(m driver.Model, data []interface{}) (driver.Result, []int64, error) {
+	ids := make([]int64, 0, len(data))
+	var affected int64
+	var fields []string
+	var values []interface{}
+	count := 0
+	flush := func() error {
+		if count == 0 {
+			return nil
+		}
+		buf := getBuffer()
+		buf.WriteString("INSERT INTO ")
+		buf.WriteByte('"')
+		buf.WriteString(m.Table())
+		buf.WriteString("\" (")
+		for ii, v := range fields {
+			if ii > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('"')
+			buf.WriteString(v)
+			buf.WriteByte('"')
+		}
+		buf.WriteString(") VALUES ")
+		p := 0
+		for ii := 0; ii < count; ii++ {
+			if ii > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('(')
+			for jj := range fields {
+				if jj > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(d.backend.Placeholder(p))
+				p++
+			}
+			buf.WriteByte(')')
+		}
+		res, batchIds, err := d.backend.InsertMany(d.db, m, buftos(buf), count, values...)
+		putBuffer(buf)
+		if err != nil {
+			return err
+		}
+		if aff, err := res.RowsAffected(); err == nil {
+			affected += aff
+		}
+		if batchIds == nil {
+			batchIds = make([]int64, count)
+		}
+		ids = append(ids, batchIds...)
+		fields = nil
+		values = nil
+		count = 0
+		return nil
+	}
+	for _, v := range data {
+		_, f, vals, err := d.saveParameters(m, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(f) == 0 {
+			// No fields, must use DEFAULT VALUES
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+			res, err := d.Insert(m, v)
+			if err != nil {
+				return nil, nil, err
+			}
+			id, _ := res.LastInsertId()
+			ids = append(ids, id)
+			affected++
+			continue
+		}
+		if count > 0 && (!equalStrings(fields, f) || len(values)+len(vals) > maxInsertParameters) {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+		}
+		fields = f
+		values = append(values, vals...)
+		count++
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	res := &bulkResult{affected: affected}
+	if len(ids) > 0 {
+		res.lastId = ids[len(ids)-1]
+	}
+	return res, ids, nil
+}
+
 func (d *Driver) Operate(m driver.Model, q query.Q, ops []*operation.Operation) (driver.Result, error) {
 	buf := getBuffer()
 	buf.WriteString("UPDATE ")
@@ -259,8 +363,16 @@ func (d *Driver) Operate(m driver.Model, q query.Q, ops []*operation.Operation)
 				}
 				buf.WriteString(unquote(fieldName))
 			} else {
+				value := op.Value
+				if value != nil && d.transforms != nil {
+					if v := reflect.ValueOf(value); d.hasTransform(v.Type()) {
+						if value, err = d.backend.TransformOutValue(v); err != nil {
+							return nil, err
+						}
+					}
+				}
 				buf.WriteString(d.backend.Placeholder(len(params)))
-				params = append(params, op.Value)
+				params = append(params, value)
 			}
 		default:
 			return nil, fmt.Errorf("operator %d is not supported", op.Operator)
@@ -396,6 +508,11 @@ func (d *Driver) fieldByIndex(val reflect.Value, indexes []int, alloc bool) refl
 	return val
 }
 
+func (d *Driver) hasTransform(typ reflect.Type) bool {
+	_, ok := d.transforms[typ]
+	return ok
+}
+
 func (d *Driver) saveParameters(m driver.Model, data interface{}) (reflect.Value, []string, []interface{}, error) {
 	// data is guaranteed to be of m.Type()
 	val := driver.Direct(reflect.ValueOf(data))
@@ -39,3 +39,28 @@ func DescField(idx *index.Index, field string) bool {
 func buftos(buf *bytes.Buffer) string {
 	return buf.String()
 }
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for ii, v := range a {
+		if b[ii] != v {
+			return false
+		}
+	}
+	return true
+}
+
+type bulkResult struct {
+	lastId   int64
+	affected int64
+}
+
+func (r *bulkResult) LastInsertId() (int64, error) {
+	return r.lastId, nil
+}
+
+func (r *bulkResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
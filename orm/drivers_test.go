@@ -10,6 +10,7 @@ import (
 	"os/user"
 	"testing"
 
+	_ "gnd.la/orm/driver/memory"
 	_ "gnd.la/orm/driver/mysql"
 	_ "gnd.la/orm/driver/postgres"
 	_ "gnd.la/orm/driver/sqlite"
@@ -74,6 +75,19 @@ func (o *mysqlOpener) Open(t T) (*Orm, interface{}) {
 
 func (o *mysqlOpener) Close(_ interface{}) {}
 
+type memoryOpener struct {
+}
+
+func (o *memoryOpener) Open(t T) (*Orm, interface{}) {
+	return newOrm(t, "memory://", true), nil
+}
+
+func (o *memoryOpener) Close(_ interface{}) {}
+
+func TestMemory(t *testing.T) {
+	runAllTests(t, &memoryOpener{})
+}
+
 func TestSqlite(t *testing.T) {
 	runAllTests(t, &sqliteOpener{})
 }
@@ -89,6 +103,7 @@ func TestMysql(t *testing.T) {
 func init() {
 	openers["default"] = &sqliteOpener{}
 	openers["sqlite"] = &sqliteOpener{}
+	openers["memory"] = &memoryOpener{}
 	openers["postgres"] = &postgresOpener{}
 	openers["mysql"] = &mysqlOpener{}
 }
@@ -0,0 +1,45 @@
+// Package fixtures implements dumping and loading ORM data
+// to and from JSON or YAML files, which can be used for seeding
+// development databases or for setting up the data required by
+// tests. Since it only uses the public ORM API, it works with
+// every ORM driver.
+//
+// A fixture file contains an object with table names as keys
+// and lists of objects as values. Each object maps the qualified
+// field names (as seen by the ORM, e.g. Address.City for nested
+// structs) to their values. e.g.
+//
+//  {
+//      "users": [
+//          {"Id": 1, "Username": "alice", "Created": "2014-01-02T15:04:05Z"}
+//      ],
+//      "articles": [
+//          {"Id": 1, "AuthorId": 1, "Title": "Hello"}
+//      ]
+//  }
+//
+// Or, using YAML:
+//
+//  users:
+//    - Id: 1
+//      Username: alice
+//  articles:
+//    - Id: 1
+//      AuthorId: 1
+//      Title: Hello
+//
+// Fields omitted from an object are left at their zero values (or
+// their defaults, if the model declares any). Primary keys are loaded
+// as they appear in the file, so objects can reference each other.
+// When loading, tables are populated in reference order, so tables
+// which are referenced by other tables are loaded first, regardless
+// of their order in the files.
+//
+// When using PostgreSQL, the sequences backing auto_increment primary
+// keys are advanced after loading, so new objects can be inserted
+// without conflicting with the loaded ones.
+//
+// Gondola provides the dump-fixtures and load-fixtures commands, which
+// use this package. See also gnd.la/app/tester.Tester.Fixtures for
+// loading fixtures in tests.
+package fixtures
@@ -0,0 +1,542 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gnd.la/orm"
+	"gnd.la/orm/driver"
+	"gnd.la/util/yaml"
+)
+
+// Format represents the encoding used for fixture files.
+type Format int
+
+const (
+	// JSON encodes the fixtures as JSON. Its file extension is .json.
+	JSON Format = iota + 1
+	// YAML encodes the fixtures as YAML. Its file extensions are
+	// .yaml and .yml.
+	YAML
+)
+
+func (f Format) String() string {
+	switch f {
+	case JSON:
+		return "json"
+	case YAML:
+		return "yaml"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// Extension returns the file extension used for files in this
+// format, including the leading dot.
+func (f Format) Extension() string {
+	return "." + f.String()
+}
+
+// ParseFormat returns the Format with the given name, either
+// json, yaml or yml.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSON, nil
+	case "yaml", "yml":
+		return YAML, nil
+	}
+	return 0, fmt.Errorf("unknown fixtures format %q", name)
+}
+
+// FileFormat returns the Format for the given file name, determined
+// by its extension.
+func FileFormat(filename string) (Format, error) {
+	ext := filepath.Ext(filename)
+	if ext == "" {
+		return 0, fmt.Errorf("can't determine fixtures format for %s without an extension", filename)
+	}
+	return ParseFormat(ext[1:])
+}
+
+// data holds the decoded objects for each table, with
+// their field values still encoded as JSON.
+type data map[string][]map[string]json.RawMessage
+
+func (d data) merge(other data) {
+	for k, v := range other {
+		d[k] = append(d[k], v...)
+	}
+}
+
+// Tables returns the tables registered in the given ORM with the given
+// names, which might be either table names or model names. If no names
+// are provided, all the registered tables are returned.
+func Tables(o *orm.Orm, names ...string) ([]*orm.Table, error) {
+	tables := o.Tables()
+	if len(names) == 0 {
+		return tables, nil
+	}
+	var selected []*orm.Table
+	for _, v := range names {
+		t := findTable(tables, v)
+		if t == nil {
+			return nil, fmt.Errorf("no table named %q", v)
+		}
+		selected = append(selected, t)
+	}
+	return selected, nil
+}
+
+func findTable(tables []*orm.Table, name string) *orm.Table {
+	for _, v := range tables {
+		if v.TableName() == name || v.Name() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Sort returns the given tables sorted in reference order, so every
+// table appears after the tables it references. Tables which reference
+// each other are returned in the order they were received.
+func Sort(tables []*orm.Table) []*orm.Table {
+	byName := make(map[string]*orm.Table, len(tables))
+	for _, v := range tables {
+		byName[v.TableName()] = v
+	}
+	visited := make(map[string]bool, len(tables))
+	sorted := make([]*orm.Table, 0, len(tables))
+	var visit func(t *orm.Table)
+	visit = func(t *orm.Table) {
+		if visited[t.TableName()] {
+			return
+		}
+		visited[t.TableName()] = true
+		for _, v := range references(t) {
+			if ref := byName[v]; ref != nil {
+				visit(ref)
+			}
+		}
+		sorted = append(sorted, t)
+	}
+	for _, v := range tables {
+		visit(v)
+	}
+	return sorted
+}
+
+// references returns the sorted names of the tables
+// referenced by t, excluding itself.
+func references(t *orm.Table) []string {
+	var names []string
+	for _, v := range t.Fields().References {
+		if name := v.Model.Table(); name != t.TableName() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dump writes all the objects in the given tables to w, using the given
+// format. Soft deleted objects are also included. If no tables are provided,
+// all the tables registered in the ORM are dumped.
+func Dump(o *orm.Orm, w io.Writer, format Format, tables ...*orm.Table) error {
+	if len(tables) == 0 {
+		tables = o.Tables()
+	}
+	objects := make(map[string][]map[string]interface{}, len(tables))
+	for _, v := range tables {
+		values, err := dumpTable(o, v)
+		if err != nil {
+			return err
+		}
+		objects[v.TableName()] = values
+	}
+	return encode(w, format, objects)
+}
+
+// DumpDir works like Dump, but writes each table to its own file
+// in the given directory, which is created if it doesn't exist.
+// Files are named after the table, with the extension corresponding
+// to the format. The names of the written files are returned.
+func DumpDir(o *orm.Orm, dir string, format Format, tables ...*orm.Table) ([]string, error) {
+	if len(tables) == 0 {
+		tables = o.Tables()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, v := range tables {
+		filename := filepath.Join(dir, v.TableName()+format.Extension())
+		f, err := os.Create(filename)
+		if err != nil {
+			return files, err
+		}
+		err = Dump(o, f, format, v)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return files, fmt.Errorf("error writing %s: %s", filename, err)
+		}
+		files = append(files, filename)
+	}
+	return files, nil
+}
+
+func dumpTable(o *orm.Orm, t *orm.Table) ([]map[string]interface{}, error) {
+	fields := t.Fields()
+	q := o.Table(t).Primary().WithDeleted()
+	if fields.PrimaryKey >= 0 {
+		q = q.Sort(fields.QNames[fields.PrimaryKey], orm.ASC)
+	} else {
+		for _, v := range fields.CompositePrimaryKey {
+			q = q.Sort(fields.QNames[v], orm.ASC)
+		}
+	}
+	var values []map[string]interface{}
+	iter := q.Iter()
+	for {
+		obj := reflect.New(t.Type())
+		if !iter.Next(obj.Interface()) {
+			break
+		}
+		m := make(map[string]interface{}, len(fields.QNames))
+		for ii, v := range fields.QNames {
+			if fval := fieldValue(obj, fields.Indexes[ii], false); fval.IsValid() {
+				m[v] = fval.Interface()
+			}
+		}
+		values = append(values, m)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("error dumping table %s: %s", t.TableName(), err)
+	}
+	if values == nil {
+		// Encode empty tables as an empty list rather than null
+		values = []map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// Load loads the objects encoded in r with the given format into the
+// ORM. See the package documentation for the format of the data. All
+// the objects are inserted in a single transaction if the ORM driver
+// supports it. The tables which received objects are returned in
+// the order they were loaded.
+func Load(o *orm.Orm, r io.Reader, format Format) ([]*orm.Table, error) {
+	d, err := decode(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return load(o, d)
+}
+
+// LoadFiles works like Load, but loads the objects from the given
+// files, determining their format from their extensions. If any
+// of the files is a directory, all the JSON and YAML files in
+// it are loaded. All the objects in all the files are inserted
+// in a single transaction, if the ORM driver supports it.
+func LoadFiles(o *orm.Orm, files ...string) ([]*orm.Table, error) {
+	d := make(data)
+	for _, v := range files {
+		st, err := os.Stat(v)
+		if err != nil {
+			return nil, err
+		}
+		if st.IsDir() {
+			dd, err := decodeDir(v)
+			if err != nil {
+				return nil, err
+			}
+			d.merge(dd)
+			continue
+		}
+		fd, err := decodeFile(v)
+		if err != nil {
+			return nil, err
+		}
+		d.merge(fd)
+	}
+	return load(o, d)
+}
+
+func decodeDir(dir string) (data, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	d := make(data)
+	for _, v := range infos {
+		if v.IsDir() {
+			continue
+		}
+		if _, err := FileFormat(v.Name()); err != nil {
+			continue
+		}
+		fd, err := decodeFile(filepath.Join(dir, v.Name()))
+		if err != nil {
+			return nil, err
+		}
+		d.merge(fd)
+	}
+	return d, nil
+}
+
+func decodeFile(filename string) (data, error) {
+	format, err := FileFormat(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	d, err := decode(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %s", filename, err)
+	}
+	return d, nil
+}
+
+func load(o *orm.Orm, d data) ([]*orm.Table, error) {
+	all := o.Tables()
+	var tables []*orm.Table
+	objects := make(map[string]reflect.Value, len(d))
+	for k, v := range d {
+		t := findTable(all, k)
+		if t == nil {
+			return nil, fmt.Errorf("no table named %q", k)
+		}
+		objs, err := loadObjects(t, v)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+		objects[t.TableName()] = objs
+	}
+	sort.Sort(tablesByName(tables))
+	tables = Sort(tables)
+	insert := func(o *orm.Orm) error {
+		for _, v := range tables {
+			objs := objects[v.TableName()]
+			if objs.Len() == 0 {
+				continue
+			}
+			if _, err := o.InsertMany(objs.Interface(), 0); err != nil {
+				return fmt.Errorf("error loading table %s: %s", v.TableName(), err)
+			}
+		}
+		return nil
+	}
+	var err error
+	if o.Driver().Capabilities()&driver.CAP_BEGIN != 0 {
+		err = o.Transaction(insert)
+	} else {
+		err = insert(o)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := resetSequences(o, tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// resetSequences advances the PostgreSQL sequences backing the
+// auto_increment primary keys in the given tables past the loaded
+// ids. Otherwise, inserting new objects after loading the fixtures
+// would fail with duplicate keys.
+func resetSequences(o *orm.Orm, tables []*orm.Table) error {
+	if tags := o.Driver().Tags(); len(tags) == 0 || tags[0] != "postgres" {
+		return nil
+	}
+	for _, v := range tables {
+		fields := v.Fields()
+		if !fields.AutoincrementPk {
+			continue
+		}
+		name := v.TableName()
+		column := fields.MNames[fields.PrimaryKey]
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), (SELECT MAX(\"%s\") FROM \"%s\"))", column, name)
+		if _, err := o.SqlDB().Exec(query, "\""+name+"\"", column); err != nil {
+			return fmt.Errorf("error resetting sequence for table %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// loadObjects returns a []*T with the objects of the given table.
+func loadObjects(t *orm.Table, values []map[string]json.RawMessage) (reflect.Value, error) {
+	fields := t.Fields()
+	objs := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(t.Type())), 0, len(values))
+	for _, v := range values {
+		obj := reflect.New(t.Type())
+		for name, raw := range v {
+			idx, ok := fields.QNameMap[name]
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("table %s has no field named %q", t.TableName(), name)
+			}
+			fval := fieldValue(obj, fields.Indexes[idx], true)
+			if err := json.Unmarshal(raw, fval.Addr().Interface()); err != nil {
+				return reflect.Value{}, fmt.Errorf("error loading field %s in table %s: %s", name, t.TableName(), err)
+			}
+		}
+		objs = reflect.Append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Truncate removes all the objects from the given tables, in
+// reverse reference order. Objects are removed from the database
+// even if the model supports soft deletes.
+func Truncate(o *orm.Orm, tables ...*orm.Table) error {
+	sorted := Sort(tables)
+	for ii := len(sorted) - 1; ii >= 0; ii-- {
+		if _, err := o.HardDeleteFrom(sorted[ii], nil); err != nil {
+			return fmt.Errorf("error truncating table %s: %s", sorted[ii].TableName(), err)
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the field at the given indexes in the struct
+// pointed by v. If create is false and the field is inside a nil
+// pointer, an invalid reflect.Value is returned. Otherwise, nil
+// pointers are initialized.
+func fieldValue(v reflect.Value, indexes []int, create bool) reflect.Value {
+	for _, ii := range indexes {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !create {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(ii)
+	}
+	return v
+}
+
+func encode(w io.Writer, format Format, objects map[string][]map[string]interface{}) error {
+	var out []byte
+	switch format {
+	case JSON:
+		b, err := json.MarshalIndent(objects, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(b, '\n')
+	case YAML:
+		// Encode the values as JSON first, so all the types
+		// are encoded as they would be in a JSON file (e.g.
+		// time.Time as an RFC 3339 string) and can be loaded
+		// back using the same code.
+		b, err := json.Marshal(objects)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		var val interface{}
+		if err := dec.Decode(&val); err != nil {
+			return err
+		}
+		if out, err = yaml.Marshal(yamlValue(val)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid fixtures format %s", format)
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+func decode(r io.Reader, format Format) (data, error) {
+	var d data
+	switch format {
+	case JSON:
+		if err := json.NewDecoder(r).Decode(&d); err != nil {
+			return nil, err
+		}
+	case YAML:
+		var val interface{}
+		if err := yaml.UnmarshalReader(r, &val); err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(jsonValue(val))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid fixtures format %s", format)
+	}
+	return d, nil
+}
+
+// yamlValue converts the json.Number values decoded from
+// JSON to int64 or float64, so they're encoded as numbers
+// by the YAML encoder.
+func yamlValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = yamlValue(e)
+		}
+	case []interface{}:
+		for ii, e := range v {
+			v[ii] = yamlValue(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return val
+}
+
+// jsonValue converts the map[interface{}]interface{} values
+// decoded from YAML to map[string]interface{}, so they can
+// be encoded as JSON.
+func jsonValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for ii, e := range v {
+			v[ii] = jsonValue(e)
+		}
+	}
+	return val
+}
+
+type tablesByName []*orm.Table
+
+func (t tablesByName) Len() int           { return len(t) }
+func (t tablesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t tablesByName) Less(i, j int) bool { return t[i].TableName() < t[j].TableName() }
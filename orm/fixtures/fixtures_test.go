@@ -0,0 +1,259 @@
+package fixtures
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gnd.la/config"
+	"gnd.la/orm"
+
+	_ "gnd.la/orm/driver/memory"
+	_ "gnd.la/orm/driver/postgres"
+)
+
+type FixtureAuthor struct {
+	Id   int64 `orm:",primary_key,auto_increment"`
+	Name string
+}
+
+type FixtureAddress struct {
+	City string
+}
+
+type FixtureArticle struct {
+	Id        int64 `orm:",primary_key,auto_increment"`
+	AuthorId  int64 `orm:",references=FixtureAuthor"`
+	Title     string
+	Address   *FixtureAddress
+	Created   time.Time
+	DeletedAt time.Time `orm:",deleted_at"`
+}
+
+const articlesFirst = `{
+	"fixture_articles": [
+		{"Id": 3, "AuthorId": 7, "Title": "Hello", "Address.City": "Sydney"}
+	],
+	"fixture_authors": [
+		{"Id": 7, "Name": "Alice"}
+	]
+}`
+
+var (
+	testOrm       *orm.Orm
+	authorsTable  *orm.Table
+	articlesTable *orm.Table
+)
+
+// newOrm returns the ORM used for the tests, with empty tables. Models
+// can only be registered once per driver, so all the tests share it.
+func newOrm(t *testing.T) (*orm.Orm, *orm.Table, *orm.Table) {
+	if testOrm == nil {
+		o, err := orm.New(config.MustParseURL("memory://"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authorsTable, err = o.Register((*FixtureAuthor)(nil), &orm.Options{Table: "fixture_authors"}); err != nil {
+			t.Fatal(err)
+		}
+		if articlesTable, err = o.Register((*FixtureArticle)(nil), &orm.Options{Table: "fixture_articles"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.Initialize(); err != nil {
+			t.Fatal(err)
+		}
+		testOrm = o
+	}
+	if err := Truncate(testOrm, authorsTable, articlesTable); err != nil {
+		t.Fatal(err)
+	}
+	return testOrm, authorsTable, articlesTable
+}
+
+func TestSort(t *testing.T) {
+	_, authors, articles := newOrm(t)
+	sorted := Sort([]*orm.Table{articles, authors})
+	if sorted[0].TableName() != authors.TableName() || sorted[1].TableName() != articles.TableName() {
+		t.Errorf("expecting authors before articles, got %s, %s", sorted[0].TableName(), sorted[1].TableName())
+	}
+}
+
+func TestLoadReferenceOrder(t *testing.T) {
+	o, authors, articles := newOrm(t)
+	tables, err := Load(o, strings.NewReader(articlesFirst), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].TableName() != authors.TableName() {
+		t.Errorf("expecting authors to be loaded first, got %v", tables)
+	}
+	var article FixtureArticle
+	if !o.MustOne(orm.Eq("Id", 3), &article) {
+		t.Fatal("article not loaded")
+	}
+	if article.AuthorId != 7 || article.Title != "Hello" || article.Address == nil || article.Address.City != "Sydney" {
+		t.Errorf("unexpected article loaded %+v", article)
+	}
+	if err := Truncate(o, articles, authors); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []*orm.Table{authors, articles} {
+		if c := o.Table(v).WithDeleted().MustCount(); c != 0 {
+			t.Errorf("expecting empty table %s after truncating, got %d objects", v.TableName(), c)
+		}
+	}
+}
+
+func TestUnknownField(t *testing.T) {
+	o, _, _ := newOrm(t)
+	if _, err := Load(o, strings.NewReader(`{"fixture_authors": [{"Nope": 1}]}`), JSON); err == nil {
+		t.Error("expecting an error when loading an unknown field")
+	}
+	if _, err := Load(o, strings.NewReader(`{"nope": []}`), JSON); err == nil {
+		t.Error("expecting an error when loading an unknown table")
+	}
+}
+
+func testRoundTrip(t *testing.T, format Format) {
+	o, authors, articles := newOrm(t)
+	created := time.Date(2014, 1, 2, 15, 4, 5, 0, time.UTC)
+	author := &FixtureAuthor{Name: "Bob"}
+	o.MustInsert(author)
+	o.MustInsert(&FixtureArticle{AuthorId: author.Id, Title: "First", Created: created})
+	deleted := &FixtureArticle{AuthorId: author.Id, Title: "Deleted", Address: &FixtureAddress{City: "Perth"}}
+	o.MustInsert(deleted)
+	o.MustDelete(deleted)
+	dir, err := ioutil.TempDir("", "fixtures-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files, err := DumpDir(o, dir, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expecting 2 files, got %v", files)
+	}
+	if err := Truncate(o, authors, articles); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFiles(o, dir); err != nil {
+		t.Fatal(err)
+	}
+	if c := o.Table(articles).MustCount(); c != 1 {
+		t.Errorf("expecting 1 article, got %d", c)
+	}
+	var loaded FixtureArticle
+	if !o.Table(articles).WithDeleted().Filter(orm.Eq("Id", deleted.Id)).MustOne(&loaded) {
+		t.Fatal("soft deleted article not loaded")
+	}
+	if loaded.DeletedAt.IsZero() || loaded.Address == nil || loaded.Address.City != "Perth" {
+		t.Errorf("unexpected soft deleted article %+v", loaded)
+	}
+	if !o.MustOne(orm.Eq("Title", "First"), &loaded) {
+		t.Fatal("article not loaded")
+	}
+	if !loaded.Created.Equal(created) || loaded.AuthorId != author.Id || loaded.Address != nil {
+		t.Errorf("unexpected article %+v", loaded)
+	}
+	// Dumping again must produce the same output
+	var buf1, buf2 bytes.Buffer
+	if err := Dump(o, &buf1, format); err != nil {
+		t.Fatal(err)
+	}
+	if err := Truncate(o, authors, articles); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(o, bytes.NewReader(buf1.Bytes()), format); err != nil {
+		t.Fatal(err)
+	}
+	if err := Dump(o, &buf2, format); err != nil {
+		t.Fatal(err)
+	}
+	if buf1.String() != buf2.String() {
+		t.Errorf("dumps differ:\n%s\n%s", buf1.String(), buf2.String())
+	}
+}
+
+func TestRoundTripJSON(t *testing.T) {
+	testRoundTrip(t, JSON)
+}
+
+func TestRoundTripYAML(t *testing.T) {
+	testRoundTrip(t, YAML)
+}
+
+func TestFileFormat(t *testing.T) {
+	cases := map[string]Format{
+		"a.json":                    JSON,
+		"b.yaml":                    YAML,
+		filepath.Join("c", "d.YML"): YAML,
+	}
+	for k, v := range cases {
+		f, err := FileFormat(k)
+		if err != nil {
+			t.Error(err)
+		} else if f != v {
+			t.Errorf("expecting format %s for %s, got %s", v, k, f)
+		}
+	}
+	if _, err := FileFormat("e.txt"); err == nil {
+		t.Error("expecting an error for .txt")
+	}
+}
+
+func testInsertAfterLoad(t *testing.T, o *orm.Orm, authors *orm.Table) {
+	if _, err := Load(o, strings.NewReader(articlesFirst), JSON); err != nil {
+		t.Fatal(err)
+	}
+	author := &FixtureAuthor{Name: "Bob"}
+	if _, err := o.Insert(author); err != nil {
+		t.Fatalf("error inserting after loading fixtures: %s", err)
+	}
+	if author.Id <= 7 {
+		t.Errorf("expecting new author id > 7, got %d", author.Id)
+	}
+	if c := o.Table(authors).MustCount(); c != 2 {
+		t.Errorf("expecting 2 authors, got %d", c)
+	}
+}
+
+func TestInsertAfterLoad(t *testing.T) {
+	o, authors, _ := newOrm(t)
+	testInsertAfterLoad(t, o, authors)
+}
+
+func TestInsertAfterLoadPostgres(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.Command("dropdb", "gotest").Run()
+	if err := exec.Command("createdb", "gotest").Run(); err != nil {
+		t.Skip("cannot create gotest postgres database, skipping test")
+	}
+	o, err := orm.New(config.MustParseURL(fmt.Sprintf("postgres://dbname=gotest user=%v password=%v", u.Username, u.Username)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	authors, err := o.Register((*FixtureAuthor)(nil), &orm.Options{Table: "fixture_authors"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Register((*FixtureArticle)(nil), &orm.Options{Table: "fixture_articles"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	testInsertAfterLoad(t, o, authors)
+}
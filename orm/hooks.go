@@ -0,0 +1,160 @@
+package orm
+
+import (
+	"gnd.la/orm/query"
+	"gnd.la/signal"
+)
+
+// Models might implement any of the following interfaces to run
+// code when the ORM inserts, updates or deletes them. The Interface
+// received by each hook is the one performing the operation, so any
+// queries made from the hook run inside the same transaction. If a
+// Before* hook returns an error, the operation is aborted and the
+// error is returned to the caller. Errors returned from After* hooks
+// are also returned to the caller, but the operation has already
+// been performed (use a transaction if it must be undone).
+//
+// Note that Save and Upsert might perform an update followed by an
+// insert when no rows are affected by the update. In that case,
+// both BeforeUpdate and BeforeInsert will be called, while only
+// AfterInsert will be. Operations which work on a query rather than
+// an object (like DeleteFrom or Operate) don't call any hooks, but
+// they still emit signals.
+//
+// Keep in mind that, like the Load and Save methods, hooks with
+// a pointer receiver will only be called when a pointer to the
+// model is passed to the ORM.
+
+// BeforeInserter is implemented by models which need to
+// run code before being inserted.
+type BeforeInserter interface {
+	BeforeInsert(o Interface) error
+}
+
+// AfterInserter is implemented by models which need to run code
+// after being inserted. When AfterInsert is called, the object
+// has already been assigned its auto_increment primary key, if any.
+type AfterInserter interface {
+	AfterInsert(o Interface) error
+}
+
+// BeforeUpdater is implemented by models which need to
+// run code before being updated.
+type BeforeUpdater interface {
+	BeforeUpdate(o Interface) error
+}
+
+// AfterUpdater is implemented by models which need to run
+// code after being updated. It's only called when the update
+// affected at least one row.
+type AfterUpdater interface {
+	AfterUpdate(o Interface) error
+}
+
+// BeforeDeleter is implemented by models which need to
+// run code before being deleted.
+type BeforeDeleter interface {
+	BeforeDelete(o Interface) error
+}
+
+// AfterDeleter is implemented by models which need to
+// run code after being deleted.
+type AfterDeleter interface {
+	AfterDelete(o Interface) error
+}
+
+const (
+	insertedSignalPrefix = "gnd.la/orm.inserted:"
+	updatedSignalPrefix  = "gnd.la/orm.updated:"
+	deletedSignalPrefix  = "gnd.la/orm.deleted:"
+)
+
+// SignalData is the object emitted with the signals
+// returned by InsertedSignal, UpdatedSignal and DeletedSignal.
+type SignalData struct {
+	// Orm is the ORM (or transaction) which performed
+	// the operation.
+	Orm *Orm
+	// Table is the table which was modified.
+	Table *Table
+	// Object is the inserted, updated or deleted object. It's
+	// nil when the operation used a query rather than an object
+	// (e.g. DeleteFrom or Operate).
+	Object interface{}
+	// Query is the query used to select the rows which were updated
+	// or deleted. It's nil for inserts.
+	Query query.Q
+}
+
+// InsertedSignal returns the name of the signal emitted after an
+// object is inserted into the given table. The signal object is a
+// *SignalData. Note that signals are emitted as soon as the operation
+// is performed, even if it's done inside a transaction which is
+// later rolled back.
+func InsertedSignal(t *Table) string {
+	return insertedSignalPrefix + t.Name()
+}
+
+// UpdatedSignal returns the name of the signal emitted after rows
+// in the given table are updated, either by Update, Save, Upsert or
+// Operate. See InsertedSignal for more details.
+func UpdatedSignal(t *Table) string {
+	return updatedSignalPrefix + t.Name()
+}
+
+// DeletedSignal returns the name of the signal emitted after rows
+// in the given table are deleted. See InsertedSignal for more details.
+func DeletedSignal(t *Table) string {
+	return deletedSignalPrefix + t.Name()
+}
+
+func (o *Orm) emit(prefix string, m *model, obj interface{}, q query.Q) {
+	signal.Emit(prefix+m.name, &SignalData{
+		Orm:    o,
+		Table:  tableWithModel(m),
+		Object: obj,
+		Query:  q,
+	})
+}
+
+func (o *Orm) beforeInsert(obj interface{}) error {
+	if h, ok := obj.(BeforeInserter); ok {
+		return h.BeforeInsert(o)
+	}
+	return nil
+}
+
+func (o *Orm) afterInsert(obj interface{}) error {
+	if h, ok := obj.(AfterInserter); ok {
+		return h.AfterInsert(o)
+	}
+	return nil
+}
+
+func (o *Orm) beforeUpdate(obj interface{}) error {
+	if h, ok := obj.(BeforeUpdater); ok {
+		return h.BeforeUpdate(o)
+	}
+	return nil
+}
+
+func (o *Orm) afterUpdate(obj interface{}) error {
+	if h, ok := obj.(AfterUpdater); ok {
+		return h.AfterUpdate(o)
+	}
+	return nil
+}
+
+func (o *Orm) beforeDelete(obj interface{}) error {
+	if h, ok := obj.(BeforeDeleter); ok {
+		return h.BeforeDelete(o)
+	}
+	return nil
+}
+
+func (o *Orm) afterDelete(obj interface{}) error {
+	if h, ok := obj.(AfterDeleter); ok {
+		return h.AfterDelete(o)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+
+	"gnd.la/orm/driver"
+	"gnd.la/signal"
+)
+
+var (
+	keepError = errors.New("can't delete, keeping")
+)
+
+type Hooked struct {
+	Id      int64 `orm:",primary_key,auto_increment"`
+	Value   string
+	calls   []string `orm:"-"`
+	afterId int64    `orm:"-"`
+}
+
+func (h *Hooked) BeforeInsert(o Interface) error {
+	h.calls = append(h.calls, "BeforeInsert")
+	return nil
+}
+
+func (h *Hooked) AfterInsert(o Interface) error {
+	h.calls = append(h.calls, "AfterInsert")
+	h.afterId = h.Id
+	// Insert a log entry using the Interface received by the hook
+	_, err := o.Insert(&HookLog{HookedId: h.Id})
+	return err
+}
+
+func (h *Hooked) BeforeUpdate(o Interface) error {
+	h.calls = append(h.calls, "BeforeUpdate")
+	return nil
+}
+
+func (h *Hooked) AfterUpdate(o Interface) error {
+	h.calls = append(h.calls, "AfterUpdate")
+	return nil
+}
+
+func (h *Hooked) BeforeDelete(o Interface) error {
+	h.calls = append(h.calls, "BeforeDelete")
+	if h.Value == "keep" {
+		return keepError
+	}
+	return nil
+}
+
+func (h *Hooked) AfterDelete(o Interface) error {
+	h.calls = append(h.calls, "AfterDelete")
+	return nil
+}
+
+type HookLog struct {
+	Id       int64 `orm:",primary_key,auto_increment"`
+	HookedId int64
+}
+
+func testHooks(t *testing.T, o *Orm) {
+	hookedTable := o.mustRegister((*Hooked)(nil), &Options{
+		Table: "test_hooked",
+	})
+	logTable := o.mustRegister((*HookLog)(nil), &Options{
+		Table: "test_hook_log",
+	})
+	o.mustInitialize()
+	var signals []string
+	listener := func(name string, obj interface{}) {
+		data := obj.(*SignalData)
+		if data.Table.Name() != hookedTable.Name() {
+			t.Errorf("expecting table %s in signal, got %s", hookedTable.Name(), data.Table.Name())
+		}
+		signals = append(signals, name)
+	}
+	for _, v := range []string{InsertedSignal(hookedTable), UpdatedSignal(hookedTable), DeletedSignal(hookedTable)} {
+		signal.Listen(v, listener)
+		defer signal.Stop(v, nil)
+	}
+	checkCalls := func(h *Hooked, calls ...string) {
+		if len(h.calls) != len(calls) {
+			t.Errorf("expecting calls %v, got %v", calls, h.calls)
+			return
+		}
+		for ii, v := range calls {
+			if h.calls[ii] != v {
+				t.Errorf("expecting calls %v, got %v", calls, h.calls)
+				return
+			}
+		}
+	}
+	h := &Hooked{Value: "foo"}
+	o.MustSave(h)
+	checkCalls(h, "BeforeInsert", "AfterInsert")
+	if h.afterId == 0 || h.afterId != h.Id {
+		t.Errorf("expecting id %d in AfterInsert, got %d", h.Id, h.afterId)
+	}
+	h.calls = nil
+	h.Value = "keep"
+	o.MustSave(h)
+	checkCalls(h, "BeforeUpdate", "AfterUpdate")
+	h.calls = nil
+	if err := o.Delete(h); err != keepError {
+		t.Errorf("expecting error %v when deleting, got %v", keepError, err)
+	}
+	checkCalls(h, "BeforeDelete")
+	if !o.MustOne(Eq("Id", h.Id), &Hooked{}) {
+		t.Error("object was deleted, even if BeforeDelete returned an error")
+	}
+	h.calls = nil
+	h.Value = "bar"
+	o.MustSave(h)
+	h.calls = nil
+	o.MustDelete(h)
+	checkCalls(h, "BeforeDelete", "AfterDelete")
+	expected := []string{InsertedSignal(hookedTable), UpdatedSignal(hookedTable), UpdatedSignal(hookedTable), DeletedSignal(hookedTable)}
+	if len(signals) != len(expected) {
+		t.Errorf("expecting signals %v, got %v", expected, signals)
+	} else {
+		for ii, v := range expected {
+			if signals[ii] != v {
+				t.Errorf("expecting signals %v, got %v", expected, signals)
+				break
+			}
+		}
+	}
+	if c := o.Table(logTable).MustCount(); c != 1 {
+		t.Errorf("expecting 1 log entry, got %d", c)
+	}
+	if o.Driver().Capabilities()&driver.CAP_BEGIN == 0 {
+		return
+	}
+	// Hooks must run inside the transaction
+	tx := o.MustBegin()
+	tx.MustSave(&Hooked{Value: "tx"})
+	if c, err := tx.Count(logTable, nil); err != nil {
+		t.Error(err)
+	} else if c != 2 {
+		t.Errorf("expecting 2 log entries inside transaction, got %d", c)
+	}
+	tx.MustRollback()
+	if c := o.Table(logTable).MustCount(); c != 1 {
+		t.Errorf("expecting 1 log entry after rollback, got %d", c)
+	}
+}
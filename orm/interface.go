@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"gnd.la/orm/operation"
 	"gnd.la/orm/query"
 )
 
@@ -15,23 +16,33 @@ type Interface interface {
 	Exists(t *Table, q query.Q) (bool, error)
 	Count(t *Table, q query.Q) (uint64, error)
 	Query(q query.Q) *Query
-	One(q query.Q, out ...interface{}) error
+	One(q query.Q, out ...interface{}) (bool, error)
+	MustOne(q query.Q, out ...interface{}) bool
 	All() *Query
 	Insert(obj interface{}) (Result, error)
 	MustInsert(obj interface{}) Result
-	InsertInto(t *Table, obj interface{}) (Result, error)
-	MustInsertInto(t *Table, obj interface{}) Result
+	InsertMany(objs interface{}, batchSize int) (Result, error)
+	MustInsertMany(objs interface{}, batchSize int) Result
 	Update(q query.Q, obj interface{}) (Result, error)
 	MustUpdate(q query.Q, obj interface{}) Result
+	UpdateMany(objs interface{}) (Result, error)
+	MustUpdateMany(objs interface{}) Result
 	Upsert(q query.Q, obj interface{}) (Result, error)
 	MustUpsert(q query.Q, obj interface{}) Result
 	Save(obj interface{}) (Result, error)
 	MustSave(obj interface{}) Result
-	SaveInto(t *Table, obj interface{}) (Result, error)
-	MustSaveInto(t *Table, obj interface{}) Result
-	DeleteFromTable(t *Table, q query.Q) (Result, error)
+	Operate(t *Table, q query.Q, ops ...*operation.Operation) (Result, error)
+	MustOperate(t *Table, q query.Q, ops ...*operation.Operation) Result
+	DeleteFrom(t *Table, q query.Q) (Result, error)
 	Delete(obj interface{}) error
 	MustDelete(obj interface{})
-	DeleteFrom(t *Table, obj interface{}) error
+	HardDeleteFrom(t *Table, q query.Q) (Result, error)
+	HardDelete(obj interface{}) error
+	MustHardDelete(obj interface{})
 	Begin() (*Tx, error)
 }
+
+var (
+	_ Interface = (*Orm)(nil)
+	_ Interface = (*Tx)(nil)
+)
@@ -8,7 +8,9 @@ type Iter struct {
 	q     *Query
 	limit int
 	driver.Iter
-	err error
+	conn    driver.Conn
+	results bool
+	err     error
 }
 
 // Next advances the iter to the next result,
@@ -30,10 +32,22 @@ func (i *Iter) Next(out ...interface{}) bool {
 				i.q.methods = append(i.q.methods, cur.model.fields.Methods)
 			}
 		}
-		i.Iter = i.q.exec(i.limit)
+		i.conn = i.q.orm.reader(i.q.primary)
+		i.Iter = i.q.exec(i.conn, i.limit)
 	}
 	ok := i.Iter.Next(out...)
+	if !ok && !i.results && i.Iter.Err() != nil && i.q.orm.replicaFailed(i.conn) {
+		// The replica failed before returning any results,
+		// so the query can be safely retried on the primary.
+		// Failures after some results have been returned
+		// are reported as errors instead.
+		i.Iter.Close()
+		i.conn = i.q.orm.conn
+		i.Iter = i.q.exec(i.conn, i.limit)
+		ok = i.Iter.Next(out...)
+	}
 	if ok {
+		i.results = true
 		for ii, v := range out {
 			if i.err = i.q.methods[ii].Load(v); i.err != nil {
 				break
@@ -103,7 +103,9 @@ func testMigrations(t *testing.T, o *Orm) {
 		t.Errorf("error initializing Migration4: %s", err)
 	}
 	tx := o.MustBegin()
-	tx.SqlDB().Exec("PRAGMA foreign_keys = on")
+	if db := tx.SqlDB(); db != nil {
+		db.Exec("PRAGMA foreign_keys = on")
+	}
 	if _, err := tx.Insert(&Migration4{Reference: 42}); err == nil {
 		t.Error("expecting an error when violating Migration4 FK")
 	}
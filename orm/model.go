@@ -47,6 +47,10 @@ type model struct {
 	references      map[string]*reference
 	modelReferences map[*model][]*join
 	namedReferences map[string]*model
+	// index of the version and deleted_at fields
+	// or -1 if the model doesn't have them.
+	version   int
+	deletedAt int
 }
 
 func (m *model) Type() reflect.Type {
@@ -15,7 +15,13 @@ func (o *Orm) Operate(table *Table, q query.Q, ops ...*operation.Operation) (Res
 	if len(ops) == 0 {
 		return nil, errNoOperations
 	}
-	return o.conn.Operate(table.model, q, ops)
+	o.wrote()
+	res, err := o.conn.Operate(table.model, q, versionOperations(table.model.model, ops))
+	if err != nil {
+		return nil, err
+	}
+	o.emit(updatedSignalPrefix, table.model.model, nil, q)
+	return res, nil
 }
 
 func (o *Orm) MustOperate(table *Table, q query.Q, ops ...*operation.Operation) Result {
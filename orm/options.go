@@ -31,4 +31,12 @@ type Options struct {
 	// defined in both the a field tag and using this field, an
 	// error will be returned when registering the model.
 	PrimaryKey []string
+	// Version is the qualified name of an integer field used for
+	// optimistic locking. It can also be declared by tagging the
+	// field with orm:",version". See ConflictError for more details.
+	Version string
+	// DeletedAt is the qualified name of a time.Time or *time.Time
+	// field used for soft deletes. It can also be declared by tagging
+	// the field with orm:",deleted_at". See Orm.Delete for more details.
+	DeletedAt string
 }
@@ -21,6 +21,7 @@ var (
 		"sqlite":   "gnd.la/orm/driver/sqlite",
 		"sqlite3":  "gnd.la/orm/driver/sqlite",
 		"mysql":    "gnd.la/orm/driver/mysql",
+		"memory":   "gnd.la/orm/driver/memory",
 	}
 	errUntypedNilPointer = errors.New("untyped nil pointer passed to Next(). Please, cast it to the appropriate type e.g. (*MyType)(nil)")
 	errNoModel           = errors.New("query without model - did you forget output parameters?")
@@ -43,6 +44,12 @@ type Orm struct {
 	logger       *log.Logger
 	tags         string
 	typeRegistry typeRegistry
+	// replicas is non-nil iff the ORM has read replicas
+	replicas *replicaSet
+	// primary is true when all queries must go to the primary
+	primary bool
+	// written is non-nil iff read-your-writes was requested
+	written *int32
 	// these fields are non-nil iff the ORM driver uses database/sql
 	db *sql.DB
 }
@@ -136,6 +143,40 @@ func (o *Orm) insert(m *model, obj interface{}) (Result, error) {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("insert", m.name).End()
 	}
+	obj, pkVal, err := o.prepareInsert(m, obj)
+	if err != nil {
+		return nil, err
+	}
+	o.wrote()
+	res, err := o.conn.Insert(m, obj)
+	if err == nil && pkVal.IsValid() && pkVal.Int() == 0 {
+		id, err := res.LastInsertId()
+		if err == nil && id != 0 {
+			o.setPrimaryKey(m, pkVal, id)
+		} else if err != nil && o.logger != nil {
+			o.logger.Errorf("could not obtain last insert id: %s", err)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := o.inserted(m, obj); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// prepareInsert runs the BeforeInsert hook and sets the version
+// and default values of obj. It returns the object to be inserted,
+// which might be a copy of obj if it wasn't addressable, and the
+// auto_increment primary key field, if any.
+func (o *Orm) prepareInsert(m *model, obj interface{}) (interface{}, reflect.Value, error) {
+	if err := o.beforeInsert(obj); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	if err := o.initVersion(m, obj); err != nil {
+		return nil, reflect.Value{}, err
+	}
 	var pkName string
 	var pkVal reflect.Value
 	f := m.fields
@@ -143,7 +184,7 @@ func (o *Orm) insert(m *model, obj interface{}) (Result, error) {
 		pkName, pkVal = o.primaryKey(f, obj)
 		if pkVal.Int() == 0 && !pkVal.CanSet() {
 			typ := reflect.TypeOf(obj)
-			return nil, fmt.Errorf("can't set primary key field %q. Please, insert a %v rather than a %v", pkName, reflect.PtrTo(typ), typ)
+			return nil, reflect.Value{}, fmt.Errorf("can't set primary key field %q. Please, insert a %v rather than a %v", pkName, reflect.PtrTo(typ), typ)
 		}
 	}
 	if f.Defaults != nil {
@@ -170,19 +211,21 @@ func (o *Orm) insert(m *model, obj interface{}) (Result, error) {
 			}
 		}
 	}
-	res, err := o.conn.Insert(m, obj)
-	if err == nil && pkVal.IsValid() && pkVal.Int() == 0 {
-		id, err := res.LastInsertId()
-		if err == nil && id != 0 {
-			if o.logger != nil {
-				o.logger.Debugf("Setting primary key %q to %d on model %v", pkName, id, m.Type())
-			}
-			pkVal.SetInt(id)
-		} else if err != nil && o.logger != nil {
-			o.logger.Errorf("could not obtain last insert id: %s", err)
-		}
+	return obj, pkVal, nil
+}
+
+func (o *Orm) setPrimaryKey(m *model, pkVal reflect.Value, id int64) {
+	if o.logger != nil {
+		o.logger.Debugf("Setting primary key %q to %d on model %v", m.fields.QNames[m.fields.PrimaryKey], id, m.Type())
 	}
-	return res, err
+	pkVal.SetInt(id)
+}
+
+// inserted emits the insert signal and calls the
+// AfterInsert hook.
+func (o *Orm) inserted(m *model, obj interface{}) error {
+	o.emit(insertedSignalPrefix, m, obj, nil)
+	return o.afterInsert(obj)
 }
 
 func (o *Orm) Update(q query.Q, obj interface{}) (Result, error) {
@@ -210,7 +253,34 @@ func (o *Orm) update(m *model, q query.Q, obj interface{}) (Result, error) {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("update", m.name).End()
 	}
-	return o.conn.Update(m, q, obj)
+	if err := o.beforeUpdate(obj); err != nil {
+		return nil, err
+	}
+	o.wrote()
+	var res Result
+	var err error
+	if m.version >= 0 {
+		res, err = o.updateVersioned(m, q, obj)
+	} else {
+		res, err = o.conn.Update(m, q, obj)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := o.updated(m, res, obj, q); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// updated emits the update signal and calls the AfterUpdate
+// hook if the update affected any rows.
+func (o *Orm) updated(m *model, res Result, obj interface{}, q query.Q) error {
+	if aff, err := res.RowsAffected(); err == nil && aff == 0 {
+		return nil
+	}
+	o.emit(updatedSignalPrefix, m, obj, q)
+	return o.afterUpdate(obj)
 }
 
 // Upsert tries to perform an update with the given query
@@ -226,11 +296,22 @@ func (o *Orm) Upsert(q query.Q, obj interface{}) (Result, error) {
 	if err := m.fields.Methods.Save(obj); err != nil {
 		return nil, err
 	}
-	if o.driver.Upserts() {
+	if o.driver.Upserts() && m.version < 0 {
 		if profile.On && profile.Profiling() {
 			defer profile.Start(orm).Note("upsert", "").End()
 		}
-		return o.conn.Upsert(m, q, obj)
+		if err := o.beforeUpdate(obj); err != nil {
+			return nil, err
+		}
+		o.wrote()
+		res, err := o.conn.Upsert(m, q, obj)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.updated(m, res, obj, q); err != nil {
+			return nil, err
+		}
+		return res, nil
 	}
 	res, err := o.update(m, q, obj)
 	if err != nil {
@@ -333,20 +414,30 @@ func (o *Orm) save(m *model, obj interface{}) (Result, error) {
 }
 
 // DeleteFrom removes all objects from the given table matching
-// the query.
+// the query. If the model supports soft deletes, the objects are
+// marked as deleted instead. See Delete for more details.
 func (o *Orm) DeleteFrom(t *Table, q query.Q) (Result, error) {
-	return o.delete(t.model.model, q)
+	return o.delete(t.model.model, q, nil, false)
 }
 
 // Delete removes the given object, which must be of a type
 // previously registered as a table and must have a primary key,
 // either simple or composite.
+//
+// If the model has a deleted_at field (declared with the tag
+// orm:",deleted_at" or Options.DeletedAt), the object is not
+// removed from the database. Instead, its deleted_at field is
+// set to the current time and it's excluded from the results
+// of any subsequent queries, unless Query.WithDeleted is used.
+// To restore a soft deleted object, set its deleted_at field to
+// its zero value and save it. To remove it from the database,
+// use HardDelete.
 func (o *Orm) Delete(obj interface{}) error {
 	m, err := o.model(obj)
 	if err != nil {
 		return err
 	}
-	return o.deleteByPk(m, obj)
+	return o.deleteByPk(m, obj, false)
 }
 
 // MustDelete works like Delete, but panics if there's an error.
@@ -356,33 +447,37 @@ func (o *Orm) MustDelete(obj interface{}) {
 	}
 }
 
-func (o *Orm) deleteByPk(m *model, obj interface{}) error {
-	var q query.Q
-	if m.fields.PrimaryKey >= 0 {
-		pkName, pkVal := o.primaryKey(m.fields, obj)
-		if pkVal.IsValid() && pkName != "" {
-			q = Eq(pkName, pkVal.Interface())
-		}
-	} else if len(m.fields.CompositePrimaryKey) > 0 {
-		names, values := o.compositePrimaryKey(m.fields, obj)
-		conditions := make([]query.Q, len(names))
-		for ii, v := range names {
-			conditions[ii] = Eq(v, values[ii].Interface())
-		}
-		q = And(conditions...)
+func (o *Orm) deleteByPk(m *model, obj interface{}, hard bool) error {
+	q, err := o.primaryKeyQuery(m, obj)
+	if err != nil {
+		return err
 	}
-	if q == nil {
-		return fmt.Errorf("type %T does not have a primary key", obj)
+	if err := o.beforeDelete(obj); err != nil {
+		return err
 	}
-	_, err := o.delete(m, q)
-	return err
+	if _, err := o.delete(m, q, obj, hard); err != nil {
+		return err
+	}
+	return o.afterDelete(obj)
 }
 
-func (o *Orm) delete(m *model, q query.Q) (Result, error) {
+func (o *Orm) delete(m *model, q query.Q, obj interface{}, hard bool) (Result, error) {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("delete", m.name).End()
 	}
-	return o.conn.Delete(m, q)
+	o.wrote()
+	var res Result
+	var err error
+	if m.deletedAt >= 0 && !hard {
+		res, err = o.softDelete(m, q, obj)
+	} else {
+		res, err = o.conn.Delete(m, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	o.emit(deletedSignalPrefix, m, obj, q)
+	return res, nil
 }
 
 // Begin starts a new transaction. If the driver does
@@ -405,6 +500,7 @@ func (o *Orm) Begin() (*Tx, error) {
 	}
 	cpy := *o
 	cpy.conn = tx
+	cpy.primary = true
 	return &Tx{
 		Orm: cpy,
 		o:   o,
@@ -452,6 +548,7 @@ func (o *Orm) Transaction(f func(o *Orm) error) error {
 	err := o.driver.Transaction(func(d driver.Driver) error {
 		oc := *o
 		oc.conn = d
+		oc.primary = true
 		return f(&oc)
 	})
 	if err == Rollback {
@@ -466,6 +563,9 @@ func (o *Orm) Transaction(f func(o *Orm) error) error {
 // create a ORM instance when starting up your application
 // and always use it.
 func (o *Orm) Close() error {
+	if err := o.closeReplicas(); err != nil {
+		return err
+	}
 	if o.driver != nil {
 		err := o.driver.Close()
 		o.driver = nil
@@ -577,6 +677,29 @@ func (o *Orm) primaryKey(f *driver.Fields, obj interface{}) (string, reflect.Val
 	return f.QNames[pk], o.fieldByIndex(val, f.Indexes[pk])
 }
 
+// primaryKeyQuery returns a query which matches obj by its
+// primary key, either simple or composite.
+func (o *Orm) primaryKeyQuery(m *model, obj interface{}) (query.Q, error) {
+	var q query.Q
+	if m.fields.PrimaryKey >= 0 {
+		pkName, pkVal := o.primaryKey(m.fields, obj)
+		if pkVal.IsValid() && pkName != "" {
+			q = Eq(pkName, pkVal.Interface())
+		}
+	} else if len(m.fields.CompositePrimaryKey) > 0 {
+		names, values := o.compositePrimaryKey(m.fields, obj)
+		conditions := make([]query.Q, len(names))
+		for ii, v := range names {
+			conditions[ii] = Eq(v, values[ii].Interface())
+		}
+		q = And(conditions...)
+	}
+	if q == nil {
+		return nil, fmt.Errorf("type %T does not have a primary key", obj)
+	}
+	return q, nil
+}
+
 func (o *Orm) compositePrimaryKey(f *driver.Fields, obj interface{}) ([]string, []reflect.Value) {
 	if len(f.CompositePrimaryKey) == 0 {
 		return nil, nil
@@ -594,17 +717,9 @@ func (o *Orm) compositePrimaryKey(f *driver.Fields, obj interface{}) ([]string,
 // Open creates a new ORM using the specified
 // configuration URL.
 func New(url *config.URL) (*Orm, error) {
-	name := url.Scheme
-	opener := driver.Get(name)
-	if opener == nil {
-		if imp, ok := imports[name]; ok {
-			return nil, fmt.Errorf("please, import package %q to use driver %q", imp, name)
-		}
-		return nil, fmt.Errorf("no ORM driver named %q", name)
-	}
-	drv, err := opener(url)
+	drv, err := open(url)
 	if err != nil {
-		return nil, fmt.Errorf("error opening ORM driver %q: %s", name, err)
+		return nil, err
 	}
 	if err := drv.Check(); err != nil {
 		return nil, err
@@ -624,3 +739,19 @@ func New(url *config.URL) (*Orm, error) {
 	}
 	return o, nil
 }
+
+func open(url *config.URL) (driver.Driver, error) {
+	name := url.Scheme
+	opener := driver.Get(name)
+	if opener == nil {
+		if imp, ok := imports[name]; ok {
+			return nil, fmt.Errorf("please, import package %q to use driver %q", imp, name)
+		}
+		return nil, fmt.Errorf("no ORM driver named %q", name)
+	}
+	drv, err := opener(url)
+	if err != nil {
+		return nil, fmt.Errorf("error opening ORM driver %q: %s", name, err)
+	}
+	return drv, nil
+}
@@ -3,6 +3,8 @@ package orm
 import (
 	"bytes"
 	"flag"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -653,6 +655,47 @@ func testSaveUnchanged(t *testing.T, o *Orm) {
 	}
 }
 
+func testTables(t *testing.T, o *Orm) {
+	o.mustRegister((*AutoIncrement)(nil), &Options{
+		Table: "test_tables_auto",
+	})
+	o.mustRegister((*Composite)(nil), &Options{
+		Table:      "test_tables_composite",
+		PrimaryKey: []string{"Id", "Name"},
+	})
+	o.mustInitialize()
+	tables := o.Tables()
+	if len(tables) != 2 {
+		t.Fatalf("expecting 2 tables, got %d", len(tables))
+	}
+	expect := []struct {
+		name  string
+		table string
+		typ   reflect.Type
+	}{
+		{"AutoIncrement", "test_tables_auto", reflect.TypeOf(AutoIncrement{})},
+		{"Composite", "test_tables_composite", reflect.TypeOf(Composite{})},
+	}
+	for ii, v := range expect {
+		tbl := tables[ii]
+		if !strings.HasSuffix(tbl.Name(), v.name) {
+			t.Errorf("expecting table %d name to end with %q, got %q", ii, v.name, tbl.Name())
+		}
+		if tbl.TableName() != v.table {
+			t.Errorf("expecting table %d to be %q, got %q", ii, v.table, tbl.TableName())
+		}
+		if tbl.Type() != v.typ {
+			t.Errorf("expecting table %d type %v, got %v", ii, v.typ, tbl.Type())
+		}
+	}
+	if pk := tables[0].Fields().PrimaryKey; pk < 0 || !tables[0].Fields().AutoincrementPk {
+		t.Errorf("expecting autoincrement pk on %s", tables[0].Name())
+	}
+	if cpk := tables[1].Fields().CompositePrimaryKey; len(cpk) != 2 {
+		t.Errorf("expecting 2 fields in composite pk, got %v", cpk)
+	}
+}
+
 func runAllTests(t *testing.T, o opener) {
 	orm, data := o.Open(t)
 	defer o.Close(data)
@@ -668,16 +711,22 @@ func testOrm(t *testing.T, o *Orm) {
 		testSaveDelete,
 		testLoadSaveMethods,
 		testLoadSaveMethodsErrors,
+		testHooks,
 		testData,
 		testInnerPointer,
 		testTransactions,
 		testFuncTransactions,
 		testCompositePrimaryKey,
 		testReferences,
+		testPreload,
+		testVersion,
+		testSoftDelete,
+		testBulk,
 		testQueryAll,
 		testDefaults,
 		testMigrations,
 		testSaveUnchanged,
+		testTables,
 	}
 	for _, v := range tests {
 		clearRegistry(o)
@@ -64,6 +64,26 @@ func TestLoadSaveMethodsErrors(t *testing.T) {
 	runTest(t, testLoadSaveMethodsErrors)
 }
 
+func TestHooks(t *testing.T) {
+	runTest(t, testHooks)
+}
+
+func TestPreload(t *testing.T) {
+	runTest(t, testPreload)
+}
+
+func TestVersion(t *testing.T) {
+	runTest(t, testVersion)
+}
+
+func TestSoftDelete(t *testing.T) {
+	runTest(t, testSoftDelete)
+}
+
+func TestBulk(t *testing.T) {
+	runTest(t, testBulk)
+}
+
 func TestDefaults(t *testing.T) {
 	runTest(t, testDefaults)
 }
@@ -72,6 +92,10 @@ func TestSaveUnchanged(t *testing.T) {
 	runTest(t, testSaveUnchanged)
 }
 
+func TestTables(t *testing.T) {
+	runTest(t, testTables)
+}
+
 func BenchmarkLoadSaveMethods(b *testing.B) {
 	runBenchmark(b, benchmarkLoadSaveMethods)
 }
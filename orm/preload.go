@@ -0,0 +1,254 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gnd.la/orm/driver"
+	"gnd.la/orm/query"
+)
+
+// Preload indicates that the given fields should be loaded for the objects
+// returned by One or All, using one additional query for each field,
+// regardless of the number of results (for drivers without support for OR
+// queries, like datastore, one query per distinct key is required).
+//
+// Fields must be ignored by the ORM (tagged with orm:"-") and their type
+// must be a registered model, either a struct or a pointer to a struct, or
+// a slice of them. Preloaded models are matched using the references
+// declared between them and the model being queried, as follows:
+//
+//  - If the model being queried references the preloaded model, the field
+//	is set to the referenced object (many-to-one).
+//  - If the preloaded model references the model being queried, the field
+//	is set to all the objects referencing it (one-to-many). For non-slice
+//	fields, the first one is used (one-to-one).
+//
+// e.g.
+//
+//  type Author struct {
+//	Id   int64 `orm:",primary_key,auto_increment"`
+//	Name string
+//  }
+//
+//  type Article struct {
+//	Id       int64 `orm:",primary_key,auto_increment"`
+//	AuthorId int64 `orm:",references=Author"`
+//	Author   *Author `orm:"-"`
+//	Comments []*Comment `orm:"-"`
+//  }
+//
+//  type Comment struct {
+//	Id        int64 `orm:",primary_key,auto_increment"`
+//	ArticleId int64 `orm:",references=Article"`
+//	Text      string
+//  }
+//
+//  var articles []*Article
+//  err := o.All().Preload("Author", "Comments").All(&articles)
+//
+// Note that Preload has no effect on Iter.
+func (q *Query) Preload(fields ...string) *Query {
+	q.preload = append(q.preload, fields...)
+	return q
+}
+
+// relation represents a reference between two models, used
+// for preloading. localField and remoteField are the qualified
+// names of the fields which must match.
+type relation struct {
+	localField  string
+	remoteField string
+}
+
+func (o *Orm) preloadRelation(m *model, field string, target *model, many bool) (*relation, error) {
+	var candidates []*relation
+	if !many {
+		// The queried model might reference the target
+		for k, v := range m.fields.References {
+			if v.Model == target {
+				candidates = append(candidates, &relation{localField: k, remoteField: v.Field})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		// The target might reference the queried model
+		for k, v := range target.fields.References {
+			if v.Model == m {
+				candidates = append(candidates, &relation{localField: v.Field, remoteField: k})
+			}
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("can't preload %s.%s: there are no references between %s and %s", m.Type(), field, m.name, target.name)
+	case 1:
+		return candidates[0], nil
+	}
+	return nil, fmt.Errorf("can't preload %s.%s: there are multiple references between %s and %s", m.Type(), field, m.name, target.name)
+}
+
+// preloadValues preloads the fields for the given values, which
+// might be pointers to structs or pointers to slices.
+func (q *Query) preloadValues(out []interface{}) error {
+	objs := make(map[reflect.Type][]reflect.Value)
+	var types []reflect.Type
+	for _, v := range out {
+		val := reflect.ValueOf(v)
+		for val.Kind() == reflect.Ptr && !val.IsNil() {
+			val = val.Elem()
+		}
+		switch val.Kind() {
+		case reflect.Struct:
+			if _, ok := objs[val.Type()]; !ok {
+				types = append(types, val.Type())
+			}
+			objs[val.Type()] = append(objs[val.Type()], val)
+		case reflect.Slice:
+			for ii := 0; ii < val.Len(); ii++ {
+				el := val.Index(ii)
+				for el.Kind() == reflect.Ptr && !el.IsNil() {
+					el = el.Elem()
+				}
+				if el.Kind() != reflect.Struct {
+					continue
+				}
+				if _, ok := objs[el.Type()]; !ok {
+					types = append(types, el.Type())
+				}
+				objs[el.Type()] = append(objs[el.Type()], el)
+			}
+		}
+	}
+	for _, field := range q.preload {
+		found := false
+		for _, typ := range types {
+			if _, ok := typ.FieldByName(field); ok {
+				found = true
+				if err := q.orm.preload(typ, objs[typ], field); err != nil {
+					return err
+				}
+			}
+		}
+		if !found && len(types) > 0 {
+			return fmt.Errorf("can't preload field %q, it's not present in any of the results", field)
+		}
+	}
+	return nil
+}
+
+// preload sets the given field in objs, which must be
+// addressable structs of type typ.
+func (o *Orm) preload(typ reflect.Type, objs []reflect.Value, field string) error {
+	m := o.typeRegistry[typ]
+	if m == nil {
+		return fmt.Errorf("can't preload %s.%s: no model registered for type %s", typ, field, typ)
+	}
+	sf, _ := typ.FieldByName(field)
+	ft := sf.Type
+	many := ft.Kind() == reflect.Slice
+	elemType := ft
+	if many {
+		elemType = ft.Elem()
+	}
+	base := elemType
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	target := o.typeRegistry[base]
+	if target == nil {
+		return fmt.Errorf("can't preload %s.%s: no model registered for type %s", typ, field, base)
+	}
+	rel, err := o.preloadRelation(m, field, target, many)
+	if err != nil {
+		return err
+	}
+	localIdx := m.fields.QNameMap[rel.localField]
+	remoteIdx := target.fields.QNameMap[rel.remoteField]
+	// Collect the distinct keys
+	keys := make([]interface{}, len(objs))
+	var values []interface{}
+	seen := make(map[interface{}]bool)
+	for ii, v := range objs {
+		kv := o.fieldByIndex(v, m.fields.Indexes[localIdx])
+		if !kv.IsValid() || driver.IsZero(kv) {
+			continue
+		}
+		key := reflect.Indirect(kv).Interface()
+		keys[ii] = key
+		if !seen[key] {
+			seen[key] = true
+			values = append(values, key)
+		}
+	}
+	results := make(map[interface{}][]reflect.Value)
+	load := func(q query.Q) error {
+		tq := o.Query(q).Table(tableWithModel(target))
+		if pk := target.fields.PrimaryKey; pk >= 0 {
+			tq.Sort(target.fields.QNames[pk], ASC)
+		}
+		iter := tq.Iter()
+		for {
+			obj := reflect.New(base)
+			if !iter.Next(obj.Interface()) {
+				break
+			}
+			kv := o.fieldByIndex(obj.Elem(), target.fields.Indexes[remoteIdx])
+			if !kv.IsValid() || driver.IsZero(kv) {
+				continue
+			}
+			key := reflect.Indirect(kv).Interface()
+			results[key] = append(results[key], obj)
+		}
+		return iter.Err()
+	}
+	if len(values) > 0 {
+		if o.driver.Capabilities()&driver.CAP_OR != 0 {
+			if err := load(In(rel.remoteField, values)); err != nil {
+				return err
+			}
+		} else {
+			for _, v := range values {
+				if err := load(Eq(rel.remoteField, v)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for ii, v := range objs {
+		var found []reflect.Value
+		if keys[ii] != nil {
+			found = results[keys[ii]]
+		}
+		fval := v.FieldByIndex(sf.Index)
+		if many {
+			slice := reflect.MakeSlice(ft, 0, len(found))
+			for _, r := range found {
+				slice = reflect.Append(slice, preloadValue(r, elemType))
+			}
+			fval.Set(slice)
+			continue
+		}
+		if len(found) > 0 {
+			fval.Set(preloadValue(found[0], elemType))
+		} else {
+			fval.Set(reflect.Zero(ft))
+		}
+	}
+	return nil
+}
+
+// preloadValue converts val, which is a pointer to a struct,
+// into a value of type typ.
+func preloadValue(val reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() != reflect.Ptr {
+		return val.Elem()
+	}
+	for typ.Elem().Kind() == reflect.Ptr {
+		p := reflect.New(val.Type())
+		p.Elem().Set(val)
+		val = p
+		typ = typ.Elem()
+	}
+	return val
+}
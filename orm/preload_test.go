@@ -0,0 +1,94 @@
+package orm
+
+import (
+	"testing"
+)
+
+type PreloadAuthor struct {
+	Id       int64 `orm:",primary_key,auto_increment"`
+	Name     string
+	Articles []PreloadArticle `orm:"-"`
+}
+
+type PreloadArticle struct {
+	Id       int64 `orm:",primary_key,auto_increment"`
+	AuthorId int64 `orm:",references=PreloadAuthor"`
+	Title    string
+	Author   *PreloadAuthor    `orm:"-"`
+	Comments []*PreloadComment `orm:"-"`
+}
+
+type PreloadComment struct {
+	Id        int64 `orm:",primary_key,auto_increment"`
+	ArticleId int64 `orm:",references=PreloadArticle"`
+	Text      string
+}
+
+func testPreload(t *testing.T, o *Orm) {
+	o.mustRegister((*PreloadAuthor)(nil), &Options{
+		Table: "test_preload_author",
+	})
+	o.mustRegister((*PreloadArticle)(nil), &Options{
+		Table: "test_preload_article",
+	})
+	o.mustRegister((*PreloadComment)(nil), &Options{
+		Table: "test_preload_comment",
+	})
+	o.mustInitialize()
+	a1 := &PreloadAuthor{Name: "A1"}
+	a2 := &PreloadAuthor{Name: "A2"}
+	o.MustSave(a1)
+	o.MustSave(a2)
+	art1 := &PreloadArticle{AuthorId: a1.Id, Title: "Art1"}
+	art2 := &PreloadArticle{AuthorId: a1.Id, Title: "Art2"}
+	art3 := &PreloadArticle{Title: "Art3"}
+	o.MustSave(art1)
+	o.MustSave(art2)
+	o.MustSave(art3)
+	o.MustSave(&PreloadComment{ArticleId: art1.Id, Text: "C1"})
+	o.MustSave(&PreloadComment{ArticleId: art1.Id, Text: "C2"})
+	o.MustSave(&PreloadComment{ArticleId: art2.Id, Text: "C3"})
+	var articles []*PreloadArticle
+	if err := o.Query(nil).Sort("Id", ASC).Preload("Author", "Comments").All(&articles); err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 3 {
+		t.Fatalf("expecting 3 articles, got %d", len(articles))
+	}
+	for _, v := range articles[:2] {
+		if v.Author == nil || v.Author.Id != a1.Id || v.Author.Name != a1.Name {
+			t.Errorf("expecting author %+v in article %s, got %+v", a1, v.Title, v.Author)
+		}
+	}
+	if articles[2].Author != nil {
+		t.Errorf("expecting no author in article %s, got %+v", articles[2].Title, articles[2].Author)
+	}
+	expectComments := [][]string{{"C1", "C2"}, {"C3"}, nil}
+	for ii, v := range articles {
+		var texts []string
+		for _, c := range v.Comments {
+			texts = append(texts, c.Text)
+		}
+		if len(texts) != len(expectComments[ii]) {
+			t.Errorf("expecting comments %v in article %s, got %v", expectComments[ii], v.Title, texts)
+			continue
+		}
+		for jj, c := range texts {
+			if c != expectComments[ii][jj] {
+				t.Errorf("expecting comments %v in article %s, got %v", expectComments[ii], v.Title, texts)
+				break
+			}
+		}
+	}
+	var author PreloadAuthor
+	if !o.Query(Eq("Id", a1.Id)).Preload("Articles").MustOne(&author) {
+		t.Fatal("author not found")
+	}
+	if len(author.Articles) != 2 || author.Articles[0].Title != "Art1" || author.Articles[1].Title != "Art2" {
+		t.Errorf("expecting articles Art1 and Art2 for author %s, got %+v", author.Name, author.Articles)
+	}
+	var a PreloadAuthor
+	if _, err := o.Query(nil).Preload("Nonexistent").One(&a); err == nil {
+		t.Error("expecting an error when preloading a nonexistent field")
+	}
+}
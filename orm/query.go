@@ -17,6 +17,9 @@ type Query struct {
 	sort    []driver.Sort
 	limit   int
 	offset  int
+	preload []string
+	primary bool
+	deleted bool
 	err     error
 }
 
@@ -88,6 +91,22 @@ func (q *Query) Sort(field string, dir Sort) *Query {
 	return q
 }
 
+// Primary indicates that the query must be sent to the primary
+// database, even if the ORM has read replicas. Use it for queries
+// which can't tolerate replication lag. See NewWithReplicas for
+// more details.
+func (q *Query) Primary() *Query {
+	q.primary = true
+	return q
+}
+
+// WithDeleted makes the query include the objects which have
+// been soft deleted. See Orm.Delete for more details.
+func (q *Query) WithDeleted() *Query {
+	q.deleted = true
+	return q
+}
+
 // One fetches the first result for this query. The first
 // return value indicates if a result was found.
 func (q *Query) One(out ...interface{}) (bool, error) {
@@ -96,6 +115,11 @@ func (q *Query) One(out ...interface{}) (bool, error) {
 		// Must close the iter manually, because we're not
 		// reaching the end.
 		iter.Close()
+		if len(q.preload) > 0 {
+			if err := q.preloadValues(out); err != nil {
+				return false, err
+			}
+		}
 		return true, nil
 	}
 	if err := iter.Err(); err != nil {
@@ -122,7 +146,13 @@ func (q *Query) Exists() (bool, error) {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("exists", q.model.String()).End()
 	}
-	return q.orm.driver.Exists(q.model, q.q)
+	conn := q.orm.reader(q.primary)
+	qu := q.query()
+	ok, err := conn.Exists(q.model, qu)
+	if err != nil && q.orm.replicaFailed(conn) {
+		return q.orm.conn.Exists(q.model, qu)
+	}
+	return ok, err
 }
 
 // Iter returns an Iter object which lets you
@@ -173,7 +203,13 @@ func (q *Query) All(out ...interface{}) error {
 			v.Set(reflect.Append(v, reflect.ValueOf(result[ii]).Elem()))
 		}
 	}
-	return iter.Err()
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(q.preload) > 0 {
+		return q.preloadValues(out)
+	}
+	return nil
 }
 
 // MustAll works like All, but panics if there's an error.
@@ -193,7 +229,13 @@ func (q *Query) Count() (uint64, error) {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("count", q.model.String()).End()
 	}
-	return q.orm.driver.Count(q.model, q.q, q.limit, q.offset)
+	conn := q.orm.reader(q.primary)
+	qu := q.query()
+	c, err := conn.Count(q.model, qu, q.limit, q.offset)
+	if err != nil && q.orm.replicaFailed(conn) {
+		return q.orm.conn.Count(q.model, qu, q.limit, q.offset)
+	}
+	return c, err
 }
 
 // MustCount works like Count, but panics if there's an error.
@@ -208,13 +250,16 @@ func (q *Query) MustCount() uint64 {
 // Clone returns a copy of the query.
 func (q *Query) Clone() *Query {
 	return &Query{
-		orm:    q.orm,
-		model:  q.model,
-		q:      q.q,
-		sort:   q.sort,
-		limit:  q.limit,
-		offset: q.offset,
-		err:    q.err,
+		orm:     q.orm,
+		model:   q.model,
+		q:       q.q,
+		sort:    q.sort,
+		limit:   q.limit,
+		offset:  q.offset,
+		preload: q.preload,
+		primary: q.primary,
+		deleted: q.deleted,
+		err:     q.err,
 	}
 }
 
@@ -226,11 +271,27 @@ func (q *Query) iter(limit int) *Iter {
 	}
 }
 
-func (q *Query) exec(limit int) driver.Iter {
+func (q *Query) exec(conn driver.Conn, limit int) driver.Iter {
 	if profile.On && profile.Profiling() {
 		defer profile.Start(orm).Note("query", q.model.String()).End()
 	}
-	return q.orm.conn.Query(q.model, q.q, q.sort, limit, q.offset)
+	return conn.Query(q.model, q.query(), q.sort, limit, q.offset)
+}
+
+// query returns the query to be sent to the driver, which
+// excludes soft deleted objects unless WithDeleted was used.
+func (q *Query) query() query.Q {
+	if q.deleted {
+		return q.q
+	}
+	nd := notDeleted(q.model)
+	if nd == nil {
+		return q.q
+	}
+	if q.q == nil {
+		return nd
+	}
+	return And(q.q, nd)
 }
 
 // Field is a conveniency function which returns a reference to a field
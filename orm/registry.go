@@ -148,6 +148,14 @@ func (o *Orm) registerLocked(t interface{}, opts *Options) (*Table, error) {
 			}
 		}
 	}
+	version, err := versionField(name, fields, opts)
+	if err != nil {
+		return nil, err
+	}
+	deletedAt, err := deletedAtField(name, fields, opts)
+	if err != nil {
+		return nil, err
+	}
 	model := &model{
 		fields:     fields,
 		name:       name,
@@ -156,6 +164,8 @@ func (o *Orm) registerLocked(t interface{}, opts *Options) (*Table, error) {
 		options:    opts,
 		table:      table,
 		tags:       o.tags,
+		version:    version,
+		deletedAt:  deletedAt,
 	}
 	names[table] = model
 	types[s.Type] = model
@@ -481,6 +491,32 @@ func defaultsToNullEmpty(typ reflect.Type, t *structs.Tag) bool {
 	return false
 }
 
+// taggedField returns the index of the field in f declared either with
+// the given tag or using the given qualified name in the model Options,
+// or -1 if there's no such field.
+func taggedField(model string, f *driver.Fields, tag string, qname string) (int, error) {
+	idx := -1
+	for ii, v := range f.Tags {
+		if v.Has(tag) {
+			if idx >= 0 {
+				return -1, fmt.Errorf("duplicate %s field in model %q (%s and %s)", tag, model, f.QNames[idx], f.QNames[ii])
+			}
+			idx = ii
+		}
+	}
+	if qname != "" {
+		pos, ok := f.QNameMap[qname]
+		if !ok {
+			return -1, fmt.Errorf("can't map qualified name %q on model %q when setting %s field", qname, model, tag)
+		}
+		if idx >= 0 && idx != pos {
+			return -1, fmt.Errorf("duplicate %s field in model %q. tags define %q, Options define %q", tag, model, f.QNames[idx], qname)
+		}
+		idx = pos
+	}
+	return idx, nil
+}
+
 func defaultsToOmitEmpty(typ reflect.Type, t *structs.Tag) bool {
 	return t.Has("auto_increment") || (t.Has("default") && typ.Kind() != reflect.Bool)
 }
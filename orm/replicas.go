@@ -0,0 +1,237 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gnd.la/config"
+	"gnd.la/orm/driver"
+)
+
+var (
+	// ReplicaCheckInterval is the interval between health checks
+	// for read replicas. Replicas which fail their check are not
+	// used until they pass it again. Changing this value only
+	// affects ORMs created after the change.
+	ReplicaCheckInterval = 10 * time.Second
+)
+
+type replica struct {
+	url    *config.URL
+	driver driver.Driver
+	down   int32
+}
+
+func (r *replica) isDown() bool {
+	return atomic.LoadInt32(&r.down) != 0
+}
+
+func (r *replica) setDown(down bool) bool {
+	var v int32
+	if down {
+		v = 1
+	}
+	return atomic.SwapInt32(&r.down, v) != v
+}
+
+type replicaSet struct {
+	replicas []*replica
+	next     uint32
+	stop     chan struct{}
+	// The set is shared by the copies returned by Primary
+	// and ReadYourWrites, so it might be closed several times.
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// close stops the health checks and closes the replicas. Only
+// the first call has any effect, subsequent calls just return
+// the same error.
+func (s *replicaSet) close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		for _, v := range s.replicas {
+			if err := v.driver.Close(); err != nil && s.closeErr == nil {
+				s.closeErr = err
+			}
+		}
+	})
+	return s.closeErr
+}
+
+// pick returns the next healthy replica using round-robin
+// selection, or nil if all of them are down.
+func (s *replicaSet) pick() *replica {
+	count := uint32(len(s.replicas))
+	start := atomic.AddUint32(&s.next, 1)
+	for ii := uint32(0); ii < count; ii++ {
+		if r := s.replicas[(start+ii)%count]; !r.isDown() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (o *Orm) checkReplicas(set *replicaSet) {
+	for _, v := range set.replicas {
+		err := v.driver.Check()
+		if v.setDown(err != nil) && o.logger != nil {
+			if err != nil {
+				o.logger.Errorf("read replica %s is down: %s", v.url, err)
+			} else {
+				o.logger.Infof("read replica %s is up again", v.url)
+			}
+		}
+	}
+}
+
+func (o *Orm) watchReplicas(set *replicaSet, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			o.checkReplicas(set)
+		case <-set.stop:
+			return
+		}
+	}
+}
+
+func (o *Orm) closeReplicas() error {
+	if o.replicas == nil {
+		return nil
+	}
+	err := o.replicas.close()
+	o.replicas = nil
+	return err
+}
+
+// reader returns the connection used for read only queries. Reads
+// go to the primary when there are no replicas, when the ORM is
+// pinned to the primary (e.g. inside a transaction), when read-your-writes
+// was requested and the ORM has written anything or when all the
+// replicas are down.
+func (o *Orm) reader(primary bool) driver.Conn {
+	if primary || o.replicas == nil || o.primary || (o.written != nil && atomic.LoadInt32(o.written) != 0) {
+		return o.conn
+	}
+	if r := o.replicas.pick(); r != nil {
+		return r.driver
+	}
+	return o.conn
+}
+
+// replicaFailed is called after a read using conn fails. If conn is
+// a replica and it fails its health check, it's marked as down, so
+// subsequent queries avoid it until it passes a check again. It
+// returns true iff the replica was marked as down, indicating the
+// query should be retried on the primary.
+func (o *Orm) replicaFailed(conn driver.Conn) bool {
+	if o.replicas == nil || conn == o.conn {
+		return false
+	}
+	for _, v := range o.replicas.replicas {
+		if driver.Conn(v.driver) == conn {
+			err := v.driver.Check()
+			if err == nil {
+				return false
+			}
+			if v.setDown(true) && o.logger != nil {
+				o.logger.Errorf("read replica %s is down: %s", v.url, err)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// wrote records that the ORM has written to the database, so
+// reads are sent to the primary if read-your-writes was requested.
+func (o *Orm) wrote() {
+	if o.written != nil {
+		atomic.StoreInt32(o.written, 1)
+	}
+}
+
+// Primary returns a copy of the ORM which sends all its
+// queries to the primary database, ignoring any read replicas.
+// Use it when a set of reads can't tolerate replication lag.
+// To send only one query to the primary, see Query.Primary.
+func (o *Orm) Primary() *Orm {
+	cpy := *o
+	cpy.primary = true
+	return &cpy
+}
+
+// ReadYourWrites returns a copy of the ORM which sends its reads
+// to the replicas until it writes to the database. Once any insert,
+// update or delete is performed using the returned ORM, all its
+// subsequent reads are sent to the primary, so they always observe
+// the previous writes. Note that writes performed using the original
+// ORM or other copies of it are not taken into account.
+func (o *Orm) ReadYourWrites() *Orm {
+	cpy := *o
+	cpy.written = new(int32)
+	return &cpy
+}
+
+// Replicas returns the number of read replicas used by the ORM.
+func (o *Orm) Replicas() int {
+	if o.replicas == nil {
+		return 0
+	}
+	return len(o.replicas.replicas)
+}
+
+// NewWithReplicas works like New, but also opens the given read
+// replicas. Read only queries (Query, One, All, Iter, Count and
+// Exists) are distributed among the healthy replicas using
+// round-robin, while writes always go to the primary. Replicas
+// are checked every ReplicaCheckInterval and any replica which fails
+// its check or a query is skipped until it passes a check again. If
+// all replicas are down, reads are sent to the primary.
+//
+// Reads performed inside a transaction always use the primary. To
+// tolerate replication lag, see Query.Primary, Orm.Primary and
+// Orm.ReadYourWrites. Note that Initialize only initializes the
+// primary, the schema is expected to reach the replicas via
+// replication.
+func NewWithReplicas(url *config.URL, replicas []*config.URL) (*Orm, error) {
+	o, err := New(url)
+	if err != nil {
+		return nil, err
+	}
+	if len(replicas) == 0 {
+		return o, nil
+	}
+	set := &replicaSet{stop: make(chan struct{})}
+	for _, v := range replicas {
+		if v.Scheme != url.Scheme {
+			o.Close()
+			return nil, fmt.Errorf("replica %s uses driver %q, while the primary uses %q", v, v.Scheme, url.Scheme)
+		}
+		drv, err := open(v)
+		if err == nil && strings.Join(drv.Tags(), "-") != o.tags {
+			drv.Close()
+			err = fmt.Errorf("driver tags differ from the primary")
+		}
+		if err != nil {
+			for _, r := range set.replicas {
+				r.driver.Close()
+			}
+			o.Close()
+			return nil, fmt.Errorf("error opening replica %s: %s", v, err)
+		}
+		set.replicas = append(set.replicas, &replica{url: v, driver: drv})
+	}
+	o.replicas = set
+	o.checkReplicas(set)
+	if ReplicaCheckInterval > 0 {
+		go o.watchReplicas(set, ReplicaCheckInterval)
+	}
+	return o, nil
+}
@@ -0,0 +1,219 @@
+// +build !appengine
+
+package orm
+
+import (
+	"errors"
+	"testing"
+
+	"gnd.la/config"
+	"gnd.la/orm/driver"
+	"gnd.la/orm/query"
+)
+
+type Replicated struct {
+	Id    int64 `orm:",primary_key,auto_increment"`
+	Value string
+}
+
+func TestReplicas(t *testing.T) {
+	interval := ReplicaCheckInterval
+	ReplicaCheckInterval = 0
+	defer func() {
+		ReplicaCheckInterval = interval
+	}()
+	clearRegistry(nil)
+	replicas := []*config.URL{config.MustParseURL("memory://"), config.MustParseURL("memory://")}
+	o, err := NewWithReplicas(config.MustParseURL("memory://"), replicas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	if c := o.Replicas(); c != len(replicas) {
+		t.Fatalf("expecting %d replicas, got %d", len(replicas), c)
+	}
+	table := o.mustRegister((*Replicated)(nil), &Options{
+		Table: "test_replicated",
+	})
+	o.mustInitialize()
+	m := table.model.model
+	for _, v := range o.replicas.replicas {
+		if err := v.driver.Initialize([]driver.Model{m}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func(o *Orm, expect uint64) {
+		if c := o.Table(table).MustCount(); c != expect {
+			t.Errorf("expecting %d objects, got %d", expect, c)
+		}
+	}
+	o.MustSave(&Replicated{Value: "primary"})
+	// Reads go to the replicas, which haven't received the object
+	count(o, 0)
+	if o.MustOne(Eq("Value", "primary"), &Replicated{}) {
+		t.Error("expecting no results from replica")
+	}
+	// Unless the query or the ORM is pinned to the primary
+	if c := o.Table(table).Primary().MustCount(); c != 1 {
+		t.Errorf("expecting 1 object in primary, got %d", c)
+	}
+	count(o.Primary(), 1)
+	// Transactions always use the primary
+	tx := o.MustBegin()
+	count(&tx.Orm, 1)
+	tx.MustRollback()
+	// Read your writes
+	rw := o.ReadYourWrites()
+	count(rw, 0)
+	rw.MustSave(&Replicated{Value: "rw"})
+	count(rw, 2)
+	count(o, 0)
+	// Round robin
+	if _, err := o.replicas.replicas[0].driver.Insert(m, &Replicated{Value: "replica"}); err != nil {
+		t.Fatal(err)
+	}
+	first := o.Table(table).MustCount()
+	second := o.Table(table).MustCount()
+	if first+second != 1 {
+		t.Errorf("expecting one query on each replica, got counts %d and %d", first, second)
+	}
+	// Replicas which are down are skipped
+	o.replicas.replicas[1].setDown(true)
+	for ii := 0; ii < 3; ii++ {
+		count(o, 1)
+	}
+	o.replicas.replicas[0].setDown(true)
+	count(o, 2)
+	// Health checks bring them back
+	o.checkReplicas(o.replicas)
+	if c := o.Table(table).Filter(Eq("Value", "rw")).MustCount(); c != 0 {
+		t.Errorf("expecting no objects with value rw in replicas, got %d", c)
+	}
+}
+
+func TestReplicasClose(t *testing.T) {
+	replicas := []*config.URL{config.MustParseURL("memory://")}
+	o, err := NewWithReplicas(config.MustParseURL("memory://"), replicas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Copies share the replicas, so closing all of
+	// them must stop the health checks only once.
+	copies := []*Orm{o.Primary(), o.ReadYourWrites(), o}
+	for _, v := range copies {
+		if err := v.Close(); err != nil {
+			t.Error(err)
+		}
+		if c := v.Replicas(); c != 0 {
+			t.Errorf("expecting no replicas after closing, got %d", c)
+		}
+	}
+	if err := o.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+var errReplicaDown = errors.New("replica is down")
+
+// failingDriver simulates a replica which went down: all its
+// reads and health checks fail.
+type failingDriver struct {
+	driver.Driver
+}
+
+func (d *failingDriver) Check() error {
+	return errReplicaDown
+}
+
+func (d *failingDriver) Query(m driver.Model, q query.Q, sort []driver.Sort, limit int, offset int) driver.Iter {
+	return &failingIter{}
+}
+
+func (d *failingDriver) Count(m driver.Model, q query.Q, limit int, offset int) (uint64, error) {
+	return 0, errReplicaDown
+}
+
+func (d *failingDriver) Exists(m driver.Model, q query.Q) (bool, error) {
+	return false, errReplicaDown
+}
+
+type failingIter struct{}
+
+func (i *failingIter) Next(out ...interface{}) bool { return false }
+func (i *failingIter) Err() error                   { return errReplicaDown }
+func (i *failingIter) Close() error                 { return nil }
+
+func TestReplicaFailure(t *testing.T) {
+	interval := ReplicaCheckInterval
+	ReplicaCheckInterval = 0
+	defer func() {
+		ReplicaCheckInterval = interval
+	}()
+	clearRegistry(nil)
+	o, err := NewWithReplicas(config.MustParseURL("memory://"), []*config.URL{config.MustParseURL("memory://")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	table := o.mustRegister((*Replicated)(nil), &Options{
+		Table: "test_replicated",
+	})
+	o.mustInitialize()
+	o.MustSave(&Replicated{Value: "primary"})
+	r := o.replicas.replicas[0]
+	cases := []struct {
+		name string
+		read func() (int, error)
+	}{
+		{"All", func() (int, error) {
+			var objs []*Replicated
+			err := o.Query(Eq("Value", "primary")).All(&objs)
+			return len(objs), err
+		}},
+		{"One", func() (int, error) {
+			ok, err := o.One(Eq("Value", "primary"), &Replicated{})
+			if ok {
+				return 1, err
+			}
+			return 0, err
+		}},
+		{"Iter", func() (int, error) {
+			count := 0
+			iter := o.Table(table).Iter()
+			for iter.Next(&Replicated{}) {
+				count++
+			}
+			return count, iter.Err()
+		}},
+		{"Count", func() (int, error) {
+			c, err := o.Count(table, nil)
+			return int(c), err
+		}},
+		{"Exists", func() (int, error) {
+			ok, err := o.Exists(table, Eq("Value", "primary"))
+			if ok {
+				return 1, err
+			}
+			return 0, err
+		}},
+	}
+	for _, v := range cases {
+		// Replace the replica with a failing one, which
+		// is marked as down and the read is retried on
+		// the primary.
+		working := r.driver
+		r.driver = &failingDriver{Driver: working}
+		r.setDown(false)
+		n, err := v.read()
+		if err != nil {
+			t.Errorf("%s: expecting no error after a replica failure, got %s", v.name, err)
+		}
+		if n != 1 {
+			t.Errorf("%s: expecting 1 result from the primary, got %d", v.name, n)
+		}
+		if !r.isDown() {
+			t.Errorf("%s: expecting replica to be marked as down", v.name)
+		}
+		r.driver = working
+	}
+}
@@ -0,0 +1,111 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gnd.la/orm/driver"
+	"gnd.la/orm/operation"
+	"gnd.la/orm/query"
+)
+
+func deletedAtField(model string, f *driver.Fields, opts *Options) (int, error) {
+	var qname string
+	if opts != nil {
+		qname = opts.DeletedAt
+	}
+	idx, err := taggedField(model, f, "deleted_at", qname)
+	if err != nil || idx < 0 {
+		return idx, err
+	}
+	typ := f.Types[idx]
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != timeType {
+		return -1, fmt.Errorf("deleted_at field %q in model %q must be of type time.Time or *time.Time, not %s", f.QNames[idx], model, f.Types[idx])
+	}
+	return idx, nil
+}
+
+// notDeleted returns a query which excludes the soft deleted
+// rows from all the models in jm, or nil if none of them
+// supports soft deletes.
+func notDeleted(jm *joinModel) query.Q {
+	var conditions []query.Q
+	for cur := jm; cur != nil; {
+		if m := cur.model; m.deletedAt >= 0 {
+			conditions = append(conditions, Eq(m.fullName(m.fields.QNames[m.deletedAt]), nil))
+		}
+		if cur.join == nil {
+			break
+		}
+		cur = cur.join.model
+	}
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0]
+	}
+	return And(conditions...)
+}
+
+// softDelete marks the rows matching q as deleted, setting
+// their deleted_at field to the current time. If obj is not
+// nil, its deleted_at field is also updated.
+func (o *Orm) softDelete(m *model, q query.Q, obj interface{}) (Result, error) {
+	name := m.fields.QNames[m.deletedAt]
+	now := funcNow()
+	dq := Eq(name, nil)
+	if q != nil {
+		dq = And(q, dq)
+	}
+	ops := versionOperations(m, []*operation.Operation{operation.Set(name, now)})
+	res, err := o.conn.Operate(m, dq, ops)
+	if err != nil {
+		return nil, err
+	}
+	if obj != nil {
+		val := o.fieldByIndexCreating(driver.Direct(reflect.ValueOf(obj)), m.fields.Indexes[m.deletedAt])
+		if val.CanSet() {
+			if val.Kind() == reflect.Ptr {
+				val.Set(reflect.New(timeType))
+				val = val.Elem()
+			}
+			val.Set(reflect.ValueOf(now))
+		}
+		if m.version >= 0 {
+			if aff, err := res.RowsAffected(); err == nil && aff > 0 {
+				if v, err := o.versionValue(m, obj); err == nil {
+					setVersion(v, versionInt(v)+1)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// HardDelete works like Delete, but it always removes the object
+// from the database, even if its model supports soft deletes.
+func (o *Orm) HardDelete(obj interface{}) error {
+	m, err := o.model(obj)
+	if err != nil {
+		return err
+	}
+	return o.deleteByPk(m, obj, true)
+}
+
+// MustHardDelete works like HardDelete, but panics if there's an error.
+func (o *Orm) MustHardDelete(obj interface{}) {
+	if err := o.HardDelete(obj); err != nil {
+		panic(err)
+	}
+}
+
+// HardDeleteFrom works like DeleteFrom, but it always removes the
+// matching rows from the database, even if the model supports soft
+// deletes.
+func (o *Orm) HardDeleteFrom(t *Table, q query.Q) (Result, error) {
+	return o.delete(t.model.model, q, nil, true)
+}
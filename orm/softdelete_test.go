@@ -0,0 +1,85 @@
+package orm
+
+import (
+	"testing"
+	"time"
+)
+
+type SoftDeleted struct {
+	Id        int64 `orm:",primary_key,auto_increment"`
+	Value     string
+	DeletedAt time.Time `orm:",deleted_at"`
+}
+
+type SoftDeletedReference struct {
+	Id            int64 `orm:",primary_key,auto_increment"`
+	SoftDeletedId int64 `orm:",references=SoftDeleted"`
+}
+
+func testSoftDelete(t *testing.T, o *Orm) {
+	table := o.mustRegister((*SoftDeleted)(nil), &Options{
+		Table: "test_soft_deleted",
+	})
+	refTable := o.mustRegister((*SoftDeletedReference)(nil), &Options{
+		Table: "test_soft_deleted_reference",
+	})
+	o.mustInitialize()
+	count := func(q *Query, expect uint64) {
+		if c := q.MustCount(); c != expect {
+			t.Errorf("expecting %d objects, got %d", expect, c)
+		}
+	}
+	objs := []*SoftDeleted{{Value: "a"}, {Value: "b"}, {Value: "c"}}
+	for _, v := range objs {
+		o.MustSave(v)
+	}
+	o.MustSave(&SoftDeletedReference{SoftDeletedId: objs[0].Id})
+	o.MustDelete(objs[0])
+	if objs[0].DeletedAt.IsZero() {
+		t.Error("DeletedAt was not set when deleting")
+	}
+	count(o.Table(table), 2)
+	count(o.Table(table).WithDeleted(), 3)
+	if ok, _ := o.Exists(table, Eq("Id", objs[0].Id)); ok {
+		t.Error("soft deleted object exists")
+	}
+	if o.MustOne(Eq("Id", objs[0].Id), &SoftDeleted{}) {
+		t.Error("soft deleted object was returned by One")
+	}
+	var deleted SoftDeleted
+	if !o.Query(Eq("Id", objs[0].Id)).WithDeleted().MustOne(&deleted) {
+		t.Fatal("soft deleted object not returned by WithDeleted")
+	}
+	if deleted.DeletedAt.IsZero() {
+		t.Error("soft deleted object has no DeletedAt")
+	}
+	var all []*SoftDeleted
+	o.Query(nil).Sort("Id", ASC).MustAll(&all)
+	if len(all) != 2 || all[0].Value != "b" || all[1].Value != "c" {
+		t.Errorf("expecting objects b and c, got %+v", all)
+	}
+	// Joins exclude soft deleted objects too
+	var ref SoftDeletedReference
+	var sd SoftDeleted
+	if o.Query(Eq("SoftDeletedReference|Id", 1)).MustOne(&ref, &sd) {
+		t.Error("join returned a soft deleted object")
+	}
+	count(o.Table(refTable), 1)
+	// Restore
+	deleted.DeletedAt = time.Time{}
+	o.MustSave(&deleted)
+	count(o.Table(table), 3)
+	// DeleteFrom
+	if _, err := o.DeleteFrom(table, Eq("Value", "b")); err != nil {
+		t.Fatal(err)
+	}
+	count(o.Table(table), 2)
+	count(o.Table(table).WithDeleted(), 3)
+	// Hard deletes
+	if _, err := o.HardDeleteFrom(table, Eq("Value", "b")); err != nil {
+		t.Fatal(err)
+	}
+	count(o.Table(table).WithDeleted(), 2)
+	o.MustHardDelete(objs[2])
+	count(o.Table(table).WithDeleted(), 1)
+}
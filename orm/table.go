@@ -1,6 +1,10 @@
 package orm
 
 import (
+	"reflect"
+	"sort"
+
+	"gnd.la/orm/driver"
 	"gnd.la/orm/query"
 )
 
@@ -37,6 +41,45 @@ func (t *Table) Skip() *Table {
 	return &Table{model: model}
 }
 
+// Name returns the model name for this table. For joined tables,
+// the name of the first model in the join is returned.
+func (t *Table) Name() string {
+	return t.model.name
+}
+
+// TableName returns the name of the table or collection in the
+// database which stores the model.
+func (t *Table) TableName() string {
+	return t.model.table
+}
+
+// Type returns the Go type of the model.
+func (t *Table) Type() reflect.Type {
+	return t.model.Type()
+}
+
+// Fields returns the fields of the model, as seen by the ORM.
+func (t *Table) Fields() *driver.Fields {
+	return t.model.fields
+}
+
+// Tables returns all the tables registered in this ORM,
+// sorted by their model name.
+func (o *Orm) Tables() []*Table {
+	var tables []*Table
+	for _, v := range o.typeRegistry {
+		tables = append(tables, tableWithModel(v))
+	}
+	sort.Sort(tablesByName(tables))
+	return tables
+}
+
+type tablesByName []*Table
+
+func (t tablesByName) Len() int           { return len(t) }
+func (t tablesByName) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t tablesByName) Less(i, j int) bool { return t[i].Name() < t[j].Name() }
+
 func tableWithModel(m *model) *Table {
 	return &Table{model: &joinModel{model: m}}
 }
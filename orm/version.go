@@ -0,0 +1,136 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gnd.la/orm/driver"
+	"gnd.la/orm/operation"
+	"gnd.la/orm/query"
+	"gnd.la/util/types"
+)
+
+// ConflictError is returned by Update, Save and Upsert when the
+// object being updated has a version field (declared with the tag
+// orm:",version" or Options.Version) and its version doesn't match
+// the one stored in the database, which means the object was modified
+// by someone else after it was loaded.
+//
+// Every time an object with a version field is updated, the update
+// only succeeds if the stored version is equal to the version in the
+// object, and the version is incremented by one in both the database
+// and the object. Inserting an object with a zero version sets it to 1.
+// Operate also increments the version of the rows it updates.
+//
+// When a ConflictError is returned, the object keeps its original
+// version, so it can be reloaded and the update retried.
+type ConflictError struct {
+	// Table is the table where the conflict occurred.
+	Table *Table
+	// Object is the object which couldn't be updated.
+	Object interface{}
+	// Version is the version of Object, which doesn't
+	// match the one stored in the database.
+	Version int64
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("can't update %s: version %d is outdated, it has been modified since it was loaded", e.Table.Name(), e.Version)
+}
+
+func versionField(model string, f *driver.Fields, opts *Options) (int, error) {
+	var qname string
+	if opts != nil {
+		qname = opts.Version
+	}
+	idx, err := taggedField(model, f, "version", qname)
+	if err != nil || idx < 0 {
+		return idx, err
+	}
+	if k := types.Kind(f.Types[idx].Kind()); k != types.Int && k != types.Uint {
+		return -1, fmt.Errorf("version field %q in model %q must be of integer type, not %s", f.QNames[idx], model, f.Types[idx])
+	}
+	return idx, nil
+}
+
+// versionValue returns the version field in obj, returning
+// an error if it can't be set.
+func (o *Orm) versionValue(m *model, obj interface{}) (reflect.Value, error) {
+	val := o.fieldByIndex(driver.Direct(reflect.ValueOf(obj)), m.fields.Indexes[m.version])
+	if !val.IsValid() || !val.CanSet() {
+		typ := reflect.TypeOf(obj)
+		return reflect.Value{}, fmt.Errorf("can't set version field %q. Please, use a %v rather than a %v", m.fields.QNames[m.version], reflect.PtrTo(typ), typ)
+	}
+	return val, nil
+}
+
+func versionInt(val reflect.Value) int64 {
+	if types.Kind(val.Kind()) == types.Uint {
+		return int64(val.Uint())
+	}
+	return val.Int()
+}
+
+func setVersion(val reflect.Value, version int64) {
+	if types.Kind(val.Kind()) == types.Uint {
+		val.SetUint(uint64(version))
+	} else {
+		val.SetInt(version)
+	}
+}
+
+// initVersion sets the version of obj to 1 if it's zero.
+func (o *Orm) initVersion(m *model, obj interface{}) error {
+	if m.version < 0 {
+		return nil
+	}
+	val, err := o.versionValue(m, obj)
+	if err != nil {
+		return err
+	}
+	if versionInt(val) == 0 {
+		setVersion(val, 1)
+	}
+	return nil
+}
+
+// updateVersioned performs an update of an object with a version
+// field, returning a *ConflictError if the update didn't affect
+// any rows because of a version mismatch.
+func (o *Orm) updateVersioned(m *model, q query.Q, obj interface{}) (Result, error) {
+	val, err := o.versionValue(m, obj)
+	if err != nil {
+		return nil, err
+	}
+	version := versionInt(val)
+	vq := Eq(m.fields.QNames[m.version], val.Interface())
+	if q != nil {
+		vq = And(q, vq)
+	}
+	setVersion(val, version+1)
+	res, err := o.conn.Update(m, vq, obj)
+	if err != nil {
+		setVersion(val, version)
+		return nil, err
+	}
+	if aff, err := res.RowsAffected(); err == nil && aff == 0 {
+		setVersion(val, version)
+		exists, err := o.conn.Exists(m, q)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, &ConflictError{Table: tableWithModel(m), Object: obj, Version: version}
+		}
+	}
+	return res, nil
+}
+
+// versionOperations adds an increment of the version field
+// to ops if the model has one.
+func versionOperations(m *model, ops []*operation.Operation) []*operation.Operation {
+	if m.version < 0 {
+		return ops
+	}
+	return append(ops, operation.Inc(m.fields.QNames[m.version]))
+}
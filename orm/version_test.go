@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"testing"
+
+	"gnd.la/orm/operation"
+)
+
+type Versioned struct {
+	Id      int64 `orm:",primary_key,auto_increment"`
+	Value   string
+	Version int64 `orm:",version"`
+}
+
+type VersionedByOptions struct {
+	Id  int64 `orm:",primary_key,auto_increment"`
+	Rev uint
+}
+
+func testVersion(t *testing.T, o *Orm) {
+	table := o.mustRegister((*Versioned)(nil), &Options{
+		Table: "test_versioned",
+	})
+	o.mustRegister((*VersionedByOptions)(nil), &Options{
+		Table:   "test_versioned_options",
+		Version: "Rev",
+	})
+	o.mustInitialize()
+	obj := &Versioned{Value: "foo"}
+	o.MustSave(obj)
+	if obj.Version != 1 {
+		t.Errorf("expecting version 1 after insert, got %d", obj.Version)
+	}
+	var other Versioned
+	if !o.MustOne(Eq("Id", obj.Id), &other) {
+		t.Fatal("object not found")
+	}
+	obj.Value = "bar"
+	o.MustSave(obj)
+	if obj.Version != 2 {
+		t.Errorf("expecting version 2 after update, got %d", obj.Version)
+	}
+	// other still has version 1
+	other.Value = "baz"
+	_, err := o.Save(&other)
+	if cerr, ok := err.(*ConflictError); !ok {
+		t.Errorf("expecting *ConflictError, got %v", err)
+	} else if cerr.Version != 1 || cerr.Table.Name() != table.Name() {
+		t.Errorf("invalid conflict error %+v", cerr)
+	}
+	if other.Version != 1 {
+		t.Errorf("expecting version 1 after conflict, got %d", other.Version)
+	}
+	var stored Versioned
+	o.MustOne(Eq("Id", obj.Id), &stored)
+	if stored.Value != "bar" || stored.Version != 2 {
+		t.Errorf("expecting value bar with version 2, got %+v", stored)
+	}
+	// Reloading and retrying works
+	o.MustOne(Eq("Id", obj.Id), &other)
+	other.Value = "baz"
+	o.MustSave(&other)
+	if other.Version != 3 {
+		t.Errorf("expecting version 3 after retrying, got %d", other.Version)
+	}
+	// Update with a query which doesn't match shouldn't generate a conflict
+	if res := o.MustUpdate(Eq("Id", obj.Id+1000), &other); res != nil {
+		if aff, _ := res.RowsAffected(); aff != 0 {
+			t.Errorf("expecting 0 affected rows, got %d", aff)
+		}
+	}
+	// Operate increments the version
+	o.MustOperate(table, Eq("Id", obj.Id), operation.Set("Value", "op"))
+	o.MustOne(Eq("Id", obj.Id), &stored)
+	if stored.Value != "op" || stored.Version != 4 {
+		t.Errorf("expecting value op with version 4, got %+v", stored)
+	}
+	if _, err := o.Save(&other); err == nil {
+		t.Error("expecting an error when saving after Operate")
+	}
+	// Options
+	opt := &VersionedByOptions{}
+	o.MustSave(opt)
+	o.MustSave(opt)
+	if opt.Rev != 2 {
+		t.Errorf("expecting revision 2, got %d", opt.Rev)
+	}
+	if _, err := o.Save(VersionedByOptions{Id: opt.Id}); err == nil {
+		t.Error("expecting an error when saving a non-pointer with a version")
+	}
+}
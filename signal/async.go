@@ -0,0 +1,113 @@
+package signal
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"gnd.la/log"
+)
+
+// AsyncWorkers is the number of goroutines used by
+// each Registry for calling listeners asynchronously.
+const AsyncWorkers = 4
+
+// asyncPool runs the submitted functions from AsyncWorkers
+// goroutines. Its queue is unbounded, so submitting never
+// blocks, not even from a function running in the pool (e.g.
+// a listener calling EmitAsync). Otherwise, the workers could
+// block each other while the queue is full.
+type asyncPool struct {
+	once    sync.Once
+	mu      sync.Mutex
+	ready   *sync.Cond
+	idle    *sync.Cond
+	queue   []func()
+	pending int
+}
+
+func (p *asyncPool) start() {
+	p.ready = sync.NewCond(&p.mu)
+	p.idle = sync.NewCond(&p.mu)
+	for ii := 0; ii < AsyncWorkers; ii++ {
+		go p.work()
+	}
+}
+
+func (p *asyncPool) submit(f func()) {
+	p.once.Do(p.start)
+	p.mu.Lock()
+	p.queue = append(p.queue, f)
+	p.pending++
+	p.ready.Signal()
+	p.mu.Unlock()
+}
+
+func (p *asyncPool) work() {
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 {
+			p.ready.Wait()
+		}
+		f := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		p.run(f)
+		p.mu.Lock()
+		p.pending--
+		if p.pending == 0 {
+			p.idle.Broadcast()
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *asyncPool) run(f func()) {
+	// A panicking listener must not stop the worker
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("panic in asynchronous signal listener: %v\n%s", r, debug.Stack())
+		}
+	}()
+	f()
+}
+
+func (p *asyncPool) wait() {
+	p.once.Do(p.start)
+	p.mu.Lock()
+	for p.pending > 0 {
+		p.idle.Wait()
+	}
+	p.mu.Unlock()
+}
+
+// EmitAsync works like Emit, but the listeners are called from a pool
+// of AsyncWorkers goroutines, rather than in the caller's one. Listeners
+// for the same emission are still called sequentially, in the order they
+// were registered, but there are no ordering guarantees between different
+// emissions. EmitAsync never blocks, emissions waiting for a worker are
+// queued, so listeners might call EmitAsync too. Panics in listeners
+// called asynchronously are recovered and logged.
+func (r *Registry) EmitAsync(name string, object interface{}) {
+	log.Debugf("Emitting signal %s asynchronously with %T object", name, object)
+	r.async.submit(func() {
+		r.emit(name, object)
+	})
+	r.publish(name, object)
+}
+
+// Wait blocks until all the signals emitted with EmitAsync (as well as any
+// messages published to the Bridge) have been processed.
+func (r *Registry) Wait() {
+	r.async.wait()
+}
+
+// EmitAsync calls EmitAsync on the default Registry.
+func EmitAsync(name string, object interface{}) {
+	defaultRegistry.EmitAsync(name, object)
+}
+
+// Wait calls Wait on the default Registry.
+func Wait() {
+	defaultRegistry.Wait()
+}
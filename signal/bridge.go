@@ -0,0 +1,244 @@
+package signal
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sync"
+
+	"gnd.la/log"
+)
+
+// Bridge connects Registry instances in different processes (e.g. all
+// the instances of an app in a cluster), so signals emitted in one of
+// them are also received by the rest. Only the signals explicitly
+// forwarded with Forward are sent over the Bridge, with their objects
+// encoded as JSON.
+//
+// See the gnd.la/signal/redis package for a Bridge implemented on top
+// of redis pub/sub, and Loopback for a Bridge intended for tests.
+type Bridge interface {
+	// Publish sends the message to all the subscribers, including
+	// the ones in the same process.
+	Publish(msg []byte) error
+	// Subscribe starts calling f for each published message, until
+	// the returned io.Closer is closed. f might be called from any
+	// goroutine, but never concurrently with itself.
+	Subscribe(f func(msg []byte)) (io.Closer, error)
+}
+
+type bridgeMessage struct {
+	Origin string          `json:"origin"`
+	Name   string          `json:"name"`
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+type bridgeState struct {
+	mu        sync.RWMutex
+	bridge    Bridge
+	sub       io.Closer
+	forwarded map[string]reflect.Type
+}
+
+// SetBridge sets the Bridge used to send and receive forwarded
+// signals, closing the subscription to the previous one, if any.
+// Passing nil disconnects the Registry from its current Bridge.
+func (r *Registry) SetBridge(b Bridge) error {
+	var sub io.Closer
+	if b != nil {
+		var err error
+		if sub, err = b.Subscribe(r.receive); err != nil {
+			return err
+		}
+	}
+	r.bridge.mu.Lock()
+	prev := r.bridge.sub
+	r.bridge.bridge = b
+	r.bridge.sub = sub
+	r.bridge.mu.Unlock()
+	if prev != nil {
+		return prev.Close()
+	}
+	return nil
+}
+
+// Forward makes the Registry publish the signal with the given name to
+// its Bridge every time it's emitted, as well as emit it when it's
+// received from other processes. Listeners are not called twice for
+// signals emitted in the same Registry.
+//
+// Received objects are decoded from JSON into a new value with the same
+// type as proto. If proto is a pointer, the object is decoded into a new
+// pointer of the same type. If proto is nil, the object is decoded into
+// an interface{}, as encoding/json does.
+func (r *Registry) Forward(name string, proto interface{}) {
+	if name == "" {
+		panic(errEmptyName)
+	}
+	var typ reflect.Type
+	if proto != nil {
+		typ = reflect.TypeOf(proto)
+	}
+	r.bridge.mu.Lock()
+	if r.bridge.forwarded == nil {
+		r.bridge.forwarded = make(map[string]reflect.Type)
+	}
+	r.bridge.forwarded[name] = typ
+	r.bridge.mu.Unlock()
+}
+
+// publish sends the signal to the Bridge from a worker
+// goroutine, if the Registry forwards it.
+func (r *Registry) publish(name string, object interface{}) {
+	r.bridge.mu.RLock()
+	b := r.bridge.bridge
+	_, forwarded := r.bridge.forwarded[name]
+	r.bridge.mu.RUnlock()
+	if b == nil || !forwarded {
+		return
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		log.Errorf("error encoding object for signal %s: %s", name, err)
+		return
+	}
+	msg, err := json.Marshal(&bridgeMessage{Origin: r.id, Name: name, Object: data})
+	if err != nil {
+		log.Errorf("error encoding signal %s: %s", name, err)
+		return
+	}
+	r.async.submit(func() {
+		if err := b.Publish(msg); err != nil {
+			log.Errorf("error publishing signal %s: %s", name, err)
+		}
+	})
+}
+
+func (r *Registry) receive(data []byte) {
+	var msg bridgeMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Errorf("error decoding signal from bridge: %s", err)
+		return
+	}
+	if msg.Origin == r.id {
+		return
+	}
+	r.bridge.mu.RLock()
+	typ, forwarded := r.bridge.forwarded[msg.Name]
+	r.bridge.mu.RUnlock()
+	if !forwarded {
+		return
+	}
+	object, err := decodeObject(msg.Object, typ)
+	if err != nil {
+		log.Errorf("error decoding object for signal %s: %s", msg.Name, err)
+		return
+	}
+	log.Debugf("Received signal %s with %T object from bridge", msg.Name, object)
+	defer func() {
+		if e := recover(); e != nil {
+			log.Errorf("panic in listener for signal %s received from bridge: %v", msg.Name, e)
+		}
+	}()
+	r.emit(msg.Name, object)
+}
+
+func decodeObject(data []byte, typ reflect.Type) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	if typ == nil {
+		var object interface{}
+		err := json.Unmarshal(data, &object)
+		return object, err
+	}
+	if typ.Kind() == reflect.Ptr {
+		val := reflect.New(typ.Elem())
+		err := json.Unmarshal(data, val.Interface())
+		return val.Interface(), err
+	}
+	val := reflect.New(typ)
+	err := json.Unmarshal(data, val.Interface())
+	return val.Elem().Interface(), err
+}
+
+// SetBridge calls SetBridge on the default Registry.
+func SetBridge(b Bridge) error {
+	return defaultRegistry.SetBridge(b)
+}
+
+// Forward calls Forward on the default Registry.
+func Forward(name string, proto interface{}) {
+	defaultRegistry.Forward(name, proto)
+}
+
+// Loopback is a Bridge which delivers the published messages to
+// its subscribers in the same process, synchronously. It's intended
+// for tests, connecting several Registry instances as if they were
+// running in different processes. e.g.
+//
+//  lo := signal.NewLoopback()
+//  r1 := signal.NewRegistry()
+//  r1.SetBridge(lo)
+//  r2 := signal.NewRegistry()
+//  r2.SetBridge(lo)
+//
+// Signals forwarded by r1 will be received by r2 and vice versa.
+type Loopback struct {
+	mu   sync.RWMutex
+	subs []*loopbackSub
+}
+
+type loopbackSub struct {
+	lo *Loopback
+	mu sync.Mutex
+	f  func([]byte)
+}
+
+func (s *loopbackSub) deliver(msg []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.f(msg)
+}
+
+func (s *loopbackSub) Close() error {
+	lo := s.lo
+	lo.mu.Lock()
+	var subs []*loopbackSub
+	for _, v := range lo.subs {
+		if v != s {
+			subs = append(subs, v)
+		}
+	}
+	lo.subs = subs
+	lo.mu.Unlock()
+	return nil
+}
+
+// NewLoopback returns a new Loopback without any subscribers.
+func NewLoopback() *Loopback {
+	return &Loopback{}
+}
+
+func (lo *Loopback) Publish(msg []byte) error {
+	lo.mu.RLock()
+	subs := lo.subs
+	lo.mu.RUnlock()
+	for _, v := range subs {
+		// Subscribers must not share the buffer
+		cpy := make([]byte, len(msg))
+		copy(cpy, msg)
+		v.deliver(cpy)
+	}
+	return nil
+}
+
+func (lo *Loopback) Subscribe(f func(msg []byte)) (io.Closer, error) {
+	sub := &loopbackSub{lo: lo, f: f}
+	lo.mu.Lock()
+	subs := make([]*loopbackSub, len(lo.subs), len(lo.subs)+1)
+	copy(subs, lo.subs)
+	lo.subs = append(subs, sub)
+	lo.mu.Unlock()
+	return sub, nil
+}
@@ -1,4 +1,15 @@
 // Package signal implements functions for emitting and receiving
 // signals on events. Gondola provides some builtin signals, but
 // users can define additional ones
+//
+// Listeners might be registered and removed at any time from any
+// goroutine. Signals are emitted either synchronously, using Emit,
+// or from a pool of worker goroutines, using EmitAsync. The Signal
+// type provides helper methods with typed listeners, which avoid
+// runtime checks and reflection.
+//
+// Selected signals might also be forwarded to other processes (e.g.
+// to propagate cache invalidations or configuration reloads across
+// all the instances of an app) by setting a Bridge. See Forward and
+// SetBridge for more details.
 package signal
@@ -0,0 +1,169 @@
+// Package redis implements a signal.Bridge using redis pub/sub.
+//
+// A Bridge is usually created from a redis cache, since its connection
+// pool can be shared, e.g.
+//
+//  b, err := redis.FromCache(ctx.Cache().Cache)
+//  if err != nil {
+//	panic(err)
+//  }
+//  signal.SetBridge(b)
+//  MyCacheInvalidated.Forward("")
+//
+// Where MyCacheInvalidated is a signal.Signal whose object is the
+// invalidated key.
+package redis
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gnd.la/cache"
+	"gnd.la/log"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+const (
+	// DefaultChannel is the redis channel used
+	// when no channel is provided to New.
+	DefaultChannel = "gnd.la/signal"
+	// ReconnectInterval is the time a subscription waits before
+	// reconnecting after its connection is closed or fails.
+	ReconnectInterval = time.Second
+)
+
+// Bridge implements signal.Bridge by publishing the
+// messages to a redis channel.
+type Bridge struct {
+	pool    *redis.Pool
+	channel string
+}
+
+// New returns a new Bridge which uses connections from the given
+// pool and publishes the messages to the given channel. If channel
+// is empty, DefaultChannel is used.
+func New(pool *redis.Pool, channel string) *Bridge {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &Bridge{pool: pool, channel: channel}
+}
+
+// FromCache returns a new Bridge which shares the connection pool
+// of the given cache, which must use the redis driver, and uses
+// DefaultChannel.
+func FromCache(c *cache.Cache) (*Bridge, error) {
+	pool, ok := c.Connection().(*redis.Pool)
+	if !ok {
+		return nil, fmt.Errorf("cache connection is %T, not a redis pool", c.Connection())
+	}
+	return New(pool, ""), nil
+}
+
+// Channel returns the redis channel used by the Bridge.
+func (b *Bridge) Channel() string {
+	return b.channel
+}
+
+func (b *Bridge) Publish(msg []byte) error {
+	conn := b.pool.Get()
+	_, err := conn.Do("PUBLISH", b.channel, msg)
+	conn.Close()
+	return err
+}
+
+// Subscribe subscribes to the Bridge channel using a dedicated connection
+// from the pool. If the connection fails, the subscription reconnects
+// after ReconnectInterval. Messages published while disconnected are lost.
+func (b *Bridge) Subscribe(f func(msg []byte)) (io.Closer, error) {
+	psc, err := b.subscribe()
+	if err != nil {
+		return nil, err
+	}
+	s := &subscription{bridge: b, f: f, psc: psc}
+	go s.run()
+	return s, nil
+}
+
+func (b *Bridge) subscribe() (*redis.PubSubConn, error) {
+	psc := &redis.PubSubConn{Conn: b.pool.Get()}
+	if err := psc.Subscribe(b.channel); err != nil {
+		psc.Close()
+		return nil, err
+	}
+	return psc, nil
+}
+
+type subscription struct {
+	bridge *Bridge
+	f      func([]byte)
+	mu     sync.Mutex
+	psc    *redis.PubSubConn
+	closed bool
+}
+
+func (s *subscription) run() {
+	for {
+		s.mu.Lock()
+		psc := s.psc
+		s.mu.Unlock()
+		if psc == nil {
+			if !s.reconnect() {
+				return
+			}
+			continue
+		}
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			s.f(v.Data)
+		case error:
+			s.mu.Lock()
+			closed := s.closed
+			s.psc = nil
+			s.mu.Unlock()
+			psc.Close()
+			if closed {
+				return
+			}
+			log.Errorf("error receiving from redis channel %s: %s", s.bridge.channel, v)
+		}
+	}
+}
+
+// reconnect waits for ReconnectInterval and then tries to subscribe again.
+// It returns false if the subscription was closed.
+func (s *subscription) reconnect() bool {
+	time.Sleep(ReconnectInterval)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	psc, err := s.bridge.subscribe()
+	if err != nil {
+		log.Errorf("error subscribing to redis channel %s: %s", s.bridge.channel, err)
+		return true
+	}
+	s.psc = psc
+	return true
+}
+
+// Close unsubscribes from the channel and returns
+// the connection to the pool.
+func (s *subscription) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.psc != nil {
+		// Closing the connection makes Receive return
+		// an error, which stops the goroutine.
+		return s.psc.Close()
+	}
+	return nil
+}
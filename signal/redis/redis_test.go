@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"gnd.la/util/stringutil"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func newTestBridge(t *testing.T) *Bridge {
+	conn, err := net.Dial("tcp", "localhost:6379")
+	if err != nil {
+		t.Skip("redis is not running. start redis on localhost to run this test")
+	}
+	conn.Close()
+	pool := &redis.Pool{
+		MaxIdle: 2,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", "localhost:6379")
+		},
+	}
+	// Use a random channel, so concurrent runs don't
+	// receive each other's messages.
+	return New(pool, DefaultChannel+"-test-"+stringutil.Random(8))
+}
+
+// receive publishes msg until it's received from ch, since the
+// subscription might not be active yet when Subscribe returns.
+// Note that msg might be received several times.
+func receive(t *testing.T, b *Bridge, ch chan []byte, msg string) {
+	timeout := time.After(5 * time.Second)
+	for {
+		if err := b.Publish([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case data := <-ch:
+			// Ignore the messages from previous calls
+			if string(data) == msg {
+				return
+			}
+		case <-time.After(50 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("message %q was not received", msg)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	b := newTestBridge(t)
+	defer b.pool.Close()
+	ch1 := make(chan []byte, 100)
+	ch2 := make(chan []byte, 100)
+	sub1, err := b.Subscribe(func(msg []byte) { ch1 <- msg })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub1.Close()
+	sub2, err := b.Subscribe(func(msg []byte) { ch2 <- msg })
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, b, ch1, "first")
+	receive(t, b, ch2, "first")
+	// Closing a subscription stops its messages, but
+	// not the ones for the rest of the subscribers.
+	if err := sub2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sub2.Close(); err != nil {
+		t.Errorf("closing a subscription twice returned an error: %s", err)
+	}
+	receive(t, b, ch1, "second")
+	time.Sleep(100 * time.Millisecond)
+	for len(ch2) > 0 {
+		if msg := string(<-ch2); msg == "second" {
+			t.Fatalf("received %q after closing the subscription", msg)
+		}
+	}
+}
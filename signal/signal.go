@@ -4,17 +4,48 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 
 	"gnd.la/internal/runtimeutil"
 	"gnd.la/log"
+	"gnd.la/util/stringutil"
 )
 
 var (
-	signals = map[string][]*reflect.Value{}
+	defaultRegistry = NewRegistry()
+	errEmptyName    = errors.New("signal name can't be empty")
 )
 
+// Func is the signature of the listeners which receive both
+// the signal name and its object.
+type Func func(name string, object interface{})
+
 type Token struct {
-	val *reflect.Value
+	f Func
+}
+
+// Registry holds a set of listeners. It's safe to use a Registry
+// from multiple goroutines, including registering and removing
+// listeners from a listener itself. Most users should just use
+// the package level functions, which operate on the default
+// Registry. Additional registries are mostly useful in tests,
+// e.g. for simulating several processes connected by a Bridge.
+type Registry struct {
+	mu sync.RWMutex
+	// Slices in signals are never modified in place, so
+	// they can be iterated without holding the lock.
+	signals map[string][]*Token
+	id      string
+	async   asyncPool
+	bridge  bridgeState
+}
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		signals: make(map[string][]*Token),
+		id:      stringutil.Random(16),
+	}
 }
 
 // Listen adds a new listener for the given signal name. The
@@ -31,24 +62,35 @@ type Token struct {
 // returned value is the token, which is required to unregister this
 // listener. If you don't need to unregister it, you can safely ignore
 // the first returned value.
-func Listen(name string, f interface{}) *Token {
-	tok, err := listen(name, f)
+//
+// Functions with an unnamed type (e.g. function literals) or of type
+// Func are called directly, the rest of them are called using reflection.
+func (r *Registry) Listen(name string, f interface{}) *Token {
+	fn, err := makeFunc(f)
 	if err != nil {
 		panic(err)
 	}
-	return tok
+	return r.ListenFunc(name, fn)
 }
 
-func listen(name string, f interface{}) (*Token, error) {
+// ListenFunc works like Listen, but accepts only a Func. Since its
+// type is checked at compile time, it never panics unless the name
+// is empty.
+func (r *Registry) ListenFunc(name string, f Func) *Token {
 	if name == "" {
-		return nil, errors.New("signal name can't be empty")
+		panic(errEmptyName)
 	}
-	val := reflect.ValueOf(f)
-	if err := checkListener(val); err != nil {
-		return nil, err
+	if f == nil {
+		panic(errors.New("listener can't be nil"))
 	}
-	signals[name] = append(signals[name], &val)
-	return &Token{&val}, nil
+	tok := &Token{f: f}
+	r.mu.Lock()
+	rec := r.signals[name]
+	added := make([]*Token, len(rec), len(rec)+1)
+	copy(added, rec)
+	r.signals[name] = append(added, tok)
+	r.mu.Unlock()
+	return tok
 }
 
 // Stop removes a listener, previously registered using Listen. The
@@ -56,53 +98,111 @@ func listen(name string, f interface{}) (*Token, error) {
 // be removed for all the signal. The second argument is the token returned by
 // Listen(). If it's empty, all the listeners for the given signals will be
 // removed.
-func Stop(name string, t *Token) {
+func (r *Registry) Stop(name string, t *Token) {
+	r.mu.Lock()
 	if name == "" {
-		for k := range signals {
-			removeToken(signals, k, t)
+		for k := range r.signals {
+			r.removeToken(k, t)
 		}
 	} else {
-		removeToken(signals, name, t)
+		r.removeToken(name, t)
 	}
+	r.mu.Unlock()
 }
 
-// Emit calls all the listeners for the given signal.
-func Emit(name string, object interface{}) {
+// Emit calls all the listeners for the given signal, in the same goroutine
+// and in the order they were registered. If the signal is forwarded (see
+// Forward), it's also published to the Bridge. To call the listeners
+// without blocking the caller, see EmitAsync.
+func (r *Registry) Emit(name string, object interface{}) {
 	log.Debugf("Emitting signal %s with %T object", name, object)
-	if rec := signals[name]; rec != nil {
-		params := []reflect.Value{reflect.ValueOf(name), reflect.ValueOf(object)}
-		for _, v := range rec {
-			v.Call(params[:v.Type().NumIn()])
+	r.emit(name, object)
+	r.publish(name, object)
+}
+
+func (r *Registry) emit(name string, object interface{}) {
+	r.mu.RLock()
+	rec := r.signals[name]
+	r.mu.RUnlock()
+	for _, v := range rec {
+		v.f(name, object)
+	}
+}
+
+// removeToken must be called with r.mu held.
+func (r *Registry) removeToken(name string, t *Token) {
+	rec := r.signals[name]
+	if rec == nil {
+		return
+	}
+	if t == nil {
+		delete(r.signals, name)
+		return
+	}
+	var kept []*Token
+	for _, v := range rec {
+		if v != t {
+			kept = append(kept, v)
 		}
 	}
+	if len(kept) == 0 {
+		delete(r.signals, name)
+	} else {
+		r.signals[name] = kept
+	}
+}
+
+// Listen calls Listen on the default Registry.
+func Listen(name string, f interface{}) *Token {
+	return defaultRegistry.Listen(name, f)
+}
+
+// ListenFunc calls ListenFunc on the default Registry.
+func ListenFunc(name string, f Func) *Token {
+	return defaultRegistry.ListenFunc(name, f)
+}
+
+// Stop calls Stop on the default Registry.
+func Stop(name string, t *Token) {
+	defaultRegistry.Stop(name, t)
 }
 
-func removeToken(s map[string][]*reflect.Value, name string, t *Token) {
-	rec := s[name]
-	if rec != nil {
-		if t == nil {
-			delete(s, name)
-			return
+// Emit calls Emit on the default Registry.
+func Emit(name string, object interface{}) {
+	defaultRegistry.Emit(name, object)
+}
+
+// makeFunc returns a Func which calls the given listener, checking
+// its type first. Reflection is only used for the listeners with a
+// named type other than Func.
+func makeFunc(f interface{}) (Func, error) {
+	switch fn := f.(type) {
+	case Func:
+		if fn != nil {
+			return fn, nil
+		}
+	case func(string, interface{}):
+		if fn != nil {
+			return Func(fn), nil
 		}
-		r := t.val
-		for {
-			idx := -1
-			for ii, v := range rec {
-				if v == r {
-					idx = ii
-					break
-				}
-			}
-			if idx >= 0 {
-				rec[len(rec)-1], rec[idx], rec = nil, rec[len(rec)-1], rec[:len(rec)-1]
-				continue
-			}
-			break
+	case func(string):
+		if fn != nil {
+			return func(name string, _ interface{}) { fn(name) }, nil
 		}
-		if len(rec) == 0 {
-			delete(s, name)
+	case func():
+		if fn != nil {
+			return func(string, interface{}) { fn() }, nil
 		}
 	}
+	val := reflect.ValueOf(f)
+	if err := checkListener(val); err != nil {
+		return nil, err
+	}
+	numIn := val.Type().NumIn()
+	return func(name string, object interface{}) {
+		params := []reflect.Value{reflect.ValueOf(name), reflect.ValueOf(&object).Elem()}
+		val.Call(params[:numIn])
+	}, nil
 }
 
 func checkListener(val reflect.Value) error {
@@ -112,6 +212,9 @@ func checkListener(val reflect.Value) error {
 	if val.Kind() != reflect.Func {
 		return fmt.Errorf("listener is of type %s, not function", val.Type())
 	}
+	if val.IsNil() {
+		return errors.New("listener is a nil function")
+	}
 	vt := val.Type()
 	if vt.NumOut() > 0 {
 		return fmt.Errorf("listeners can't return arguments, %s returns %d", runtimeutil.FuncName(val.Interface()), vt.NumOut())
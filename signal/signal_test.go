@@ -0,0 +1,190 @@
+package signal
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gnd.la/log"
+)
+
+type namedListener func(string, interface{})
+
+func TestListeners(t *testing.T) {
+	r := NewRegistry()
+	var calls []string
+	r.Listen("foo", func() { calls = append(calls, "empty") })
+	r.Listen("foo", func(name string) { calls = append(calls, name) })
+	tok := r.Listen("foo", func(name string, obj interface{}) { calls = append(calls, obj.(string)) })
+	r.Listen("foo", namedListener(func(_ string, obj interface{}) { calls = append(calls, "named") }))
+	r.Emit("foo", "bar")
+	r.Stop("foo", tok)
+	r.Emit("foo", "baz")
+	r.Stop("", nil)
+	r.Emit("foo", "qux")
+	expect := []string{"empty", "foo", "bar", "named", "empty", "foo", "named"}
+	if len(calls) != len(expect) {
+		t.Fatalf("expecting calls %v, got %v", expect, calls)
+	}
+	for ii, v := range expect {
+		if calls[ii] != v {
+			t.Errorf("expecting call %d = %q, got %q", ii, v, calls[ii])
+		}
+	}
+}
+
+func TestInvalidListeners(t *testing.T) {
+	invalid := []interface{}{
+		nil,
+		1,
+		func(int) {},
+		func(string, int) {},
+		func() error { return nil },
+		func(string, interface{}, int) {},
+	}
+	for _, v := range invalid {
+		if _, err := makeFunc(v); err == nil {
+			t.Errorf("expecting an error for listener %T", v)
+		}
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	r := NewRegistry()
+	var count int64
+	var wg sync.WaitGroup
+	for ii := 0; ii < 10; ii++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tok := r.ListenFunc("concurrent", func(string, interface{}) {
+				atomic.AddInt64(&count, 1)
+			})
+			r.Stop("concurrent", tok)
+		}()
+		go func() {
+			defer wg.Done()
+			r.Emit("concurrent", nil)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestEmitAsync(t *testing.T) {
+	r := NewRegistry()
+	var count int64
+	r.ListenFunc("async", func(_ string, obj interface{}) {
+		atomic.AddInt64(&count, int64(obj.(int)))
+	})
+	r.Listen("async", func() { panic("recovered") })
+	// Don't print the recovered panics
+	defer log.Std.SetLevel(log.Std.Level())
+	log.Std.SetLevel(log.LNone)
+	for ii := 1; ii <= 100; ii++ {
+		r.EmitAsync("async", ii)
+	}
+	r.Wait()
+	if c := atomic.LoadInt64(&count); c != 5050 {
+		t.Errorf("expecting count = 5050, got %d", c)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	const sig Signal = "gnd.la/signal.test"
+	var received interface{}
+	tok := sig.Listen(func(obj interface{}) { received = obj })
+	defer sig.Stop(tok)
+	sig.Emit(42)
+	if received != 42 {
+		t.Errorf("expecting 42, got %v", received)
+	}
+}
+
+type reloaded struct {
+	Version int
+}
+
+func TestLoopback(t *testing.T) {
+	lo := NewLoopback()
+	r1 := NewRegistry()
+	r2 := NewRegistry()
+	for _, v := range []*Registry{r1, r2} {
+		if err := v.SetBridge(lo); err != nil {
+			t.Fatal(err)
+		}
+		v.Forward("reloaded", (*reloaded)(nil))
+	}
+	r2.Forward("invalidated", "")
+	var mu sync.Mutex
+	var calls1, calls2 []interface{}
+	r1.ListenFunc("reloaded", func(_ string, obj interface{}) {
+		mu.Lock()
+		calls1 = append(calls1, obj)
+		mu.Unlock()
+	})
+	r2.ListenFunc("reloaded", func(_ string, obj interface{}) {
+		mu.Lock()
+		calls2 = append(calls2, obj)
+		mu.Unlock()
+	})
+	r2.ListenFunc("invalidated", func(_ string, obj interface{}) {
+		mu.Lock()
+		calls2 = append(calls2, obj)
+		mu.Unlock()
+	})
+	r1.Emit("reloaded", &reloaded{Version: 2})
+	// Not forwarded by r1, so it's not published
+	r1.Emit("invalidated", "key")
+	r1.Wait()
+	r2.Wait()
+	if len(calls1) != 1 {
+		t.Errorf("expecting 1 call in the emitting registry, got %d", len(calls1))
+	}
+	if len(calls2) != 1 {
+		t.Fatalf("expecting 1 call in the receiving registry, got %v", calls2)
+	}
+	if r, ok := calls2[0].(*reloaded); !ok || r.Version != 2 {
+		t.Errorf("expecting *reloaded with Version = 2, got %#v", calls2[0])
+	}
+	// Disconnect r2
+	if err := r2.SetBridge(nil); err != nil {
+		t.Fatal(err)
+	}
+	r1.Emit("reloaded", &reloaded{Version: 3})
+	r1.Wait()
+	if len(calls2) != 1 {
+		t.Errorf("expecting no calls after disconnecting, got %v", calls2)
+	}
+}
+
+func TestEmitAsyncNested(t *testing.T) {
+	r := NewRegistry()
+	var count int64
+	r.Listen("outer", func() {
+		// Enough nested emissions for filling any
+		// bounded queue while the workers are busy
+		for ii := 0; ii < 100; ii++ {
+			r.EmitAsync("inner", nil)
+		}
+	})
+	r.Listen("inner", func() {
+		atomic.AddInt64(&count, 1)
+	})
+	done := make(chan struct{})
+	go func() {
+		for ii := 0; ii < 100; ii++ {
+			r.EmitAsync("outer", nil)
+		}
+		r.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("EmitAsync from an asynchronous listener blocked")
+	}
+	if c := atomic.LoadInt64(&count); c != 10000 {
+		t.Errorf("expecting count = 10000, got %d", c)
+	}
+}
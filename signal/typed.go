@@ -0,0 +1,49 @@
+package signal
+
+// Signal is a helper type for declaring signals. Its methods
+// accept listeners with a fixed signature, so they're checked
+// at compile time and called without reflection. e.g.
+//
+//  const ConfigReloaded signal.Signal = "myapp.config-reloaded"
+//
+//  ConfigReloaded.Listen(func(obj interface{}) {
+//	cfg := obj.(*Config)
+//	...
+//  })
+//  ConfigReloaded.Emit(cfg)
+//
+// A Signal always uses the default Registry.
+type Signal string
+
+// Name returns the signal name.
+func (s Signal) Name() string {
+	return string(s)
+}
+
+// Listen registers a listener which receives the signal object.
+func (s Signal) Listen(f func(object interface{})) *Token {
+	return ListenFunc(string(s), func(_ string, object interface{}) {
+		f(object)
+	})
+}
+
+// Stop removes a listener previously registered with Listen. If t
+// is nil, all the listeners for this signal are removed.
+func (s Signal) Stop(t *Token) {
+	Stop(string(s), t)
+}
+
+// Emit is a shorthand for Emit(s.Name(), object).
+func (s Signal) Emit(object interface{}) {
+	Emit(string(s), object)
+}
+
+// EmitAsync is a shorthand for EmitAsync(s.Name(), object).
+func (s Signal) EmitAsync(object interface{}) {
+	EmitAsync(string(s), object)
+}
+
+// Forward is a shorthand for Forward(s.Name(), proto).
+func (s Signal) Forward(proto interface{}) {
+	Forward(string(s), proto)
+}
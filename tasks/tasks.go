@@ -173,6 +173,7 @@ func executeTask(ctx *app.Context, task *Task) (ran bool, err error) {
 		return
 	}
 	started := time.Now()
+	ctx.AddLogField("task", task.Name())
 	ctx.Logger().Infof("Starting task %s (%d instances now running) at %v", task.Name(), n, started)
 	ran = true
 	defer afterTask(ctx, task, started, &err)